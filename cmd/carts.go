@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/northwindman/book-shop/internal/app/config"
+	"github.com/northwindman/book-shop/internal/app/repository/pgrepo"
+	"github.com/northwindman/book-shop/internal/pkg/pg"
+)
+
+// cartsCommand runs the carts subcommands
+func cartsCommand(ctx context.Context, cfg config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "expire-now" {
+		return errors.New("carts: expected expire-now")
+	}
+
+	pgDB, err := pg.Dial(cfg.DSN)
+	if err != nil {
+		return fmt.Errorf("pg.Dial failed: %w", err)
+	}
+	defer pgDB.Close()
+
+	// a zero TTL treats every cart as expired
+	released, err := pgrepo.NewCartRepo(pgDB).CleanExpiredCarts(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Expired %d cart(s)", released)
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/northwindman/book-shop/internal/app/config"
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/app/repository/pgrepo"
+	"github.com/northwindman/book-shop/internal/app/services"
+	"github.com/northwindman/book-shop/internal/pkg/pg"
+)
+
+// inventoryCommand runs the inventory subcommands
+func inventoryCommand(ctx context.Context, cfg config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "reconcile" {
+		return errors.New("inventory: expected reconcile")
+	}
+
+	fs := flag.NewFlagSet("inventory reconcile", flag.ExitOnError)
+	repair := fs.Bool("repair", false, "correct the drift through the inventory ledger")
+	_ = fs.Parse(args[1:])
+
+	pgDB, err := pg.Dial(cfg.DSN)
+	if err != nil {
+		return fmt.Errorf("pg.Dial failed: %w", err)
+	}
+	defer pgDB.Close()
+
+	inventoryService := services.NewInventoryService(pgrepo.NewInventoryRepo(pgDB))
+	drifted, repairs, err := inventoryService.Reconcile(ctx, *repair)
+	if err != nil {
+		return err
+	}
+
+	for _, count := range drifted {
+		fmt.Println(formatStockDrift(count))
+	}
+	for _, movement := range repairs {
+		fmt.Printf("book %d: stock set to %d (%+d)\n", movement.BookID(), movement.StockAfter(), movement.Delta())
+	}
+
+	log.Printf("Found %d book(s) with stock drift, repaired %d", len(drifted), len(repairs))
+
+	return nil
+}
+
+// formatStockDrift describes the stock drift of a book
+func formatStockDrift(count domain.StockCount) string {
+	return fmt.Sprintf("book %d %q: stock %d, expected %d (drift %+d; received %d, sold %d, reserved %d)",
+		count.BookID, count.Title, count.Stock, count.Expected(), count.Drift(), count.Received, count.Sold, count.Reserved)
+}
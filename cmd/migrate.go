@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+
+	"github.com/northwindman/book-shop/internal/app/config"
+)
+
+// migrateCommand runs the migrate subcommands
+func migrateCommand(cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("migrate: expected one of up, down, version, force")
+	}
+
+	switch args[0] {
+	case "up":
+		if err := runPgMigrations(cfg.DSN, cfg.MigrationsPath); err != nil {
+			return fmt.Errorf("runPgMigrations failed: %w", err)
+		}
+		log.Println("Migrations applied")
+		return nil
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("migrate down: invalid number of steps %q", args[1])
+			}
+			steps = n
+		}
+		return withMigrate(cfg, func(m *migrate.Migrate) error {
+			if err := m.Steps(-steps); err != nil && err != migrate.ErrNoChange {
+				return err
+			}
+			log.Printf("Rolled back %d migration(s)", steps)
+			return nil
+		})
+	case "version":
+		return withMigrate(cfg, func(m *migrate.Migrate) error {
+			version, dirty, err := m.Version()
+			if err != nil {
+				if errors.Is(err, migrate.ErrNilVersion) {
+					fmt.Println("no migrations applied")
+					return nil
+				}
+				return err
+			}
+			fmt.Printf("version: %d, dirty: %t\n", version, dirty)
+			return nil
+		})
+	case "force":
+		if len(args) < 2 {
+			return errors.New("migrate force: version is required")
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("migrate force: invalid version %q", args[1])
+		}
+		return withMigrate(cfg, func(m *migrate.Migrate) error {
+			if err := m.Force(version); err != nil {
+				return err
+			}
+			log.Printf("Forced migration version to %d", version)
+			return nil
+		})
+	default:
+		return fmt.Errorf("migrate: unknown subcommand %q", args[0])
+	}
+}
+
+// runPgMigrations runs Postgres migrations
+func runPgMigrations(dsn, path string) error {
+	m, err := newPgMigrate(dsn, path)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		return err
+	}
+
+	return nil
+}
+
+// withMigrate creates a migrate instance for the config, passes it to fn and closes it afterwards
+func withMigrate(cfg config.Config, fn func(m *migrate.Migrate) error) error {
+	m, err := newPgMigrate(cfg.DSN, cfg.MigrationsPath)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	return fn(m)
+}
+
+func newPgMigrate(dsn, path string) (*migrate.Migrate, error) {
+	if path == "" {
+		return nil, errors.New("no migrations path provided")
+	}
+	if dsn == "" {
+		return nil, errors.New("no DSN provided")
+	}
+
+	return migrate.New(
+		path,
+		dsn,
+	)
+}
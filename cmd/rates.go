@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/northwindman/book-shop/internal/app/config"
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/app/repository/pgrepo"
+	"github.com/northwindman/book-shop/internal/pkg/pg"
+)
+
+// ratesCommand runs the exchange rates subcommands
+func ratesCommand(ctx context.Context, cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("rates: expected one of list, load")
+	}
+
+	switch args[0] {
+	case "list":
+		return listRates(ctx, cfg)
+	case "load":
+		return loadRates(ctx, cfg, args[1:])
+	default:
+		return fmt.Errorf("rates: unknown subcommand %q", args[0])
+	}
+}
+
+func listRates(ctx context.Context, cfg config.Config) error {
+	pgDB, err := pg.Dial(cfg.DSN)
+	if err != nil {
+		return fmt.Errorf("pg.Dial failed: %w", err)
+	}
+	defer pgDB.Close()
+
+	rates, err := pgrepo.NewExchangeRateRepo(pgDB).GetRates(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, rate := range rates {
+		fmt.Printf("1 %s = %s %s (updated %s)\n", domain.SettlementCurrency, rate.Rate(), rate.Currency(),
+			rate.UpdatedAt().Format("2006-01-02 15:04"))
+	}
+
+	return nil
+}
+
+// loadRates sets the exchange rates of a JSON file mapping currencies to rates, e.g. {"EUR": "0.92"}.
+// The file is validated completely before any rate is written.
+func loadRates(ctx context.Context, cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("rates load", flag.ExitOnError)
+	file := fs.String("file", "", "JSON file mapping currencies to rates per 1 "+domain.SettlementCurrency)
+	_ = fs.Parse(args)
+
+	if *file == "" {
+		return fmt.Errorf("rates load: %w: file", domain.ErrRequired)
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return fmt.Errorf("failed to read rates file: %w", err)
+	}
+
+	var fileRates map[string]json.Number
+	if err := json.Unmarshal(data, &fileRates); err != nil {
+		return fmt.Errorf("failed to parse rates file: %w", err)
+	}
+
+	rates := make([]domain.ExchangeRate, 0, len(fileRates))
+	for currency, value := range fileRates {
+		rate, err := domain.NewExchangeRate(domain.NewExchangeRateData{
+			Currency: currency,
+			Rate:     value.String(),
+		})
+		if err != nil {
+			return fmt.Errorf("invalid rate of %q: %w", currency, err)
+		}
+		rates = append(rates, rate)
+	}
+
+	pgDB, err := pg.Dial(cfg.DSN)
+	if err != nil {
+		return fmt.Errorf("pg.Dial failed: %w", err)
+	}
+	defer pgDB.Close()
+
+	savedRates, err := pgrepo.NewExchangeRateRepo(pgDB).SetRates(ctx, rates...)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Loaded %d exchange rate(s)", len(savedRates))
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand/v2"
+
+	"github.com/northwindman/book-shop/internal/app/config"
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/app/repository/pgrepo"
+	"github.com/northwindman/book-shop/internal/pkg/pg"
+)
+
+const demoCategoryName = "Demo"
+
+var (
+	demoAdjectives = []string{"Silent", "Hidden", "Last", "Crimson", "Forgotten", "Broken", "Golden", "Endless"}
+	demoNouns      = []string{"Garden", "Kingdom", "River", "Letter", "Voyage", "Mirror", "Harbor", "Orchard"}
+	demoAuthors    = []string{"Ada Lovelace", "Alan Turing", "Grace Hopper", "Edsger Dijkstra", "Barbara Liskov"}
+)
+
+// seedCommand inserts demo books into the database
+func seedCommand(ctx context.Context, cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	books := fs.Int("books", 20, "number of demo books to insert")
+	_ = fs.Parse(args)
+
+	if *books <= 0 {
+		return fmt.Errorf("seed: %w: books", domain.ErrNotPositive)
+	}
+
+	pgDB, err := pg.Dial(cfg.DSN)
+	if err != nil {
+		return fmt.Errorf("pg.Dial failed: %w", err)
+	}
+	defer pgDB.Close()
+
+	categoryID, err := demoCategoryID(ctx, pgrepo.NewCategoryRepo(pgDB))
+	if err != nil {
+		return err
+	}
+
+	bookRepo := pgrepo.NewBookRepo(pgDB)
+	for i := 0; i < *books; i++ {
+		price, err := domain.NewMoney(int64(500+rand.IntN(4500)), domain.SettlementCurrency)
+		if err != nil {
+			return fmt.Errorf("failed to create demo price: %w", err)
+		}
+
+		book, err := domain.NewBook(domain.NewBookData{
+			Title: fmt.Sprintf("The %s %s", demoAdjectives[rand.IntN(len(demoAdjectives))],
+				demoNouns[rand.IntN(len(demoNouns))]),
+			Year:        1950 + rand.IntN(75),
+			Author:      demoAuthors[rand.IntN(len(demoAuthors))],
+			Price:       price,
+			Stock:       1 + rand.IntN(20),
+			CategoryID:  categoryID,
+			WeightGrams: 200 + rand.IntN(800),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create domain book: %w", err)
+		}
+
+		if _, err := bookRepo.CreateBook(ctx, book); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Inserted %d demo books", *books)
+
+	return nil
+}
+
+// demoCategoryID returns the ID of the demo category, creating it when it does not exist yet
+func demoCategoryID(ctx context.Context, repo *pgrepo.CategoryRepo) (int, error) {
+	categories, err := repo.GetCategories(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, category := range categories {
+		if category.Name() == demoCategoryName {
+			return category.ID(), nil
+		}
+	}
+
+	category, err := domain.NewCategory(domain.NewCategoryData{Name: demoCategoryName})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create domain category: %w", err)
+	}
+
+	insertedCategory, err := repo.CreateCategory(ctx, category)
+	if err != nil {
+		return 0, err
+	}
+
+	return insertedCategory.ID(), nil
+}
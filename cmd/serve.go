@@ -0,0 +1,381 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/northwindman/book-shop/internal/app/config"
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/app/events"
+	"github.com/northwindman/book-shop/internal/app/notify"
+	"github.com/northwindman/book-shop/internal/app/repository/pgrepo"
+	"github.com/northwindman/book-shop/internal/app/services"
+	"github.com/northwindman/book-shop/internal/app/transport/httpserver"
+	"github.com/northwindman/book-shop/internal/app/webhooks"
+	"github.com/northwindman/book-shop/internal/pkg/pg"
+	"github.com/northwindman/book-shop/internal/pkg/scheduler"
+)
+
+const tokenTTL = time.Minute * 5
+
+// serve runs migrations and starts the HTTP server
+func serve(cfg config.Config) error {
+	pgDB, err := pg.Dial(cfg.DSN)
+	if err != nil {
+		return fmt.Errorf("pg.Dial failed: %w", err)
+	}
+
+	// run Postgres migrations
+	if pgDB != nil {
+		log.Println("Running PostgreSQL migrations")
+		if err := runPgMigrations(cfg.DSN, cfg.MigrationsPath); err != nil {
+			return fmt.Errorf("runPgMigrations failed: %w", err)
+		}
+	}
+
+	// create repositories
+	userRepo := pgrepo.NewUserRepo(pgDB)
+	bookRepo := pgrepo.NewBookRepo(pgDB)
+	categoryRepo := pgrepo.NewCategoryRepo(pgDB)
+	cartRepo := pgrepo.NewCartRepo(pgDB)
+	orderRepo := pgrepo.NewOrderRepo(pgDB)
+	exchangeRateRepo := pgrepo.NewExchangeRateRepo(pgDB)
+	promotionRepo := pgrepo.NewPromotionRepo(pgDB)
+	taxRateRepo := pgrepo.NewTaxRateRepo(pgDB)
+	addressRepo := pgrepo.NewAddressRepo(pgDB)
+	shippingRateRepo := pgrepo.NewShippingRateRepo(pgDB)
+	idempotencyRepo := pgrepo.NewIdempotencyRepo(pgDB)
+	inventoryRepo := pgrepo.NewInventoryRepo(pgDB)
+	stockSubscriptionRepo := pgrepo.NewStockSubscriptionRepo(pgDB)
+	wishlistRepo := pgrepo.NewWishlistRepo(pgDB)
+	reviewRepo := pgrepo.NewReviewRepo(pgDB)
+	recommendationRepo := pgrepo.NewRecommendationRepo(pgDB)
+	authorRepo := pgrepo.NewAuthorRepo(pgDB)
+	outboxRepo := pgrepo.NewOutboxRepo(pgDB)
+	webhookRepo := pgrepo.NewWebhookRepo(pgDB)
+
+	userService := services.NewUserService(userRepo)
+	bookService := services.NewBookService(bookRepo)
+	categoryService := services.NewCategoryService(categoryRepo)
+	tokenService := services.NewTokenService(tokenTTL)
+	cartService := services.NewCartService(cartRepo, bookRepo, promotionRepo, addressRepo, shippingRateRepo,
+		services.NewTableTaxCalculator(taxRateRepo))
+	orderService := services.NewOrderService(orderRepo)
+	exchangeRateService := services.NewExchangeRateService(exchangeRateRepo)
+	promotionService := services.NewPromotionService(promotionRepo)
+	taxService := services.NewTaxService(taxRateRepo)
+	addressService := services.NewAddressService(addressRepo)
+	shippingService := services.NewShippingService(shippingRateRepo)
+	webhookService := services.NewWebhookService(webhookRepo)
+	idempotencyService := services.NewIdempotencyService(idempotencyRepo)
+	inventoryService := services.NewInventoryService(inventoryRepo)
+	stockSubscriptionService := services.NewStockSubscriptionService(stockSubscriptionRepo)
+	wishlistService := services.NewWishlistService(wishlistRepo, cartRepo)
+	reviewService := services.NewReviewService(reviewRepo)
+	recommendationService := services.NewRecommendationService(recommendationRepo, cartRepo)
+	authorService := services.NewAuthorService(authorRepo)
+
+	// register background jobs, exclusive jobs run on a single replica at a time and once per interval across replicas
+	jobs := scheduler.New(pg.NewAdvisoryLocker(pgDB), pgrepo.NewJobRunRepo(pgDB))
+	jobs.Register(scheduler.Job{
+		Name:      "clean-expired-carts",
+		Interval:  time.Minute,
+		Jitter:    10 * time.Second,
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			released, err := cartRepo.CleanExpiredCarts(ctx, time.Minute)
+			if err != nil {
+				return fmt.Errorf("cartRepo.CleanExpiredCarts failed: %w", err)
+			}
+			if released > 0 {
+				log.Printf("Released %d expired cart(s)", released)
+			}
+			return nil
+		},
+	})
+
+	jobs.Register(scheduler.Job{
+		Name:      "purge-idempotency-keys",
+		Interval:  time.Hour,
+		Jitter:    time.Minute,
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			deleted, err := idempotencyRepo.DeleteExpired(ctx, domain.IdempotencyKeyTTL)
+			if err != nil {
+				return fmt.Errorf("idempotencyRepo.DeleteExpired failed: %w", err)
+			}
+			if deleted > 0 {
+				log.Printf("Purged %d expired idempotency key(s)", deleted)
+			}
+			return nil
+		},
+	})
+
+	// report stock drift, and correct it when INVENTORY_AUTO_REPAIR is set
+	jobs.Register(scheduler.Job{
+		Name:      "reconcile-inventory",
+		Interval:  time.Hour,
+		Jitter:    5 * time.Minute,
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			drifted, repairs, err := inventoryService.Reconcile(ctx, cfg.InventoryAutoRepair)
+			if err != nil {
+				return fmt.Errorf("inventoryService.Reconcile failed: %w", err)
+			}
+			for _, count := range drifted {
+				log.Printf("Stock drift of %s", formatStockDrift(count))
+			}
+			if len(repairs) > 0 {
+				log.Printf("Repaired the stock of %d book(s)", len(repairs))
+			}
+			return nil
+		},
+	})
+
+	// alert the staff of books at or below their reorder threshold
+	notifier := notify.NewMulti(notify.NewLogNotifier(), notify.NewWebhookNotifier(outboxRepo),
+		notify.NewMailNotifier(notify.NewDirMailer(cfg.MailDir), cfg.MailFrom, cfg.StaffEmails))
+	jobs.Register(scheduler.Job{
+		Name:      "alert-low-stock",
+		Interval:  24 * time.Hour,
+		Jitter:    10 * time.Minute,
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			sent, err := inventoryService.AlertLowStock(ctx, notifier)
+			if sent > 0 {
+				log.Printf("Sent %d low stock alert(s)", sent)
+			}
+			if err != nil {
+				return fmt.Errorf("inventoryService.AlertLowStock failed: %w", err)
+			}
+			return nil
+		},
+	})
+
+	// notify subscribers of books back in stock, first come first served and rate limited per user. Customers are
+	// only mailed, a notice is marked notified once sent so it must not go through channels that fail separately.
+	customerNotifier := notify.NewMulti(notify.NewLogNotifier(),
+		notify.NewMailNotifier(notify.NewDirMailer(cfg.MailDir), cfg.MailFrom, nil))
+	jobs.Register(scheduler.Job{
+		Name:      "notify-back-in-stock",
+		Interval:  time.Minute,
+		Jitter:    10 * time.Second,
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			sent, err := stockSubscriptionService.NotifyBackInStock(ctx, customerNotifier)
+			if sent > 0 {
+				log.Printf("Sent %d back in stock notification(s)", sent)
+			}
+			if err != nil {
+				return fmt.Errorf("stockSubscriptionService.NotifyBackInStock failed: %w", err)
+			}
+			return nil
+		},
+	})
+
+	// recompute the "customers also bought" recommendations from the order history
+	jobs.Register(scheduler.Job{
+		Name:      "compute-recommendations",
+		Interval:  time.Hour,
+		Jitter:    5 * time.Minute,
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			stored, err := recommendationService.ComputeRecommendations(ctx)
+			if err != nil {
+				return fmt.Errorf("recommendationService.ComputeRecommendations failed: %w", err)
+			}
+			log.Printf("Computed %d book recommendation(s)", stored)
+			return nil
+		},
+	})
+
+	// relay domain events from the outbox to the configured sinks
+	sinks := []events.Sink{events.NewLogSink(), webhooks.NewSink(webhookRepo)}
+	if cfg.OutboxFilePath != "" {
+		sinks = append(sinks, events.NewFileSink(cfg.OutboxFilePath))
+	}
+	relay := events.NewRelay(outboxRepo, sinks...)
+	jobs.Register(scheduler.Job{
+		Name:      "relay-outbox",
+		Interval:  5 * time.Second,
+		Jitter:    time.Second,
+		Exclusive: true,
+		Run:       relay.Run,
+	})
+
+	// send webhook deliveries, failed deliveries are retried with exponential backoff
+	jobs.Register(scheduler.Job{
+		Name:      "dispatch-webhooks",
+		Interval:  5 * time.Second,
+		Jitter:    time.Second,
+		Exclusive: true,
+		Run:       webhooks.NewDispatcher(webhookRepo).Run,
+	})
+
+	// create http server with application injected
+	httpServer := httpserver.NewHttpServer(httpserver.Services{
+		UserService:              userService,
+		TokenService:             tokenService,
+		BookService:              bookService,
+		CategoryService:          categoryService,
+		CartService:              cartService,
+		OrderService:             orderService,
+		AddressService:           addressService,
+		PromotionService:         promotionService,
+		TaxService:               taxService,
+		ShippingService:          shippingService,
+		ExchangeRateService:      exchangeRateService,
+		JobService:               jobs,
+		WebhookService:           webhookService,
+		IdempotencyService:       idempotencyService,
+		InventoryService:         inventoryService,
+		StockSubscriptionService: stockSubscriptionService,
+		WishlistService:          wishlistService,
+		ReviewService:            reviewService,
+		RecommendationService:    recommendationService,
+		AuthorService:            authorService,
+	})
+
+	// create http router
+	router := mux.NewRouter()
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("book-shop API v0.1"))
+	}).Methods("GET")
+
+	router.HandleFunc("/signup", httpServer.SignUp).Methods(http.MethodPost)
+	router.HandleFunc("/signin", httpServer.SignIn).Methods(http.MethodPost)
+
+	router.HandleFunc("/books", httpServer.GetBooks).Methods(http.MethodGet)
+	router.HandleFunc("/book/{book_id}", httpServer.GetBook).Methods(http.MethodGet)
+	router.HandleFunc("/book/isbn/{isbn}", httpServer.GetBookByISBN).Methods(http.MethodGet)
+	router.HandleFunc("/book", httpServer.CheckAdmin(httpServer.CreateBook)).Methods(http.MethodPost)
+	router.HandleFunc("/book/{book_id}", httpServer.CheckAdmin(httpServer.UpdateBook)).Methods(http.MethodPatch)
+	router.HandleFunc("/book/{book_id}", httpServer.CheckAdmin(httpServer.DeleteBook)).Methods(http.MethodDelete)
+	router.HandleFunc("/book/{book_id}/stock-adjustments", httpServer.CheckAdmin(httpServer.AdjustStock)).Methods(http.MethodPost)
+	router.HandleFunc("/book/{book_id}/stock-movements", httpServer.CheckAdmin(httpServer.GetStockMovements)).Methods(http.MethodGet)
+	router.HandleFunc("/book/{book_id}/notify-me", httpServer.CheckAuthorizedUser(httpServer.SubscribeBackInStock)).Methods(http.MethodPost)
+	router.HandleFunc("/book/{book_id}/notify-me", httpServer.CheckAuthorizedUser(httpServer.UnsubscribeBackInStock)).Methods(http.MethodDelete)
+	router.HandleFunc("/book/{book_id}/reviews", httpServer.GetBookReviews).Methods(http.MethodGet)
+	router.HandleFunc("/book/{book_id}/recommendations", httpServer.OptionalUser(httpServer.GetRecommendations)).Methods(http.MethodGet)
+	router.HandleFunc("/book/{book_id}/reviews", httpServer.CheckAuthorizedUser(httpServer.CreateReview)).Methods(http.MethodPost)
+
+	router.HandleFunc("/authors", httpServer.GetAuthors).Methods(http.MethodGet)
+	router.HandleFunc("/author/{author_id}", httpServer.GetAuthor).Methods(http.MethodGet)
+	router.HandleFunc("/author", httpServer.CheckAdmin(httpServer.CreateAuthor)).Methods(http.MethodPost)
+	router.HandleFunc("/author/{author_id}", httpServer.CheckAdmin(httpServer.UpdateAuthor)).Methods(http.MethodPatch)
+	router.HandleFunc("/author/{author_id}", httpServer.CheckAdmin(httpServer.DeleteAuthor)).Methods(http.MethodDelete)
+
+	router.HandleFunc("/categories", httpServer.GetCategories).Methods(http.MethodGet)
+	router.HandleFunc("/category/{category_id}", httpServer.GetCategory).Methods(http.MethodGet)
+	router.HandleFunc("/category", httpServer.CheckAdmin(httpServer.CreateCategory)).Methods(http.MethodPost)
+	router.HandleFunc("/category/{category_id}", httpServer.CheckAdmin(httpServer.UpdateCategory)).Methods(http.MethodPatch)
+	router.HandleFunc("/category/{category_id}", httpServer.CheckAdmin(httpServer.DeleteCategory)).Methods(http.MethodDelete)
+
+	router.HandleFunc("/cart", httpServer.CheckAuthorizedUser(httpServer.GetCart)).Methods(http.MethodGet)
+	router.HandleFunc("/cart", httpServer.CheckAuthorizedUser(httpServer.Idempotent(httpServer.UpdateCart))).Methods(http.MethodPost)
+	router.HandleFunc("/cart/coupon", httpServer.CheckAuthorizedUser(httpServer.Idempotent(httpServer.ApplyCoupon))).Methods(http.MethodPost)
+	router.HandleFunc("/cart/coupon", httpServer.CheckAuthorizedUser(httpServer.Idempotent(httpServer.RemoveCoupon))).Methods(http.MethodDelete)
+	router.HandleFunc("/cart/shipping-options", httpServer.CheckAuthorizedUser(httpServer.GetShippingOptions)).Methods(http.MethodGet)
+	router.HandleFunc("/checkout", httpServer.CheckAuthorizedUser(httpServer.Idempotent(httpServer.Checkout))).Methods(http.MethodPost)
+
+	router.HandleFunc("/orders", httpServer.CheckAuthorizedUser(httpServer.GetOrders)).Methods(http.MethodGet)
+	router.HandleFunc("/order/{order_id}", httpServer.CheckAuthorizedUser(httpServer.GetOrder)).Methods(http.MethodGet)
+
+	router.HandleFunc("/me/wishlist", httpServer.CheckAuthorizedUser(httpServer.GetWishlist)).Methods(http.MethodGet)
+	router.HandleFunc("/me/wishlist/items", httpServer.CheckAuthorizedUser(httpServer.AddWishlistItem)).Methods(http.MethodPost)
+	router.HandleFunc("/me/wishlist/items/{book_id}", httpServer.CheckAuthorizedUser(httpServer.RemoveWishlistItem)).Methods(http.MethodDelete)
+	router.HandleFunc("/me/wishlist/move-to-cart", httpServer.CheckAuthorizedUser(httpServer.Idempotent(httpServer.MoveWishlistToCart))).Methods(http.MethodPost)
+	router.HandleFunc("/me/wishlist/share", httpServer.CheckAuthorizedUser(httpServer.ShareWishlist)).Methods(http.MethodPost)
+	router.HandleFunc("/me/wishlist/share", httpServer.CheckAuthorizedUser(httpServer.UnshareWishlist)).Methods(http.MethodDelete)
+	router.HandleFunc("/wishlists/{token}", httpServer.GetSharedWishlist).Methods(http.MethodGet)
+
+	router.HandleFunc("/me/addresses", httpServer.CheckAuthorizedUser(httpServer.GetAddresses)).Methods(http.MethodGet)
+	router.HandleFunc("/me/addresses", httpServer.CheckAuthorizedUser(httpServer.CreateAddress)).Methods(http.MethodPost)
+	router.HandleFunc("/me/addresses/{address_id}", httpServer.CheckAuthorizedUser(httpServer.GetAddress)).Methods(http.MethodGet)
+	router.HandleFunc("/me/addresses/{address_id}", httpServer.CheckAuthorizedUser(httpServer.UpdateAddress)).Methods(http.MethodPatch)
+	router.HandleFunc("/me/addresses/{address_id}", httpServer.CheckAuthorizedUser(httpServer.DeleteAddress)).Methods(http.MethodDelete)
+
+	router.HandleFunc("/admin/promotions", httpServer.CheckAdmin(httpServer.GetPromotions)).Methods(http.MethodGet)
+	router.HandleFunc("/admin/promotions", httpServer.CheckAdmin(httpServer.CreatePromotion)).Methods(http.MethodPost)
+	router.HandleFunc("/admin/promotions/{promotion_id}", httpServer.CheckAdmin(httpServer.GetPromotion)).Methods(http.MethodGet)
+	router.HandleFunc("/admin/promotions/{promotion_id}", httpServer.CheckAdmin(httpServer.UpdatePromotion)).Methods(http.MethodPatch)
+	router.HandleFunc("/admin/promotions/{promotion_id}", httpServer.CheckAdmin(httpServer.DeletePromotion)).Methods(http.MethodDelete)
+
+	router.HandleFunc("/admin/tax-rates", httpServer.CheckAdmin(httpServer.GetTaxRates)).Methods(http.MethodGet)
+	router.HandleFunc("/admin/tax-rates", httpServer.CheckAdmin(httpServer.SetTaxRate)).Methods(http.MethodPut)
+	router.HandleFunc("/admin/tax-rates/{tax_rate_id}", httpServer.CheckAdmin(httpServer.DeleteTaxRate)).Methods(http.MethodDelete)
+
+	router.HandleFunc("/admin/shipping-rates", httpServer.CheckAdmin(httpServer.GetShippingRates)).Methods(http.MethodGet)
+	router.HandleFunc("/admin/shipping-rates", httpServer.CheckAdmin(httpServer.SetShippingRate)).Methods(http.MethodPut)
+	router.HandleFunc("/admin/shipping-rates/{shipping_rate_id}", httpServer.CheckAdmin(httpServer.DeleteShippingRate)).Methods(http.MethodDelete)
+
+	router.HandleFunc("/admin/exchange-rates", httpServer.CheckAdmin(httpServer.GetExchangeRates)).Methods(http.MethodGet)
+	router.HandleFunc("/admin/exchange-rates/{currency}", httpServer.CheckAdmin(httpServer.SetExchangeRate)).Methods(http.MethodPut)
+	router.HandleFunc("/admin/exchange-rates/{currency}", httpServer.CheckAdmin(httpServer.DeleteExchangeRate)).Methods(http.MethodDelete)
+
+	router.HandleFunc("/admin/reviews", httpServer.CheckAdmin(httpServer.GetReviews)).Methods(http.MethodGet)
+	router.HandleFunc("/admin/reviews/{review_id}/approve", httpServer.CheckAdmin(httpServer.ApproveReview)).Methods(http.MethodPost)
+	router.HandleFunc("/admin/reviews/{review_id}/reject", httpServer.CheckAdmin(httpServer.RejectReview)).Methods(http.MethodPost)
+
+	router.HandleFunc("/admin/inventory/low-stock", httpServer.CheckAdmin(httpServer.GetLowStock)).Methods(http.MethodGet)
+
+	router.HandleFunc("/admin/jobs", httpServer.CheckAdmin(httpServer.GetJobs)).Methods(http.MethodGet)
+
+	router.HandleFunc("/admin/webhooks", httpServer.CheckAdmin(httpServer.GetWebhooks)).Methods(http.MethodGet)
+	router.HandleFunc("/admin/webhooks", httpServer.CheckAdmin(httpServer.CreateWebhook)).Methods(http.MethodPost)
+	router.HandleFunc("/admin/webhooks/{webhook_id}", httpServer.CheckAdmin(httpServer.GetWebhook)).Methods(http.MethodGet)
+	router.HandleFunc("/admin/webhooks/{webhook_id}", httpServer.CheckAdmin(httpServer.UpdateWebhook)).Methods(http.MethodPatch)
+	router.HandleFunc("/admin/webhooks/{webhook_id}", httpServer.CheckAdmin(httpServer.DeleteWebhook)).Methods(http.MethodDelete)
+	router.HandleFunc("/admin/webhooks/{webhook_id}/deliveries", httpServer.CheckAdmin(httpServer.GetWebhookDeliveries)).Methods(http.MethodGet)
+	router.HandleFunc("/admin/webhooks/{webhook_id}/deliveries/{delivery_id}/retry", httpServer.CheckAdmin(httpServer.RetryWebhookDelivery)).Methods(http.MethodPost)
+
+	srv := &http.Server{
+		Addr:    cfg.HTTPAddr,
+		Handler: router,
+	}
+
+	// listen to OS signals, ctx is cancelled on shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// start background jobs, they are stopped together with the HTTP server
+	jobsDone := make(chan struct{})
+	go func() {
+		jobs.Run(ctx)
+		close(jobsDone)
+	}()
+
+	// gracefully shutdown HTTP server
+	stopped := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("HTTP Server Shutdown Error: %v", err)
+		}
+		close(stopped)
+	}()
+
+	log.Printf("Starting HTTP server on %s", cfg.HTTPAddr)
+
+	// start HTTP server
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		log.Fatalf("HTTP server ListenAndServe Error: %v", err)
+	}
+
+	<-stopped
+	<-jobsDone
+
+	log.Printf("Have a nice day!")
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/northwindman/book-shop/internal/app/config"
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/app/repository/pgrepo"
+	"github.com/northwindman/book-shop/internal/pkg/password"
+	"github.com/northwindman/book-shop/internal/pkg/pg"
+)
+
+// userCommand runs the user subcommands
+func userCommand(ctx context.Context, cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("user: expected one of create, set-admin")
+	}
+
+	switch args[0] {
+	case "create":
+		return createUser(ctx, cfg, args[1:])
+	case "set-admin":
+		return setAdmin(ctx, cfg, args[1:])
+	default:
+		return fmt.Errorf("user: unknown subcommand %q", args[0])
+	}
+}
+
+func createUser(ctx context.Context, cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("user create", flag.ExitOnError)
+	username := fs.String("username", "", "username of the new user")
+	pass := fs.String("password", "", "password of the new user")
+	admin := fs.Bool("admin", false, "grant admin permissions")
+	_ = fs.Parse(args)
+
+	if *username == "" {
+		return fmt.Errorf("user create: %w: username", domain.ErrRequired)
+	}
+	if *pass == "" {
+		return fmt.Errorf("user create: %w: password", domain.ErrRequired)
+	}
+
+	pgDB, err := pg.Dial(cfg.DSN)
+	if err != nil {
+		return fmt.Errorf("pg.Dial failed: %w", err)
+	}
+	defer pgDB.Close()
+
+	hashedPassword, err := password.Hash(*pass)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user, err := domain.NewUser(domain.NewUserData{
+		Username: *username,
+		Password: hashedPassword,
+		Admin:    *admin,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create domain user: %w", err)
+	}
+
+	createdUser, err := pgrepo.NewUserRepo(pgDB).CreateUser(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Created user %q (id %d, admin: %t)", createdUser.Username(), createdUser.ID(), createdUser.Admin())
+
+	return nil
+}
+
+func setAdmin(ctx context.Context, cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("user set-admin", flag.ExitOnError)
+	username := fs.String("username", "", "username of the user to update")
+	admin := fs.Bool("admin", true, "admin flag to set")
+	_ = fs.Parse(args)
+
+	if *username == "" {
+		return fmt.Errorf("user set-admin: %w: username", domain.ErrRequired)
+	}
+
+	pgDB, err := pg.Dial(cfg.DSN)
+	if err != nil {
+		return fmt.Errorf("pg.Dial failed: %w", err)
+	}
+	defer pgDB.Close()
+
+	user, err := pgrepo.NewUserRepo(pgDB).SetAdmin(ctx, *username, *admin)
+	if err != nil {
+		return fmt.Errorf("failed to set admin flag for %q: %w", *username, err)
+	}
+
+	log.Printf("User %q admin: %t", user.Username(), user.Admin())
+
+	return nil
+}
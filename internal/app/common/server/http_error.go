@@ -10,6 +10,9 @@ import (
 	"github.com/northwindman/book-shop/internal/app/common/slugerrors"
 )
 
+// ProblemContentType is the media type of RFC 7807 problem details
+const ProblemContentType = "application/problem+json"
+
 func InternalError(slug string, err error, w http.ResponseWriter, r *http.Request) {
 	httpRespondWithError(err, slug, w, r, "Internal server error", http.StatusInternalServerError)
 }
@@ -23,7 +26,23 @@ func BadRequest(slug string, err error, w http.ResponseWriter, r *http.Request)
 }
 
 func NotFound(slug string, err error, w http.ResponseWriter, r *http.Request) {
-	httpRespondWithError(err, slug, w, r, "Not found", http.StatusBadRequest)
+	httpRespondWithError(err, slug, w, r, "Not found", http.StatusNotFound)
+}
+
+func Forbidden(slug string, err error, w http.ResponseWriter, r *http.Request) {
+	httpRespondWithError(err, slug, w, r, "Forbidden", http.StatusForbidden)
+}
+
+func Conflict(slug string, err error, w http.ResponseWriter, r *http.Request) {
+	httpRespondWithError(err, slug, w, r, "Conflict", http.StatusConflict)
+}
+
+func PreconditionFailed(slug string, err error, w http.ResponseWriter, r *http.Request) {
+	httpRespondWithError(err, slug, w, r, "Precondition failed", http.StatusPreconditionFailed)
+}
+
+func PreconditionRequired(slug string, err error, w http.ResponseWriter, r *http.Request) {
+	httpRespondWithError(err, slug, w, r, "Precondition required", http.StatusPreconditionRequired)
 }
 
 func RespondWithError(err error, w http.ResponseWriter, r *http.Request) {
@@ -40,31 +59,63 @@ func RespondWithError(err error, w http.ResponseWriter, r *http.Request) {
 		BadRequest(slugError.Slug(), slugError, w, r)
 	case slugerrors.ErrorTypeNotFound:
 		NotFound(slugError.Slug(), slugError, w, r)
+	case slugerrors.ErrorTypeForbidden:
+		Forbidden(slugError.Slug(), slugError, w, r)
+	case slugerrors.ErrorTypeConflict:
+		Conflict(slugError.Slug(), slugError, w, r)
+	case slugerrors.ErrorTypePreconditionFailed:
+		PreconditionFailed(slugError.Slug(), slugError, w, r)
+	case slugerrors.ErrorTypePreconditionRequired:
+		PreconditionRequired(slugError.Slug(), slugError, w, r)
 	default:
 		InternalError(slugError.Slug(), slugError, w, r)
 	}
 }
 
-func httpRespondWithError(err error, slug string, w http.ResponseWriter, r *http.Request, msg string, status int) {
-	log.Printf("error: %s, slug: %s, msg: %s", err, slug, msg)
+func httpRespondWithError(err error, slug string, w http.ResponseWriter, r *http.Request, title string, status int) {
+	log.Printf("error: %s, slug: %s, msg: %s", err, slug, title)
+
+	problem := ProblemDetails{
+		Type:     "/problems/" + slug,
+		Title:    title,
+		Status:   status,
+		Instance: r.URL.RequestURI(),
+		Slug:     slug,
+	}
 
-	resp := ErrorResponse{Slug: slug, httpStatus: status}
-	if os.Getenv("DEBUG_ERRORS") != "" && err != nil {
-		resp.Error = err.Error()
+	// client errors describe the request and are safe to return, server errors only in debug mode
+	if err != nil && (status < http.StatusInternalServerError || os.Getenv("DEBUG_ERRORS") != "") {
+		problem.Detail = err.Error()
+	}
+
+	var slugError slugerrors.SlugError
+	if errors.As(err, &slugError) {
+		for _, violation := range slugError.Violations() {
+			problem.Errors = append(problem.Errors, FieldError{
+				Field:   violation.Field,
+				Message: violation.Message,
+			})
+		}
 	}
 
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Type", ProblemContentType)
 	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(resp)
+	_ = json.NewEncoder(w).Encode(problem)
 }
 
-type ErrorResponse struct {
-	Slug       string `json:"slug"`
-	Error      string `json:"error,omitempty"`
-	httpStatus int
+// ProblemDetails is an RFC 7807 error response, Slug and Errors are extension members
+type ProblemDetails struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Slug     string       `json:"slug"`
+	Errors   []FieldError `json:"errors,omitempty"`
 }
 
-func (e ErrorResponse) Render(w http.ResponseWriter, _ *http.Request) error {
-	w.WriteHeader(e.httpStatus)
-	return nil
+// FieldError is a single invalid field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/northwindman/book-shop/internal/app/common/slugerrors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRespondWithError_ValidationProblem(t *testing.T) {
+	err := slugerrors.NewValidationError("invalid book", "invalid-request",
+		slugerrors.FieldViolation{Field: "title", Message: "required value"},
+		slugerrors.FieldViolation{Field: "price", Message: "negative value"},
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/book?x=1", nil)
+	w := httptest.NewRecorder()
+
+	RespondWithError(err, w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	require.Equal(t, ProblemContentType, res.Header.Get("Content-Type"))
+
+	var problem ProblemDetails
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&problem))
+	require.Equal(t, "/problems/invalid-request", problem.Type)
+	require.Equal(t, "Bad request", problem.Title)
+	require.Equal(t, http.StatusBadRequest, problem.Status)
+	require.Equal(t, "invalid book", problem.Detail)
+	require.Equal(t, "/book?x=1", problem.Instance)
+	require.Equal(t, []FieldError{
+		{Field: "title", Message: "required value"},
+		{Field: "price", Message: "negative value"},
+	}, problem.Errors)
+}
+
+func TestNotFound_Status(t *testing.T) {
+	w := httptest.NewRecorder()
+	NotFound("book-not-found", errors.New("not found"), w, httptest.NewRequest(http.MethodGet, "/book/1", nil))
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestInternalError_HidesDetail(t *testing.T) {
+	t.Setenv("DEBUG_ERRORS", "")
+
+	w := httptest.NewRecorder()
+	InternalError("internal-server-error", errors.New("pq: connection refused"), w, httptest.NewRequest(http.MethodGet, "/books", nil))
+
+	var problem ProblemDetails
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+	require.Equal(t, http.StatusInternalServerError, problem.Status)
+	require.Empty(t, problem.Detail)
+}
@@ -9,12 +9,26 @@ var (
 	ErrorTypeAuthorization = ErrorType{"authorization"}
 	ErrorTypeBadRequest    = ErrorType{"bad-request"}
 	ErrorTypeNotFound      = ErrorType{"not-found"}
+	// ErrorTypeForbidden is a request of an authenticated user who may not perform it
+	ErrorTypeForbidden = ErrorType{"forbidden"}
+	ErrorTypeConflict  = ErrorType{"conflict"}
+	// ErrorTypePreconditionFailed is a conditional request made against a stale version of a resource
+	ErrorTypePreconditionFailed = ErrorType{"precondition-failed"}
+	// ErrorTypePreconditionRequired is an unconditional request to a resource that must be edited conditionally
+	ErrorTypePreconditionRequired = ErrorType{"precondition-required"}
 )
 
+// FieldViolation describes why a single field of a request is invalid
+type FieldViolation struct {
+	Field   string
+	Message string
+}
+
 type SlugError struct {
-	error     string
-	slug      string
-	errorType ErrorType
+	error      string
+	slug       string
+	errorType  ErrorType
+	violations []FieldViolation
 }
 
 func (s SlugError) Error() string {
@@ -29,6 +43,11 @@ func (s SlugError) ErrorType() ErrorType {
 	return s.errorType
 }
 
+// Violations returns the invalid fields of a validation error
+func (s SlugError) Violations() []FieldViolation {
+	return s.violations
+}
+
 func NewSlugError(error string, slug string) SlugError {
 	return SlugError{
 		error:     error,
@@ -60,3 +79,45 @@ func NewNotFoundError(error string, slug string) SlugError {
 		errorType: ErrorTypeNotFound,
 	}
 }
+
+func NewForbiddenError(error string, slug string) SlugError {
+	return SlugError{
+		error:     error,
+		slug:      slug,
+		errorType: ErrorTypeForbidden,
+	}
+}
+
+func NewConflictError(error string, slug string) SlugError {
+	return SlugError{
+		error:     error,
+		slug:      slug,
+		errorType: ErrorTypeConflict,
+	}
+}
+
+func NewPreconditionFailedError(error string, slug string) SlugError {
+	return SlugError{
+		error:     error,
+		slug:      slug,
+		errorType: ErrorTypePreconditionFailed,
+	}
+}
+
+func NewPreconditionRequiredError(error string, slug string) SlugError {
+	return SlugError{
+		error:     error,
+		slug:      slug,
+		errorType: ErrorTypePreconditionRequired,
+	}
+}
+
+// NewValidationError creates a bad request error listing every invalid field
+func NewValidationError(error string, slug string, violations ...FieldViolation) SlugError {
+	return SlugError{
+		error:      error,
+		slug:       slug,
+		errorType:  ErrorTypeBadRequest,
+		violations: violations,
+	}
+}
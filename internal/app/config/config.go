@@ -1,12 +1,25 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"strings"
+)
 
 // Config is a simple config
 type Config struct {
 	HTTPAddr       string
 	DSN            string
 	MigrationsPath string
+	// OutboxFilePath is an optional file the outbox relay appends events to
+	OutboxFilePath string
+	// InventoryAutoRepair lets the scheduled inventory reconciliation correct the stock drift it finds
+	InventoryAutoRepair bool
+	// MailDir is the directory the local mailer writes emails to
+	MailDir  string
+	MailFrom string
+	// StaffEmails receive alerts such as low stock, alerts are only logged and sent to webhooks without them
+	StaffEmails []string
 }
 
 // Read reads config from environment.
@@ -24,5 +37,31 @@ func Read() Config {
 	if exists {
 		config.MigrationsPath = migrationsPath
 	}
+	outboxFilePath, exists := os.LookupEnv("OUTBOX_FILE_PATH")
+	if exists {
+		config.OutboxFilePath = outboxFilePath
+	}
+	inventoryAutoRepair, exists := os.LookupEnv("INVENTORY_AUTO_REPAIR")
+	if exists {
+		config.InventoryAutoRepair, _ = strconv.ParseBool(inventoryAutoRepair)
+	}
+	config.MailDir = "mail"
+	mailDir, exists := os.LookupEnv("MAIL_DIR")
+	if exists {
+		config.MailDir = mailDir
+	}
+	config.MailFrom = "book-shop@localhost"
+	mailFrom, exists := os.LookupEnv("MAIL_FROM")
+	if exists {
+		config.MailFrom = mailFrom
+	}
+	staffEmails, exists := os.LookupEnv("STAFF_EMAILS")
+	if exists {
+		for _, email := range strings.Split(staffEmails, ",") {
+			if email = strings.TrimSpace(email); email != "" {
+				config.StaffEmails = append(config.StaffEmails, email)
+			}
+		}
+	}
 	return config
 }
@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxAddressFieldLength bounds the free-text fields of an address.
+const maxAddressFieldLength = 100
+
+var (
+	postalCodePattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9 -]{1,9}$`)
+	phonePattern      = regexp.MustCompile(`^\+?[0-9 ()-]{5,20}$`)
+)
+
+// countriesWithStates require the state of an address, it selects the state sales tax.
+var countriesWithStates = []string{"US", "CA", "AU"}
+
+// Address is a shipping address of a user.
+type Address struct {
+	id         int
+	userID     int
+	name       string
+	line1      string
+	line2      string
+	city       string
+	state      string
+	postalCode string
+	country    string
+	phone      string
+	createdAt  time.Time
+}
+
+type NewAddressData struct {
+	ID int
+	// UserID is zero for addresses copied onto orders
+	UserID int
+	// Name is the recipient
+	Name       string
+	Line1      string
+	Line2      string
+	City       string
+	State      string
+	PostalCode string
+	// Country is an ISO 3166-1 alpha-2 code
+	Country   string
+	Phone     string
+	CreatedAt time.Time
+}
+
+// NewAddress creates a new address. Fields are trimmed, codes are upper-cased.
+// The recipient, first line, city, postal code and country are required, the state too in countries taxed by state.
+func NewAddress(data NewAddressData) (Address, error) {
+	name := strings.TrimSpace(data.Name)
+	line1 := strings.TrimSpace(data.Line1)
+	line2 := strings.TrimSpace(data.Line2)
+	city := strings.TrimSpace(data.City)
+	state := strings.ToUpper(strings.TrimSpace(data.State))
+	postalCode := strings.ToUpper(strings.TrimSpace(data.PostalCode))
+	country := strings.ToUpper(strings.TrimSpace(data.Country))
+	phone := strings.TrimSpace(data.Phone)
+
+	var v validator
+	checkText := func(value, field string, required bool) {
+		v.check(!required || value != "", field, ErrRequired)
+		v.check(utf8.RuneCountInString(value) <= maxAddressFieldLength, field, ErrOutOfRange)
+	}
+	checkText(name, "name", true)
+	checkText(line1, "line1", true)
+	checkText(line2, "line2", false)
+	checkText(city, "city", true)
+	v.check(postalCode != "", "postal_code", ErrRequired)
+	v.check(postalCode == "" || postalCodePattern.MatchString(postalCode), "postal_code", ErrInvalidFormat)
+	v.check(country != "", "country", ErrRequired)
+	v.check(country == "" || countryPattern.MatchString(country), "country", ErrInvalidFormat)
+	v.check(state != "" || !slices.Contains(countriesWithStates, country), "state", ErrRequired)
+	v.check(state == "" || statePattern.MatchString(state), "state", ErrInvalidFormat)
+	v.check(phone == "" || phonePattern.MatchString(phone), "phone", ErrInvalidFormat)
+	if err := v.err(); err != nil {
+		return Address{}, err
+	}
+
+	return Address{
+		id:         data.ID,
+		userID:     data.UserID,
+		name:       name,
+		line1:      line1,
+		line2:      line2,
+		city:       city,
+		state:      state,
+		postalCode: postalCode,
+		country:    country,
+		phone:      phone,
+		createdAt:  data.CreatedAt,
+	}, nil
+}
+
+// ID returns the address ID.
+func (a Address) ID() int {
+	return a.id
+}
+
+// UserID returns the ID of the user the address belongs to.
+func (a Address) UserID() int {
+	return a.userID
+}
+
+// Name returns the recipient.
+func (a Address) Name() string {
+	return a.name
+}
+
+// Line1 returns the first address line.
+func (a Address) Line1() string {
+	return a.line1
+}
+
+// Line2 returns the optional second address line.
+func (a Address) Line2() string {
+	return a.line2
+}
+
+// City returns the city.
+func (a Address) City() string {
+	return a.city
+}
+
+// State returns the state code, empty in countries without states.
+func (a Address) State() string {
+	return a.state
+}
+
+// PostalCode returns the postal code.
+func (a Address) PostalCode() string {
+	return a.postalCode
+}
+
+// Country returns the ISO 3166-1 alpha-2 country code.
+func (a Address) Country() string {
+	return a.country
+}
+
+// Phone returns the optional phone number of the recipient.
+func (a Address) Phone() string {
+	return a.phone
+}
+
+// CreatedAt returns when the address was added.
+func (a Address) CreatedAt() time.Time {
+	return a.createdAt
+}
+
+// IsZero reports whether the address is unset, as on orders placed before addresses were collected.
+func (a Address) IsZero() bool {
+	return a.country == ""
+}
+
+// TaxRegion returns the region books shipped to the address are taxed in.
+func (a Address) TaxRegion() TaxRegion {
+	return TaxRegion{Country: a.country, State: a.state}
+}
@@ -0,0 +1,174 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Author roles, the role of a person in the making of a book.
+const (
+	AuthorRoleAuthor      = "author"
+	AuthorRoleEditor      = "editor"
+	AuthorRoleTranslator  = "translator"
+	AuthorRoleIllustrator = "illustrator"
+)
+
+// AuthorRoles lists the roles of authors.
+var AuthorRoles = []string{AuthorRoleAuthor, AuthorRoleEditor, AuthorRoleTranslator, AuthorRoleIllustrator}
+
+// Author is a person credited on books.
+type Author struct {
+	id      int
+	name    string
+	bio     string
+	version int
+}
+
+type NewAuthorData struct {
+	ID   int
+	Name string
+	Bio  string
+	// Version is incremented by every edit, edits of a stale version are rejected
+	Version int
+}
+
+// NewAuthor creates a new author with a trimmed name, it must have letters or digits.
+func NewAuthor(data NewAuthorData) (Author, error) {
+	return newAuthor(data, validator{})
+}
+
+func newAuthor(data NewAuthorData, v validator) (Author, error) {
+	name := strings.TrimSpace(data.Name)
+
+	v.check(AuthorNameKey(name) != "", "name", ErrRequired)
+	if err := v.err(); err != nil {
+		return Author{}, err
+	}
+
+	return Author{
+		id:      data.ID,
+		name:    name,
+		bio:     strings.TrimSpace(data.Bio),
+		version: data.Version,
+	}, nil
+}
+
+// AuthorNameKey returns the key that identifies the name of an author regardless of case, spacing and punctuation,
+// so "J.R.R. Tolkien" and "J. R. R. Tolkien" are the same author.
+func AuthorNameKey(name string) string {
+	var key strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			key.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return key.String()
+}
+
+// ID returns the author ID.
+func (a Author) ID() int {
+	return a.id
+}
+
+// Name returns the name of the author.
+func (a Author) Name() string {
+	return a.name
+}
+
+// NameKey returns the key that identifies the name of the author.
+func (a Author) NameKey() string {
+	return AuthorNameKey(a.name)
+}
+
+// Bio returns the biography of the author.
+func (a Author) Bio() string {
+	return a.bio
+}
+
+// Version returns the version of the author, it changes with every edit.
+func (a Author) Version() int {
+	return a.version
+}
+
+// AuthorPatch lists the fields of an author to change, nil fields are left unchanged.
+type AuthorPatch struct {
+	Name *string
+	Bio  *string
+}
+
+// Patch returns the author with the patch applied and the fields it changed, fields set to their current value
+// are not changed. Only the fields of the patch are validated, the version is kept for the update to check.
+func (a Author) Patch(patch AuthorPatch) (Author, []string, error) {
+	data := NewAuthorData{
+		ID:      a.id,
+		Name:    a.name,
+		Bio:     a.bio,
+		Version: a.version,
+	}
+	v := validator{partial: true}
+	if patch.Name != nil {
+		data.Name = *patch.Name
+		v.only = append(v.only, "name")
+	}
+	if patch.Bio != nil {
+		data.Bio = *patch.Bio
+	}
+
+	patched, err := newAuthor(data, v)
+	if err != nil {
+		return Author{}, nil, err
+	}
+
+	var changed []string
+	if patched.name != a.name {
+		changed = append(changed, "name")
+	}
+	if patched.bio != a.bio {
+		changed = append(changed, "bio")
+	}
+
+	return patched, changed, nil
+}
+
+// BookAuthor credits an author on a book in a role.
+type BookAuthor struct {
+	// AuthorID is the credited author, zero for an author known by name only, who is looked up by name or created
+	AuthorID int
+	Name     string
+	Role     string
+}
+
+// AuthorBook is a book credited to an author in a role.
+type AuthorBook struct {
+	Book Book
+	Role string
+}
+
+// checkBookAuthors records the violations of the credits of a book: every credit names an author in a known role,
+// and no author is credited twice in the same role.
+func checkBookAuthors(v *validator, authors []BookAuthor) {
+	v.check(authors == nil || len(authors) > 0, "authors", ErrRequired)
+	for i, author := range authors {
+		v.check(author.AuthorID > 0 || AuthorNameKey(author.Name) != "", "authors",
+			fmt.Errorf("%w: credit %d has no author", ErrRequired, i+1))
+		v.check(slices.Contains(AuthorRoles, author.Role), "authors",
+			fmt.Errorf("%w: unknown author role %q", ErrInvalidFormat, author.Role))
+		duplicate := slices.ContainsFunc(authors[:i], func(other BookAuthor) bool {
+			return other.Role == author.Role && (author.AuthorID > 0 && other.AuthorID == author.AuthorID ||
+				author.AuthorID == 0 && other.AuthorID == 0 && AuthorNameKey(other.Name) == AuthorNameKey(author.Name))
+		})
+		v.check(!duplicate, "authors", fmt.Errorf("%w: author credited twice as %s", ErrInvalidFormat, author.Role))
+	}
+}
+
+// sameBookAuthors reports whether two lists credit the same authors in the same roles and order.
+func sameBookAuthors(a, b []BookAuthor) bool {
+	return slices.EqualFunc(a, b, func(x, y BookAuthor) bool {
+		if x.AuthorID == 0 || y.AuthorID == 0 {
+			return x.AuthorID == y.AuthorID && AuthorNameKey(x.Name) == AuthorNameKey(y.Name) && x.Role == y.Role
+		}
+		return x.AuthorID == y.AuthorID && x.Role == y.Role
+	})
+}
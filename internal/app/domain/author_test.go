@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorNameKey(t *testing.T) {
+	require.Equal(t, "jrrtolkien", AuthorNameKey("J.R.R. Tolkien"))
+	require.Equal(t, AuthorNameKey("J.R.R. Tolkien"), AuthorNameKey(" j. r. r. TOLKIEN "))
+	require.Equal(t, "", AuthorNameKey(" - . "))
+}
+
+func TestNewAuthor(t *testing.T) {
+	author, err := NewAuthor(NewAuthorData{Name: "  Ursula K. Le Guin ", Bio: " American author. "})
+	require.NoError(t, err)
+	require.Equal(t, "Ursula K. Le Guin", author.Name())
+	require.Equal(t, "American author.", author.Bio())
+	require.Equal(t, "ursulakleguin", author.NameKey())
+
+	_, err = NewAuthor(NewAuthorData{Name: "..."})
+	require.Equal(t, []string{"name"}, validationFields(t, err))
+}
+
+func TestAuthor_Patch(t *testing.T) {
+	author, err := NewAuthor(NewAuthorData{ID: 1, Name: "Frank Herbert", Version: 3})
+	require.NoError(t, err)
+
+	name, bio := " Frank Herbert ", "Wrote Dune."
+	patched, changed, err := author.Patch(AuthorPatch{Name: &name, Bio: &bio})
+	require.NoError(t, err)
+	require.Equal(t, []string{"bio"}, changed)
+	require.Equal(t, "Wrote Dune.", patched.Bio())
+	require.Equal(t, 3, patched.Version())
+
+	empty := " "
+	_, _, err = author.Patch(AuthorPatch{Name: &empty})
+	require.Equal(t, []string{"name"}, validationFields(t, err))
+}
+
+func TestNewBook_Authors(t *testing.T) {
+	data := NewBookData{
+		Title:      "Good Omens",
+		Year:       1990,
+		Price:      Money{amount: 1000, currency: SettlementCurrency},
+		CategoryID: 1,
+		Authors: []BookAuthor{
+			{Name: "Terry Pratchett", Role: AuthorRoleAuthor},
+			{AuthorID: 7, Role: AuthorRoleAuthor},
+		},
+	}
+	book, err := NewBook(data)
+	require.NoError(t, err)
+	require.Len(t, book.Authors(), 2)
+
+	data.Authors = []BookAuthor{
+		{Name: "Terry Pratchett", Role: AuthorRoleAuthor},
+		{Name: "terry pratchett", Role: AuthorRoleAuthor},
+		{Role: "narrator"},
+	}
+	_, err = NewBook(data)
+	require.Equal(t, []string{"authors", "authors", "authors"}, validationFields(t, err))
+
+	data.Authors = []BookAuthor{}
+	_, err = NewBook(data)
+	require.Equal(t, []string{"author", "authors"}, validationFields(t, err))
+}
+
+func TestBook_PatchAuthors(t *testing.T) {
+	book, err := NewBook(NewBookData{
+		ID:         1,
+		Title:      "Dune",
+		Year:       1965,
+		Author:     "Frank Herbert",
+		Authors:    []BookAuthor{{AuthorID: 2, Name: "Frank Herbert", Role: AuthorRoleAuthor}},
+		Price:      Money{amount: 1000, currency: SettlementCurrency},
+		CategoryID: 1,
+	})
+	require.NoError(t, err)
+
+	same := "Frank Herbert"
+	patched, changed, err := book.Patch(BookPatch{Author: &same})
+	require.NoError(t, err)
+	require.Empty(t, changed)
+	require.Equal(t, book.Authors(), patched.Authors())
+
+	byline := "Brian Herbert"
+	patched, changed, err = book.Patch(BookPatch{Author: &byline})
+	require.NoError(t, err)
+	require.Equal(t, []string{"author"}, changed)
+	require.Nil(t, patched.Authors())
+
+	authors := []BookAuthor{
+		{AuthorID: 2, Role: AuthorRoleAuthor},
+		{Name: "John Schoenherr", Role: AuthorRoleIllustrator},
+	}
+	patched, changed, err = book.Patch(BookPatch{Authors: &authors})
+	require.NoError(t, err)
+	require.Equal(t, []string{"authors"}, changed)
+	require.Len(t, patched.Authors(), 2)
+
+	invalid := []BookAuthor{{AuthorID: 2, Role: "ghostwriter"}}
+	_, _, err = book.Patch(BookPatch{Authors: &invalid})
+	require.Equal(t, []string{"authors"}, validationFields(t, err))
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// BackInStockRateLimit is the number of back-in-stock notifications a user receives at most within
+	// BackInStockRateWindow, further notifications wait in the queue
+	BackInStockRateLimit  = 3
+	BackInStockRateWindow = 24 * time.Hour
+)
+
+var ErrBookInStock = errors.New("book is in stock")
+
+// StockSubscription is a request of a user to be notified when a sold-out book is back in stock.
+// It is queued when the stock of the book rises above zero and notified in the order subscriptions were made.
+type StockSubscription struct {
+	userID     int
+	bookID     int
+	createdAt  time.Time
+	queuedAt   time.Time
+	notifiedAt time.Time
+}
+
+type NewStockSubscriptionData struct {
+	UserID    int
+	BookID    int
+	CreatedAt time.Time
+	// QueuedAt is when the book came back in stock, zero while it is sold out
+	QueuedAt time.Time
+	// NotifiedAt is when the user was notified, zero until then
+	NotifiedAt time.Time
+}
+
+// NewStockSubscription creates a new stock subscription.
+func NewStockSubscription(data NewStockSubscriptionData) (StockSubscription, error) {
+	var v validator
+	v.check(data.UserID > 0, "user_id", ErrRequired)
+	v.check(data.BookID > 0, "book_id", ErrRequired)
+	if err := v.err(); err != nil {
+		return StockSubscription{}, err
+	}
+
+	return StockSubscription{
+		userID:     data.UserID,
+		bookID:     data.BookID,
+		createdAt:  data.CreatedAt,
+		queuedAt:   data.QueuedAt,
+		notifiedAt: data.NotifiedAt,
+	}, nil
+}
+
+// UserID returns the ID of the subscribed user.
+func (s StockSubscription) UserID() int {
+	return s.userID
+}
+
+// BookID returns the ID of the sold-out book.
+func (s StockSubscription) BookID() int {
+	return s.bookID
+}
+
+// CreatedAt returns when the user subscribed, earlier subscribers are notified first.
+func (s StockSubscription) CreatedAt() time.Time {
+	return s.createdAt
+}
+
+// QueuedAt returns when the book came back in stock, zero while it is sold out.
+func (s StockSubscription) QueuedAt() time.Time {
+	return s.queuedAt
+}
+
+// NotifiedAt returns when the user was notified, zero until then.
+func (s StockSubscription) NotifiedAt() time.Time {
+	return s.notifiedAt
+}
+
+// Status returns "waiting", "queued" or "notified".
+func (s StockSubscription) Status() string {
+	switch {
+	case !s.notifiedAt.IsZero():
+		return "notified"
+	case !s.queuedAt.IsZero():
+		return "queued"
+	default:
+		return "waiting"
+	}
+}
+
+// BackInStockNotice is a queued subscription due to be notified.
+type BackInStockNotice struct {
+	Subscription StockSubscription
+	Title        string
+	Stock        int
+	// Email is the email address of the user, empty for users without one
+	Email string
+	// RecentlyNotified is the number of notifications the user received within BackInStockRateWindow
+	RecentlyNotified int
+}
+
+// Notification returns the notification of the user.
+func (n BackInStockNotice) Notification() Notification {
+	var to []string
+	if n.Email != "" {
+		to = []string{n.Email}
+	}
+
+	return Notification{
+		Type:          EventUserBackInStock,
+		AggregateType: AggregateUser,
+		AggregateID:   n.Subscription.UserID(),
+		To:            to,
+		Subject:       fmt.Sprintf("%s is back in stock", n.Title),
+		Body: fmt.Sprintf("%q is back in stock, %d left. Add it to your cart before it sells out again.\n",
+			n.Title, n.Stock),
+		Payload: BackInStockPayload{
+			BookID: n.Subscription.BookID(),
+			Stock:  n.Stock,
+		},
+	}
+}
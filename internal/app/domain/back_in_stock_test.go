@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStockSubscription_Status(t *testing.T) {
+	subscription, err := NewStockSubscription(NewStockSubscriptionData{UserID: 1, BookID: 2, CreatedAt: time.Now()})
+	require.NoError(t, err)
+	require.Equal(t, "waiting", subscription.Status())
+
+	subscription, err = NewStockSubscription(NewStockSubscriptionData{UserID: 1, BookID: 2, QueuedAt: time.Now()})
+	require.NoError(t, err)
+	require.Equal(t, "queued", subscription.Status())
+
+	subscription, err = NewStockSubscription(NewStockSubscriptionData{UserID: 1, BookID: 2, QueuedAt: time.Now(), NotifiedAt: time.Now()})
+	require.NoError(t, err)
+	require.Equal(t, "notified", subscription.Status())
+
+	_, err = NewStockSubscription(NewStockSubscriptionData{})
+	require.Equal(t, []string{"user_id", "book_id"}, validationFields(t, err))
+}
+
+func TestBackInStockNotice_Notification(t *testing.T) {
+	subscription, err := NewStockSubscription(NewStockSubscriptionData{UserID: 1, BookID: 2})
+	require.NoError(t, err)
+
+	notification := BackInStockNotice{Subscription: subscription, Title: "Dune", Stock: 3, Email: "reader@example.com"}.Notification()
+	require.Equal(t, EventUserBackInStock, notification.Type)
+	require.Equal(t, AggregateUser, notification.AggregateType)
+	require.Equal(t, []string{"reader@example.com"}, notification.To)
+	require.Equal(t, BackInStockPayload{BookID: 2, Stock: 3}, notification.Payload)
+
+	// users without an email address have no one to mail
+	notification = BackInStockNotice{Subscription: subscription, Title: "Dune", Stock: 3}.Notification()
+	require.Empty(t, notification.To)
+}
+
+func TestUser_Email(t *testing.T) {
+	user, err := NewUser(NewUserData{Username: "reader@example.com"})
+	require.NoError(t, err)
+	require.Equal(t, "reader@example.com", user.Email())
+
+	user, err = NewUser(NewUserData{Username: "reader"})
+	require.NoError(t, err)
+	require.Empty(t, user.Email())
+}
@@ -1,14 +1,63 @@
 package domain
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MinBookYear is the earliest accepted publication year.
+const MinBookYear = 1450
+
+// MaxBookDescriptionLength is the maximum length of a book description in characters.
+const MaxBookDescriptionLength = 10000
+
+// Book formats, how a book is published.
+const (
+	BookFormatHardcover = "hardcover"
+	BookFormatPaperback = "paperback"
+	BookFormatEbook     = "ebook"
+)
+
+// BookFormats lists the formats of books.
+var BookFormats = []string{BookFormatHardcover, BookFormatPaperback, BookFormatEbook}
+
 // Book is a domain book.
 type Book struct {
 	id         int
 	title      string
 	year       int
 	author     string
-	price      int
+	price      Money
 	stock      int
 	categoryID int
+	taxClass   string
+	weight     int
+	// reorderThreshold is the stock at or below which the book is reported as low on stock
+	reorderThreshold int
+	releaseDate      time.Time
+	preorder         bool
+	preorderCap      int
+	// preorders is the number of copies pre-ordered and not yet fulfilled
+	preorders int
+	// ratingCount and ratingTotal aggregate the ratings of approved reviews
+	ratingCount int
+	ratingTotal int
+	// authors credits the authors of the book in their roles, author is their byline
+	authors []BookAuthor
+	// isbn is the ISBN-13 of the book, empty when unknown
+	isbn        string
+	publisher   string
+	edition     int
+	language    string
+	pageCount   int
+	format      string
+	description string
+	// categoryIDs are the categories of the book, its primary category categoryID first
+	categoryIDs []int
+	version     int
 }
 
 type NewBookData struct {
@@ -16,24 +65,162 @@ type NewBookData struct {
 	Title      string
 	Year       int
 	Author     string
-	Price      int
+	Price      Money
 	Stock      int
 	CategoryID int
+	// TaxClass selects the sales tax rate of the book, it defaults to TaxClassStandard
+	TaxClass string
+	// WeightGrams is the shipping weight of the book, zero when unknown
+	WeightGrams int
+	// ReorderThreshold is the stock at or below which the book is reported as low on stock
+	ReorderThreshold int
+	// ReleaseDate is the publication date, zero when the book is released or the date is unknown
+	ReleaseDate time.Time
+	// Preorder lets customers order the book while it is out of stock, up to PreorderCap copies
+	Preorder    bool
+	PreorderCap int
+	// Preorders is the number of copies pre-ordered and not yet fulfilled, it is maintained by carts and checkouts
+	Preorders int
+	// RatingCount and RatingTotal aggregate the ratings of approved reviews, they are maintained by moderation
+	RatingCount int
+	RatingTotal int
+	// Authors credits the authors of the book in their roles and Author is their byline, it may be left empty when
+	// Authors are given. Authors is nil while the byline is not resolved to authors.
+	Authors []BookAuthor
+	// ISBN is an ISBN-10 or ISBN-13, it is stored as an ISBN-13 and is empty when unknown
+	ISBN      string
+	Publisher string
+	// Edition is the edition number, zero when unknown
+	Edition int
+	// Language is the ISO 639-1 code of the language of the book, empty when unknown
+	Language  string
+	PageCount int
+	// Format is one of BookFormats, empty when unknown
+	Format      string
+	Description string
+	// CategoryIDs are the categories of the book. CategoryID is its primary category, listed first, and defaults to
+	// the first of CategoryIDs. CategoryIDs is nil while the categories of the book are not loaded.
+	CategoryIDs []int
+	// Version is incremented by every edit, edits of a stale version are rejected
+	Version int
 }
 
 // NewBook creates a new book.
+// Title and author are trimmed, the year must lie between MinBookYear and next year,
+// the price must be positive and in the settlement currency, the stock and weight non-negative and the book must belong to a category.
+// A book may belong to several categories, each listed once.
+// Books need a byline or credited authors, and books taking pre-orders need a positive pre-order cap.
+// The publication metadata is optional: a given ISBN must pass its checksum, the language must be a two letter code
+// and the format one of BookFormats.
 func NewBook(data NewBookData) (Book, error) {
+	return newBook(data, validator{})
+}
+
+// RestoreBook recreates a stored book without validating it, so books saved before the book rules still load.
+// Changed fields are validated by Patch.
+func RestoreBook(data NewBookData) Book {
+	book, _ := newBook(data, restoring)
+	return book
+}
+
+func newBook(data NewBookData, v validator) (Book, error) {
+	title := strings.TrimSpace(data.Title)
+	author := strings.TrimSpace(data.Author)
+
+	v.check(title != "", "title", ErrRequired)
+	v.check(data.Year >= MinBookYear && data.Year <= MaxBookYear(), "year", ErrOutOfRange)
+	v.check(author != "" || len(data.Authors) > 0, "author", ErrRequired)
+	checkBookAuthors(&v, data.Authors)
+	switch {
+	case data.Price.Currency() == "":
+		v.check(false, "price", ErrRequired)
+	case data.Price.Currency() != SettlementCurrency:
+		v.check(false, "price", fmt.Errorf("%w: books are priced in %s", ErrCurrencyMismatch, SettlementCurrency))
+	default:
+		v.check(data.Price.IsPositive(), "price", ErrNotPositive)
+	}
+	v.check(data.Stock >= 0, "stock", ErrNegative)
+	categoryID, categoryIDs := data.CategoryID, data.CategoryIDs
+	if categoryID == 0 && len(categoryIDs) > 0 {
+		categoryID = categoryIDs[0]
+	}
+	v.check(categoryID > 0, "category_id", ErrRequired)
+	for i, id := range categoryIDs {
+		v.check(id > 0, "category_ids", fmt.Errorf("%w: category %d has no ID", ErrRequired, i+1))
+		v.check(!slices.Contains(categoryIDs[:i], id), "category_ids",
+			fmt.Errorf("%w: category %d is listed twice", ErrInvalidFormat, id))
+	}
+	if categoryIDs != nil {
+		categoryIDs = append([]int{categoryID}, slices.DeleteFunc(slices.Clone(categoryIDs), func(id int) bool {
+			return id == categoryID
+		})...)
+	}
+	taxClass := data.TaxClass
+	if taxClass == "" {
+		taxClass = TaxClassStandard
+	}
+	v.check(slices.Contains(TaxClasses, taxClass), "tax_class", fmt.Errorf("%w: unknown tax class %q", ErrInvalidFormat, taxClass))
+	v.check(data.WeightGrams >= 0, "weight_grams", ErrNegative)
+	v.check(data.ReorderThreshold >= 0, "reorder_threshold", ErrNegative)
+	v.check(data.PreorderCap >= 0, "preorder_cap", ErrNegative)
+	v.check(!data.Preorder || data.PreorderCap != 0, "preorder_cap", fmt.Errorf("%w: books taking pre-orders need a cap", ErrRequired))
+	v.check(data.Preorders >= 0, "preorders", ErrNegative)
+	v.check(data.RatingCount >= 0, "rating_count", ErrNegative)
+	v.check(data.RatingTotal >= 0, "rating_total", ErrNegative)
+	isbn := strings.TrimSpace(data.ISBN)
+	if isbn != "" {
+		var err error
+		isbn, err = NormalizeISBN(isbn)
+		v.check(err == nil, "isbn", err)
+	}
+	v.check(data.Edition >= 0, "edition", ErrNegative)
+	language := strings.ToLower(strings.TrimSpace(data.Language))
+	v.check(language == "" || len(language) == 2 && strings.Trim(language, "abcdefghijklmnopqrstuvwxyz") == "", "language",
+		fmt.Errorf("%w: languages are ISO 639-1 codes such as \"en\"", ErrInvalidFormat))
+	v.check(data.PageCount >= 0, "page_count", ErrNegative)
+	v.check(data.Format == "" || slices.Contains(BookFormats, data.Format), "format",
+		fmt.Errorf("%w: unknown book format %q", ErrInvalidFormat, data.Format))
+	description := strings.TrimSpace(data.Description)
+	v.check(utf8.RuneCountInString(description) <= MaxBookDescriptionLength, "description", ErrOutOfRange)
+	if err := v.err(); err != nil {
+		return Book{}, err
+	}
+
 	return Book{
-		id:         data.ID,
-		title:      data.Title,
-		year:       data.Year,
-		author:     data.Author,
-		price:      data.Price,
-		stock:      data.Stock,
-		categoryID: data.CategoryID,
+		id:               data.ID,
+		title:            title,
+		year:             data.Year,
+		author:           author,
+		authors:          data.Authors,
+		price:            data.Price,
+		stock:            data.Stock,
+		categoryID:       categoryID,
+		taxClass:         taxClass,
+		weight:           data.WeightGrams,
+		version:          data.Version,
+		reorderThreshold: data.ReorderThreshold,
+		releaseDate:      data.ReleaseDate,
+		preorder:         data.Preorder,
+		preorderCap:      data.PreorderCap,
+		preorders:        data.Preorders,
+		ratingCount:      data.RatingCount,
+		ratingTotal:      data.RatingTotal,
+		isbn:             isbn,
+		publisher:        strings.TrimSpace(data.Publisher),
+		edition:          data.Edition,
+		language:         language,
+		pageCount:        data.PageCount,
+		format:           data.Format,
+		description:      description,
+		categoryIDs:      categoryIDs,
 	}, nil
 }
 
+// MaxBookYear is the latest accepted publication year, books may be announced a year ahead.
+func MaxBookYear() int {
+	return time.Now().Year() + 1
+}
+
 // ID returns the book ID.
 func (b Book) ID() int {
 	return b.id
@@ -49,13 +236,18 @@ func (b Book) Year() int {
 	return b.year
 }
 
-// Author returns the book author.
+// Author returns the byline of the book, the names of its authors.
 func (b Book) Author() string {
 	return b.author
 }
 
-// Price returns the book price.
-func (b Book) Price() int {
+// Authors returns the authors credited on the book in their roles.
+func (b Book) Authors() []BookAuthor {
+	return b.authors
+}
+
+// Price returns the book price in the settlement currency.
+func (b Book) Price() Money {
 	return b.price
 }
 
@@ -64,7 +256,324 @@ func (b Book) Stock() int {
 	return b.stock
 }
 
-// CategoryID returns the book category ID.
+// CategoryID returns the primary category of the book.
 func (b Book) CategoryID() int {
 	return b.categoryID
 }
+
+// CategoryIDs returns the categories of the book, its primary category first.
+func (b Book) CategoryIDs() []int {
+	return b.categoryIDs
+}
+
+// TaxClass returns the sales tax class of the book.
+func (b Book) TaxClass() string {
+	return b.taxClass
+}
+
+// WeightGrams returns the shipping weight of the book in grams.
+func (b Book) WeightGrams() int {
+	return b.weight
+}
+
+// ReorderThreshold returns the stock at or below which the book is low on stock.
+func (b Book) ReorderThreshold() int {
+	return b.reorderThreshold
+}
+
+// ReleaseDate returns the publication date of the book, zero when it is released or the date is unknown.
+func (b Book) ReleaseDate() time.Time {
+	return b.releaseDate
+}
+
+// Preorder reports whether the book can be pre-ordered while it is out of stock.
+func (b Book) Preorder() bool {
+	return b.preorder
+}
+
+// PreorderCap returns the number of copies that can be pre-ordered.
+func (b Book) PreorderCap() int {
+	return b.preorderCap
+}
+
+// Preorders returns the number of copies pre-ordered and not yet fulfilled.
+func (b Book) Preorders() int {
+	return b.preorders
+}
+
+// AcceptsPreorders reports whether the book can be pre-ordered: it takes pre-orders, is out of stock, has pre-orders
+// left under its cap and is not released yet.
+func (b Book) AcceptsPreorders(now time.Time) bool {
+	return b.preorder && b.stock == 0 && b.preorders < b.preorderCap &&
+		(b.releaseDate.IsZero() || now.Before(b.releaseDate))
+}
+
+// RatingCount returns the number of approved reviews of the book.
+func (b Book) RatingCount() int {
+	return b.ratingCount
+}
+
+// RatingAverage returns the average rating of the approved reviews of the book, zero when it has none.
+func (b Book) RatingAverage() float64 {
+	if b.ratingCount == 0 {
+		return 0
+	}
+	return float64(b.ratingTotal) / float64(b.ratingCount)
+}
+
+// RatingTotal returns the sum of the ratings of the approved reviews of the book.
+func (b Book) RatingTotal() int {
+	return b.ratingTotal
+}
+
+// ISBN returns the ISBN-13 of the book, empty when unknown.
+func (b Book) ISBN() string {
+	return b.isbn
+}
+
+// Publisher returns the publisher of the book.
+func (b Book) Publisher() string {
+	return b.publisher
+}
+
+// Edition returns the edition number of the book, zero when unknown.
+func (b Book) Edition() int {
+	return b.edition
+}
+
+// Language returns the ISO 639-1 code of the language of the book, empty when unknown.
+func (b Book) Language() string {
+	return b.language
+}
+
+// PageCount returns the number of pages of the book, zero when unknown.
+func (b Book) PageCount() int {
+	return b.pageCount
+}
+
+// Format returns the format of the book, empty when unknown.
+func (b Book) Format() string {
+	return b.format
+}
+
+// Description returns the description of the book.
+func (b Book) Description() string {
+	return b.description
+}
+
+// Version returns the version of the book, it changes with every edit.
+func (b Book) Version() int {
+	return b.version
+}
+
+// BookPatch lists the fields of a book to change, nil fields are left unchanged.
+// Stock is not patched, it only changes through the inventory ledger, pre-orders only through carts and checkouts
+// and ratings only through review moderation.
+type BookPatch struct {
+	Title            *string
+	Year             *int
+	Author           *string
+	Price            *Money
+	CategoryID       *int
+	TaxClass         *string
+	WeightGrams      *int
+	ReorderThreshold *int
+	// ReleaseDate is set to the zero time to remove the release date
+	ReleaseDate *time.Time
+	Preorder    *bool
+	PreorderCap *int
+	// Authors replaces the credited authors, a changed Author replaces them with a single author known by name
+	Authors *[]BookAuthor
+	// the publication metadata is removed by setting it to its zero value
+	ISBN        *string
+	Publisher   *string
+	Edition     *int
+	Language    *string
+	PageCount   *int
+	Format      *string
+	Description *string
+	// CategoryIDs replaces the categories of the book, a changed CategoryID replaces its primary category only
+	CategoryIDs *[]int
+}
+
+// Patch returns the book with the patch applied and the fields it changed, fields set to their current value are
+// not changed. Only the fields of the patch are validated, the version is kept for the update to check.
+func (b Book) Patch(patch BookPatch) (Book, []string, error) {
+	data := NewBookData{
+		ID:               b.id,
+		Title:            b.title,
+		Year:             b.year,
+		Author:           b.author,
+		Authors:          b.authors,
+		Price:            b.price,
+		Stock:            b.stock,
+		CategoryID:       b.categoryID,
+		TaxClass:         b.taxClass,
+		WeightGrams:      b.weight,
+		Version:          b.version,
+		ReorderThreshold: b.reorderThreshold,
+		ReleaseDate:      b.releaseDate,
+		Preorder:         b.preorder,
+		PreorderCap:      b.preorderCap,
+		Preorders:        b.preorders,
+		RatingCount:      b.ratingCount,
+		RatingTotal:      b.ratingTotal,
+		ISBN:             b.isbn,
+		Publisher:        b.publisher,
+		Edition:          b.edition,
+		Language:         b.language,
+		PageCount:        b.pageCount,
+		Format:           b.format,
+		Description:      b.description,
+		CategoryIDs:      b.categoryIDs,
+	}
+	v := validator{partial: true}
+	if patch.Title != nil {
+		data.Title = *patch.Title
+		v.only = append(v.only, "title")
+	}
+	if patch.Year != nil {
+		data.Year = *patch.Year
+		v.only = append(v.only, "year")
+	}
+	if patch.Author != nil {
+		data.Author = *patch.Author
+		if strings.TrimSpace(*patch.Author) != b.author {
+			data.Authors = nil
+		}
+		v.only = append(v.only, "author")
+	}
+	if patch.Authors != nil {
+		data.Authors = *patch.Authors
+		v.only = append(v.only, "authors")
+	}
+	if patch.Price != nil {
+		data.Price = *patch.Price
+		v.only = append(v.only, "price")
+	}
+	if patch.CategoryID != nil {
+		data.CategoryID = *patch.CategoryID
+		data.CategoryIDs = slices.DeleteFunc(slices.Clone(data.CategoryIDs), func(id int) bool {
+			return id == b.categoryID
+		})
+		v.only = append(v.only, "category_id")
+	}
+	if patch.CategoryIDs != nil {
+		data.CategoryIDs = *patch.CategoryIDs
+		if patch.CategoryID == nil {
+			data.CategoryID = 0
+		}
+		v.only = append(v.only, "category_id", "category_ids")
+	}
+	if patch.TaxClass != nil {
+		data.TaxClass = *patch.TaxClass
+		v.only = append(v.only, "tax_class")
+	}
+	if patch.WeightGrams != nil {
+		data.WeightGrams = *patch.WeightGrams
+		v.only = append(v.only, "weight_grams")
+	}
+	if patch.ReorderThreshold != nil {
+		data.ReorderThreshold = *patch.ReorderThreshold
+		v.only = append(v.only, "reorder_threshold")
+	}
+	if patch.ReleaseDate != nil {
+		data.ReleaseDate = *patch.ReleaseDate
+		v.only = append(v.only, "release_date")
+	}
+	// the cap is checked together with the pre-order flag
+	if patch.Preorder != nil || patch.PreorderCap != nil {
+		if patch.Preorder != nil {
+			data.Preorder = *patch.Preorder
+		}
+		if patch.PreorderCap != nil {
+			data.PreorderCap = *patch.PreorderCap
+		}
+		v.only = append(v.only, "preorder", "preorder_cap")
+	}
+	if patch.ISBN != nil {
+		data.ISBN = *patch.ISBN
+		v.only = append(v.only, "isbn")
+	}
+	if patch.Publisher != nil {
+		data.Publisher = *patch.Publisher
+		v.only = append(v.only, "publisher")
+	}
+	if patch.Edition != nil {
+		data.Edition = *patch.Edition
+		v.only = append(v.only, "edition")
+	}
+	if patch.Language != nil {
+		data.Language = *patch.Language
+		v.only = append(v.only, "language")
+	}
+	if patch.PageCount != nil {
+		data.PageCount = *patch.PageCount
+		v.only = append(v.only, "page_count")
+	}
+	if patch.Format != nil {
+		data.Format = *patch.Format
+		v.only = append(v.only, "format")
+	}
+	if patch.Description != nil {
+		data.Description = *patch.Description
+		v.only = append(v.only, "description")
+	}
+
+	patched, err := newBook(data, v)
+	if err != nil {
+		return Book{}, nil, err
+	}
+
+	var changed []string
+	for _, field := range v.only {
+		var same bool
+		switch field {
+		case "title":
+			same = patched.title == b.title
+		case "year":
+			same = patched.year == b.year
+		case "author":
+			same = patched.author == b.author
+		case "authors":
+			same = sameBookAuthors(patched.authors, b.authors)
+		case "price":
+			same = patched.price == b.price
+		case "category_id":
+			same = patched.categoryID == b.categoryID
+		case "category_ids":
+			same = slices.Equal(patched.categoryIDs, b.categoryIDs)
+		case "tax_class":
+			same = patched.taxClass == b.taxClass
+		case "weight_grams":
+			same = patched.weight == b.weight
+		case "reorder_threshold":
+			same = patched.reorderThreshold == b.reorderThreshold
+		case "release_date":
+			same = patched.releaseDate.Equal(b.releaseDate)
+		case "preorder":
+			same = patched.preorder == b.preorder
+		case "preorder_cap":
+			same = patched.preorderCap == b.preorderCap
+		case "isbn":
+			same = patched.isbn == b.isbn
+		case "publisher":
+			same = patched.publisher == b.publisher
+		case "edition":
+			same = patched.edition == b.edition
+		case "language":
+			same = patched.language == b.language
+		case "page_count":
+			same = patched.pageCount == b.pageCount
+		case "format":
+			same = patched.format == b.format
+		case "description":
+			same = patched.description == b.description
+		}
+		if !same {
+			changed = append(changed, field)
+		}
+	}
+
+	return patched, changed, nil
+}
@@ -1,19 +1,68 @@
 package domain
 
+import (
+	"fmt"
+	"slices"
+)
+
 type Cart struct {
-	userID  int
-	bookIDs []int
+	userID          int
+	bookIDs         []int
+	preorderBookIDs []int
+	couponCode      string
 }
 
 type NewCartData struct {
 	UserID  int
 	BookIDs []int
+	// PreorderBookIDs are the books of the cart pre-ordered instead of reserved in stock
+	PreorderBookIDs []int
+	CouponCode      string
 }
 
+// NewCart creates a new cart of a user with unique, positive book IDs, the pre-ordered books must be in the cart.
 func NewCart(data NewCartData) (Cart, error) {
+	return newCart(data, validator{})
+}
+
+// RestoreCart recreates a stored cart without validating it, so carts saved before the cart rules still load and
+// expire. Changed carts are validated by NewCart.
+func RestoreCart(data NewCartData) Cart {
+	cart, _ := newCart(data, restoring)
+	return cart
+}
+
+func newCart(data NewCartData, v validator) (Cart, error) {
+	v.check(data.UserID > 0, "user_id", ErrInvalidUserID)
+
+	seen := make(map[int]struct{}, len(data.BookIDs))
+	for _, bookID := range data.BookIDs {
+		_, duplicate := seen[bookID]
+		seen[bookID] = struct{}{}
+		if bookID <= 0 {
+			v.check(false, "book_ids", fmt.Errorf("%w: %d is not positive", ErrInvalidBookIDs, bookID))
+			break
+		}
+		if duplicate {
+			v.check(false, "book_ids", fmt.Errorf("%w: %d is duplicated", ErrInvalidBookIDs, bookID))
+			break
+		}
+	}
+	for _, bookID := range data.PreorderBookIDs {
+		if !slices.Contains(data.BookIDs, bookID) {
+			v.check(false, "preorder_book_ids", fmt.Errorf("%w: %d is not in the cart", ErrInvalidBookIDs, bookID))
+			break
+		}
+	}
+	if err := v.err(); err != nil {
+		return Cart{}, err
+	}
+
 	return Cart{
-		userID:  data.UserID,
-		bookIDs: data.BookIDs,
+		userID:          data.UserID,
+		bookIDs:         data.BookIDs,
+		preorderBookIDs: data.PreorderBookIDs,
+		couponCode:      NormalizeCouponCode(data.CouponCode),
 	}, nil
 }
 
@@ -25,6 +74,21 @@ func (c Cart) BookIDs() []int {
 	return c.bookIDs
 }
 
+// PreorderBookIDs returns the books of the cart pre-ordered instead of reserved in stock.
+func (c Cart) PreorderBookIDs() []int {
+	return c.preorderBookIDs
+}
+
+// IsPreorder reports whether a book of the cart is pre-ordered.
+func (c Cart) IsPreorder(bookID int) bool {
+	return slices.Contains(c.preorderBookIDs, bookID)
+}
+
+// CouponCode returns the coupon applied to the cart, if any.
+func (c Cart) CouponCode() string {
+	return c.couponCode
+}
+
 func (c Cart) Diff(old Cart) Cart {
 	diff := Cart{
 		userID:  c.userID,
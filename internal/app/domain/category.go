@@ -1,21 +1,51 @@
 package domain
 
+import (
+	"errors"
+	"strings"
+)
+
+// ErrCategoryCycle is returned when a category would become its own ancestor.
+var ErrCategoryCycle = errors.New("category cannot be its own ancestor")
+
 // Category is a domain category.
 type Category struct {
 	id   int
 	name string
+	// parentID is the category the category belongs to, zero for top level categories
+	parentID int
+	version  int
 }
 
 type NewCategoryData struct {
 	ID   int
 	Name string
+	// ParentID is the category the category belongs to, zero for top level categories
+	ParentID int
+	// Version is incremented by every edit, edits of a stale version are rejected
+	Version int
 }
 
-// NewCategory creates a new book.
+// NewCategory creates a new category with a trimmed, non-empty name, a category cannot be its own parent.
 func NewCategory(data NewCategoryData) (Category, error) {
+	return newCategory(data, validator{})
+}
+
+func newCategory(data NewCategoryData, v validator) (Category, error) {
+	name := strings.TrimSpace(data.Name)
+
+	v.check(name != "", "name", ErrRequired)
+	v.check(data.ParentID >= 0, "parent_id", ErrNegative)
+	v.check(data.ID == 0 || data.ParentID != data.ID, "parent_id", ErrCategoryCycle)
+	if err := v.err(); err != nil {
+		return Category{}, err
+	}
+
 	return Category{
-		id:   data.ID,
-		name: data.Name,
+		id:       data.ID,
+		name:     name,
+		parentID: data.ParentID,
+		version:  data.Version,
 	}, nil
 }
 
@@ -28,3 +58,89 @@ func (b Category) ID() int {
 func (b Category) Name() string {
 	return b.name
 }
+
+// ParentID returns the category the category belongs to, zero for top level categories.
+func (b Category) ParentID() int {
+	return b.parentID
+}
+
+// Version returns the version of the category, it changes with every edit.
+func (b Category) Version() int {
+	return b.version
+}
+
+// CategoryPatch lists the fields of a category to change, nil fields are left unchanged.
+type CategoryPatch struct {
+	Name *string
+	// ParentID is set to zero to make the category a top level category
+	ParentID *int
+}
+
+// Patch returns the category with the patch applied and the fields it changed, fields set to their current value
+// are not changed. Only the fields of the patch are validated, the version is kept for the update to check.
+func (b Category) Patch(patch CategoryPatch) (Category, []string, error) {
+	data := NewCategoryData{
+		ID:       b.id,
+		Name:     b.name,
+		ParentID: b.parentID,
+		Version:  b.version,
+	}
+	v := validator{partial: true}
+	if patch.Name != nil {
+		data.Name = *patch.Name
+		v.only = append(v.only, "name")
+	}
+	if patch.ParentID != nil {
+		data.ParentID = *patch.ParentID
+		v.only = append(v.only, "parent_id")
+	}
+
+	patched, err := newCategory(data, v)
+	if err != nil {
+		return Category{}, nil, err
+	}
+
+	var changed []string
+	if patch.Name != nil && patched.name != b.name {
+		changed = append(changed, "name")
+	}
+	if patch.ParentID != nil && patched.parentID != b.parentID {
+		changed = append(changed, "parent_id")
+	}
+
+	return patched, changed, nil
+}
+
+// CategoryNode is a category with the categories below it.
+type CategoryNode struct {
+	Category Category
+	Children []CategoryNode
+}
+
+// CategoryTree arranges categories into trees under their parents, keeping their order. Categories whose parent is
+// not listed are roots.
+func CategoryTree(categories []Category) []CategoryNode {
+	listed := make(map[int]bool, len(categories))
+	children := make(map[int][]Category, len(categories))
+	for _, category := range categories {
+		listed[category.id] = true
+		children[category.parentID] = append(children[category.parentID], category)
+	}
+
+	var build func(category Category) CategoryNode
+	build = func(category Category) CategoryNode {
+		node := CategoryNode{Category: category, Children: []CategoryNode{}}
+		for _, child := range children[category.id] {
+			node.Children = append(node.Children, build(child))
+		}
+		return node
+	}
+
+	roots := []CategoryNode{}
+	for _, category := range categories {
+		if category.parentID == 0 || !listed[category.parentID] {
+			roots = append(roots, build(category))
+		}
+	}
+	return roots
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCategory_Parent(t *testing.T) {
+	category, err := NewCategory(NewCategoryData{Name: " Epic Fantasy ", ParentID: 3})
+	require.NoError(t, err)
+	require.Equal(t, "Epic Fantasy", category.Name())
+	require.Equal(t, 3, category.ParentID())
+
+	_, err = NewCategory(NewCategoryData{ID: 3, Name: "Fantasy", ParentID: 3})
+	require.Equal(t, []string{"parent_id"}, validationFields(t, err))
+	require.ErrorIs(t, err, ErrCategoryCycle)
+}
+
+func TestCategory_PatchParent(t *testing.T) {
+	category, err := NewCategory(NewCategoryData{ID: 4, Name: "Epic Fantasy", ParentID: 3, Version: 2})
+	require.NoError(t, err)
+
+	root := 0
+	patched, changed, err := category.Patch(CategoryPatch{ParentID: &root})
+	require.NoError(t, err)
+	require.Equal(t, []string{"parent_id"}, changed)
+	require.Zero(t, patched.ParentID())
+
+	self := 4
+	_, _, err = category.Patch(CategoryPatch{ParentID: &self})
+	require.ErrorIs(t, err, ErrCategoryCycle)
+}
+
+func TestCategoryTree(t *testing.T) {
+	category := func(id, parentID int, name string) Category {
+		category, err := NewCategory(NewCategoryData{ID: id, Name: name, ParentID: parentID})
+		require.NoError(t, err)
+		return category
+	}
+	tree := CategoryTree([]Category{
+		category(1, 0, "Fiction"),
+		category(2, 1, "Fantasy"),
+		category(3, 0, "Young Adult"),
+		category(4, 2, "Epic Fantasy"),
+		category(5, 9, "Orphan"),
+	})
+
+	require.Len(t, tree, 3)
+	require.Equal(t, "Fiction", tree[0].Category.Name())
+	require.Equal(t, "Fantasy", tree[0].Children[0].Category.Name())
+	require.Equal(t, "Epic Fantasy", tree[0].Children[0].Children[0].Category.Name())
+	require.Empty(t, tree[1].Children)
+	require.Equal(t, "Orphan", tree[2].Category.Name())
+}
+
+func TestNewBook_Categories(t *testing.T) {
+	data := NewBookData{
+		Title:       "Eragon",
+		Year:        2002,
+		Author:      "Christopher Paolini",
+		Price:       Money{amount: 1000, currency: SettlementCurrency},
+		CategoryIDs: []int{3, 7},
+	}
+	book, err := NewBook(data)
+	require.NoError(t, err)
+	require.Equal(t, 3, book.CategoryID())
+	require.Equal(t, []int{3, 7}, book.CategoryIDs())
+
+	data.CategoryID = 7
+	book, err = NewBook(data)
+	require.NoError(t, err)
+	require.Equal(t, []int{7, 3}, book.CategoryIDs())
+
+	data.CategoryIDs = []int{3, 3, 0}
+	_, err = NewBook(data)
+	require.Equal(t, []string{"category_ids", "category_ids"}, validationFields(t, err))
+}
+
+func TestBook_PatchCategories(t *testing.T) {
+	book, err := NewBook(NewBookData{
+		ID:          1,
+		Title:       "Eragon",
+		Year:        2002,
+		Author:      "Christopher Paolini",
+		Price:       Money{amount: 1000, currency: SettlementCurrency},
+		CategoryIDs: []int{3, 7},
+	})
+	require.NoError(t, err)
+
+	primary := 5
+	patched, changed, err := book.Patch(BookPatch{CategoryID: &primary})
+	require.NoError(t, err)
+	require.Equal(t, []string{"category_id"}, changed)
+	require.Equal(t, []int{5, 7}, patched.CategoryIDs())
+
+	categoryIDs := []int{7, 3}
+	patched, changed, err = book.Patch(BookPatch{CategoryIDs: &categoryIDs})
+	require.NoError(t, err)
+	require.Equal(t, []string{"category_id", "category_ids"}, changed)
+	require.Equal(t, 7, patched.CategoryID())
+
+	same := []int{3, 7}
+	_, changed, err = book.Patch(BookPatch{CategoryIDs: &same})
+	require.NoError(t, err)
+	require.Empty(t, changed)
+
+	none := []int{}
+	_, _, err = book.Patch(BookPatch{CategoryIDs: &none})
+	require.Equal(t, []string{"category_id"}, validationFields(t, err))
+}
+
+func TestPromotion_DiscountSecondaryCategory(t *testing.T) {
+	lines := []QuoteLine{
+		{BookID: 1, Price: usd(t, 1000), CategoryID: 3, CategoryIDs: []int{3, 7}},
+		{BookID: 2, Price: usd(t, 2000), CategoryID: 4, CategoryIDs: []int{4}},
+	}
+
+	youngAdult, err := NewPromotion(NewPromotionData{Kind: PromotionPercent, Percent: 10, Scope: PromotionScopeCategory, CategoryID: 7, Active: true})
+	require.NoError(t, err)
+	discount, err := youngAdult.Discount(lines)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), discount.Amount())
+}
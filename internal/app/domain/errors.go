@@ -1,13 +1,83 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"slices"
+	"strings"
+)
 
 var (
 	ErrRequired        = errors.New("required value")
 	ErrNotFound        = errors.New("not found")
 	ErrNil             = errors.New("nil data")
 	ErrNegative        = errors.New("negative value")
+	ErrNotPositive     = errors.New("must be positive")
+	ErrOutOfRange      = errors.New("out of range")
+	ErrInvalidFormat   = errors.New("invalid format")
 	ErrInvalidUserID   = errors.New("invalid user ID")
 	ErrInvalidBookIDs  = errors.New("invalid book IDs")
 	ErrNoUserInContext = errors.New("no user in context")
+	ErrInvalidWebhook  = errors.New("invalid webhook")
 )
+
+// ValidationError is a violated invariant of a single field.
+type ValidationError struct {
+	Field string
+	Err   error
+}
+
+func (e ValidationError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors lists every violated invariant of a domain object.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// validator collects violated invariants so constructors report all of them at once.
+type validator struct {
+	errs ValidationErrors
+	// partial limits the checks to the fields listed in only, as when patching an object
+	partial bool
+	only    []string
+}
+
+// restoring checks no field, it recreates stored objects that may predate their invariants.
+var restoring = validator{partial: true}
+
+// check records err for field unless ok holds.
+func (v *validator) check(ok bool, field string, err error) {
+	if v.partial && !slices.Contains(v.only, field) {
+		return
+	}
+	if !ok {
+		v.errs = append(v.errs, ValidationError{Field: field, Err: err})
+	}
+}
+
+// err returns the collected violations, or nil when there are none.
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
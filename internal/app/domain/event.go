@@ -0,0 +1,199 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Event types published through the outbox.
+const (
+	EventBookCreated       = "book.created"
+	EventBookStockChanged  = "book.stock_changed"
+	EventBookOutOfStock    = "book.out_of_stock"
+	EventBookLowStock      = "book.low_stock"
+	EventBookBackInStock   = "book.back_in_stock"
+	EventCheckoutCompleted = "checkout.completed"
+	// EventOrderReleased is published when the pre-ordered books of an order are all fulfilled
+	EventOrderReleased = "order.released"
+	// EventUserBackInStock is the notice sent to a user subscribed to a book back in stock
+	EventUserBackInStock = "user.back_in_stock"
+)
+
+// Aggregate types the events relate to.
+const (
+	AggregateBook = "book"
+	AggregateUser = "user"
+)
+
+// Event is a domain event.
+type Event struct {
+	id            int64
+	eventType     string
+	aggregateType string
+	aggregateID   int
+	payload       json.RawMessage
+	occurredAt    time.Time
+}
+
+type NewEventData struct {
+	ID            int64
+	Type          string
+	AggregateType string
+	AggregateID   int
+	// Payload is either raw JSON or a value marshalled to JSON.
+	Payload    any
+	OccurredAt time.Time
+}
+
+// NewEvent creates a new event.
+func NewEvent(data NewEventData) (Event, error) {
+	if data.Type == "" {
+		return Event{}, fmt.Errorf("%w: event type", ErrRequired)
+	}
+
+	var payload json.RawMessage
+	switch p := data.Payload.(type) {
+	case json.RawMessage:
+		payload = p
+	case []byte:
+		payload = p
+	default:
+		var err error
+		payload, err = json.Marshal(p)
+		if err != nil {
+			return Event{}, fmt.Errorf("failed to marshal event payload: %w", err)
+		}
+	}
+
+	occurredAt := data.OccurredAt
+	if occurredAt.IsZero() {
+		occurredAt = time.Now()
+	}
+
+	return Event{
+		id:            data.ID,
+		eventType:     data.Type,
+		aggregateType: data.AggregateType,
+		aggregateID:   data.AggregateID,
+		payload:       payload,
+		occurredAt:    occurredAt,
+	}, nil
+}
+
+// ID returns the event ID.
+func (e Event) ID() int64 {
+	return e.id
+}
+
+// Type returns the event type.
+func (e Event) Type() string {
+	return e.eventType
+}
+
+// AggregateType returns the type of the aggregate the event relates to.
+func (e Event) AggregateType() string {
+	return e.aggregateType
+}
+
+// AggregateID returns the ID of the aggregate the event relates to.
+func (e Event) AggregateID() int {
+	return e.aggregateID
+}
+
+// Payload returns the JSON payload of the event.
+func (e Event) Payload() json.RawMessage {
+	return e.payload
+}
+
+// OccurredAt returns the time the event occurred.
+func (e Event) OccurredAt() time.Time {
+	return e.occurredAt
+}
+
+// OutboxDelivery is the delivery of an outbox event to a sink, every sink receives the events on its own so a failing
+// sink doesn't hold back the others.
+type OutboxDelivery struct {
+	Event Event
+	Sink  string
+	// Attempts is the number of attempts made so far
+	Attempts  int
+	LastError string
+	// NextAttemptAt is when a failed delivery is retried
+	NextAttemptAt time.Time
+	DeliveredAt   time.Time
+	// DeadAt is set when the delivery ran out of attempts, the sink no longer receives the event
+	DeadAt time.Time
+}
+
+// WithAttempt returns the delivery updated with the outcome of an attempt made at attemptedAt.
+// A failed delivery is retried at retryAt, or dead-lettered when retryAt is zero.
+func (d OutboxDelivery) WithAttempt(err error, attemptedAt, retryAt time.Time) OutboxDelivery {
+	d.Attempts++
+
+	switch {
+	case err == nil:
+		d.LastError = ""
+		d.DeliveredAt = attemptedAt
+	case retryAt.IsZero():
+		d.LastError = err.Error()
+		d.DeadAt = attemptedAt
+	default:
+		d.LastError = err.Error()
+		d.NextAttemptAt = retryAt
+	}
+
+	return d
+}
+
+// BookCreatedPayload is the payload of EventBookCreated.
+type BookCreatedPayload struct {
+	BookID     int    `json:"book_id"`
+	Title      string `json:"title"`
+	Author     string `json:"author"`
+	Stock      int    `json:"stock"`
+	CategoryID int    `json:"category_id"`
+}
+
+// StockChangedPayload is the payload of EventBookStockChanged.
+type StockChangedPayload struct {
+	BookID int `json:"book_id"`
+	Stock  int `json:"stock"`
+	Delta  int `json:"delta"`
+}
+
+// OutOfStockPayload is the payload of EventBookOutOfStock.
+type OutOfStockPayload struct {
+	BookID int `json:"book_id"`
+}
+
+// BackInStockPayload is the payload of EventBookBackInStock.
+type BackInStockPayload struct {
+	BookID int `json:"book_id"`
+	Stock  int `json:"stock"`
+}
+
+// LowStockPayload is the payload of EventBookLowStock.
+type LowStockPayload struct {
+	BookID           int    `json:"book_id"`
+	Title            string `json:"title"`
+	Stock            int    `json:"stock"`
+	ReorderThreshold int    `json:"reorder_threshold"`
+	SoldRecently     int    `json:"sold_recently"`
+	SuggestedReorder int    `json:"suggested_reorder"`
+}
+
+// OrderReleasedPayload is the payload of EventOrderReleased.
+type OrderReleasedPayload struct {
+	UserID  int `json:"user_id"`
+	OrderID int `json:"order_id"`
+}
+
+// CheckoutCompletedPayload is the payload of EventCheckoutCompleted.
+type CheckoutCompletedPayload struct {
+	UserID      int    `json:"user_id"`
+	OrderID     int    `json:"order_id"`
+	BookIDs     []int  `json:"book_ids"`
+	TotalAmount int64  `json:"total_amount"`
+	Currency    string `json:"currency"`
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// exchangeRateScale is the number of decimal digits exchange rates are kept with.
+const exchangeRateScale = 8
+
+// ExchangeRate is the amount of a display currency one unit of the settlement currency is worth.
+type ExchangeRate struct {
+	currency  string
+	rate      *big.Rat
+	updatedAt time.Time
+}
+
+type NewExchangeRateData struct {
+	Currency string
+	// Rate is a positive decimal, e.g. "0.92" EUR per USD
+	Rate      string
+	UpdatedAt time.Time
+}
+
+// NewExchangeRate creates a new exchange rate from the settlement currency to a display currency.
+func NewExchangeRate(data NewExchangeRateData) (ExchangeRate, error) {
+	currency := strings.ToUpper(strings.TrimSpace(data.Currency))
+
+	var v validator
+	_, err := CurrencyExponent(currency)
+	v.check(err == nil, "currency", ErrUnknownCurrency)
+	v.check(currency != SettlementCurrency, "currency", fmt.Errorf("%w: %s is the settlement currency", ErrInvalidFormat, SettlementCurrency))
+
+	rate, ok := new(big.Rat).SetString(strings.TrimSpace(data.Rate))
+	switch {
+	case strings.TrimSpace(data.Rate) == "":
+		v.check(false, "rate", ErrRequired)
+	case !ok || strings.ContainsAny(data.Rate, "/eE"):
+		v.check(false, "rate", ErrInvalidFormat)
+	default:
+		v.check(rate.Sign() > 0, "rate", ErrNotPositive)
+	}
+	if err := v.err(); err != nil {
+		return ExchangeRate{}, err
+	}
+
+	return ExchangeRate{
+		currency:  currency,
+		rate:      rate,
+		updatedAt: data.UpdatedAt,
+	}, nil
+}
+
+// Currency returns the display currency.
+func (r ExchangeRate) Currency() string {
+	return r.currency
+}
+
+// Rate returns the rate as a decimal string without trailing zeros.
+func (r ExchangeRate) Rate() string {
+	rate := r.rate.FloatString(exchangeRateScale)
+	rate = strings.TrimRight(rate, "0")
+	return strings.TrimSuffix(rate, ".")
+}
+
+// UpdatedAt returns when the rate was last set.
+func (r ExchangeRate) UpdatedAt() time.Time {
+	return r.updatedAt
+}
+
+// Convert converts an amount of the settlement currency to the display currency.
+// The exact result is rounded half away from zero to the minor unit of the display currency,
+// so the same price and rate always give the same display price.
+func (r ExchangeRate) Convert(money Money) (Money, error) {
+	if money.Currency() != SettlementCurrency {
+		return Money{}, fmt.Errorf("%w: can only convert %s, got %s", ErrCurrencyMismatch, SettlementCurrency, money.Currency())
+	}
+
+	fromExponent, err := CurrencyExponent(money.Currency())
+	if err != nil {
+		return Money{}, err
+	}
+	toExponent, err := CurrencyExponent(r.currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	converted := new(big.Rat).Mul(new(big.Rat).SetInt64(money.Amount()), r.rate)
+	converted.Mul(converted, new(big.Rat).SetFrac(pow10(toExponent), pow10(fromExponent)))
+
+	return NewMoney(roundHalfAwayFromZero(converted), r.currency)
+}
+
+func pow10(exponent int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+}
+
+// roundHalfAwayFromZero rounds a rational to the nearest integer, halves are rounded away from zero.
+func roundHalfAwayFromZero(r *big.Rat) int64 {
+	num := new(big.Int).Abs(r.Num())
+	den := r.Denom()
+
+	// (2 * |num| + den) / (2 * den) is |r| rounded half up
+	rounded := new(big.Int).Mul(num, big.NewInt(2))
+	rounded.Add(rounded, den)
+	rounded.Quo(rounded, new(big.Int).Mul(den, big.NewInt(2)))
+
+	if r.Sign() < 0 {
+		rounded.Neg(rounded)
+	}
+	return rounded.Int64()
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// IdempotencyKeyTTL is how long responses are kept for replay.
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// maxIdempotencyKeyLength bounds client-chosen keys, UUIDs are recommended.
+const maxIdempotencyKeyLength = 255
+
+var (
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was already used for a different request")
+	ErrIdempotencyKeyInProgress = errors.New("a request with the idempotency key is still in progress")
+)
+
+// IdempotentRequest is a request a user sent with an idempotency key. Keys are scoped to the user,
+// the fingerprint tells a retry of the same request from a reuse of the key for another one.
+type IdempotentRequest struct {
+	userID      int
+	key         string
+	fingerprint string
+}
+
+// NewIdempotentRequest creates a new idempotent request, the fingerprint covers the method, path, raw query and body.
+func NewIdempotentRequest(userID int, key, method, path, query string, body []byte) (IdempotentRequest, error) {
+	var v validator
+	v.check(userID > 0, "user_id", ErrInvalidUserID)
+	v.check(key != "", "idempotency_key", ErrRequired)
+	v.check(len(key) <= maxIdempotencyKeyLength && isPrintableASCII(key), "idempotency_key", ErrInvalidFormat)
+	if err := v.err(); err != nil {
+		return IdempotentRequest{}, err
+	}
+
+	hash := sha256.New()
+	target := path
+	if query != "" {
+		target += "?" + query
+	}
+	hash.Write([]byte(method + " " + target + "\n"))
+	hash.Write(body)
+
+	return IdempotentRequest{
+		userID:      userID,
+		key:         key,
+		fingerprint: hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+// UserID returns the ID of the user who sent the request.
+func (r IdempotentRequest) UserID() int {
+	return r.userID
+}
+
+// Key returns the idempotency key.
+func (r IdempotentRequest) Key() string {
+	return r.key
+}
+
+// Fingerprint returns the hex-encoded SHA-256 of the request.
+func (r IdempotentRequest) Fingerprint() string {
+	return r.fingerprint
+}
+
+// IdempotentResponse is the response stored for an idempotency key and replayed to retries.
+type IdempotentResponse struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+func isPrintableASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
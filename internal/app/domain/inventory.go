@@ -0,0 +1,291 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Types of inventory movements, every change of the stock of a book is recorded as one.
+const (
+	MovementRestock = "restock"
+	// MovementSale records a reserved book sold at checkout, it follows the release of its reservation
+	MovementSale = "sale"
+	// MovementReservation records a book added to a cart, carts hold their books out of stock
+	MovementReservation = "reservation"
+	// MovementRelease records a book removed from a cart, expired with it or sold from it
+	MovementRelease = "release"
+	// MovementCorrection records a manual correction of the stock, it requires a reason
+	MovementCorrection = "correction"
+	MovementReturn     = "return"
+	// MovementReconciliation records a correction of stock drift by the inventory reconciliation, unlike manual
+	// corrections it does not count as stock received
+	MovementReconciliation = "reconciliation"
+)
+
+// AdjustmentTypes lists the movement types admins can record, the others are recorded by carts and checkouts.
+var AdjustmentTypes = []string{MovementRestock, MovementCorrection, MovementReturn}
+
+// maxMovementReasonLength bounds the reason of a movement.
+const maxMovementReasonLength = 500
+
+var ErrInsufficientStock = errors.New("not enough stock")
+
+// InventoryMovement is an entry of the inventory ledger: a change of the stock of a book and the stock after it.
+type InventoryMovement struct {
+	id           int64
+	bookID       int
+	movementType string
+	delta        int
+	stockAfter   int
+	reason       string
+	orderID      int
+	userID       int
+	createdAt    time.Time
+}
+
+type NewInventoryMovementData struct {
+	ID     int64
+	BookID int
+	Type   string
+	// Delta is the change of the stock, positive for restocks, releases and returns and negative for
+	// reservations and sales
+	Delta      int
+	StockAfter int
+	Reason     string
+	// OrderID is the order a sale or a return relates to, zero for none
+	OrderID int
+	// UserID is the user whose cart reserved the book or the admin who adjusted the stock, zero for jobs
+	UserID    int
+	CreatedAt time.Time
+}
+
+// NewInventoryMovement creates a new inventory movement. The sign of the delta must match the type,
+// corrections may go either way but must give a reason.
+func NewInventoryMovement(data NewInventoryMovementData) (InventoryMovement, error) {
+	movementType := strings.ToLower(strings.TrimSpace(data.Type))
+	reason := strings.TrimSpace(data.Reason)
+
+	var v validator
+	v.check(data.BookID > 0, "book_id", ErrRequired)
+	switch movementType {
+	case MovementRestock, MovementRelease, MovementReturn:
+		v.check(data.Delta > 0, "delta", ErrNotPositive)
+	case MovementReservation, MovementSale:
+		v.check(data.Delta < 0, "delta", fmt.Errorf("%w: %s must decrease the stock", ErrOutOfRange, movementType))
+	case MovementCorrection, MovementReconciliation:
+		v.check(data.Delta != 0, "delta", ErrRequired)
+		v.check(reason != "", "reason", ErrRequired)
+	case "":
+		v.check(false, "type", ErrRequired)
+	default:
+		v.check(false, "type", fmt.Errorf("%w: unknown movement type %q", ErrInvalidFormat, movementType))
+	}
+	v.check(utf8.RuneCountInString(reason) <= maxMovementReasonLength, "reason", ErrOutOfRange)
+	v.check(data.StockAfter >= 0, "stock_after", ErrNegative)
+	if err := v.err(); err != nil {
+		return InventoryMovement{}, err
+	}
+
+	return InventoryMovement{
+		id:           data.ID,
+		bookID:       data.BookID,
+		movementType: movementType,
+		delta:        data.Delta,
+		stockAfter:   data.StockAfter,
+		reason:       reason,
+		orderID:      data.OrderID,
+		userID:       data.UserID,
+		createdAt:    data.CreatedAt,
+	}, nil
+}
+
+// NewStockAdjustment creates a movement recorded by an admin, the stock after it is set when it is applied.
+func NewStockAdjustment(bookID int, movementType string, delta int, reason string, orderID, adminID int) (InventoryMovement, error) {
+	movementType = strings.ToLower(strings.TrimSpace(movementType))
+	if movementType != "" && !slices.Contains(AdjustmentTypes, movementType) {
+		return InventoryMovement{}, ValidationErrors{{
+			Field: "type",
+			Err:   fmt.Errorf("%w: stock adjustments are one of %s", ErrInvalidFormat, strings.Join(AdjustmentTypes, ", ")),
+		}}
+	}
+
+	return NewInventoryMovement(NewInventoryMovementData{
+		BookID:  bookID,
+		Type:    movementType,
+		Delta:   delta,
+		Reason:  reason,
+		OrderID: orderID,
+		UserID:  adminID,
+	})
+}
+
+// Apply returns the movement applied to a stock, it fails when the stock would become negative.
+func (m InventoryMovement) Apply(stock int) (InventoryMovement, error) {
+	if stock+m.delta < 0 {
+		return InventoryMovement{}, fmt.Errorf("%w: %d in stock, %d requested", ErrInsufficientStock, stock, -m.delta)
+	}
+	m.stockAfter = stock + m.delta
+	return m, nil
+}
+
+// ID returns the movement ID.
+func (m InventoryMovement) ID() int64 {
+	return m.id
+}
+
+// BookID returns the ID of the book whose stock changed.
+func (m InventoryMovement) BookID() int {
+	return m.bookID
+}
+
+// Type returns the movement type.
+func (m InventoryMovement) Type() string {
+	return m.movementType
+}
+
+// Delta returns the change of the stock.
+func (m InventoryMovement) Delta() int {
+	return m.delta
+}
+
+// StockAfter returns the stock of the book after the movement.
+func (m InventoryMovement) StockAfter() int {
+	return m.stockAfter
+}
+
+// Reason returns why the stock was adjusted, empty for movements without one.
+func (m InventoryMovement) Reason() string {
+	return m.reason
+}
+
+// OrderID returns the order the movement relates to, zero for none.
+func (m InventoryMovement) OrderID() int {
+	return m.orderID
+}
+
+// UserID returns the user who caused the movement, zero for jobs.
+func (m InventoryMovement) UserID() int {
+	return m.userID
+}
+
+// CreatedAt returns when the movement was recorded.
+func (m InventoryMovement) CreatedAt() time.Time {
+	return m.createdAt
+}
+
+// StockCount is the stock of a book together with the counts its expected availability is computed from.
+type StockCount struct {
+	BookID int
+	Title  string
+	Stock  int
+	// Received is the stock brought in by the initial stock, restocks, returns and manual corrections
+	Received int
+	// Sold is the number of books sold by orders placed since the ledger of the book was opened, pre-ordered books
+	// count once fulfilled from stock received since then
+	Sold int
+	// Reserved is the number of carts holding the book
+	Reserved int
+}
+
+// Expected returns the stock the book should have.
+func (c StockCount) Expected() int {
+	return c.Received - c.Sold - c.Reserved
+}
+
+// Drift returns how many books the stock has in excess of the expected stock, negative when books are missing.
+func (c StockCount) Drift() int {
+	return c.Stock - c.Expected()
+}
+
+// Repair returns the reconciliation movement that sets the stock to the expected stock.
+// Stock expected below zero cannot be repaired, there are more books sold or reserved than were received.
+func (c StockCount) Repair() (InventoryMovement, error) {
+	if c.Expected() < 0 {
+		return InventoryMovement{}, fmt.Errorf("%w: book %d expects %d in stock", ErrInsufficientStock, c.BookID, c.Expected())
+	}
+
+	return NewInventoryMovement(NewInventoryMovementData{
+		BookID:     c.BookID,
+		Type:       MovementReconciliation,
+		Delta:      -c.Drift(),
+		StockAfter: c.Expected(),
+		Reason:     fmt.Sprintf("stock %d, expected %d", c.Stock, c.Expected()),
+	})
+}
+
+// SalesVelocityWindow is the period recent sales are counted over to suggest reorder quantities.
+const SalesVelocityWindow = 30 * 24 * time.Hour
+
+// LowStockRealertInterval is after how long the staff is alerted again of a book still low on stock.
+const LowStockRealertInterval = 7 * 24 * time.Hour
+
+// LowStockItem is a book at or below its reorder threshold with its recent sales.
+type LowStockItem struct {
+	BookID           int
+	Title            string
+	Stock            int
+	ReorderThreshold int
+	// SoldRecently is the number of books sold within the sales velocity window
+	SoldRecently int
+	// AlertedAt is when the staff was last alerted of the book being low on stock, zero when it was not since
+	// the stock last recovered
+	AlertedAt time.Time
+}
+
+// AlertDue reports whether the staff should be alerted of the book, it is alerted once when its stock runs low and
+// again every LowStockRealertInterval while it stays low.
+func (i LowStockItem) AlertDue(now time.Time) bool {
+	return i.AlertedAt.IsZero() || !now.Before(i.AlertedAt.Add(LowStockRealertInterval))
+}
+
+// DailyVelocity returns the number of books sold per day within the sales velocity window.
+func (i LowStockItem) DailyVelocity() float64 {
+	return float64(i.SoldRecently) / SalesVelocityWindow.Hours() * 24
+}
+
+// DaysOfCover returns how many days the stock lasts at the recent sales pace, false when the book did not sell.
+func (i LowStockItem) DaysOfCover() (int, bool) {
+	if i.SoldRecently == 0 {
+		return 0, false
+	}
+	return int(float64(i.Stock) / i.DailyVelocity()), true
+}
+
+// SuggestedReorder returns how many books to reorder so the stock covers another window of sales at the recent pace
+// and stays above the reorder threshold.
+func (i LowStockItem) SuggestedReorder() int {
+	target := max(i.SoldRecently+i.ReorderThreshold, i.ReorderThreshold+1)
+	return max(target-i.Stock, 0)
+}
+
+// Notification returns the low stock alert of the book for the shop staff.
+func (i LowStockItem) Notification() Notification {
+	body := fmt.Sprintf("%q (book %d) has %d in stock, its reorder threshold is %d.\n", i.Title, i.BookID, i.Stock, i.ReorderThreshold)
+	if days, ok := i.DaysOfCover(); ok {
+		body += fmt.Sprintf("%d sold in the last %d days, the stock lasts about %d more days.\n",
+			i.SoldRecently, int(SalesVelocityWindow.Hours()/24), days)
+	} else {
+		body += fmt.Sprintf("None sold in the last %d days.\n", int(SalesVelocityWindow.Hours()/24))
+	}
+	body += fmt.Sprintf("Suggested reorder: %d.\n", i.SuggestedReorder())
+
+	return Notification{
+		Type:          EventBookLowStock,
+		AggregateType: AggregateBook,
+		AggregateID:   i.BookID,
+		Subject:       fmt.Sprintf("Low stock: %s (%d left)", i.Title, i.Stock),
+		Body:          body,
+		Payload: LowStockPayload{
+			BookID:           i.BookID,
+			Title:            i.Title,
+			Stock:            i.Stock,
+			ReorderThreshold: i.ReorderThreshold,
+			SoldRecently:     i.SoldRecently,
+			SuggestedReorder: i.SuggestedReorder(),
+		},
+	}
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStockAdjustment(t *testing.T) {
+	restock, err := NewStockAdjustment(1, "Restock", 5, "", 0, 2)
+	require.NoError(t, err)
+	require.Equal(t, MovementRestock, restock.Type())
+
+	applied, err := restock.Apply(3)
+	require.NoError(t, err)
+	require.Equal(t, 8, applied.StockAfter())
+
+	correction, err := NewStockAdjustment(1, MovementCorrection, -4, "damaged in storage", 0, 2)
+	require.NoError(t, err)
+	_, err = correction.Apply(3)
+	require.ErrorIs(t, err, ErrInsufficientStock)
+
+	_, err = NewStockAdjustment(1, MovementCorrection, 0, " ", 0, 2)
+	require.Equal(t, []string{"delta", "reason"}, validationFields(t, err))
+
+	_, err = NewStockAdjustment(1, MovementReturn, -1, "", 7, 2)
+	require.Equal(t, []string{"delta"}, validationFields(t, err))
+
+	// movements of carts and checkouts are not recorded by admins
+	_, err = NewStockAdjustment(1, MovementSale, -1, "", 0, 2)
+	require.Equal(t, []string{"type"}, validationFields(t, err))
+}
+
+func TestStockCount_Repair(t *testing.T) {
+	count := StockCount{BookID: 1, Stock: 7, Received: 10, Sold: 4, Reserved: 1}
+	require.Equal(t, 5, count.Expected())
+	require.Equal(t, 2, count.Drift())
+
+	repair, err := count.Repair()
+	require.NoError(t, err)
+	require.Equal(t, MovementReconciliation, repair.Type())
+	require.Equal(t, -2, repair.Delta())
+	require.Equal(t, 5, repair.StockAfter())
+
+	count = StockCount{BookID: 1, Stock: 0, Received: 2, Sold: 3}
+	require.Equal(t, 1, count.Drift())
+	_, err = count.Repair()
+	require.ErrorIs(t, err, ErrInsufficientStock)
+}
+
+func TestLowStockItem_SuggestedReorder(t *testing.T) {
+	item := LowStockItem{BookID: 1, Title: "Dune", Stock: 2, ReorderThreshold: 5, SoldRecently: 30}
+	require.Equal(t, 33, item.SuggestedReorder())
+	days, ok := item.DaysOfCover()
+	require.True(t, ok)
+	require.Equal(t, 2, days)
+
+	// books that did not sell are restocked just above the threshold
+	item = LowStockItem{BookID: 2, Title: "Emma", Stock: 0, ReorderThreshold: 0}
+	require.Equal(t, 1, item.SuggestedReorder())
+	_, ok = item.DaysOfCover()
+	require.False(t, ok)
+
+	notification := item.Notification()
+	require.Equal(t, EventBookLowStock, notification.Type)
+	require.Equal(t, 2, notification.AggregateID)
+}
+
+func TestLowStockItem_AlertDue(t *testing.T) {
+	now := time.Now()
+	require.True(t, LowStockItem{BookID: 1}.AlertDue(now))
+	require.False(t, LowStockItem{BookID: 1, AlertedAt: now.Add(-time.Hour)}.AlertDue(now))
+	require.True(t, LowStockItem{BookID: 1, AlertedAt: now.Add(-LowStockRealertInterval)}.AlertDue(now))
+}
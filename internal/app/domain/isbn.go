@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidISBN = errors.New("invalid ISBN")
+
+// NormalizeISBN validates an ISBN-10 or ISBN-13 and returns it as an ISBN-13 of digits only, so every spelling of
+// an ISBN has a single form. Hyphens and spaces are ignored, ISBN-10 are converted with the 978 prefix.
+func NormalizeISBN(isbn string) (string, error) {
+	digits := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		if r == 'x' {
+			return 'X'
+		}
+		return r
+	}, isbn)
+
+	switch len(digits) {
+	case 10:
+		if !isISBN10(digits) {
+			return "", fmt.Errorf("%w: %q fails the ISBN-10 checksum", ErrInvalidISBN, isbn)
+		}
+		isbn13 := "978" + digits[:9]
+		return isbn13 + string(isbn13CheckDigit(isbn13)), nil
+	case 13:
+		if !isDigits(digits) || !strings.HasPrefix(digits, "978") && !strings.HasPrefix(digits, "979") {
+			return "", fmt.Errorf("%w: %q is not an ISBN-13", ErrInvalidISBN, isbn)
+		}
+		if isbn13CheckDigit(digits[:12]) != digits[12] {
+			return "", fmt.Errorf("%w: %q fails the ISBN-13 checksum", ErrInvalidISBN, isbn)
+		}
+		return digits, nil
+	default:
+		return "", fmt.Errorf("%w: %q has neither 10 nor 13 digits", ErrInvalidISBN, isbn)
+	}
+}
+
+// isISBN10 reports whether an ISBN-10 has nine digits and a valid check digit, X standing for ten
+func isISBN10(isbn string) bool {
+	if !isDigits(isbn[:9]) {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(isbn[i]-'0') * (10 - i)
+	}
+	switch check := isbn[9]; {
+	case check == 'X':
+		sum += 10
+	case check >= '0' && check <= '9':
+		sum += int(check - '0')
+	default:
+		return false
+	}
+	return sum%11 == 0
+}
+
+// isbn13CheckDigit returns the check digit of the first twelve digits of an ISBN-13
+func isbn13CheckDigit(isbn string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(isbn[i]-'0') * weight
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
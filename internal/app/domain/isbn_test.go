@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeISBN(t *testing.T) {
+	tests := []struct {
+		isbn string
+		want string
+	}{
+		{"978-0-306-40615-7", "9780306406157"},
+		{"0-306-40615-2", "9780306406157"},
+		{"0 8044 2957 x", "9780804429573"},
+		{"979-10-90636-07-1", "9791090636071"},
+	}
+	for _, tt := range tests {
+		isbn, err := NormalizeISBN(tt.isbn)
+		require.NoError(t, err, tt.isbn)
+		require.Equal(t, tt.want, isbn, tt.isbn)
+	}
+
+	for _, isbn := range []string{"978-0-306-40615-8", "0-306-40615-3", "977-0-306-40615-7", "030640615", "X306406152", ""} {
+		_, err := NormalizeISBN(isbn)
+		require.ErrorIs(t, err, ErrInvalidISBN, isbn)
+	}
+}
+
+func TestNewBook_Metadata(t *testing.T) {
+	data := NewBookData{
+		Title:       "Dune",
+		Year:        1965,
+		Author:      "Frank Herbert",
+		Price:       Money{amount: 1000, currency: SettlementCurrency},
+		CategoryID:  1,
+		ISBN:        "0-441-17271-7",
+		Publisher:   " Ace ",
+		Edition:     2,
+		Language:    "EN",
+		PageCount:   412,
+		Format:      BookFormatPaperback,
+		Description: " Desert planet. ",
+	}
+	book, err := NewBook(data)
+	require.NoError(t, err)
+	require.Equal(t, "9780441172719", book.ISBN())
+	require.Equal(t, "Ace", book.Publisher())
+	require.Equal(t, "en", book.Language())
+	require.Equal(t, "Desert planet.", book.Description())
+
+	data.ISBN = "0-441-17271-8"
+	data.Edition = -1
+	data.Language = "english"
+	data.PageCount = -1
+	data.Format = "audiobook"
+	_, err = NewBook(data)
+	require.Equal(t, []string{"isbn", "edition", "language", "page_count", "format"}, validationFields(t, err))
+}
+
+func TestBook_PatchMetadata(t *testing.T) {
+	book, err := NewBook(NewBookData{
+		ID:         1,
+		Title:      "Dune",
+		Year:       1965,
+		Author:     "Frank Herbert",
+		Price:      Money{amount: 1000, currency: SettlementCurrency},
+		CategoryID: 1,
+		ISBN:       "9780441172719",
+	})
+	require.NoError(t, err)
+
+	isbn, format := "0-441-17271-7", BookFormatHardcover
+	patched, changed, err := book.Patch(BookPatch{ISBN: &isbn, Format: &format})
+	require.NoError(t, err)
+	require.Equal(t, []string{"format"}, changed)
+	require.Equal(t, BookFormatHardcover, patched.Format())
+
+	empty := ""
+	patched, changed, err = book.Patch(BookPatch{ISBN: &empty})
+	require.NoError(t, err)
+	require.Equal(t, []string{"isbn"}, changed)
+	require.Empty(t, patched.ISBN())
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SettlementCurrency is the currency books are priced in and orders are settled in.
+const SettlementCurrency = "USD"
+
+var (
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+)
+
+// currencyExponents maps supported ISO 4217 currencies to their number of minor unit digits.
+var currencyExponents = map[string]int{
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"CHF": 2,
+	"CAD": 2,
+	"AUD": 2,
+	"JPY": 0,
+}
+
+// CurrencyExponent returns the number of minor unit digits of a supported currency.
+func CurrencyExponent(currency string) (int, error) {
+	exponent, ok := currencyExponents[currency]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+	return exponent, nil
+}
+
+// Money is an amount in minor units (e.g. cents) of an ISO 4217 currency.
+type Money struct {
+	amount   int64
+	currency string
+}
+
+// NewMoney creates a new amount of money in minor units of currency.
+func NewMoney(amount int64, currency string) (Money, error) {
+	currency = strings.ToUpper(currency)
+	if _, err := CurrencyExponent(currency); err != nil {
+		return Money{}, err
+	}
+
+	return Money{
+		amount:   amount,
+		currency: currency,
+	}, nil
+}
+
+// Zero returns no money in currency.
+func Zero(currency string) Money {
+	return Money{currency: currency}
+}
+
+// Amount returns the amount in minor units.
+func (m Money) Amount() int64 {
+	return m.amount
+}
+
+// Currency returns the ISO 4217 currency code.
+func (m Money) Currency() string {
+	return m.currency
+}
+
+// IsPositive reports whether the amount is greater than zero.
+func (m Money) IsPositive() bool {
+	return m.amount > 0
+}
+
+// IsNegative reports whether the amount is less than zero.
+func (m Money) IsNegative() bool {
+	return m.amount < 0
+}
+
+// IsZero reports whether the amount is zero.
+func (m Money) IsZero() bool {
+	return m.amount == 0
+}
+
+// Add returns the sum of two amounts of the same currency.
+func (m Money) Add(other Money) (Money, error) {
+	if m.currency != other.currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency)
+	}
+	return Money{amount: m.amount + other.amount, currency: m.currency}, nil
+}
+
+// Sub returns the difference of two amounts of the same currency.
+func (m Money) Sub(other Money) (Money, error) {
+	if m.currency != other.currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency)
+	}
+	return Money{amount: m.amount - other.amount, currency: m.currency}, nil
+}
+
+// Multiply returns the amount multiplied by n.
+func (m Money) Multiply(n int64) Money {
+	return Money{amount: m.amount * n, currency: m.currency}
+}
+
+// String returns the amount as a decimal string in major units, e.g. "12.34".
+func (m Money) String() string {
+	exponent, ok := currencyExponents[m.currency]
+	if !ok || exponent == 0 {
+		return strconv.FormatInt(m.amount, 10)
+	}
+
+	sign := ""
+	amount := m.amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := fmt.Sprintf("%0*d", exponent+1, amount)
+	split := len(digits) - exponent
+
+	return sign + digits[:split] + "." + digits[split:]
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoney_String(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		currency string
+		want     string
+	}{
+		{1234, "USD", "12.34"},
+		{5, "USD", "0.05"},
+		{0, "EUR", "0.00"},
+		{-250, "GBP", "-2.50"},
+		{1500, "JPY", "1500"},
+	}
+	for _, tt := range tests {
+		money, err := NewMoney(tt.amount, tt.currency)
+		require.NoError(t, err)
+		require.Equal(t, tt.want, money.String())
+	}
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	a, err := NewMoney(1099, "usd")
+	require.NoError(t, err)
+	require.Equal(t, "USD", a.Currency())
+
+	sum, err := a.Add(a.Multiply(2))
+	require.NoError(t, err)
+	require.Equal(t, int64(3297), sum.Amount())
+
+	eur, err := NewMoney(100, "EUR")
+	require.NoError(t, err)
+	_, err = a.Add(eur)
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = NewMoney(100, "XXX")
+	require.ErrorIs(t, err, ErrUnknownCurrency)
+}
+
+func TestExchangeRate_Convert(t *testing.T) {
+	tests := []struct {
+		currency string
+		rate     string
+		amount   int64
+		want     int64
+	}{
+		{"EUR", "0.92", 1999, 1839},    // 1839.08
+		{"EUR", "0.925", 1000, 925},    // exact
+		{"GBP", "0.785", 1010, 793},    // 792.85 rounds half away from zero
+		{"GBP", "0.785", -1010, -793},  // symmetric for refunds
+		{"JPY", "151.237", 1999, 3023}, // 3023.227 yen, no minor units
+	}
+	for _, tt := range tests {
+		rate, err := NewExchangeRate(NewExchangeRateData{Currency: tt.currency, Rate: tt.rate})
+		require.NoError(t, err)
+
+		price, err := NewMoney(tt.amount, SettlementCurrency)
+		require.NoError(t, err)
+
+		converted, err := rate.Convert(price)
+		require.NoError(t, err)
+		require.Equal(t, tt.want, converted.Amount(), "%s %s", tt.currency, tt.rate)
+		require.Equal(t, tt.currency, converted.Currency())
+	}
+}
+
+func TestNewExchangeRate(t *testing.T) {
+	rate, err := NewExchangeRate(NewExchangeRateData{Currency: " eur ", Rate: "0.9200"})
+	require.NoError(t, err)
+	require.Equal(t, "EUR", rate.Currency())
+	require.Equal(t, "0.92", rate.Rate())
+
+	_, err = NewExchangeRate(NewExchangeRateData{Currency: "USD", Rate: "1/3"})
+	require.Equal(t, []string{"currency", "rate"}, validationFields(t, err))
+
+	_, err = NewExchangeRate(NewExchangeRateData{Currency: "XXX", Rate: "-1"})
+	require.ErrorIs(t, err, ErrUnknownCurrency)
+	require.ErrorIs(t, err, ErrNotPositive)
+}
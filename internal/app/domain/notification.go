@@ -0,0 +1,25 @@
+package domain
+
+// Notification is a message sent by the shop through the configured notifiers.
+type Notification struct {
+	// Type is the event type webhooks receive the notification as
+	Type          string
+	AggregateType string
+	AggregateID   int
+	// To lists the email addresses of the recipients, empty for the shop staff
+	To      []string
+	Subject string
+	Body    string
+	// Payload is marshalled to JSON for webhooks
+	Payload any
+}
+
+// Event returns the notification as a domain event for webhooks.
+func (n Notification) Event() (Event, error) {
+	return NewEvent(NewEventData{
+		Type:          n.Type,
+		AggregateType: n.AggregateType,
+		AggregateID:   n.AggregateID,
+		Payload:       n.Payload,
+	})
+}
@@ -0,0 +1,179 @@
+package domain
+
+import "time"
+
+// Order statuses.
+const (
+	OrderStatusCompleted = "completed"
+	// OrderStatusAwaitingRelease is the status of orders with pre-ordered books not yet fulfilled
+	OrderStatusAwaitingRelease = "awaiting_release"
+)
+
+// OrderStatuses lists the statuses of placed orders, their books are sold.
+var OrderStatuses = []string{OrderStatusCompleted, OrderStatusAwaitingRelease}
+
+// Order is a domain order placed at checkout.
+type Order struct {
+	id        int
+	userID    int
+	status    string
+	lines     []QuoteLine
+	discounts []QuoteDiscount
+	address   Address
+	shipping  ShippingOption
+	taxRegion TaxRegion
+	taxes     []TaxLine
+	subtotal  Money
+	total     Money
+	createdAt time.Time
+}
+
+type NewOrderData struct {
+	ID        int
+	UserID    int
+	Status    string
+	Lines     []QuoteLine
+	Discounts []QuoteDiscount
+	// Address is the shipping address, zero for orders placed before addresses were collected
+	Address   Address
+	Shipping  ShippingOption
+	TaxRegion TaxRegion
+	Taxes     []TaxLine
+	Subtotal  Money
+	Total     Money
+	CreatedAt time.Time
+}
+
+// NewOrder creates a new order.
+func NewOrder(data NewOrderData) (Order, error) {
+	var v validator
+	v.check(data.UserID > 0, "user_id", ErrInvalidUserID)
+	v.check(len(data.Lines) > 0, "lines", ErrRequired)
+	v.check(!data.Total.IsNegative(), "total", ErrNegative)
+	if err := v.err(); err != nil {
+		return Order{}, err
+	}
+
+	status := data.Status
+	if status == "" {
+		status = OrderStatusCompleted
+	}
+
+	return Order{
+		id:        data.ID,
+		userID:    data.UserID,
+		status:    status,
+		lines:     data.Lines,
+		discounts: data.Discounts,
+		address:   data.Address,
+		shipping:  data.Shipping,
+		taxRegion: data.TaxRegion,
+		taxes:     data.Taxes,
+		subtotal:  data.Subtotal,
+		total:     data.Total,
+		createdAt: data.CreatedAt,
+	}, nil
+}
+
+// NewOrderFromQuote creates a new order of a user for a quote shipped to an address, it is completed unless it
+// pre-orders books.
+func NewOrderFromQuote(userID int, quote Quote, address Address) (Order, error) {
+	status := OrderStatusCompleted
+	for _, line := range quote.Lines() {
+		if line.Preorder {
+			status = OrderStatusAwaitingRelease
+		}
+	}
+
+	return NewOrder(NewOrderData{
+		UserID:    userID,
+		Status:    status,
+		Lines:     quote.Lines(),
+		Discounts: quote.Discounts(),
+		Address:   address,
+		Shipping:  quote.Shipping(),
+		TaxRegion: quote.TaxRegion(),
+		Taxes:     quote.Taxes(),
+		Subtotal:  quote.Subtotal(),
+		Total:     quote.Total(),
+	})
+}
+
+// ID returns the order ID.
+func (o Order) ID() int {
+	return o.id
+}
+
+// UserID returns the ID of the user who placed the order.
+func (o Order) UserID() int {
+	return o.userID
+}
+
+// Status returns the order status.
+func (o Order) Status() string {
+	return o.status
+}
+
+// Lines returns the ordered books with the prices they were sold at.
+func (o Order) Lines() []QuoteLine {
+	return o.lines
+}
+
+// BookIDs returns the IDs of the ordered books.
+func (o Order) BookIDs() []int {
+	bookIDs := make([]int, len(o.lines))
+	for i, line := range o.lines {
+		bookIDs[i] = line.BookID
+	}
+	return bookIDs
+}
+
+// Subtotal returns the sum of the line prices.
+func (o Order) Subtotal() Money {
+	return o.subtotal
+}
+
+// Discounts returns the promotions applied to the order.
+func (o Order) Discounts() []QuoteDiscount {
+	return o.discounts
+}
+
+// DiscountTotal returns the sum of the discounts.
+func (o Order) DiscountTotal() Money {
+	return sumDiscounts(o.discounts)
+}
+
+// Address returns the address the order is shipped to.
+func (o Order) Address() Address {
+	return o.address
+}
+
+// Shipping returns the shipping method of the order and its cost.
+func (o Order) Shipping() ShippingOption {
+	return o.shipping
+}
+
+// TaxRegion returns the region the order was taxed for.
+func (o Order) TaxRegion() TaxRegion {
+	return o.taxRegion
+}
+
+// Taxes returns the sales tax lines of the order.
+func (o Order) Taxes() []TaxLine {
+	return o.taxes
+}
+
+// TaxTotal returns the sum of the taxes.
+func (o Order) TaxTotal() Money {
+	return sumTaxes(o.taxes)
+}
+
+// Total returns the amount paid.
+func (o Order) Total() Money {
+	return o.total
+}
+
+// CreatedAt returns when the order was placed.
+func (o Order) CreatedAt() time.Time {
+	return o.createdAt
+}
@@ -0,0 +1,295 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Promotion kinds.
+const (
+	PromotionPercent = "percent"
+	PromotionFixed   = "fixed"
+)
+
+// Promotion scopes.
+const (
+	PromotionScopeAll      = "all"
+	PromotionScopeCategory = "category"
+	PromotionScopeBooks    = "books"
+)
+
+var (
+	ErrInvalidPromotion       = errors.New("invalid promotion")
+	ErrPromotionNotActive     = errors.New("promotion is not active")
+	ErrPromotionExhausted     = errors.New("promotion usage limit reached")
+	ErrPromotionNotApplicable = errors.New("promotion does not apply to the cart")
+)
+
+var couponCodePattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9_-]{2,31}$`)
+
+// NormalizeCouponCode returns the canonical form coupon codes are stored and compared in.
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Promotion is a discount on books of a scope. Promotions without a coupon code apply to every cart,
+// the others only to carts the coupon was applied to.
+type Promotion struct {
+	id             int
+	code           string
+	description    string
+	kind           string
+	percent        int
+	amount         Money
+	minSubtotal    Money
+	scope          string
+	categoryID     int
+	bookIDs        []int
+	startsAt       time.Time
+	endsAt         time.Time
+	maxUses        int
+	maxUsesPerUser int
+	active         bool
+}
+
+type NewPromotionData struct {
+	ID          int
+	Code        string
+	Description string
+	Kind        string
+	// Percent is the discount of percent promotions, from 1 to 100
+	Percent int
+	// Amount is the discount of fixed promotions
+	Amount Money
+	// MinSubtotal is the minimum subtotal of the books in scope, zero for none
+	MinSubtotal Money
+	Scope       string
+	CategoryID  int
+	BookIDs     []int
+	// StartsAt and EndsAt bound the validity window, zero values leave it open
+	StartsAt time.Time
+	EndsAt   time.Time
+	// MaxUses and MaxUsesPerUser limit the number of orders, zero for unlimited
+	MaxUses        int
+	MaxUsesPerUser int
+	Active         bool
+}
+
+// NewPromotion creates a new promotion.
+func NewPromotion(data NewPromotionData) (Promotion, error) {
+	code := NormalizeCouponCode(data.Code)
+
+	var v validator
+	v.check(code == "" || couponCodePattern.MatchString(code), "code", ErrInvalidFormat)
+	switch data.Kind {
+	case PromotionPercent:
+		v.check(data.Percent >= 1 && data.Percent <= 100, "percent", ErrOutOfRange)
+	case PromotionFixed:
+		v.check(data.Amount.Currency() == SettlementCurrency, "amount",
+			fmt.Errorf("%w: discounts are in %s", ErrCurrencyMismatch, SettlementCurrency))
+		v.check(data.Amount.IsPositive(), "amount", ErrNotPositive)
+	case "":
+		v.check(false, "kind", ErrRequired)
+	default:
+		v.check(false, "kind", fmt.Errorf("%w: unknown kind %q", ErrInvalidPromotion, data.Kind))
+	}
+	minSubtotal := data.MinSubtotal
+	if minSubtotal.Currency() == "" {
+		minSubtotal = Zero(SettlementCurrency)
+	}
+	v.check(minSubtotal.Currency() == SettlementCurrency, "min_subtotal",
+		fmt.Errorf("%w: discounts are in %s", ErrCurrencyMismatch, SettlementCurrency))
+	v.check(!minSubtotal.IsNegative(), "min_subtotal", ErrNegative)
+	switch data.Scope {
+	case PromotionScopeAll:
+	case PromotionScopeCategory:
+		v.check(data.CategoryID > 0, "category_id", ErrRequired)
+	case PromotionScopeBooks:
+		v.check(len(data.BookIDs) > 0, "book_ids", ErrRequired)
+		for _, bookID := range data.BookIDs {
+			v.check(bookID > 0, "book_ids", fmt.Errorf("%w: %d is not positive", ErrInvalidBookIDs, bookID))
+		}
+	case "":
+		v.check(false, "scope", ErrRequired)
+	default:
+		v.check(false, "scope", fmt.Errorf("%w: unknown scope %q", ErrInvalidPromotion, data.Scope))
+	}
+	v.check(data.StartsAt.IsZero() || data.EndsAt.IsZero() || data.EndsAt.After(data.StartsAt), "ends_at",
+		fmt.Errorf("%w: must be after starts_at", ErrOutOfRange))
+	v.check(data.MaxUses >= 0, "max_uses", ErrNegative)
+	v.check(data.MaxUsesPerUser >= 0, "max_uses_per_user", ErrNegative)
+	if err := v.err(); err != nil {
+		return Promotion{}, err
+	}
+
+	return Promotion{
+		id:             data.ID,
+		code:           code,
+		description:    strings.TrimSpace(data.Description),
+		kind:           data.Kind,
+		percent:        data.Percent,
+		amount:         data.Amount,
+		minSubtotal:    minSubtotal,
+		scope:          data.Scope,
+		categoryID:     data.CategoryID,
+		bookIDs:        data.BookIDs,
+		startsAt:       data.StartsAt,
+		endsAt:         data.EndsAt,
+		maxUses:        data.MaxUses,
+		maxUsesPerUser: data.MaxUsesPerUser,
+		active:         data.Active,
+	}, nil
+}
+
+// ID returns the promotion ID.
+func (p Promotion) ID() int {
+	return p.id
+}
+
+// Code returns the coupon code, empty for automatic promotions.
+func (p Promotion) Code() string {
+	return p.code
+}
+
+// Description returns the description shown with the discount.
+func (p Promotion) Description() string {
+	return p.description
+}
+
+// Kind returns the promotion kind, percent or fixed.
+func (p Promotion) Kind() string {
+	return p.kind
+}
+
+// Percent returns the discount of percent promotions.
+func (p Promotion) Percent() int {
+	return p.percent
+}
+
+// Amount returns the discount of fixed promotions.
+func (p Promotion) Amount() Money {
+	return p.amount
+}
+
+// MinSubtotal returns the minimum subtotal of the books in scope.
+func (p Promotion) MinSubtotal() Money {
+	return p.minSubtotal
+}
+
+// Scope returns the scope of books the promotion applies to.
+func (p Promotion) Scope() string {
+	return p.scope
+}
+
+// CategoryID returns the category of category promotions.
+func (p Promotion) CategoryID() int {
+	return p.categoryID
+}
+
+// BookIDs returns the books of books promotions.
+func (p Promotion) BookIDs() []int {
+	return p.bookIDs
+}
+
+// StartsAt returns the start of the validity window, zero when open.
+func (p Promotion) StartsAt() time.Time {
+	return p.startsAt
+}
+
+// EndsAt returns the end of the validity window, zero when open.
+func (p Promotion) EndsAt() time.Time {
+	return p.endsAt
+}
+
+// MaxUses returns the maximum number of orders, zero when unlimited.
+func (p Promotion) MaxUses() int {
+	return p.maxUses
+}
+
+// MaxUsesPerUser returns the maximum number of orders per user, zero when unlimited.
+func (p Promotion) MaxUsesPerUser() int {
+	return p.maxUsesPerUser
+}
+
+// Active reports whether the promotion is enabled.
+func (p Promotion) Active() bool {
+	return p.active
+}
+
+// Automatic reports whether the promotion applies without a coupon code.
+func (p Promotion) Automatic() bool {
+	return p.code == ""
+}
+
+// PromotionUsage is the number of orders a promotion was used in.
+type PromotionUsage struct {
+	Total  int
+	ByUser int
+}
+
+// CheckRedeemable checks that the promotion is active at now and its usage limits are not reached.
+func (p Promotion) CheckRedeemable(now time.Time, usage PromotionUsage) error {
+	switch {
+	case !p.active:
+		return ErrPromotionNotActive
+	case !p.startsAt.IsZero() && now.Before(p.startsAt):
+		return fmt.Errorf("%w: starts at %s", ErrPromotionNotActive, p.startsAt.Format(time.RFC3339))
+	case !p.endsAt.IsZero() && !now.Before(p.endsAt):
+		return fmt.Errorf("%w: ended at %s", ErrPromotionNotActive, p.endsAt.Format(time.RFC3339))
+	case p.maxUses > 0 && usage.Total >= p.maxUses:
+		return ErrPromotionExhausted
+	case p.maxUsesPerUser > 0 && usage.ByUser >= p.maxUsesPerUser:
+		return fmt.Errorf("%w: already used %d time(s)", ErrPromotionExhausted, usage.ByUser)
+	}
+	return nil
+}
+
+// Discount returns the discount on the lines in scope. Percent discounts are rounded half away from zero
+// to the minor unit, fixed discounts never exceed the subtotal of the lines in scope.
+func (p Promotion) Discount(lines []QuoteLine) (Money, error) {
+	eligible := Zero(SettlementCurrency)
+	for _, line := range lines {
+		if !p.covers(line) {
+			continue
+		}
+		var err error
+		eligible, err = eligible.Add(line.Price)
+		if err != nil {
+			return Money{}, err
+		}
+	}
+
+	if eligible.IsZero() {
+		return Money{}, fmt.Errorf("%w: no books in scope", ErrPromotionNotApplicable)
+	}
+	if eligible.Amount() < p.minSubtotal.Amount() {
+		return Money{}, fmt.Errorf("%w: requires a subtotal of at least %s %s", ErrPromotionNotApplicable,
+			p.minSubtotal, p.minSubtotal.Currency())
+	}
+
+	if p.kind == PromotionFixed {
+		if p.amount.Amount() > eligible.Amount() {
+			return eligible, nil
+		}
+		return p.amount, nil
+	}
+
+	discount := new(big.Rat).SetFrac64(eligible.Amount()*int64(p.percent), 100)
+	return NewMoney(roundHalfAwayFromZero(discount), SettlementCurrency)
+}
+
+func (p Promotion) covers(line QuoteLine) bool {
+	switch p.scope {
+	case PromotionScopeCategory:
+		return line.CategoryID == p.categoryID || slices.Contains(line.CategoryIDs, p.categoryID)
+	case PromotionScopeBooks:
+		return slices.Contains(p.bookIDs, line.BookID)
+	default:
+		return true
+	}
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func usd(t *testing.T, amount int64) Money {
+	money, err := NewMoney(amount, SettlementCurrency)
+	require.NoError(t, err)
+	return money
+}
+
+func TestPromotion_Discount(t *testing.T) {
+	lines := []QuoteLine{
+		{BookID: 1, Price: usd(t, 1999), CategoryID: 3},
+		{BookID: 2, Price: usd(t, 2501), CategoryID: 4},
+		{BookID: 3, Price: usd(t, 1000), CategoryID: 3},
+	}
+
+	fantasy, err := NewPromotion(NewPromotionData{Kind: PromotionPercent, Percent: 20, Scope: PromotionScopeCategory, CategoryID: 3, Active: true})
+	require.NoError(t, err)
+	discount, err := fantasy.Discount(lines)
+	require.NoError(t, err)
+	require.Equal(t, int64(600), discount.Amount()) // 20% of 29.99 is 5.998
+
+	fiveOff, err := NewPromotion(NewPromotionData{Code: "five-off", Kind: PromotionFixed, Amount: usd(t, 500),
+		MinSubtotal: usd(t, 5000), Scope: PromotionScopeAll, Active: true})
+	require.NoError(t, err)
+	require.Equal(t, "FIVE-OFF", fiveOff.Code())
+	discount, err = fiveOff.Discount(lines)
+	require.NoError(t, err)
+	require.Equal(t, int64(500), discount.Amount())
+
+	_, err = fiveOff.Discount(lines[:2])
+	require.ErrorIs(t, err, ErrPromotionNotApplicable)
+
+	quote, err := NewQuote(lines)
+	require.NoError(t, err)
+	quote, err = quote.WithPromotion(fantasy)
+	require.NoError(t, err)
+	quote, err = quote.WithPromotion(fiveOff)
+	require.NoError(t, err)
+	require.Equal(t, int64(5500), quote.Subtotal().Amount())
+	require.Equal(t, int64(1100), quote.DiscountTotal().Amount())
+	require.Equal(t, int64(4400), quote.Total().Amount())
+}
+
+func TestPromotion_CheckRedeemable(t *testing.T) {
+	now := time.Date(2026, 12, 1, 12, 0, 0, 0, time.UTC)
+	promotion, err := NewPromotion(NewPromotionData{
+		Code:           "XMAS",
+		Kind:           PromotionPercent,
+		Percent:        10,
+		Scope:          PromotionScopeAll,
+		StartsAt:       now.Add(-time.Hour),
+		EndsAt:         now.Add(time.Hour),
+		MaxUses:        100,
+		MaxUsesPerUser: 1,
+		Active:         true,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, promotion.CheckRedeemable(now, PromotionUsage{Total: 99}))
+	require.ErrorIs(t, promotion.CheckRedeemable(now, PromotionUsage{Total: 100}), ErrPromotionExhausted)
+	require.ErrorIs(t, promotion.CheckRedeemable(now, PromotionUsage{Total: 1, ByUser: 1}), ErrPromotionExhausted)
+	require.ErrorIs(t, promotion.CheckRedeemable(now.Add(time.Hour), PromotionUsage{}), ErrPromotionNotActive)
+	require.ErrorIs(t, promotion.CheckRedeemable(now.Add(-2*time.Hour), PromotionUsage{}), ErrPromotionNotActive)
+}
+
+func TestNewPromotion_Invalid(t *testing.T) {
+	now := time.Now()
+	_, err := NewPromotion(NewPromotionData{
+		Code:     "x",
+		Kind:     PromotionPercent,
+		Percent:  150,
+		Scope:    PromotionScopeBooks,
+		StartsAt: now,
+		EndsAt:   now.Add(-time.Hour),
+		MaxUses:  -1,
+	})
+	require.Equal(t, []string{"code", "percent", "book_ids", "ends_at", "max_uses"}, validationFields(t, err))
+}
@@ -0,0 +1,298 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+)
+
+// QuoteLine is a single priced book of a quote.
+type QuoteLine struct {
+	BookID     int
+	Title      string
+	Price      Money
+	CategoryID int
+	// CategoryIDs are all the categories of the book, CategoryID is its primary category
+	CategoryIDs []int
+	TaxClass    string
+	// WeightGrams is the shipping weight of the book
+	WeightGrams int
+	// Preorder marks a book ordered before it is in stock, it is fulfilled when stock arrives
+	Preorder bool
+}
+
+// QuoteDiscount is a promotion applied to a quote.
+type QuoteDiscount struct {
+	PromotionID int
+	Code        string
+	Description string
+	Amount      Money
+}
+
+// Quote is a priced cart: its lines, discounts, shipping, taxes and totals in the settlement currency.
+type Quote struct {
+	lines []QuoteLine
+	// lineDiscounts are the discounts allocated to each line in minor units
+	lineDiscounts []int64
+	discounts     []QuoteDiscount
+	shipping      ShippingOption
+	taxRegion     TaxRegion
+	taxes         []TaxLine
+	subtotal      Money
+	total         Money
+	couponError   error
+}
+
+// NewQuote prices the lines, all lines must be in the settlement currency.
+func NewQuote(lines []QuoteLine) (Quote, error) {
+	subtotal := Zero(SettlementCurrency)
+	for _, line := range lines {
+		var err error
+		subtotal, err = subtotal.Add(line.Price)
+		if err != nil {
+			return Quote{}, fmt.Errorf("failed to add price of book %d: %w", line.BookID, err)
+		}
+	}
+
+	return Quote{
+		lines:         lines,
+		lineDiscounts: make([]int64, len(lines)),
+		subtotal:      subtotal,
+		total:         subtotal,
+	}, nil
+}
+
+// QuoteFromBooks creates a quote for the books at their current prices.
+func QuoteFromBooks(books []Book) (Quote, error) {
+	lines := make([]QuoteLine, len(books))
+	for i, book := range books {
+		lines[i] = QuoteLine{
+			BookID:      book.ID(),
+			Title:       book.Title(),
+			Price:       book.Price(),
+			CategoryID:  book.CategoryID(),
+			CategoryIDs: book.CategoryIDs(),
+			TaxClass:    book.TaxClass(),
+			WeightGrams: book.WeightGrams(),
+		}
+	}
+	return NewQuote(lines)
+}
+
+// WithPreorders marks the lines of pre-ordered books.
+func (q Quote) WithPreorders(bookIDs []int) Quote {
+	lines := make([]QuoteLine, len(q.lines))
+	for i, line := range q.lines {
+		line.Preorder = slices.Contains(bookIDs, line.BookID)
+		lines[i] = line
+	}
+	q.lines = lines
+	return q
+}
+
+// WithPromotion applies the discount of a promotion. Discounts never take the total below zero,
+// they are allocated to the lines in scope in proportion to their price so taxes apply to the discounted prices.
+// Promotions must be applied before taxes.
+func (q Quote) WithPromotion(promotion Promotion) (Quote, error) {
+	amount, err := promotion.Discount(q.lines)
+	if err != nil {
+		return Quote{}, err
+	}
+
+	var covered []int
+	for i, line := range q.lines {
+		if promotion.covers(line) {
+			covered = append(covered, i)
+		}
+	}
+	var allocated int64
+	q.lineDiscounts, allocated = allocateDiscount(q.NetLines(), q.lineDiscounts, covered, amount.Amount())
+	amount = Money{amount: allocated, currency: amount.currency}
+
+	total, err := q.total.Sub(amount)
+	if err != nil {
+		return Quote{}, err
+	}
+
+	q.discounts = append(slices.Clone(q.discounts), QuoteDiscount{
+		PromotionID: promotion.ID(),
+		Code:        promotion.Code(),
+		Description: promotion.Description(),
+		Amount:      amount,
+	})
+	q.total = total
+
+	return q, nil
+}
+
+// WithShipping adds the cost of the shipping method the quote is shipped with, shipping is not discounted.
+// A quote is shipped with a single method.
+func (q Quote) WithShipping(option ShippingOption) (Quote, error) {
+	if q.shipping.Method != "" {
+		return Quote{}, fmt.Errorf("quote is already shipped with %q", q.shipping.Method)
+	}
+
+	total, err := q.total.Add(option.Cost)
+	if err != nil {
+		return Quote{}, fmt.Errorf("failed to add shipping cost: %w", err)
+	}
+
+	q.shipping = option
+	q.total = total
+
+	return q, nil
+}
+
+// WithTaxes adds the sales tax of the region the quote is shipped to.
+func (q Quote) WithTaxes(region TaxRegion, taxes []TaxLine) (Quote, error) {
+	total := q.total
+	for _, tax := range taxes {
+		var err error
+		total, err = total.Add(tax.Amount)
+		if err != nil {
+			return Quote{}, fmt.Errorf("failed to add tax %s: %w", tax.Name, err)
+		}
+	}
+
+	q.taxRegion = region
+	q.taxes = taxes
+	q.total = total
+
+	return q, nil
+}
+
+// WithCouponError records why the coupon of the cart was not applied.
+func (q Quote) WithCouponError(err error) Quote {
+	q.couponError = err
+	return q
+}
+
+// Lines returns the priced books.
+func (q Quote) Lines() []QuoteLine {
+	return q.lines
+}
+
+// NetLines returns the lines priced after their allocated discounts.
+func (q Quote) NetLines() []QuoteLine {
+	lines := slices.Clone(q.lines)
+	for i := range lines {
+		lines[i].Price = Money{amount: lines[i].Price.amount - q.lineDiscounts[i], currency: lines[i].Price.currency}
+	}
+	return lines
+}
+
+// BookIDs returns the IDs of the quoted books.
+func (q Quote) BookIDs() []int {
+	bookIDs := make([]int, len(q.lines))
+	for i, line := range q.lines {
+		bookIDs[i] = line.BookID
+	}
+	return bookIDs
+}
+
+// Subtotal returns the sum of the line prices.
+func (q Quote) Subtotal() Money {
+	return q.subtotal
+}
+
+// Discounts returns the applied promotions.
+func (q Quote) Discounts() []QuoteDiscount {
+	return q.discounts
+}
+
+// DiscountTotal returns the sum of the discounts.
+func (q Quote) DiscountTotal() Money {
+	return sumDiscounts(q.discounts)
+}
+
+// WeightGrams returns the shipping weight of the quoted books.
+func (q Quote) WeightGrams() int {
+	var weight int
+	for _, line := range q.lines {
+		weight += line.WeightGrams
+	}
+	return weight
+}
+
+// Shipping returns the shipping method and its cost, the zero value when none was chosen.
+func (q Quote) Shipping() ShippingOption {
+	return q.shipping
+}
+
+// TaxRegion returns the region taxes were calculated for.
+func (q Quote) TaxRegion() TaxRegion {
+	return q.taxRegion
+}
+
+// Taxes returns the sales tax lines.
+func (q Quote) Taxes() []TaxLine {
+	return q.taxes
+}
+
+// TaxTotal returns the sum of the taxes.
+func (q Quote) TaxTotal() Money {
+	return sumTaxes(q.taxes)
+}
+
+// CouponError returns why the coupon of the cart was not applied, if it was not.
+func (q Quote) CouponError() error {
+	return q.couponError
+}
+
+// Total returns the amount to pay.
+func (q Quote) Total() Money {
+	return q.total
+}
+
+func sumDiscounts(discounts []QuoteDiscount) Money {
+	total := Zero(SettlementCurrency)
+	for _, discount := range discounts {
+		// discounts are created in the settlement currency
+		total, _ = total.Add(discount.Amount)
+	}
+	return total
+}
+
+// allocateDiscount spreads amount over the covered lines in proportion to their net price using the largest remainder
+// method, so the allocations add up exactly. No line is discounted below zero, the allocated amount is returned.
+func allocateDiscount(netLines []QuoteLine, lineDiscounts []int64, covered []int, amount int64) ([]int64, int64) {
+	var base int64
+	for _, i := range covered {
+		base += netLines[i].Price.amount
+	}
+	if base <= 0 || amount <= 0 {
+		return lineDiscounts, 0
+	}
+	if amount > base {
+		amount = base
+	}
+
+	allocations := slices.Clone(lineDiscounts)
+	remainders := make([]int64, len(covered))
+	var allocated int64
+	for k, i := range covered {
+		share := amount * netLines[i].Price.amount
+		allocations[i] += share / base
+		remainders[k] = share % base
+		allocated += share / base
+	}
+
+	// hand out the remaining minor units to the largest remainders, earlier lines first on ties
+	order := make([]int, len(covered))
+	for k := range order {
+		order[k] = k
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		switch {
+		case remainders[a] > remainders[b]:
+			return -1
+		case remainders[a] < remainders[b]:
+			return 1
+		}
+		return 0
+	})
+	for _, k := range order[:amount-allocated] {
+		allocations[covered[k]]++
+	}
+
+	return allocations, amount
+}
@@ -0,0 +1,20 @@
+package domain
+
+// MaxRecommendations bounds the books recommended for a book.
+const MaxRecommendations = 10
+
+// Recommendation sources.
+const (
+	// RecommendationCoPurchase recommends a book bought by the customers of the book
+	RecommendationCoPurchase = "co_purchase"
+	// RecommendationCategory recommends a popular book of the same category, for books with too few purchases
+	RecommendationCategory = "category"
+)
+
+// Recommendation is a book recommended on the page of another book.
+type Recommendation struct {
+	Book Book
+	// Score is the share of customers the books have in common, zero for category recommendations
+	Score  float64
+	Source string
+}
@@ -0,0 +1,185 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Review ratings and length.
+const (
+	MinReviewRating = 1
+	MaxReviewRating = 5
+	// MaxReviewLength bounds the text of a review in characters
+	MaxReviewLength = 5000
+)
+
+// Review statuses, reviews are published once approved by an admin.
+const (
+	ReviewStatusPending  = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusRejected = "rejected"
+)
+
+// ReviewStatuses lists the statuses of reviews.
+var ReviewStatuses = []string{ReviewStatusPending, ReviewStatusApproved, ReviewStatusRejected}
+
+var (
+	ErrNotPurchased    = errors.New("book not purchased")
+	ErrReviewModerated = errors.New("review already moderated")
+)
+
+// Book list orders.
+const (
+	// BookSortID lists books in the order they were added, it is the default
+	BookSortID = "id"
+	// BookSortRating lists the best rated books first, unrated books last
+	BookSortRating = "rating"
+	// BookSortReviews lists the most reviewed books first
+	BookSortReviews = "reviews"
+)
+
+// BookSorts lists the orders books can be listed in.
+var BookSorts = []string{BookSortID, BookSortRating, BookSortReviews}
+
+// Review is the rating and opinion of a user on a book they purchased.
+// It counts towards the rating of the book once approved.
+type Review struct {
+	id          int
+	bookID      int
+	userID      int
+	rating      int
+	body        string
+	status      string
+	reason      string
+	moderatedBy int
+	moderatedAt time.Time
+	createdAt   time.Time
+}
+
+type NewReviewData struct {
+	ID     int
+	BookID int
+	UserID int
+	Rating int
+	Body   string
+	// Status defaults to ReviewStatusPending
+	Status string
+	// Reason is why the review was rejected
+	Reason string
+	// ModeratedBy is the ID of the admin who approved or rejected the review, zero while it is pending
+	ModeratedBy int
+	ModeratedAt time.Time
+	CreatedAt   time.Time
+}
+
+// NewReview creates a new review.
+// The rating must lie between MinReviewRating and MaxReviewRating, the text is trimmed and is at most MaxReviewLength characters.
+func NewReview(data NewReviewData) (Review, error) {
+	body := strings.TrimSpace(data.Body)
+	status := data.Status
+	if status == "" {
+		status = ReviewStatusPending
+	}
+
+	var v validator
+	v.check(data.BookID > 0, "book_id", ErrRequired)
+	v.check(data.UserID > 0, "user_id", ErrInvalidUserID)
+	v.check(data.Rating >= MinReviewRating && data.Rating <= MaxReviewRating, "rating",
+		fmt.Errorf("%w: rating must be between %d and %d", ErrOutOfRange, MinReviewRating, MaxReviewRating))
+	v.check(body != "", "body", ErrRequired)
+	v.check(utf8.RuneCountInString(body) <= MaxReviewLength, "body",
+		fmt.Errorf("%w: at most %d characters", ErrOutOfRange, MaxReviewLength))
+	v.check(slices.Contains(ReviewStatuses, status), "status", fmt.Errorf("%w: unknown review status %q", ErrInvalidFormat, status))
+	if err := v.err(); err != nil {
+		return Review{}, err
+	}
+
+	return Review{
+		id:          data.ID,
+		bookID:      data.BookID,
+		userID:      data.UserID,
+		rating:      data.Rating,
+		body:        body,
+		status:      status,
+		reason:      strings.TrimSpace(data.Reason),
+		moderatedBy: data.ModeratedBy,
+		moderatedAt: data.ModeratedAt,
+		createdAt:   data.CreatedAt,
+	}, nil
+}
+
+// ID returns the review ID.
+func (r Review) ID() int {
+	return r.id
+}
+
+// BookID returns the ID of the reviewed book.
+func (r Review) BookID() int {
+	return r.bookID
+}
+
+// UserID returns the ID of the reviewer.
+func (r Review) UserID() int {
+	return r.userID
+}
+
+// Rating returns the stars given to the book.
+func (r Review) Rating() int {
+	return r.rating
+}
+
+// Body returns the text of the review.
+func (r Review) Body() string {
+	return r.body
+}
+
+// Status returns whether the review is pending, approved or rejected.
+func (r Review) Status() string {
+	return r.status
+}
+
+// Reason returns why the review was rejected.
+func (r Review) Reason() string {
+	return r.reason
+}
+
+// ModeratedBy returns the ID of the admin who moderated the review, zero while it is pending.
+func (r Review) ModeratedBy() int {
+	return r.moderatedBy
+}
+
+// ModeratedAt returns when the review was moderated, zero while it is pending.
+func (r Review) ModeratedAt() time.Time {
+	return r.moderatedAt
+}
+
+// CreatedAt returns when the review was submitted.
+func (r Review) CreatedAt() time.Time {
+	return r.createdAt
+}
+
+// Moderate returns the review approved or rejected by an admin. Only pending reviews are moderated, a rejected
+// review is pending again once its author submits it anew.
+func (r Review) Moderate(status, reason string, adminID int, at time.Time) (Review, error) {
+	if r.status != ReviewStatusPending {
+		return Review{}, fmt.Errorf("%w: review %d is %s", ErrReviewModerated, r.id, r.status)
+	}
+
+	var v validator
+	v.check(status == ReviewStatusApproved || status == ReviewStatusRejected, "status",
+		fmt.Errorf("%w: reviews are approved or rejected", ErrInvalidFormat))
+	v.check(adminID > 0, "moderated_by", ErrInvalidUserID)
+	if err := v.err(); err != nil {
+		return Review{}, err
+	}
+
+	r.status = status
+	r.reason = strings.TrimSpace(reason)
+	r.moderatedBy = adminID
+	r.moderatedAt = at
+	return r, nil
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReview(t *testing.T) {
+	review, err := NewReview(NewReviewData{BookID: 3, UserID: 1, Rating: 5, Body: "  A classic.  "})
+	require.NoError(t, err)
+	require.Equal(t, "A classic.", review.Body())
+	require.Equal(t, ReviewStatusPending, review.Status())
+
+	_, err = NewReview(NewReviewData{BookID: 0, UserID: 1, Rating: 6, Body: " "})
+	require.Equal(t, []string{"book_id", "rating", "body"}, validationFields(t, err))
+
+	_, err = NewReview(NewReviewData{BookID: 3, UserID: 1, Rating: 0, Body: strings.Repeat("a", MaxReviewLength+1)})
+	require.Equal(t, []string{"rating", "body"}, validationFields(t, err))
+}
+
+func TestReview_Moderate(t *testing.T) {
+	review, err := NewReview(NewReviewData{ID: 1, BookID: 3, UserID: 1, Rating: 4, Body: "Good"})
+	require.NoError(t, err)
+
+	now := time.Now()
+	approved, err := review.Moderate(ReviewStatusApproved, "", 2, now)
+	require.NoError(t, err)
+	require.Equal(t, ReviewStatusApproved, approved.Status())
+	require.Equal(t, 2, approved.ModeratedBy())
+	require.Equal(t, now, approved.ModeratedAt())
+
+	_, err = approved.Moderate(ReviewStatusRejected, "spam", 2, now)
+	require.ErrorIs(t, err, ErrReviewModerated)
+
+	_, err = review.Moderate(ReviewStatusPending, "", 2, now)
+	require.Equal(t, []string{"status"}, validationFields(t, err))
+}
+
+func TestBook_RatingAverage(t *testing.T) {
+	data := NewBookData{
+		Title:      "Dune",
+		Year:       1965,
+		Author:     "Frank Herbert",
+		Price:      Money{amount: 1000, currency: SettlementCurrency},
+		CategoryID: 1,
+	}
+	book, err := NewBook(data)
+	require.NoError(t, err)
+	require.Zero(t, book.RatingAverage())
+
+	data.RatingCount, data.RatingTotal = 3, 13
+	book, err = NewBook(data)
+	require.NoError(t, err)
+	require.InDelta(t, 4.333, book.RatingAverage(), 0.001)
+	require.Equal(t, 3, book.RatingCount())
+}
@@ -0,0 +1,198 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrShippingUnavailable = errors.New("shipping method is not available")
+
+var shippingMethodPattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,31}$`)
+
+// ShippingRate prices a shipping method to a country, or to every country without a rate of its own.
+// The cost of a parcel is the base amount plus an amount per book and an amount per started kilogram.
+type ShippingRate struct {
+	id            int
+	method        string
+	name          string
+	country       string
+	baseAmount    Money
+	perItemAmount Money
+	perKgAmount   Money
+	updatedAt     time.Time
+}
+
+type NewShippingRateData struct {
+	ID     int
+	Method string
+	Name   string
+	// Country is empty for the rate of countries without a rate of their own
+	Country       string
+	BaseAmount    Money
+	PerItemAmount Money
+	PerKgAmount   Money
+	UpdatedAt     time.Time
+}
+
+// NewShippingRate creates a new shipping rate, amounts must be non-negative and in the settlement currency.
+// Amounts left zero-valued are zero.
+func NewShippingRate(data NewShippingRateData) (ShippingRate, error) {
+	method := strings.ToLower(strings.TrimSpace(data.Method))
+	name := strings.TrimSpace(data.Name)
+	country := strings.ToUpper(strings.TrimSpace(data.Country))
+
+	var v validator
+	v.check(method != "", "method", ErrRequired)
+	v.check(method == "" || shippingMethodPattern.MatchString(method), "method", ErrInvalidFormat)
+	v.check(country == "" || countryPattern.MatchString(country), "country", ErrInvalidFormat)
+	checkAmount := func(amount Money, field string) Money {
+		if amount.Currency() == "" {
+			return Zero(SettlementCurrency)
+		}
+		v.check(amount.Currency() == SettlementCurrency, field,
+			fmt.Errorf("%w: shipping is priced in %s", ErrCurrencyMismatch, SettlementCurrency))
+		v.check(!amount.IsNegative(), field, ErrNegative)
+		return amount
+	}
+	base := checkAmount(data.BaseAmount, "base_amount")
+	perItem := checkAmount(data.PerItemAmount, "per_item_amount")
+	perKg := checkAmount(data.PerKgAmount, "per_kg_amount")
+	if err := v.err(); err != nil {
+		return ShippingRate{}, err
+	}
+
+	if name == "" {
+		name = strings.ToUpper(method[:1]) + method[1:] + " shipping"
+	}
+
+	return ShippingRate{
+		id:            data.ID,
+		method:        method,
+		name:          name,
+		country:       country,
+		baseAmount:    base,
+		perItemAmount: perItem,
+		perKgAmount:   perKg,
+		updatedAt:     data.UpdatedAt,
+	}, nil
+}
+
+// ID returns the shipping rate ID.
+func (r ShippingRate) ID() int {
+	return r.id
+}
+
+// Method returns the code of the shipping method.
+func (r ShippingRate) Method() string {
+	return r.method
+}
+
+// Name returns the name of the shipping method shown to customers.
+func (r ShippingRate) Name() string {
+	return r.name
+}
+
+// Country returns the country the rate applies to, empty for the fallback rate.
+func (r ShippingRate) Country() string {
+	return r.country
+}
+
+// BaseAmount returns the cost of every parcel.
+func (r ShippingRate) BaseAmount() Money {
+	return r.baseAmount
+}
+
+// PerItemAmount returns the cost of every book.
+func (r ShippingRate) PerItemAmount() Money {
+	return r.perItemAmount
+}
+
+// PerKgAmount returns the cost of every started kilogram.
+func (r ShippingRate) PerKgAmount() Money {
+	return r.perKgAmount
+}
+
+// UpdatedAt returns when the rate was last set.
+func (r ShippingRate) UpdatedAt() time.Time {
+	return r.updatedAt
+}
+
+// Cost returns the cost of shipping a parcel of items books weighing weightGrams.
+func (r ShippingRate) Cost(items, weightGrams int) Money {
+	kilograms := int64((weightGrams + 999) / 1000)
+	// all amounts are in the settlement currency
+	cost, _ := r.baseAmount.Add(r.perItemAmount.Multiply(int64(items)))
+	cost, _ = cost.Add(r.perKgAmount.Multiply(kilograms))
+	return cost
+}
+
+// ShippingOption is a shipping method priced for a parcel.
+type ShippingOption struct {
+	Method string
+	Name   string
+	Cost   Money
+}
+
+// ShippingTable prices shipping methods from a table of rates. A rate for the country replaces the fallback rate
+// of the same method, methods without either are not available in the country.
+type ShippingTable []ShippingRate
+
+// Options returns the methods available in a country priced for a quote, cheapest first.
+func (t ShippingTable) Options(country string, quote Quote) []ShippingOption {
+	var methods []string
+	for _, rate := range t {
+		if !slices.Contains(methods, rate.method) {
+			methods = append(methods, rate.method)
+		}
+	}
+
+	options := make([]ShippingOption, 0, len(methods))
+	for _, method := range methods {
+		if option, err := t.Option(method, country, quote); err == nil {
+			options = append(options, option)
+		}
+	}
+	slices.SortStableFunc(options, func(a, b ShippingOption) int {
+		switch {
+		case a.Cost.Amount() < b.Cost.Amount():
+			return -1
+		case a.Cost.Amount() > b.Cost.Amount():
+			return 1
+		}
+		return strings.Compare(a.Method, b.Method)
+	})
+
+	return options
+}
+
+// Option prices a shipping method to a country for a quote.
+func (t ShippingTable) Option(method, country string, quote Quote) (ShippingOption, error) {
+	fallback := -1
+	for i, rate := range t {
+		if rate.method != method {
+			continue
+		}
+		if rate.country == country {
+			return t[i].option(quote), nil
+		}
+		if rate.country == "" {
+			fallback = i
+		}
+	}
+	if fallback < 0 {
+		return ShippingOption{}, fmt.Errorf("%w: %q to %s", ErrShippingUnavailable, method, country)
+	}
+	return t[fallback].option(quote), nil
+}
+
+func (r ShippingRate) option(quote Quote) ShippingOption {
+	return ShippingOption{
+		Method: r.method,
+		Name:   r.name,
+		Cost:   r.Cost(len(quote.Lines()), quote.WeightGrams()),
+	}
+}
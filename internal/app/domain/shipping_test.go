@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShippingTable_Options(t *testing.T) {
+	var table ShippingTable
+	for _, data := range []NewShippingRateData{
+		{Method: "standard", BaseAmount: usd(t, 499), PerItemAmount: usd(t, 100)},
+		{Method: "standard", Country: "CA", BaseAmount: usd(t, 999), PerKgAmount: usd(t, 200)},
+		{Method: "express", Name: "Next day", BaseAmount: usd(t, 1499), PerItemAmount: usd(t, 200)},
+		{Method: "pickup", Country: "US"},
+	} {
+		rate, err := NewShippingRate(data)
+		require.NoError(t, err)
+		table = append(table, rate)
+	}
+
+	quote, err := NewQuote([]QuoteLine{
+		{BookID: 1, Price: usd(t, 1999), WeightGrams: 800},
+		{BookID: 2, Price: usd(t, 1001), WeightGrams: 350},
+	})
+	require.NoError(t, err)
+
+	costs := func(options []ShippingOption) map[string]int64 {
+		costs := map[string]int64{}
+		for _, option := range options {
+			costs[option.Method] = option.Cost.Amount()
+		}
+		return costs
+	}
+
+	us := table.Options("US", quote)
+	require.Equal(t, []string{"pickup", "standard", "express"}, []string{us[0].Method, us[1].Method, us[2].Method})
+	require.Equal(t, map[string]int64{"pickup": 0, "standard": 699, "express": 1899}, costs(us))
+	require.Equal(t, "Next day", us[2].Name)
+	require.Equal(t, "Standard shipping", us[1].Name)
+
+	// 1150g is two started kilograms
+	require.Equal(t, map[string]int64{"standard": 1399, "express": 1899}, costs(table.Options("CA", quote)))
+
+	_, err = table.Option("pickup", "GB", quote)
+	require.ErrorIs(t, err, ErrShippingUnavailable)
+
+	shipped, err := quote.WithShipping(us[1])
+	require.NoError(t, err)
+	require.Equal(t, int64(3699), shipped.Total().Amount())
+	_, err = shipped.WithShipping(us[0])
+	require.Error(t, err)
+}
+
+func TestNewShippingRate_Invalid(t *testing.T) {
+	eur, err := NewMoney(100, "EUR")
+	require.NoError(t, err)
+
+	_, err = NewShippingRate(NewShippingRateData{
+		Method:        "Next Day!",
+		Country:       "USA",
+		BaseAmount:    usd(t, -1),
+		PerItemAmount: eur,
+	})
+	require.Equal(t, []string{"method", "country", "base_amount", "per_item_amount"}, validationFields(t, err))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestNewAddress(t *testing.T) {
+	address, err := NewAddress(NewAddressData{
+		UserID:     1,
+		Name:       " Jane Doe ",
+		Line1:      "1 Market St",
+		City:       "San Francisco",
+		State:      "ca",
+		PostalCode: "94105",
+		Country:    "us",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Jane Doe", address.Name())
+	require.Equal(t, TaxRegion{Country: "US", State: "CA"}, address.TaxRegion())
+
+	_, err = NewAddress(NewAddressData{Name: "Jane", Line1: "1 High St", City: "London", PostalCode: "sw1a 1aa", Country: "GB"})
+	require.NoError(t, err)
+
+	_, err = NewAddress(NewAddressData{
+		Line1:      "1 Market St",
+		City:       "San Francisco",
+		PostalCode: "?",
+		Country:    "US",
+		Phone:      "call me",
+	})
+	require.Equal(t, []string{"name", "postal_code", "state", "phone"}, validationFields(t, err))
+}
@@ -0,0 +1,255 @@
+package domain
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Tax classes of books.
+const (
+	TaxClassStandard = "standard"
+	// TaxClassReduced is for books taxed at a reduced rate where the region has one
+	TaxClassReduced = "reduced"
+)
+
+// TaxClasses lists the tax classes books can have.
+var TaxClasses = []string{TaxClassStandard, TaxClassReduced}
+
+var (
+	countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+	statePattern   = regexp.MustCompile(`^[A-Z0-9]{1,3}$`)
+)
+
+// DefaultTaxRegion is used when no shipping region is known.
+var DefaultTaxRegion = TaxRegion{Country: "US"}
+
+// TaxRegion is the region books are shipped to: an ISO 3166-1 alpha-2 country and an optional state code.
+type TaxRegion struct {
+	Country string
+	State   string
+}
+
+// NewTaxRegion creates a new tax region, codes are upper-cased.
+func NewTaxRegion(country, state string) (TaxRegion, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	state = strings.ToUpper(strings.TrimSpace(state))
+
+	var v validator
+	v.check(country != "", "country", ErrRequired)
+	v.check(country == "" || countryPattern.MatchString(country), "country", ErrInvalidFormat)
+	v.check(state == "" || statePattern.MatchString(state), "state", ErrInvalidFormat)
+	if err := v.err(); err != nil {
+		return TaxRegion{}, err
+	}
+
+	return TaxRegion{Country: country, State: state}, nil
+}
+
+// String returns the region as "US-CA" or "GB".
+func (r TaxRegion) String() string {
+	if r.State == "" {
+		return r.Country
+	}
+	return r.Country + "-" + r.State
+}
+
+// TaxRateDecimals is the number of decimal places a tax rate is stored with.
+const TaxRateDecimals = 4
+
+// TaxRate is the sales tax percentage of a tax class in a country or a state of it.
+type TaxRate struct {
+	id        int
+	country   string
+	state     string
+	taxClass  string
+	rate      *big.Rat
+	name      string
+	updatedAt time.Time
+}
+
+type NewTaxRateData struct {
+	ID      int
+	Country string
+	// State is empty for rates of the whole country
+	State    string
+	TaxClass string
+	// Rate is a decimal percentage, e.g. "7.25"
+	Rate      string
+	Name      string
+	UpdatedAt time.Time
+}
+
+// NewTaxRate creates a new tax rate.
+func NewTaxRate(data NewTaxRateData) (TaxRate, error) {
+	var v validator
+	region, err := NewTaxRegion(data.Country, data.State)
+	if err != nil {
+		return TaxRate{}, err
+	}
+	taxClass := data.TaxClass
+	if taxClass == "" {
+		taxClass = TaxClassStandard
+	}
+	v.check(taxClass == TaxClassStandard || taxClass == TaxClassReduced, "tax_class",
+		fmt.Errorf("%w: unknown tax class %q", ErrInvalidFormat, taxClass))
+
+	rate, ok := new(big.Rat).SetString(strings.TrimSpace(data.Rate))
+	switch {
+	case strings.TrimSpace(data.Rate) == "":
+		v.check(false, "rate", ErrRequired)
+	case !ok || strings.ContainsAny(data.Rate, "/eE"):
+		v.check(false, "rate", ErrInvalidFormat)
+	default:
+		v.check(rate.Sign() >= 0 && rate.Cmp(big.NewRat(100, 1)) <= 0, "rate", ErrOutOfRange)
+		scaled := new(big.Rat).Mul(rate, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(TaxRateDecimals), nil)))
+		v.check(scaled.IsInt(), "rate", fmt.Errorf("%w: at most %d decimal places", ErrInvalidFormat, TaxRateDecimals))
+	}
+	if err := v.err(); err != nil {
+		return TaxRate{}, err
+	}
+
+	name := strings.TrimSpace(data.Name)
+	if name == "" {
+		name = fmt.Sprintf("Sales tax %s", region)
+	}
+
+	return TaxRate{
+		id:        data.ID,
+		country:   region.Country,
+		state:     region.State,
+		taxClass:  taxClass,
+		rate:      rate,
+		name:      name,
+		updatedAt: data.UpdatedAt,
+	}, nil
+}
+
+// ID returns the tax rate ID.
+func (r TaxRate) ID() int {
+	return r.id
+}
+
+// Country returns the country the rate applies in.
+func (r TaxRate) Country() string {
+	return r.country
+}
+
+// State returns the state the rate applies in, empty for the whole country.
+func (r TaxRate) State() string {
+	return r.state
+}
+
+// TaxClass returns the tax class the rate applies to.
+func (r TaxRate) TaxClass() string {
+	return r.taxClass
+}
+
+// Rate returns the percentage as a decimal string without trailing zeros.
+func (r TaxRate) Rate() string {
+	rate := strings.TrimRight(r.rate.FloatString(TaxRateDecimals), "0")
+	return strings.TrimSuffix(rate, ".")
+}
+
+// Name returns the name shown on tax lines.
+func (r TaxRate) Name() string {
+	return r.name
+}
+
+// UpdatedAt returns when the rate was last set.
+func (r TaxRate) UpdatedAt() time.Time {
+	return r.updatedAt
+}
+
+// TaxLine is the tax of the lines of a quote taxed at the same rate.
+type TaxLine struct {
+	Name     string
+	TaxClass string
+	// Rate is the decimal percentage
+	Rate    string
+	Taxable Money
+	Amount  Money
+}
+
+// TaxTable calculates taxes from a table of rates. A state rate replaces the country rate of the same class,
+// books of a class without a rate in the region are taxed at the standard rate, books without any rate are not taxed.
+type TaxTable []TaxRate
+
+// Calculate returns the tax lines of a quote shipped to a region, one line per applied rate in order of first use.
+// Taxes are calculated on the discounted prices summed per rate and rounded half away from zero to the minor unit.
+func (t TaxTable) Calculate(region TaxRegion, quote Quote) ([]TaxLine, error) {
+	var (
+		applied []int
+		taxable = map[int]Money{}
+	)
+	for _, line := range quote.NetLines() {
+		rate, ok := t.rateFor(region, line.TaxClass)
+		if !ok {
+			rate, ok = t.rateFor(region, TaxClassStandard)
+		}
+		if !ok {
+			continue
+		}
+
+		base, seen := taxable[rate]
+		if !seen {
+			applied = append(applied, rate)
+			base = Zero(SettlementCurrency)
+		}
+		var err error
+		taxable[rate], err = base.Add(line.Price)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add taxable price of book %d: %w", line.BookID, err)
+		}
+	}
+
+	taxes := make([]TaxLine, 0, len(applied))
+	for _, i := range applied {
+		rate, base := t[i], taxable[i]
+		tax := new(big.Rat).Mul(new(big.Rat).SetInt64(base.Amount()), rate.rate)
+		tax.Quo(tax, big.NewRat(100, 1))
+
+		amount, err := NewMoney(roundHalfAwayFromZero(tax), base.Currency())
+		if err != nil {
+			return nil, err
+		}
+
+		taxes = append(taxes, TaxLine{
+			Name:     rate.name,
+			TaxClass: rate.taxClass,
+			Rate:     rate.Rate(),
+			Taxable:  base,
+			Amount:   amount,
+		})
+	}
+
+	return taxes, nil
+}
+
+// rateFor returns the index of the rate of a tax class in a region
+func (t TaxTable) rateFor(region TaxRegion, taxClass string) (int, bool) {
+	countryRate := -1
+	for i, rate := range t {
+		if rate.country != region.Country || rate.taxClass != taxClass {
+			continue
+		}
+		if rate.state != "" && rate.state == region.State {
+			return i, true
+		}
+		if rate.state == "" {
+			countryRate = i
+		}
+	}
+	return countryRate, countryRate >= 0
+}
+
+func sumTaxes(taxes []TaxLine) Money {
+	total := Zero(SettlementCurrency)
+	for _, tax := range taxes {
+		// taxes are calculated in the settlement currency
+		total, _ = total.Add(tax.Amount)
+	}
+	return total
+}
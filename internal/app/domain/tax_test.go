@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaxTable_Calculate(t *testing.T) {
+	var table TaxTable
+	for _, data := range []NewTaxRateData{
+		{Country: "US", State: "CA", Rate: "7.25"},
+		{Country: "US", State: "NY", Rate: "4"},
+		{Country: "US", State: "NY", TaxClass: TaxClassReduced, Rate: "0"},
+		{Country: "GB", Rate: "20", Name: "VAT"},
+		{Country: "GB", TaxClass: TaxClassReduced, Rate: "0", Name: "VAT (zero-rated)"},
+		{Country: "DE", Rate: "19", Name: "MwSt"},
+		{Country: "DE", TaxClass: TaxClassReduced, Rate: "7", Name: "MwSt (reduced)"},
+	} {
+		rate, err := NewTaxRate(data)
+		require.NoError(t, err)
+		table = append(table, rate)
+	}
+
+	quote, err := NewQuote([]QuoteLine{
+		{BookID: 1, Price: usd(t, 1999), TaxClass: TaxClassStandard},
+		{BookID: 2, Price: usd(t, 1001), TaxClass: TaxClassReduced},
+		{BookID: 3, Price: usd(t, 500), TaxClass: TaxClassStandard},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		region TaxRegion
+		want   map[string]int64
+	}{
+		{TaxRegion{Country: "US", State: "CA"}, map[string]int64{"Sales tax US-CA": 254}}, // 7.25% of 35.00 = 2.5375
+		{TaxRegion{Country: "US", State: "NY"}, map[string]int64{"Sales tax US-NY": 100}}, // reduced rate is 0%
+		{TaxRegion{Country: "US", State: "OR"}, map[string]int64{}},
+		{TaxRegion{Country: "DE"}, map[string]int64{"MwSt": 475, "MwSt (reduced)": 70}},
+	}
+	for _, tt := range tests {
+		taxes, err := table.Calculate(tt.region, quote)
+		require.NoError(t, err)
+
+		got := map[string]int64{}
+		for _, tax := range taxes {
+			if tax.Amount.IsZero() {
+				continue
+			}
+			got[tax.Name] = tax.Amount.Amount()
+		}
+		require.Equal(t, tt.want, got, tt.region.String())
+	}
+}
+
+func TestNewTaxRate_Precision(t *testing.T) {
+	rate, err := NewTaxRate(NewTaxRateData{Country: "US", State: "CA", Rate: "7.2525"})
+	require.NoError(t, err)
+	require.Equal(t, "7.2525", rate.Rate())
+
+	// rates are stored with four decimal places, more would be rounded silently
+	_, err = NewTaxRate(NewTaxRateData{Country: "US", State: "CA", Rate: "7.25255"})
+	require.Equal(t, []string{"rate"}, validationFields(t, err))
+	require.ErrorIs(t, err, ErrInvalidFormat)
+}
+
+func TestTaxTable_CalculateDiscounted(t *testing.T) {
+	rate, err := NewTaxRate(NewTaxRateData{Country: "GB", Rate: "20", Name: "VAT"})
+	require.NoError(t, err)
+
+	quote, err := NewQuote([]QuoteLine{
+		{BookID: 1, Price: usd(t, 1000), CategoryID: 1, TaxClass: TaxClassStandard},
+		{BookID: 2, Price: usd(t, 2000), CategoryID: 2, TaxClass: TaxClassStandard},
+	})
+	require.NoError(t, err)
+
+	promotion, err := NewPromotion(NewPromotionData{Kind: PromotionFixed, Amount: usd(t, 500), Scope: PromotionScopeCategory, CategoryID: 1, Active: true})
+	require.NoError(t, err)
+	quote, err = quote.WithPromotion(promotion)
+	require.NoError(t, err)
+
+	taxes, err := TaxTable{rate}.Calculate(TaxRegion{Country: "GB"}, quote)
+	require.NoError(t, err)
+	require.Len(t, taxes, 1)
+	require.Equal(t, int64(2500), taxes[0].Taxable.Amount())
+	require.Equal(t, int64(500), taxes[0].Amount.Amount())
+
+	quote, err = quote.WithTaxes(TaxRegion{Country: "GB"}, taxes)
+	require.NoError(t, err)
+	require.Equal(t, int64(3000), quote.Total().Amount())
+}
+
+func TestAllocateDiscount(t *testing.T) {
+	quote, err := NewQuote([]QuoteLine{
+		{BookID: 1, Price: usd(t, 1000)},
+		{BookID: 2, Price: usd(t, 1000)},
+		{BookID: 3, Price: usd(t, 1000)},
+	})
+	require.NoError(t, err)
+
+	allocations, allocated := allocateDiscount(quote.NetLines(), quote.lineDiscounts, []int{0, 1, 2}, 100)
+	require.Equal(t, int64(100), allocated)
+	require.Equal(t, []int64{34, 33, 33}, allocations)
+}
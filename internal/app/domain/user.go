@@ -1,5 +1,14 @@
 package domain
 
+import (
+	"regexp"
+	"strings"
+)
+
+// usernamePattern accepts 3 to 64 characters of letters, digits and ._@+- starting with a letter or digit,
+// so that plain names as well as email addresses can be used.
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._@+-]{2,63}$`)
+
 // User is a domain User.
 type User struct {
 	id       int
@@ -15,8 +24,26 @@ type NewUserData struct {
 	Admin    bool
 }
 
-// NewUser creates a new user.
+// NewUser creates a new user with a valid username.
+// The password is a hash and is not validated.
 func NewUser(data NewUserData) (User, error) {
+	return newUser(data, validator{})
+}
+
+// RestoreUser recreates a stored user without validating it, so users signed up before the username rules still
+// load and sign in.
+func RestoreUser(data NewUserData) User {
+	user, _ := newUser(data, restoring)
+	return user
+}
+
+func newUser(data NewUserData, v validator) (User, error) {
+	v.check(data.Username != "", "username", ErrRequired)
+	v.check(data.Username == "" || usernamePattern.MatchString(data.Username), "username", ErrInvalidFormat)
+	if err := v.err(); err != nil {
+		return User{}, err
+	}
+
 	return User{
 		id:       data.ID,
 		username: data.Username,
@@ -35,6 +62,14 @@ func (u User) Username() string {
 	return u.username
 }
 
+// Email returns the username of users signed up with an email address, empty for other users.
+func (u User) Email() string {
+	if !strings.Contains(u.username, "@") {
+		return ""
+	}
+	return u.username
+}
+
 // Password returns the user password.
 func (u User) Password() string {
 	return u.password
@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func validationFields(t *testing.T, err error) []string {
+	var validationErrors ValidationErrors
+	require.True(t, errors.As(err, &validationErrors), "expected ValidationErrors, got %v", err)
+
+	fields := make([]string, len(validationErrors))
+	for i, validationError := range validationErrors {
+		fields[i] = validationError.Field
+	}
+	return fields
+}
+
+func TestNewBook(t *testing.T) {
+	book, err := NewBook(NewBookData{
+		Title:      "  The history of Toptal ",
+		Year:       2010,
+		Author:     "Taso Du Val",
+		Price:      Money{amount: 1000, currency: SettlementCurrency},
+		Stock:      0,
+		CategoryID: 1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "The history of Toptal", book.Title())
+
+	_, err = NewBook(NewBookData{
+		Title:      "   ",
+		Year:       MaxBookYear() + 1,
+		Author:     "",
+		Price:      Zero(SettlementCurrency),
+		Stock:      -1,
+		CategoryID: 0,
+	})
+	require.Equal(t, []string{"title", "year", "author", "price", "stock", "category_id"}, validationFields(t, err))
+	require.ErrorIs(t, err, ErrRequired)
+	require.ErrorIs(t, err, ErrOutOfRange)
+	require.ErrorIs(t, err, ErrNotPositive)
+	require.ErrorIs(t, err, ErrNegative)
+}
+
+func TestBook_Patch(t *testing.T) {
+	book, err := NewBook(NewBookData{
+		ID:         1,
+		Title:      "The history of Toptal",
+		Year:       2010,
+		Author:     "Taso Du Val",
+		Price:      Money{amount: 1000, currency: SettlementCurrency},
+		Stock:      5,
+		CategoryID: 1,
+		Version:    2,
+	})
+	require.NoError(t, err)
+
+	price := Money{amount: 1500, currency: SettlementCurrency}
+	sameYear := 2010
+	patched, changed, err := book.Patch(BookPatch{Price: &price, Year: &sameYear})
+	require.NoError(t, err)
+	require.Equal(t, []string{"price"}, changed)
+	require.Equal(t, price, patched.Price())
+	require.Equal(t, "The history of Toptal", patched.Title())
+	require.Equal(t, 5, patched.Stock())
+	require.Equal(t, 2, patched.Version())
+
+	blank := " "
+	zero := Zero(SettlementCurrency)
+	_, _, err = book.Patch(BookPatch{Title: &blank, Price: &zero})
+	require.Equal(t, []string{"title", "price"}, validationFields(t, err))
+
+	// fields that are not patched are not validated
+	legacy := Book{id: 1, title: "Legacy", year: 1200, author: "Anonymous", price: price, categoryID: 1, taxClass: TaxClassStandard}
+	title := "Legacy, annotated"
+	patched, changed, err = legacy.Patch(BookPatch{Title: &title})
+	require.NoError(t, err)
+	require.Equal(t, []string{"title"}, changed)
+	require.Equal(t, 1200, patched.Year())
+}
+
+func TestBook_AcceptsPreorders(t *testing.T) {
+	release := time.Date(2030, 3, 1, 0, 0, 0, 0, time.UTC)
+	data := NewBookData{
+		Title:       "Upcoming",
+		Year:        2025,
+		Author:      "Anonymous",
+		Price:       Money{amount: 1000, currency: SettlementCurrency},
+		CategoryID:  1,
+		ReleaseDate: release,
+		Preorder:    true,
+		PreorderCap: 2,
+		Preorders:   1,
+	}
+	book, err := NewBook(data)
+	require.NoError(t, err)
+	require.True(t, book.AcceptsPreorders(release.AddDate(0, -1, 0)))
+	// pre-orders close on release
+	require.False(t, book.AcceptsPreorders(release))
+
+	data.Preorders = 2
+	book, err = NewBook(data)
+	require.NoError(t, err)
+	require.False(t, book.AcceptsPreorders(release.AddDate(0, -1, 0)), "cap reached")
+
+	data.Preorders, data.Stock = 0, 3
+	book, err = NewBook(data)
+	require.NoError(t, err)
+	require.False(t, book.AcceptsPreorders(release.AddDate(0, -1, 0)), "in stock")
+
+	data.PreorderCap = 0
+	_, err = NewBook(data)
+	require.Equal(t, []string{"preorder_cap"}, validationFields(t, err))
+
+	// the cap is checked when only the pre-order flag is patched
+	data.Preorder = false
+	book, err = NewBook(data)
+	require.NoError(t, err)
+	enable := true
+	_, _, err = book.Patch(BookPatch{Preorder: &enable})
+	require.Equal(t, []string{"preorder_cap"}, validationFields(t, err))
+}
+
+func TestNewUser(t *testing.T) {
+	for _, username := range []string{"bob", "jane.doe@example.com", "user_1+test"} {
+		_, err := NewUser(NewUserData{Username: username})
+		require.NoError(t, err, username)
+	}
+
+	for _, username := range []string{"", "ab", ".bob", "bob smith", "bob/../admin"} {
+		_, err := NewUser(NewUserData{Username: username})
+		require.Equal(t, []string{"username"}, validationFields(t, err), username)
+	}
+}
+
+func TestNewCart(t *testing.T) {
+	_, err := NewCart(NewCartData{UserID: 1, BookIDs: []int{1, 2, 3}})
+	require.NoError(t, err)
+
+	_, err = NewCart(NewCartData{UserID: 1, BookIDs: []int{1, 2, 1}})
+	require.ErrorIs(t, err, ErrInvalidBookIDs)
+
+	_, err = NewCart(NewCartData{UserID: 0, BookIDs: []int{-1}})
+	require.Equal(t, []string{"user_id", "book_ids"}, validationFields(t, err))
+
+	cart, err := NewCart(NewCartData{UserID: 1, BookIDs: []int{1, 2}, PreorderBookIDs: []int{2}})
+	require.NoError(t, err)
+	require.True(t, cart.IsPreorder(2))
+	require.False(t, cart.IsPreorder(1))
+
+	_, err = NewCart(NewCartData{UserID: 1, BookIDs: []int{1}, PreorderBookIDs: []int{2}})
+	require.Equal(t, []string{"preorder_book_ids"}, validationFields(t, err))
+}
+
+func TestRestore(t *testing.T) {
+	user := RestoreUser(NewUserData{ID: 1, Username: "bob smith"})
+	require.Equal(t, "bob smith", user.Username())
+
+	cart := RestoreCart(NewCartData{UserID: 1, BookIDs: []int{5, 5}})
+	require.Equal(t, []int{5, 5}, cart.BookIDs())
+
+	book := RestoreBook(NewBookData{ID: 1, Title: "Gutenberg Bible", Year: 1200, Author: "Various", Price: Zero(SettlementCurrency)})
+	require.Equal(t, 1200, book.Year())
+	require.Zero(t, book.CategoryID())
+
+	title := "The Gutenberg Bible"
+	patched, changed, err := book.Patch(BookPatch{Title: &title})
+	require.NoError(t, err)
+	require.Equal(t, []string{"title"}, changed)
+	require.Equal(t, 1200, patched.Year())
+}
+
+func TestNewOrderFromQuote_Preorders(t *testing.T) {
+	quote, err := NewQuote([]QuoteLine{
+		{BookID: 1, Title: "In stock", Price: Money{amount: 1000, currency: SettlementCurrency}},
+		{BookID: 2, Title: "Upcoming", Price: Money{amount: 1500, currency: SettlementCurrency}},
+	})
+	require.NoError(t, err)
+
+	order, err := NewOrderFromQuote(1, quote, Address{})
+	require.NoError(t, err)
+	require.Equal(t, OrderStatusCompleted, order.Status())
+
+	order, err = NewOrderFromQuote(1, quote.WithPreorders([]int{2}), Address{})
+	require.NoError(t, err)
+	require.Equal(t, OrderStatusAwaitingRelease, order.Status())
+	require.False(t, order.Lines()[0].Preorder)
+	require.True(t, order.Lines()[1].Preorder)
+}
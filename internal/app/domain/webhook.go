@@ -0,0 +1,254 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// Webhook delivery statuses.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	// WebhookDeliveryDead is the dead-letter state of deliveries that ran out of attempts.
+	WebhookDeliveryDead = "dead"
+)
+
+// WebhookEventTypes lists the event types webhooks can subscribe to.
+var WebhookEventTypes = []string{
+	EventBookCreated,
+	EventBookStockChanged,
+	EventBookOutOfStock,
+	EventBookLowStock,
+	EventBookBackInStock,
+	EventCheckoutCompleted,
+	EventOrderReleased,
+}
+
+// WebhookSubscription is a domain webhook subscription.
+type WebhookSubscription struct {
+	id         int
+	url        string
+	secret     string
+	eventTypes []string
+	active     bool
+}
+
+type NewWebhookSubscriptionData struct {
+	ID         int
+	URL        string
+	Secret     string
+	EventTypes []string
+	Active     bool
+}
+
+// NewWebhookSubscription creates a new webhook subscription.
+func NewWebhookSubscription(data NewWebhookSubscriptionData) (WebhookSubscription, error) {
+	u, err := url.Parse(data.URL)
+	validURL := err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+
+	var v validator
+	v.check(data.URL != "", "url", ErrRequired)
+	v.check(data.URL == "" || validURL, "url", ErrInvalidFormat)
+	v.check(data.Secret != "", "secret", ErrRequired)
+	v.check(len(data.EventTypes) > 0, "event_types", ErrRequired)
+	for _, eventType := range data.EventTypes {
+		v.check(slices.Contains(WebhookEventTypes, eventType), "event_types",
+			fmt.Errorf("%w: unknown event type %q", ErrInvalidWebhook, eventType))
+	}
+	if err := v.err(); err != nil {
+		return WebhookSubscription{}, err
+	}
+
+	return WebhookSubscription{
+		id:         data.ID,
+		url:        data.URL,
+		secret:     data.Secret,
+		eventTypes: data.EventTypes,
+		active:     data.Active,
+	}, nil
+}
+
+// ID returns the subscription ID.
+func (s WebhookSubscription) ID() int {
+	return s.id
+}
+
+// URL returns the URL events are delivered to.
+func (s WebhookSubscription) URL() string {
+	return s.url
+}
+
+// Secret returns the secret payloads are signed with.
+func (s WebhookSubscription) Secret() string {
+	return s.secret
+}
+
+// EventTypes returns the subscribed event types.
+func (s WebhookSubscription) EventTypes() []string {
+	return s.eventTypes
+}
+
+// Active returns whether events are delivered to the subscription.
+func (s WebhookSubscription) Active() bool {
+	return s.active
+}
+
+// Subscribed reports whether the subscription receives events of the given type.
+func (s WebhookSubscription) Subscribed(eventType string) bool {
+	return s.active && slices.Contains(s.eventTypes, eventType)
+}
+
+// WebhookDelivery is a domain webhook delivery of one event to one subscription.
+type WebhookDelivery struct {
+	id             int64
+	subscriptionID int
+	eventID        int64
+	eventType      string
+	payload        json.RawMessage
+	status         string
+	attempts       int
+	nextAttemptAt  time.Time
+	lastStatusCode int
+	lastError      string
+	createdAt      time.Time
+	attemptLog     []WebhookAttempt
+}
+
+type NewWebhookDeliveryData struct {
+	ID             int64
+	SubscriptionID int
+	EventID        int64
+	EventType      string
+	Payload        json.RawMessage
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode int
+	LastError      string
+	CreatedAt      time.Time
+	AttemptLog     []WebhookAttempt
+}
+
+// NewWebhookDelivery creates a new webhook delivery.
+func NewWebhookDelivery(data NewWebhookDeliveryData) (WebhookDelivery, error) {
+	if data.SubscriptionID == 0 {
+		return WebhookDelivery{}, fmt.Errorf("%w: subscription_id", ErrRequired)
+	}
+	status := data.Status
+	if status == "" {
+		status = WebhookDeliveryPending
+	}
+
+	return WebhookDelivery{
+		id:             data.ID,
+		subscriptionID: data.SubscriptionID,
+		eventID:        data.EventID,
+		eventType:      data.EventType,
+		payload:        data.Payload,
+		status:         status,
+		attempts:       data.Attempts,
+		nextAttemptAt:  data.NextAttemptAt,
+		lastStatusCode: data.LastStatusCode,
+		lastError:      data.LastError,
+		createdAt:      data.CreatedAt,
+		attemptLog:     data.AttemptLog,
+	}, nil
+}
+
+// ID returns the delivery ID.
+func (d WebhookDelivery) ID() int64 {
+	return d.id
+}
+
+// SubscriptionID returns the ID of the subscription the event is delivered to.
+func (d WebhookDelivery) SubscriptionID() int {
+	return d.subscriptionID
+}
+
+// EventID returns the ID of the delivered event.
+func (d WebhookDelivery) EventID() int64 {
+	return d.eventID
+}
+
+// EventType returns the type of the delivered event.
+func (d WebhookDelivery) EventType() string {
+	return d.eventType
+}
+
+// Payload returns the body sent to the subscriber.
+func (d WebhookDelivery) Payload() json.RawMessage {
+	return d.payload
+}
+
+// Status returns the delivery status.
+func (d WebhookDelivery) Status() string {
+	return d.status
+}
+
+// Attempts returns the number of delivery attempts made.
+func (d WebhookDelivery) Attempts() int {
+	return d.attempts
+}
+
+// NextAttemptAt returns when the next attempt is due.
+func (d WebhookDelivery) NextAttemptAt() time.Time {
+	return d.nextAttemptAt
+}
+
+// LastStatusCode returns the HTTP status code of the last attempt.
+func (d WebhookDelivery) LastStatusCode() int {
+	return d.lastStatusCode
+}
+
+// LastError returns the error of the last attempt.
+func (d WebhookDelivery) LastError() string {
+	return d.lastError
+}
+
+// CreatedAt returns when the delivery was enqueued.
+func (d WebhookDelivery) CreatedAt() time.Time {
+	return d.createdAt
+}
+
+// AttemptLog returns the recorded attempts, if loaded.
+func (d WebhookDelivery) AttemptLog() []WebhookAttempt {
+	return d.attemptLog
+}
+
+// WebhookAttempt is a single delivery attempt.
+type WebhookAttempt struct {
+	DeliveryID  int64
+	AttemptedAt time.Time
+	StatusCode  int
+	Error       string
+	Duration    time.Duration
+}
+
+// Succeeded reports whether the subscriber accepted the delivery.
+func (a WebhookAttempt) Succeeded() bool {
+	return a.Error == "" && a.StatusCode >= 200 && a.StatusCode < 300
+}
+
+// WithAttempt returns the delivery updated with the outcome of an attempt.
+// A failed delivery is retried at retryAt, or moved to the dead-letter state when retryAt is zero.
+func (d WebhookDelivery) WithAttempt(attempt WebhookAttempt, retryAt time.Time) WebhookDelivery {
+	d.attempts++
+	d.lastStatusCode = attempt.StatusCode
+	d.lastError = attempt.Error
+	d.attemptLog = append(d.attemptLog, attempt)
+
+	switch {
+	case attempt.Succeeded():
+		d.status = WebhookDeliverySucceeded
+	case retryAt.IsZero():
+		d.status = WebhookDeliveryDead
+	default:
+		d.status = WebhookDeliveryPending
+		d.nextAttemptAt = retryAt
+	}
+
+	return d
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+// MaxWishlistItems bounds the number of books of a wishlist.
+const MaxWishlistItems = 200
+
+var ErrWishlistFull = errors.New("wishlist is full")
+
+// shareTokenPattern matches the tokens of shared wishlists, 32 random bytes hex encoded.
+var shareTokenPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// WishlistItem is a book of a wishlist.
+type WishlistItem struct {
+	Book    Book
+	AddedAt time.Time
+}
+
+// Wishlist is the list of books a user wants. Unlike the cart it does not reserve stock, so it is kept indefinitely.
+type Wishlist struct {
+	userID     int
+	items      []WishlistItem
+	shareToken string
+}
+
+type NewWishlistData struct {
+	UserID int
+	// Items are the books of the wishlist, oldest first
+	Items []WishlistItem
+	// ShareToken is the token of the public link to the wishlist, empty when it is not shared
+	ShareToken string
+}
+
+// NewWishlist creates a new wishlist of a user.
+func NewWishlist(data NewWishlistData) (Wishlist, error) {
+	var v validator
+	v.check(data.UserID > 0, "user_id", ErrInvalidUserID)
+	v.check(data.ShareToken == "" || ValidShareToken(data.ShareToken), "share_token", ErrInvalidFormat)
+	if err := v.err(); err != nil {
+		return Wishlist{}, err
+	}
+
+	return Wishlist{
+		userID:     data.UserID,
+		items:      data.Items,
+		shareToken: data.ShareToken,
+	}, nil
+}
+
+// ValidShareToken reports whether a token can be the token of a shared wishlist.
+func ValidShareToken(token string) bool {
+	return shareTokenPattern.MatchString(token)
+}
+
+// UserID returns the ID of the user the wishlist belongs to.
+func (w Wishlist) UserID() int {
+	return w.userID
+}
+
+// Items returns the books of the wishlist, oldest first.
+func (w Wishlist) Items() []WishlistItem {
+	return w.items
+}
+
+// BookIDs returns the IDs of the books of the wishlist.
+func (w Wishlist) BookIDs() []int {
+	bookIDs := make([]int, len(w.items))
+	for i, item := range w.items {
+		bookIDs[i] = item.Book.ID()
+	}
+	return bookIDs
+}
+
+// Contains reports whether a book is on the wishlist.
+func (w Wishlist) Contains(bookID int) bool {
+	for _, item := range w.items {
+		if item.Book.ID() == bookID {
+			return true
+		}
+	}
+	return false
+}
+
+// ShareToken returns the token of the public link to the wishlist, empty when it is not shared.
+func (w Wishlist) ShareToken() string {
+	return w.shareToken
+}
+
+// Shared reports whether the wishlist can be viewed through its share link.
+func (w Wishlist) Shared() bool {
+	return w.shareToken != ""
+}
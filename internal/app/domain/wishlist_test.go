@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWishlist(t *testing.T) {
+	book, err := NewBook(NewBookData{
+		ID:         3,
+		Title:      "Dune",
+		Year:       1965,
+		Author:     "Frank Herbert",
+		Price:      Money{amount: 1000, currency: SettlementCurrency},
+		CategoryID: 1,
+	})
+	require.NoError(t, err)
+
+	token := strings.Repeat("ab", 32)
+	wishlist, err := NewWishlist(NewWishlistData{UserID: 1, Items: []WishlistItem{{Book: book}}, ShareToken: token})
+	require.NoError(t, err)
+	require.True(t, wishlist.Contains(3))
+	require.False(t, wishlist.Contains(4))
+	require.Equal(t, []int{3}, wishlist.BookIDs())
+	require.True(t, wishlist.Shared())
+
+	_, err = NewWishlist(NewWishlistData{UserID: 0, ShareToken: "guessable"})
+	require.Equal(t, []string{"user_id", "share_token"}, validationFields(t, err))
+}
@@ -0,0 +1,119 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+const (
+	defaultBatchSize = 100
+	// defaultMaxAttempts bounds the attempts to deliver an event to a sink before it is dead-lettered for that sink
+	defaultMaxAttempts = 20
+	defaultBaseBackoff = 10 * time.Second
+	defaultMaxBackoff  = time.Hour
+)
+
+// OutboxRepository stores outbox events and their deliveries to every sink
+type OutboxRepository interface {
+	GetDueDeliveries(ctx context.Context, sink string, limit int) ([]domain.OutboxDelivery, error)
+	RecordDeliveries(ctx context.Context, deliveries []domain.OutboxDelivery) error
+	MarkPublished(ctx context.Context, sinks []string) (int, error)
+}
+
+// Relay delivers outbox events to the registered sinks
+type Relay struct {
+	repo        OutboxRepository
+	sinks       []Sink
+	batchSize   int
+	maxAttempts int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+	now         func() time.Time
+}
+
+// NewRelay creates a new relay delivering to sinks
+func NewRelay(repo OutboxRepository, sinks ...Sink) Relay {
+	return Relay{
+		repo:        repo,
+		sinks:       sinks,
+		batchSize:   defaultBatchSize,
+		maxAttempts: defaultMaxAttempts,
+		baseBackoff: defaultBaseBackoff,
+		maxBackoff:  defaultMaxBackoff,
+		now:         time.Now,
+	}
+}
+
+// Run delivers the due outbox events to every sink, then marks published the events every sink received or gave up
+// on. A sink that fails is retried with exponential backoff without holding back the other sinks, and its deliveries
+// are dead-lettered after maxAttempts failures. Events a sink failed may reach it after newer ones.
+func (r Relay) Run(ctx context.Context) error {
+	var errs []error
+	names := make([]string, len(r.sinks))
+	for i, sink := range r.sinks {
+		names[i] = sink.Name()
+		if err := r.deliver(ctx, sink); err != nil {
+			errs = append(errs, fmt.Errorf("sink %s: %w", sink.Name(), err))
+		}
+	}
+
+	if _, err := r.repo.MarkPublished(ctx, names); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// deliver sends the due events to a sink in batches until none is left or the sink fails
+func (r Relay) deliver(ctx context.Context, sink Sink) error {
+	for {
+		deliveries, err := r.repo.GetDueDeliveries(ctx, sink.Name(), r.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		events := make([]domain.Event, len(deliveries))
+		for i, delivery := range deliveries {
+			events[i] = delivery.Event
+		}
+
+		attemptedAt := r.now()
+		publishErr := sink.Publish(ctx, events)
+		for i, delivery := range deliveries {
+			var retryAt time.Time
+			if delivery.Attempts+1 < r.maxAttempts {
+				retryAt = attemptedAt.Add(r.Backoff(delivery.Attempts + 1))
+			}
+			deliveries[i] = delivery.WithAttempt(publishErr, attemptedAt, retryAt)
+		}
+
+		if err := r.repo.RecordDeliveries(ctx, deliveries); err != nil {
+			return err
+		}
+		if publishErr != nil {
+			return publishErr
+		}
+		if len(deliveries) < r.batchSize {
+			return nil
+		}
+	}
+}
+
+// Backoff returns the delay before the next attempt after the given number of failed attempts
+func (r Relay) Backoff(attempts int) time.Duration {
+	backoff := r.baseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= r.maxBackoff {
+			return r.maxBackoff
+		}
+	}
+	return backoff
+}
@@ -0,0 +1,202 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/stretchr/testify/require"
+)
+
+type deliveryKey struct {
+	eventID int64
+	sink    string
+}
+
+// fakeOutbox keeps the outbox in memory with the contract of the Postgres outbox
+type fakeOutbox struct {
+	events     []domain.Event
+	deliveries map[deliveryKey]domain.OutboxDelivery
+	published  map[int64]bool
+	now        func() time.Time
+}
+
+func newFakeOutbox(t *testing.T, n int, now func() time.Time) *fakeOutbox {
+	outbox := &fakeOutbox{
+		deliveries: map[deliveryKey]domain.OutboxDelivery{},
+		published:  map[int64]bool{},
+		now:        now,
+	}
+	for i := 1; i <= n; i++ {
+		event, err := domain.NewEvent(domain.NewEventData{
+			ID:            int64(i),
+			Type:          domain.EventBookCreated,
+			AggregateType: domain.AggregateBook,
+			AggregateID:   i,
+			Payload:       map[string]int{"book_id": i},
+		})
+		require.NoError(t, err)
+		outbox.events = append(outbox.events, event)
+	}
+	return outbox
+}
+
+func (o *fakeOutbox) GetDueDeliveries(_ context.Context, sink string, limit int) ([]domain.OutboxDelivery, error) {
+	var due []domain.OutboxDelivery
+	for _, event := range o.events {
+		if len(due) == limit {
+			break
+		}
+		delivery, ok := o.deliveries[deliveryKey{event.ID(), sink}]
+		if !ok {
+			delivery = domain.OutboxDelivery{Event: event, Sink: sink}
+		}
+		if o.published[event.ID()] || !delivery.DeliveredAt.IsZero() || !delivery.DeadAt.IsZero() ||
+			delivery.NextAttemptAt.After(o.now()) {
+			continue
+		}
+		due = append(due, delivery)
+	}
+	return due, nil
+}
+
+func (o *fakeOutbox) RecordDeliveries(_ context.Context, deliveries []domain.OutboxDelivery) error {
+	for _, delivery := range deliveries {
+		o.deliveries[deliveryKey{delivery.Event.ID(), delivery.Sink}] = delivery
+	}
+	return nil
+}
+
+func (o *fakeOutbox) MarkPublished(_ context.Context, sinks []string) (int, error) {
+	var marked int
+	for _, event := range o.events {
+		done := 0
+		for _, sink := range sinks {
+			delivery := o.deliveries[deliveryKey{event.ID(), sink}]
+			if !delivery.DeliveredAt.IsZero() || !delivery.DeadAt.IsZero() {
+				done++
+			}
+		}
+		if !o.published[event.ID()] && done == len(sinks) {
+			o.published[event.ID()] = true
+			marked++
+		}
+	}
+	return marked, nil
+}
+
+// fakeSink records the events it receives and fails the first failures calls
+type fakeSink struct {
+	name     string
+	failures int
+	calls    int
+	received []int64
+}
+
+func (s *fakeSink) Name() string {
+	return s.name
+}
+
+func (s *fakeSink) Publish(_ context.Context, events []domain.Event) error {
+	s.calls++
+	if s.calls <= s.failures {
+		return errors.New("sink unavailable")
+	}
+	for _, event := range events {
+		s.received = append(s.received, event.ID())
+	}
+	return nil
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestRelay(outbox *fakeOutbox, clock *fakeClock, sinks ...Sink) Relay {
+	relay := NewRelay(outbox, sinks...)
+	relay.now = clock.Now
+	return relay
+}
+
+func TestRelay_Run_DeliversInOrder(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	outbox := newFakeOutbox(t, 7, clock.Now)
+	sink := &fakeSink{name: "fake"}
+	relay := newTestRelay(outbox, clock, sink)
+	relay.batchSize = 3
+
+	require.NoError(t, relay.Run(context.Background()))
+
+	require.Equal(t, []int64{1, 2, 3, 4, 5, 6, 7}, sink.received)
+	require.Equal(t, 3, sink.calls)
+	for _, event := range outbox.events {
+		require.True(t, outbox.published[event.ID()])
+		require.Equal(t, 1, outbox.deliveries[deliveryKey{event.ID(), "fake"}].Attempts)
+	}
+
+	// published events are not relayed again
+	require.NoError(t, relay.Run(context.Background()))
+	require.Equal(t, 3, sink.calls)
+}
+
+func TestRelay_Run_RetriesFailedSinkOnly(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	outbox := newFakeOutbox(t, 2, clock.Now)
+	healthy := &fakeSink{name: "healthy"}
+	failing := &fakeSink{name: "failing", failures: 1}
+	relay := newTestRelay(outbox, clock, healthy, failing)
+
+	err := relay.Run(context.Background())
+	require.ErrorContains(t, err, "sink failing: sink unavailable")
+	require.Equal(t, []int64{1, 2}, healthy.received)
+	require.Empty(t, outbox.published)
+	delivery := outbox.deliveries[deliveryKey{1, "failing"}]
+	require.Equal(t, 1, delivery.Attempts)
+	require.Equal(t, "sink unavailable", delivery.LastError)
+	require.Equal(t, clock.now.Add(defaultBaseBackoff), delivery.NextAttemptAt)
+
+	// the failed sink is not retried before its backoff
+	require.NoError(t, relay.Run(context.Background()))
+	require.Equal(t, 1, failing.calls)
+
+	clock.now = clock.now.Add(defaultBaseBackoff)
+	require.NoError(t, relay.Run(context.Background()))
+	require.Equal(t, []int64{1, 2}, failing.received)
+	require.Equal(t, []int64{1, 2}, healthy.received, "healthy sinks receive no duplicates")
+	require.True(t, outbox.published[1])
+	require.True(t, outbox.published[2])
+}
+
+func TestRelay_Run_GivesUpPerSink(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	outbox := newFakeOutbox(t, 1, clock.Now)
+	healthy := &fakeSink{name: "healthy"}
+	failing := &fakeSink{name: "failing", failures: defaultMaxAttempts + 1}
+	relay := newTestRelay(outbox, clock, healthy, failing)
+
+	for i := 0; i < defaultMaxAttempts; i++ {
+		require.Error(t, relay.Run(context.Background()))
+		clock.now = clock.now.Add(defaultMaxBackoff)
+	}
+	require.False(t, outbox.deliveries[deliveryKey{1, "failing"}].DeadAt.IsZero())
+	require.False(t, outbox.deliveries[deliveryKey{1, "healthy"}].DeliveredAt.IsZero())
+	require.True(t, outbox.published[1])
+
+	// dead-lettered events are no longer relayed
+	require.NoError(t, relay.Run(context.Background()))
+	require.Equal(t, defaultMaxAttempts, failing.calls)
+	require.Equal(t, []int64{1}, healthy.received)
+}
+
+func TestRelay_Backoff(t *testing.T) {
+	relay := NewRelay(nil)
+	require.Equal(t, defaultBaseBackoff, relay.Backoff(1))
+	require.Equal(t, 4*defaultBaseBackoff, relay.Backoff(3))
+	require.Equal(t, defaultMaxBackoff, relay.Backoff(defaultMaxAttempts))
+}
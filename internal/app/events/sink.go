@@ -0,0 +1,98 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// Sink receives relayed domain events.
+// Delivery is at-least-once, so sinks must tolerate duplicates.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, events []domain.Event) error
+}
+
+// LogSink writes events to the application log
+type LogSink struct{}
+
+// NewLogSink creates a new log sink
+func NewLogSink() LogSink {
+	return LogSink{}
+}
+
+func (s LogSink) Name() string {
+	return "log"
+}
+
+func (s LogSink) Publish(_ context.Context, events []domain.Event) error {
+	for _, event := range events {
+		log.Printf("event %d %s %s/%d: %s", event.ID(), event.Type(), event.AggregateType(), event.AggregateID(), event.Payload())
+	}
+	return nil
+}
+
+// FileSink appends events as JSON lines to a local file, useful for local testing
+type FileSink struct {
+	path string
+	mu   *sync.Mutex
+}
+
+// NewFileSink creates a new file sink writing to path
+func NewFileSink(path string) FileSink {
+	return FileSink{
+		path: path,
+		mu:   &sync.Mutex{},
+	}
+}
+
+func (s FileSink) Name() string {
+	return "file"
+}
+
+func (s FileSink) Publish(_ context.Context, events []domain.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open event file: %w", err)
+	}
+	defer f.Close()
+
+	encoder := json.NewEncoder(f)
+	for _, event := range events {
+		if err := encoder.Encode(toMessage(event)); err != nil {
+			return fmt.Errorf("failed to write event: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Message is the wire representation of a domain event
+type Message struct {
+	ID            int64           `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int             `json:"aggregate_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+func toMessage(event domain.Event) Message {
+	return Message{
+		ID:            event.ID(),
+		Type:          event.Type(),
+		AggregateType: event.AggregateType(),
+		AggregateID:   event.AggregateID(),
+		OccurredAt:    event.OccurredAt(),
+		Payload:       event.Payload(),
+	}
+}
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS order_taxes;
+
+ALTER TABLE orders DROP COLUMN tax_state;
+ALTER TABLE orders DROP COLUMN tax_country;
+ALTER TABLE orders DROP COLUMN tax_amount;
+
+DROP TABLE IF EXISTS tax_rates;
+
+ALTER TABLE books DROP COLUMN tax_class;
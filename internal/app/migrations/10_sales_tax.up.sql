@@ -0,0 +1,33 @@
+ALTER TABLE books ADD COLUMN tax_class text NOT NULL DEFAULT 'standard' CHECK (tax_class IN ('standard', 'reduced'));
+
+-- rates are percentages, a state rate replaces the country-wide rate (empty state) of the same class
+CREATE TABLE tax_rates (
+    id              serial NOT NULL PRIMARY KEY,
+    country         char(2) NOT NULL,
+    state           text NOT NULL DEFAULT '',
+    tax_class       text NOT NULL CHECK (tax_class IN ('standard', 'reduced')),
+    rate            numeric(7, 4) NOT NULL CHECK (rate BETWEEN 0 AND 100),
+    name            text NOT NULL,
+    updated_at      timestamp with time zone DEFAULT now() NOT NULL,
+
+    UNIQUE (country, state, tax_class)
+);
+
+ALTER TABLE orders ADD COLUMN tax_amount bigint NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN tax_country char(2);
+ALTER TABLE orders ADD COLUMN tax_state text;
+
+CREATE TABLE order_taxes (
+    id                  serial NOT NULL PRIMARY KEY,
+    order_id            integer NOT NULL,
+    name                text NOT NULL,
+    tax_class           text NOT NULL,
+    rate                numeric(7, 4) NOT NULL,
+    taxable_amount      bigint NOT NULL,
+    amount              bigint NOT NULL,
+    currency            char(3) NOT NULL,
+
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
+);
+
+CREATE INDEX order_taxes_order_id_idx ON order_taxes (order_id);
@@ -0,0 +1,17 @@
+ALTER TABLE orders DROP COLUMN ship_to_phone;
+ALTER TABLE orders DROP COLUMN ship_to_country;
+ALTER TABLE orders DROP COLUMN ship_to_postal_code;
+ALTER TABLE orders DROP COLUMN ship_to_state;
+ALTER TABLE orders DROP COLUMN ship_to_city;
+ALTER TABLE orders DROP COLUMN ship_to_line2;
+ALTER TABLE orders DROP COLUMN ship_to_line1;
+ALTER TABLE orders DROP COLUMN ship_to_name;
+ALTER TABLE orders DROP COLUMN shipping_amount;
+ALTER TABLE orders DROP COLUMN shipping_name;
+ALTER TABLE orders DROP COLUMN shipping_method;
+
+DROP TABLE IF EXISTS shipping_rates;
+
+DROP TABLE IF EXISTS addresses;
+
+ALTER TABLE books DROP COLUMN weight_grams;
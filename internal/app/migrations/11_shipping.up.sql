@@ -0,0 +1,52 @@
+ALTER TABLE books ADD COLUMN weight_grams integer NOT NULL DEFAULT 0 CHECK (weight_grams >= 0);
+
+CREATE TABLE addresses (
+    id              serial NOT NULL PRIMARY KEY,
+    user_id         integer NOT NULL,
+    name            text NOT NULL,
+    line1           text NOT NULL,
+    line2           text NOT NULL DEFAULT '',
+    city            text NOT NULL,
+    state           text NOT NULL DEFAULT '',
+    postal_code     text NOT NULL,
+    country         char(2) NOT NULL,
+    phone           text NOT NULL DEFAULT '',
+    created_at      timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at      timestamp with time zone,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX addresses_user_id_idx ON addresses (user_id);
+
+-- a country rate replaces the fallback rate (empty country) of the same method
+CREATE TABLE shipping_rates (
+    id                  serial NOT NULL PRIMARY KEY,
+    method              text NOT NULL,
+    name                text NOT NULL,
+    country             text NOT NULL DEFAULT '',
+    base_amount         bigint NOT NULL DEFAULT 0 CHECK (base_amount >= 0),
+    per_item_amount     bigint NOT NULL DEFAULT 0 CHECK (per_item_amount >= 0),
+    per_kg_amount       bigint NOT NULL DEFAULT 0 CHECK (per_kg_amount >= 0),
+    currency            char(3) NOT NULL DEFAULT 'USD',
+    updated_at          timestamp with time zone DEFAULT now() NOT NULL,
+
+    UNIQUE (method, country)
+);
+
+INSERT INTO shipping_rates (method, name, base_amount, per_item_amount) VALUES
+    ('standard', 'Standard shipping', 499, 100),
+    ('express', 'Express shipping', 1499, 200);
+
+-- the address is copied onto the order, addresses can be changed or deleted later
+ALTER TABLE orders ADD COLUMN shipping_method text;
+ALTER TABLE orders ADD COLUMN shipping_name text;
+ALTER TABLE orders ADD COLUMN shipping_amount bigint NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN ship_to_name text;
+ALTER TABLE orders ADD COLUMN ship_to_line1 text;
+ALTER TABLE orders ADD COLUMN ship_to_line2 text;
+ALTER TABLE orders ADD COLUMN ship_to_city text;
+ALTER TABLE orders ADD COLUMN ship_to_state text;
+ALTER TABLE orders ADD COLUMN ship_to_postal_code text;
+ALTER TABLE orders ADD COLUMN ship_to_country char(2);
+ALTER TABLE orders ADD COLUMN ship_to_phone text;
@@ -0,0 +1,16 @@
+-- responses of requests sent with an Idempotency-Key header, status_code is null while the request is in progress
+CREATE TABLE idempotency_keys (
+    user_id         integer NOT NULL,
+    key             text NOT NULL,
+    fingerprint     char(64) NOT NULL,
+    status_code     integer,
+    content_type    text,
+    body            bytea,
+    locked_at       timestamp with time zone DEFAULT now() NOT NULL,
+    created_at      timestamp with time zone DEFAULT now() NOT NULL,
+
+    PRIMARY KEY (user_id, key),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idempotency_keys_created_at_idx ON idempotency_keys (created_at);
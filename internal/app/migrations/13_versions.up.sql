@@ -0,0 +1,3 @@
+-- versions of the editable fields for optimistic concurrency control, stock changes don't bump them
+ALTER TABLE books ADD COLUMN version integer NOT NULL DEFAULT 1;
+ALTER TABLE categories ADD COLUMN version integer NOT NULL DEFAULT 1;
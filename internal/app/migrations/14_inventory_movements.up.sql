@@ -0,0 +1,34 @@
+-- ledger of every change of books.stock, the deltas of a book sum up to its stock
+CREATE TABLE inventory_movements (
+    id              bigserial NOT NULL PRIMARY KEY,
+    book_id         integer NOT NULL,
+    type            text NOT NULL CHECK (type IN ('restock', 'sale', 'reservation', 'release', 'correction', 'return')),
+    delta           integer NOT NULL CHECK (delta <> 0),
+    stock_after     integer NOT NULL CHECK (stock_after >= 0),
+    reason          text NOT NULL DEFAULT '',
+    order_id        integer,
+    user_id         integer,
+    created_at      timestamp with time zone DEFAULT now() NOT NULL,
+
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE SET NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX inventory_movements_book_id_idx ON inventory_movements (book_id, id);
+
+-- open the ledger with the stock of every book including the copies reserved by carts, then reserve them again
+INSERT INTO inventory_movements (book_id, type, delta, stock_after, reason)
+SELECT b.id, 'restock', b.stock + count(c.user_id), b.stock + count(c.user_id), 'opening balance'
+FROM books b
+LEFT JOIN carts c ON b.id = ANY (c.book_ids)
+GROUP BY b.id, b.stock
+HAVING b.stock + count(c.user_id) > 0;
+
+INSERT INTO inventory_movements (book_id, type, delta, stock_after, user_id)
+SELECT b.id, 'reservation', -1,
+       b.stock + count(*) OVER (PARTITION BY b.id) - row_number() OVER (PARTITION BY b.id ORDER BY c.user_id),
+       c.user_id
+FROM books b
+JOIN carts c ON b.id = ANY (c.book_ids)
+ORDER BY b.id, c.user_id;
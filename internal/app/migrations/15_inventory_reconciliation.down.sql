@@ -0,0 +1,5 @@
+-- the repaired stock is kept, only the reconciliation movements recording the repairs are dropped
+DELETE FROM inventory_movements WHERE type = 'reconciliation';
+ALTER TABLE inventory_movements DROP CONSTRAINT IF EXISTS inventory_movements_type_check;
+ALTER TABLE inventory_movements ADD CONSTRAINT inventory_movements_type_check
+    CHECK (type IN ('restock', 'sale', 'reservation', 'release', 'correction', 'return'));
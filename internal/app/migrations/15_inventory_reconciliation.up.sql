@@ -0,0 +1,4 @@
+-- reconciliation movements correct stock drift found by the inventory reconciliation
+ALTER TABLE inventory_movements DROP CONSTRAINT inventory_movements_type_check;
+ALTER TABLE inventory_movements ADD CONSTRAINT inventory_movements_type_check
+    CHECK (type IN ('restock', 'sale', 'reservation', 'release', 'correction', 'return', 'reconciliation'));
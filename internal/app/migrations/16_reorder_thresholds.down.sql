@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS inventory_movements_sales_idx;
+ALTER TABLE books DROP COLUMN IF EXISTS low_stock_alerted_at;
+ALTER TABLE books DROP COLUMN IF EXISTS reorder_threshold;
@@ -0,0 +1,7 @@
+-- books at or below their reorder threshold are reported as low on stock, sold out books by default
+ALTER TABLE books ADD COLUMN reorder_threshold integer NOT NULL DEFAULT 0 CHECK (reorder_threshold >= 0);
+
+-- when the staff was last alerted of the book being low on stock, cleared once the stock recovers
+ALTER TABLE books ADD COLUMN low_stock_alerted_at timestamptz;
+
+CREATE INDEX inventory_movements_sales_idx ON inventory_movements (book_id, created_at) WHERE type = 'sale';
@@ -0,0 +1,12 @@
+-- users waiting for a sold-out book, queued when its stock rises above zero and notified in subscription order
+CREATE TABLE IF NOT EXISTS stock_subscriptions (
+    user_id     integer     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    book_id     integer     NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    queued_at   timestamptz,
+    notified_at timestamptz,
+    PRIMARY KEY (user_id, book_id)
+);
+
+CREATE INDEX stock_subscriptions_pending_idx ON stock_subscriptions (book_id, created_at) WHERE notified_at IS NULL;
+CREATE INDEX stock_subscriptions_notified_idx ON stock_subscriptions (user_id, notified_at) WHERE notified_at IS NOT NULL;
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS order_items_pending_preorders_idx;
+
+ALTER TABLE order_items
+    DROP COLUMN IF EXISTS fulfilled_at,
+    DROP COLUMN IF EXISTS preorder;
+
+ALTER TABLE carts DROP COLUMN IF EXISTS preorder_book_ids;
+
+ALTER TABLE books
+    DROP COLUMN IF EXISTS preorders,
+    DROP COLUMN IF EXISTS preorder_cap,
+    DROP COLUMN IF EXISTS preorder,
+    DROP COLUMN IF EXISTS release_date;
@@ -0,0 +1,17 @@
+-- books announced before publication take up to preorder_cap pre-orders while out of stock, preorders counts the copies
+-- pre-ordered by carts and orders and not yet fulfilled
+ALTER TABLE books
+    ADD COLUMN release_date date,
+    ADD COLUMN preorder     boolean NOT NULL DEFAULT false,
+    ADD COLUMN preorder_cap integer NOT NULL DEFAULT 0 CHECK (preorder_cap >= 0),
+    ADD COLUMN preorders    integer NOT NULL DEFAULT 0 CHECK (preorders >= 0);
+
+-- the books of a cart pre-ordered instead of reserved in stock
+ALTER TABLE carts ADD COLUMN preorder_book_ids integer[] NOT NULL DEFAULT '{}';
+
+-- pre-ordered items are fulfilled from stock as it arrives, their orders await release until then
+ALTER TABLE order_items
+    ADD COLUMN preorder     boolean NOT NULL DEFAULT false,
+    ADD COLUMN fulfilled_at timestamptz;
+
+CREATE INDEX order_items_pending_preorders_idx ON order_items (book_id, order_id) WHERE preorder AND fulfilled_at IS NULL;
@@ -0,0 +1,14 @@
+-- wishlists keep books without reserving stock, share_token opens a read-only public link to the wishlist
+CREATE TABLE IF NOT EXISTS wishlists (
+    user_id     integer     PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    share_token text        UNIQUE,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS wishlist_items (
+    user_id  integer     NOT NULL REFERENCES wishlists (user_id) ON DELETE CASCADE,
+    book_id  integer     NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+    added_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, book_id)
+);
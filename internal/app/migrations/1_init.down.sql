@@ -1,5 +1,4 @@
-DROP TABLE users;
-DROP TABLE categories;
-DROP TABLE books;
-DROP TABLE stocks;
-DROP TABLE carts;
\ No newline at end of file
+DROP TABLE IF EXISTS carts;
+DROP TABLE IF EXISTS books;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,5 @@
+ALTER TABLE books
+    DROP COLUMN IF EXISTS rating_total,
+    DROP COLUMN IF EXISTS rating_count;
+
+DROP TABLE IF EXISTS reviews;
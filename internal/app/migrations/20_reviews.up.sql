@@ -0,0 +1,22 @@
+-- reviews of purchased books, one per user and book, count towards the rating of the book once approved by an admin
+CREATE TABLE IF NOT EXISTS reviews (
+    id           serial PRIMARY KEY,
+    book_id      integer     NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+    user_id      integer     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    rating       integer     NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    body         text        NOT NULL,
+    status       text        NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    reason       text        NOT NULL DEFAULT '',
+    moderated_by integer     REFERENCES users (id) ON DELETE SET NULL,
+    moderated_at timestamptz,
+    created_at   timestamptz NOT NULL DEFAULT now(),
+    updated_at   timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (book_id, user_id)
+);
+
+CREATE INDEX reviews_status_idx ON reviews (status, created_at);
+
+-- the ratings of approved reviews are aggregated on the book to list and sort books without scanning reviews
+ALTER TABLE books
+    ADD COLUMN rating_count integer NOT NULL DEFAULT 0 CHECK (rating_count >= 0),
+    ADD COLUMN rating_total integer NOT NULL DEFAULT 0 CHECK (rating_total >= 0);
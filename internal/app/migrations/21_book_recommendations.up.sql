@@ -0,0 +1,12 @@
+-- "customers also bought": the books bought by the customers of a book, recomputed from order history by a job.
+-- customers counts the customers who bought both books, score normalises it by the customers of each book
+CREATE TABLE IF NOT EXISTS book_recommendations (
+    book_id             integer          NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+    recommended_book_id integer          NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+    customers           integer          NOT NULL,
+    score               double precision NOT NULL,
+    computed_at         timestamptz      NOT NULL DEFAULT now(),
+    PRIMARY KEY (book_id, recommended_book_id)
+);
+
+CREATE INDEX book_recommendations_score_idx ON book_recommendations (book_id, score DESC);
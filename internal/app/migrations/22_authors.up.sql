@@ -0,0 +1,47 @@
+-- authors are credited on books in a role, name_key identifies a name regardless of case, spacing and punctuation
+CREATE TABLE IF NOT EXISTS authors (
+    id         serial PRIMARY KEY,
+    name       text        NOT NULL,
+    name_key   text        NOT NULL UNIQUE CHECK (name_key <> ''),
+    bio        text        NOT NULL DEFAULT '',
+    version    integer     NOT NULL DEFAULT 1,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS book_authors (
+    book_id   integer NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+    author_id integer NOT NULL REFERENCES authors (id) ON DELETE RESTRICT,
+    role      text    NOT NULL CHECK (role IN ('author', 'editor', 'translator', 'illustrator')),
+    position  integer NOT NULL,
+    PRIMARY KEY (book_id, author_id, role)
+);
+
+CREATE INDEX book_authors_author_idx ON book_authors (author_id);
+
+-- the author strings of the books become authors, spellings of the same name are merged into the most used one
+WITH spellings AS (
+    SELECT btrim(author) AS name,
+           lower(regexp_replace(author, '[^[:alnum:]]+', '', 'g')) AS name_key,
+           count(*) AS books,
+           min(id) AS first_book_id
+    FROM books
+    GROUP BY 1, 2
+)
+INSERT INTO authors (name, name_key)
+SELECT DISTINCT ON (name_key) name, name_key
+FROM spellings
+WHERE name_key <> ''
+ORDER BY name_key, books DESC, first_book_id;
+
+INSERT INTO book_authors (book_id, author_id, role, position)
+SELECT b.id, a.id, 'author', 0
+FROM books b
+JOIN authors a ON a.name_key = lower(regexp_replace(b.author, '[^[:alnum:]]+', '', 'g'));
+
+-- books.author is kept as the byline of the credited authors
+UPDATE books b
+SET author = a.name
+FROM book_authors ba
+JOIN authors a ON a.id = ba.author_id
+WHERE ba.book_id = b.id AND b.author <> a.name;
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS books_isbn_idx;
+
+ALTER TABLE books
+    DROP COLUMN IF EXISTS description,
+    DROP COLUMN IF EXISTS format,
+    DROP COLUMN IF EXISTS page_count,
+    DROP COLUMN IF EXISTS language,
+    DROP COLUMN IF EXISTS edition,
+    DROP COLUMN IF EXISTS publisher,
+    DROP COLUMN IF EXISTS isbn;
@@ -0,0 +1,11 @@
+-- publication metadata of books, the ISBN is stored as an ISBN-13 of digits only and identifies a book
+ALTER TABLE books
+    ADD COLUMN isbn        text CHECK (isbn ~ '^97[89][0-9]{10}$'),
+    ADD COLUMN publisher   text    NOT NULL DEFAULT '',
+    ADD COLUMN edition     integer NOT NULL DEFAULT 0 CHECK (edition >= 0),
+    ADD COLUMN language    text    NOT NULL DEFAULT '',
+    ADD COLUMN page_count  integer NOT NULL DEFAULT 0 CHECK (page_count >= 0),
+    ADD COLUMN format      text    NOT NULL DEFAULT '' CHECK (format IN ('', 'hardcover', 'paperback', 'ebook')),
+    ADD COLUMN description text    NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX books_isbn_idx ON books (isbn);
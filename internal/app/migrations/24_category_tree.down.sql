@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS book_categories;
+
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS parent_id;
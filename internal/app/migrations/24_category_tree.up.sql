@@ -0,0 +1,20 @@
+-- categories form a tree under their optional parent, cycles are prevented by the repository
+ALTER TABLE categories
+    ADD COLUMN parent_id integer REFERENCES categories (id) ON DELETE RESTRICT CHECK (parent_id <> id);
+
+CREATE INDEX categories_parent_idx ON categories (parent_id);
+
+-- books belong to several categories, books.category_id is their primary category and listed first
+CREATE TABLE IF NOT EXISTS book_categories (
+    book_id     integer NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+    category_id integer NOT NULL REFERENCES categories (id) ON DELETE RESTRICT,
+    position    integer NOT NULL,
+    PRIMARY KEY (book_id, category_id)
+);
+
+CREATE INDEX book_categories_category_idx ON book_categories (category_id);
+
+INSERT INTO book_categories (book_id, category_id, position)
+SELECT id, category_id, 0
+FROM books
+WHERE category_id IS NOT NULL;
@@ -0,0 +1,5 @@
+-- when every exclusive background job last ran, so replicas run each job once per interval across the cluster
+CREATE TABLE IF NOT EXISTS job_runs (
+    name        text PRIMARY KEY,
+    last_run_at timestamptz NOT NULL
+);
@@ -1 +1 @@
-INSERT INTO "categories" ("id", "name", "created_at", "updated_at") VALUES (DEFAULT, 'Unicorns', DEFAULT, DEFAULT);
\ No newline at end of file
+-- 3_load_books has no up migration, so there is nothing to roll back
@@ -0,0 +1,29 @@
+CREATE TABLE outbox (
+    id              bigserial NOT NULL PRIMARY KEY,
+    event_type      text NOT NULL,
+    aggregate_type  text NOT NULL,
+    aggregate_id    integer NOT NULL,
+    payload         jsonb NOT NULL,
+    created_at      timestamp with time zone DEFAULT now() NOT NULL,
+    -- published_at is set once every sink received the event or gave up on it
+    published_at    timestamp with time zone
+);
+
+CREATE INDEX outbox_unpublished_idx ON outbox (id) WHERE published_at IS NULL;
+
+-- the delivery of an event to each sink, failed deliveries are retried with backoff and dead-lettered per sink
+CREATE TABLE outbox_deliveries (
+    event_id        bigint NOT NULL,
+    sink            text NOT NULL,
+    attempts        integer NOT NULL DEFAULT 0,
+    last_error      text,
+    next_attempt_at timestamp with time zone,
+    delivered_at    timestamp with time zone,
+    dead_at         timestamp with time zone,
+
+    PRIMARY KEY (event_id, sink),
+    FOREIGN KEY (event_id) REFERENCES outbox(id) ON DELETE CASCADE
+);
+
+CREATE INDEX outbox_deliveries_retry_idx ON outbox_deliveries (sink, next_attempt_at)
+    WHERE delivered_at IS NULL AND dead_at IS NULL;
@@ -0,0 +1,40 @@
+CREATE TABLE webhook_subscriptions (
+    id              serial NOT NULL PRIMARY KEY,
+    url             text NOT NULL,
+    secret          text NOT NULL,
+    event_types     text[] NOT NULL,
+    active          boolean NOT NULL DEFAULT true,
+    created_at      timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at      timestamp with time zone
+);
+
+CREATE TABLE webhook_deliveries (
+    id                  bigserial NOT NULL PRIMARY KEY,
+    subscription_id     integer NOT NULL,
+    event_id            bigint NOT NULL,
+    event_type          text NOT NULL,
+    payload             jsonb NOT NULL,
+    status              text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'dead')),
+    attempts            integer NOT NULL DEFAULT 0,
+    next_attempt_at     timestamp with time zone DEFAULT now() NOT NULL,
+    last_status_code    integer,
+    last_error          text,
+    created_at          timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at          timestamp with time zone,
+
+    UNIQUE (subscription_id, event_id),
+    FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+CREATE TABLE webhook_delivery_attempts (
+    id              bigserial NOT NULL PRIMARY KEY,
+    delivery_id     bigint NOT NULL,
+    attempted_at    timestamp with time zone NOT NULL,
+    status_code     integer,
+    error           text,
+    duration_ms     integer NOT NULL,
+
+    FOREIGN KEY (delivery_id) REFERENCES webhook_deliveries(id) ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+ALTER TABLE books DROP CONSTRAINT IF EXISTS books_stock_non_negative;
+ALTER TABLE books ADD CHECK (year >= 0);
@@ -0,0 +1,17 @@
+-- 1_init checked "year >= 0" on the stock column instead of the stock itself
+DO $$
+DECLARE
+    constraint_name text;
+BEGIN
+    SELECT conname INTO constraint_name
+    FROM pg_constraint
+    WHERE conrelid = 'books'::regclass
+      AND contype = 'c'
+      AND pg_get_constraintdef(oid) = 'CHECK ((year >= 0))';
+
+    IF constraint_name IS NOT NULL THEN
+        EXECUTE format('ALTER TABLE books DROP CONSTRAINT %I', constraint_name);
+    END IF;
+END $$;
+
+ALTER TABLE books ADD CONSTRAINT books_stock_non_negative CHECK (stock >= 0);
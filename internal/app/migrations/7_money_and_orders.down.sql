@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS order_items;
+DROP TABLE IF EXISTS orders;
+
+ALTER TABLE books DROP COLUMN price_currency;
+ALTER TABLE books ALTER COLUMN price_amount TYPE integer USING round(price_amount / 100.0)::integer;
+ALTER TABLE books RENAME COLUMN price_amount TO price;
@@ -0,0 +1,33 @@
+-- prices were whole US dollars, they are now stored in minor units with their currency
+ALTER TABLE books RENAME COLUMN price TO price_amount;
+ALTER TABLE books ALTER COLUMN price_amount TYPE bigint USING price_amount::bigint * 100;
+ALTER TABLE books ADD COLUMN price_currency char(3) NOT NULL DEFAULT 'USD';
+
+CREATE TABLE orders (
+    id                  serial NOT NULL PRIMARY KEY,
+    user_id             integer NOT NULL,
+    status              text NOT NULL,
+    subtotal_amount     bigint NOT NULL,
+    total_amount        bigint NOT NULL CHECK (total_amount >= 0),
+    currency            char(3) NOT NULL,
+    created_at          timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at          timestamp with time zone,
+
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX orders_user_id_idx ON orders (user_id);
+
+-- order items keep a snapshot of the book, books may change or be deleted after the order
+CREATE TABLE order_items (
+    id                  serial NOT NULL PRIMARY KEY,
+    order_id            integer NOT NULL,
+    book_id             integer NOT NULL,
+    title               text NOT NULL,
+    price_amount        bigint NOT NULL,
+    price_currency      char(3) NOT NULL,
+
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
+);
+
+CREATE INDEX order_items_order_id_idx ON order_items (order_id);
@@ -0,0 +1,6 @@
+-- rates are units of the display currency per 1 USD, the settlement currency
+CREATE TABLE exchange_rates (
+    currency        char(3) NOT NULL PRIMARY KEY,
+    rate            numeric(20, 8) NOT NULL CHECK (rate > 0),
+    updated_at      timestamp with time zone DEFAULT now() NOT NULL
+);
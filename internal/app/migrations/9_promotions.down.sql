@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS order_discounts;
+
+ALTER TABLE orders DROP COLUMN discount_amount;
+
+ALTER TABLE carts DROP COLUMN coupon_code;
+
+DROP TABLE IF EXISTS promotions;
@@ -0,0 +1,46 @@
+-- promotions without a code apply to every cart, coupon codes are stored upper-cased
+CREATE TABLE promotions (
+    id                  serial NOT NULL PRIMARY KEY,
+    code                text UNIQUE,
+    description         text NOT NULL DEFAULT '',
+    kind                text NOT NULL CHECK (kind IN ('percent', 'fixed')),
+    percent             integer NOT NULL DEFAULT 0 CHECK (percent BETWEEN 0 AND 100),
+    amount              bigint NOT NULL DEFAULT 0 CHECK (amount >= 0),
+    min_subtotal        bigint NOT NULL DEFAULT 0 CHECK (min_subtotal >= 0),
+    currency            char(3) NOT NULL DEFAULT 'USD',
+    scope               text NOT NULL CHECK (scope IN ('all', 'category', 'books')),
+    category_id         integer,
+    book_ids            integer[] NOT NULL DEFAULT '{}',
+    starts_at           timestamp with time zone,
+    ends_at             timestamp with time zone,
+    max_uses            integer NOT NULL DEFAULT 0 CHECK (max_uses >= 0),
+    max_uses_per_user   integer NOT NULL DEFAULT 0 CHECK (max_uses_per_user >= 0),
+    active              boolean NOT NULL DEFAULT true,
+    created_at          timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at          timestamp with time zone,
+
+    -- a category with promotions cannot be deleted, its promotions must be changed or deleted first
+    FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE RESTRICT
+);
+
+ALTER TABLE carts ADD COLUMN coupon_code text;
+
+ALTER TABLE orders ADD COLUMN discount_amount bigint NOT NULL DEFAULT 0;
+
+-- order discounts are the redemptions usage limits are counted from
+CREATE TABLE order_discounts (
+    id                  serial NOT NULL PRIMARY KEY,
+    order_id            integer NOT NULL,
+    promotion_id        integer,
+    user_id             integer NOT NULL,
+    code                text,
+    description         text NOT NULL,
+    amount              bigint NOT NULL,
+    currency            char(3) NOT NULL,
+
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (promotion_id) REFERENCES promotions(id) ON DELETE SET NULL
+);
+
+CREATE INDEX order_discounts_order_id_idx ON order_discounts (order_id);
+CREATE INDEX order_discounts_promotion_id_idx ON order_discounts (promotion_id, user_id);
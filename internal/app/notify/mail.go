@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// Mail is a plain text email
+type Mail struct {
+	From    string
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails
+type Mailer interface {
+	Send(ctx context.Context, mail Mail) error
+}
+
+// DirMailer stands in for a mail server locally: every email is written to a directory as an .eml file
+type DirMailer struct {
+	dir string
+}
+
+// NewDirMailer creates a new mailer writing to dir, the directory is created when the first email is sent
+func NewDirMailer(dir string) DirMailer {
+	return DirMailer{
+		dir: dir,
+	}
+}
+
+func (m DirMailer) Send(_ context.Context, mail Mail) error {
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create mail directory: %w", err)
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Errorf("failed to name mail: %w", err)
+	}
+	now := time.Now()
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000"), hex.EncodeToString(suffix))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", mail.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(mail.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mail.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(mail.Body, "\n", "\r\n"))
+
+	if err := os.WriteFile(filepath.Join(m.dir, name), []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+
+	return nil
+}
+
+// MailNotifier emails notifications to their recipients, notifications without recipients go to the shop staff
+// unless they are addressed to a user without an email address
+type MailNotifier struct {
+	mailer Mailer
+	from   string
+	staff  []string
+}
+
+// NewMailNotifier creates a new mail notifier sending from an address
+func NewMailNotifier(mailer Mailer, from string, staff []string) MailNotifier {
+	return MailNotifier{
+		mailer: mailer,
+		from:   from,
+		staff:  staff,
+	}
+}
+
+func (n MailNotifier) Name() string {
+	return "mail"
+}
+
+func (n MailNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+	to := notification.To
+	if len(to) == 0 && notification.AggregateType != domain.AggregateUser {
+		to = n.staff
+	}
+	if len(to) == 0 {
+		return nil
+	}
+
+	return n.mailer.Send(ctx, Mail{
+		From:    n.from,
+		To:      to,
+		Subject: notification.Subject,
+		Body:    notification.Body,
+	})
+}
@@ -0,0 +1,47 @@
+package notify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMailNotifier_Notify(t *testing.T) {
+	dir := t.TempDir()
+	notifier := NewMailNotifier(NewDirMailer(dir), "shop@example.com", []string{"stock@example.com"})
+
+	err := notifier.Notify(context.Background(), domain.Notification{
+		Type:    domain.EventBookLowStock,
+		Subject: "Low stock: Dune (1 left)",
+		Body:    "Suggested reorder: 5.\n",
+	})
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	mail, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	require.Contains(t, string(mail), "To: stock@example.com\r\n")
+	require.Contains(t, string(mail), "Subject: Low stock: Dune (1 left)\r\n")
+	require.Contains(t, string(mail), "\r\n\r\nSuggested reorder: 5.\r\n")
+
+	// notifications to customers are not sent to the staff
+	err = notifier.Notify(context.Background(), domain.Notification{To: []string{"reader@example.com"}, Subject: "Back in stock"})
+	require.NoError(t, err)
+	files, err = filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	// users without an email address are not emailed
+	err = notifier.Notify(context.Background(), domain.Notification{AggregateType: domain.AggregateUser, Subject: "Back in stock"})
+	require.NoError(t, err)
+	files, err = filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+}
@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// Notifier sends notifications through a channel
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, notification domain.Notification) error
+}
+
+// Multi sends notifications through every notifier, a failing notifier doesn't stop the others
+type Multi struct {
+	notifiers []Notifier
+}
+
+// NewMulti creates a notifier sending through notifiers
+func NewMulti(notifiers ...Notifier) Multi {
+	return Multi{
+		notifiers: notifiers,
+	}
+}
+
+func (m Multi) Name() string {
+	return "multi"
+}
+
+func (m Multi) Notify(ctx context.Context, notification domain.Notification) error {
+	var errs []error
+	for _, notifier := range m.notifiers {
+		if err := notifier.Notify(ctx, notification); err != nil {
+			errs = append(errs, fmt.Errorf("notifier %s: %w", notifier.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LogNotifier writes notifications to the application log
+type LogNotifier struct{}
+
+// NewLogNotifier creates a new log notifier
+func NewLogNotifier() LogNotifier {
+	return LogNotifier{}
+}
+
+func (n LogNotifier) Name() string {
+	return "log"
+}
+
+func (n LogNotifier) Notify(_ context.Context, notification domain.Notification) error {
+	log.Printf("notification %s %s/%d: %s", notification.Type, notification.AggregateType, notification.AggregateID, notification.Subject)
+	return nil
+}
+
+// EventPublisher adds events to the outbox
+type EventPublisher interface {
+	Publish(ctx context.Context, events ...domain.Event) error
+}
+
+// WebhookNotifier publishes notifications as domain events through the outbox,
+// they are delivered to the webhooks subscribed to their type with the usual signing and retries
+type WebhookNotifier struct {
+	publisher EventPublisher
+}
+
+// NewWebhookNotifier creates a new webhook notifier
+func NewWebhookNotifier(publisher EventPublisher) WebhookNotifier {
+	return WebhookNotifier{
+		publisher: publisher,
+	}
+}
+
+func (n WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+func (n WebhookNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+	event, err := notification.Event()
+	if err != nil {
+		return fmt.Errorf("failed to create notification event: %w", err)
+	}
+	return n.publisher.Publish(ctx, event)
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type Address struct {
+	bun.BaseModel `bun:"table:addresses"`
+	ID            int `bun:",pk,autoincrement"`
+	UserID        int
+	Name          string
+	Line1         string
+	Line2         string
+	City          string
+	State         string
+	PostalCode    string
+	Country       string
+	Phone         string
+	CreatedAt     time.Time `bun:",nullzero"`
+	UpdatedAt     time.Time `bun:",nullzero"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type Author struct {
+	bun.BaseModel `bun:"table:authors"`
+	ID            int `bun:",pk,autoincrement"`
+	Name          string
+	NameKey       string
+	Bio           string
+	Version       int       `bun:",nullzero"`
+	CreatedAt     time.Time `bun:",nullzero"`
+	UpdatedAt     time.Time `bun:",nullzero"`
+}
+
+type BookAuthor struct {
+	bun.BaseModel `bun:"table:book_authors"`
+	BookID        int    `bun:",pk"`
+	AuthorID      int    `bun:",pk"`
+	Role          string `bun:",pk"`
+	Position      int
+}
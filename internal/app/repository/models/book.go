@@ -8,14 +8,32 @@ import (
 
 // Book is a domain book.
 type Book struct {
-	bun.BaseModel `bun:"table:books"`
-	ID            int `bun:",pk,autoincrement"`
-	Title         string
-	Year          int
-	Author        string
-	Price         int
-	Stock         int
-	CategoryID    int
-	CreatedAt     time.Time `bun:",nullzero"`
-	UpdatedAt     time.Time `bun:",nullzero"`
+	bun.BaseModel    `bun:"table:books"`
+	ID               int `bun:",pk,autoincrement"`
+	Title            string
+	Year             int
+	Author           string
+	PriceAmount      int64
+	PriceCurrency    string
+	Stock            int
+	CategoryID       int
+	TaxClass         string
+	WeightGrams      int
+	ReorderThreshold int
+	ReleaseDate      time.Time `bun:",nullzero,type:date"`
+	Preorder         bool
+	PreorderCap      int
+	Preorders        int
+	RatingCount      int
+	RatingTotal      int
+	ISBN             string `bun:"isbn,nullzero"`
+	Publisher        string
+	Edition          int
+	Language         string
+	PageCount        int
+	Format           string
+	Description      string
+	Version          int       `bun:",nullzero"`
+	CreatedAt        time.Time `bun:",nullzero"`
+	UpdatedAt        time.Time `bun:",nullzero"`
 }
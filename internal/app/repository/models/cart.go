@@ -7,9 +7,11 @@ import (
 )
 
 type Cart struct {
-	bun.BaseModel `bun:"table:carts"`
-	UserID        int       `bun:"user_id"`
-	BookIDs       []int     `bun:"book_ids,array"`
-	CreatedAt     time.Time `bun:"created_at,nullzero,default:current_timestamp"`
-	UpdatedAt     time.Time `bun:"updated_at,nullzero"`
+	bun.BaseModel   `bun:"table:carts"`
+	UserID          int       `bun:"user_id"`
+	BookIDs         []int     `bun:"book_ids,array"`
+	PreorderBookIDs []int     `bun:"preorder_book_ids,array"`
+	CouponCode      string    `bun:"coupon_code,nullzero"`
+	CreatedAt       time.Time `bun:"created_at,nullzero,default:current_timestamp"`
+	UpdatedAt       time.Time `bun:"updated_at,nullzero"`
 }
@@ -10,6 +10,15 @@ type Category struct {
 	bun.BaseModel `bun:"table:categories"`
 	ID            int `bun:",pk,autoincrement"`
 	Name          string
+	ParentID      int       `bun:",nullzero"`
+	Version       int       `bun:",nullzero"`
 	CreatedAt     time.Time `bun:",nullzero"`
 	UpdatedAt     time.Time `bun:",nullzero"`
 }
+
+type BookCategory struct {
+	bun.BaseModel `bun:"table:book_categories"`
+	BookID        int `bun:",pk"`
+	CategoryID    int `bun:",pk"`
+	Position      int
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type ExchangeRate struct {
+	bun.BaseModel `bun:"table:exchange_rates"`
+	Currency      string `bun:",pk"`
+	Rate          string
+	UpdatedAt     time.Time `bun:",nullzero"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type IdempotencyKey struct {
+	bun.BaseModel `bun:"table:idempotency_keys"`
+	UserID        int    `bun:",pk"`
+	Key           string `bun:",pk"`
+	Fingerprint   string
+	StatusCode    int    `bun:",nullzero"`
+	ContentType   string `bun:",nullzero"`
+	Body          []byte
+	LockedAt      time.Time `bun:",nullzero"`
+	CreatedAt     time.Time `bun:",nullzero"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type InventoryMovement struct {
+	bun.BaseModel `bun:"table:inventory_movements"`
+	ID            int64 `bun:",pk,autoincrement"`
+	BookID        int
+	Type          string
+	Delta         int
+	StockAfter    int
+	Reason        string
+	OrderID       int       `bun:",nullzero"`
+	UserID        int       `bun:",nullzero"`
+	CreatedAt     time.Time `bun:",nullzero"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type JobRun struct {
+	bun.BaseModel `bun:"table:job_runs"`
+	Name          string `bun:",pk"`
+	LastRunAt     time.Time
+}
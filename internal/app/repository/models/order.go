@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type Order struct {
+	bun.BaseModel    `bun:"table:orders"`
+	ID               int `bun:",pk,autoincrement"`
+	UserID           int
+	Status           string
+	SubtotalAmount   int64
+	DiscountAmount   int64
+	ShippingMethod   string `bun:",nullzero"`
+	ShippingName     string `bun:",nullzero"`
+	ShippingAmount   int64
+	ShipToName       string `bun:",nullzero"`
+	ShipToLine1      string `bun:",nullzero"`
+	ShipToLine2      string `bun:",nullzero"`
+	ShipToCity       string `bun:",nullzero"`
+	ShipToState      string `bun:",nullzero"`
+	ShipToPostalCode string `bun:",nullzero"`
+	ShipToCountry    string `bun:",nullzero"`
+	ShipToPhone      string `bun:",nullzero"`
+	TaxAmount        int64
+	TaxCountry       string `bun:",nullzero"`
+	TaxState         string `bun:",nullzero"`
+	TotalAmount      int64
+	Currency         string
+	CreatedAt        time.Time `bun:",nullzero"`
+	UpdatedAt        time.Time `bun:",nullzero"`
+
+	Items     []OrderItem     `bun:"rel:has-many,join:id=order_id"`
+	Discounts []OrderDiscount `bun:"rel:has-many,join:id=order_id"`
+	Taxes     []OrderTax      `bun:"rel:has-many,join:id=order_id"`
+}
+
+type OrderItem struct {
+	bun.BaseModel `bun:"table:order_items"`
+	ID            int `bun:",pk,autoincrement"`
+	OrderID       int
+	BookID        int
+	Title         string
+	PriceAmount   int64
+	PriceCurrency string
+	Preorder      bool
+	FulfilledAt   time.Time `bun:",nullzero"`
+}
+
+type OrderDiscount struct {
+	bun.BaseModel `bun:"table:order_discounts"`
+	ID            int `bun:",pk,autoincrement"`
+	OrderID       int
+	PromotionID   int `bun:",nullzero"`
+	UserID        int
+	Code          string `bun:",nullzero"`
+	Description   string
+	Amount        int64
+	Currency      string
+}
+
+type OrderTax struct {
+	bun.BaseModel `bun:"table:order_taxes"`
+	ID            int `bun:",pk,autoincrement"`
+	OrderID       int
+	Name          string
+	TaxClass      string
+	Rate          string
+	TaxableAmount int64
+	Amount        int64
+	Currency      string
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// OutboxEvent is an event waiting to be relayed.
+type OutboxEvent struct {
+	bun.BaseModel `bun:"table:outbox"`
+	ID            int64 `bun:",pk,autoincrement"`
+	EventType     string
+	AggregateType string
+	AggregateID   int
+	Payload       json.RawMessage `bun:"type:jsonb"`
+	CreatedAt     time.Time       `bun:",nullzero,default:current_timestamp"`
+	PublishedAt   time.Time       `bun:",nullzero"`
+}
+
+// OutboxDelivery is the delivery of an outbox event to a sink.
+type OutboxDelivery struct {
+	bun.BaseModel `bun:"table:outbox_deliveries"`
+	EventID       int64  `bun:",pk"`
+	Sink          string `bun:",pk"`
+	Attempts      int
+	LastError     string    `bun:",nullzero"`
+	NextAttemptAt time.Time `bun:",nullzero"`
+	DeliveredAt   time.Time `bun:",nullzero"`
+	DeadAt        time.Time `bun:",nullzero"`
+}
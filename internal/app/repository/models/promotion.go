@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type Promotion struct {
+	bun.BaseModel  `bun:"table:promotions"`
+	ID             int    `bun:",pk,autoincrement"`
+	Code           string `bun:",nullzero"`
+	Description    string
+	Kind           string
+	Percent        int
+	Amount         int64
+	MinSubtotal    int64
+	Currency       string
+	Scope          string
+	CategoryID     int       `bun:",nullzero"`
+	BookIDs        []int     `bun:",array"`
+	StartsAt       time.Time `bun:",nullzero"`
+	EndsAt         time.Time `bun:",nullzero"`
+	MaxUses        int
+	MaxUsesPerUser int
+	Active         bool
+	CreatedAt      time.Time `bun:",nullzero"`
+	UpdatedAt      time.Time `bun:",nullzero"`
+}
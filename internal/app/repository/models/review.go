@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type Review struct {
+	bun.BaseModel `bun:"table:reviews"`
+	ID            int `bun:",pk,autoincrement"`
+	BookID        int
+	UserID        int
+	Rating        int
+	Body          string
+	Status        string
+	Reason        string
+	ModeratedBy   int       `bun:",nullzero"`
+	ModeratedAt   time.Time `bun:",nullzero"`
+	CreatedAt     time.Time `bun:",nullzero"`
+	UpdatedAt     time.Time `bun:",nullzero"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type ShippingRate struct {
+	bun.BaseModel `bun:"table:shipping_rates"`
+	ID            int `bun:",pk,autoincrement"`
+	Method        string
+	Name          string
+	Country       string
+	BaseAmount    int64
+	PerItemAmount int64
+	PerKgAmount   int64
+	Currency      string
+	UpdatedAt     time.Time `bun:",nullzero"`
+}
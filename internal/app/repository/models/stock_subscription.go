@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type StockSubscription struct {
+	bun.BaseModel `bun:"table:stock_subscriptions"`
+	UserID        int       `bun:",pk"`
+	BookID        int       `bun:",pk"`
+	CreatedAt     time.Time `bun:",nullzero"`
+	QueuedAt      time.Time `bun:",nullzero"`
+	NotifiedAt    time.Time `bun:",nullzero"`
+}
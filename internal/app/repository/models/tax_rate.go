@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type TaxRate struct {
+	bun.BaseModel `bun:"table:tax_rates"`
+	ID            int `bun:",pk,autoincrement"`
+	Country       string
+	State         string
+	TaxClass      string
+	Rate          string
+	Name          string
+	UpdatedAt     time.Time `bun:",nullzero"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type WebhookSubscription struct {
+	bun.BaseModel `bun:"table:webhook_subscriptions"`
+	ID            int `bun:",pk,autoincrement"`
+	URL           string
+	Secret        string
+	EventTypes    []string `bun:",array"`
+	Active        bool
+	CreatedAt     time.Time `bun:",nullzero"`
+	UpdatedAt     time.Time `bun:",nullzero"`
+}
+
+type WebhookDelivery struct {
+	bun.BaseModel  `bun:"table:webhook_deliveries"`
+	ID             int64 `bun:",pk,autoincrement"`
+	SubscriptionID int
+	EventID        int64
+	EventType      string
+	Payload        json.RawMessage `bun:"type:jsonb"`
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time `bun:",nullzero"`
+	LastStatusCode int       `bun:",nullzero"`
+	LastError      string    `bun:",nullzero"`
+	CreatedAt      time.Time `bun:",nullzero"`
+	UpdatedAt      time.Time `bun:",nullzero"`
+}
+
+type WebhookDeliveryAttempt struct {
+	bun.BaseModel `bun:"table:webhook_delivery_attempts"`
+	ID            int64 `bun:",pk,autoincrement"`
+	DeliveryID    int64
+	AttemptedAt   time.Time
+	StatusCode    int    `bun:",nullzero"`
+	Error         string `bun:",nullzero"`
+	DurationMs    int
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type Wishlist struct {
+	bun.BaseModel `bun:"table:wishlists"`
+	UserID        int       `bun:",pk"`
+	ShareToken    string    `bun:",nullzero"`
+	CreatedAt     time.Time `bun:",nullzero"`
+	UpdatedAt     time.Time `bun:",nullzero"`
+}
+
+type WishlistItem struct {
+	bun.BaseModel `bun:"table:wishlist_items"`
+	UserID        int       `bun:",pk"`
+	BookID        int       `bun:",pk"`
+	AddedAt       time.Time `bun:",nullzero"`
+}
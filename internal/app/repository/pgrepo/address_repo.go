@@ -0,0 +1,134 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/app/repository/models"
+	"github.com/northwindman/book-shop/internal/pkg/pg"
+)
+
+type AddressRepo struct {
+	db *pg.DB
+}
+
+func NewAddressRepo(db *pg.DB) *AddressRepo {
+	return &AddressRepo{
+		db: db,
+	}
+}
+
+// GetAddress returns an address of a user, addresses of other users are not found
+func (r AddressRepo) GetAddress(ctx context.Context, userID, id int) (domain.Address, error) {
+	if id == 0 {
+		return domain.Address{}, fmt.Errorf("%w: id", domain.ErrRequired)
+	}
+
+	var address models.Address
+	err := r.db.NewSelect().Model(&address).Where("id = ?", id).Where("user_id = ?", userID).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Address{}, domain.ErrNotFound
+		}
+		return domain.Address{}, fmt.Errorf("failed to get an address: %w", err)
+	}
+
+	domainAddress, err := addressToDomain(address)
+	if err != nil {
+		return domain.Address{}, fmt.Errorf("failed to create domain address: %w", err)
+	}
+
+	return domainAddress, nil
+}
+
+// GetAddresses returns the addresses of a user in the order they were added
+func (r AddressRepo) GetAddresses(ctx context.Context, userID int) ([]domain.Address, error) {
+	var addresses []models.Address
+	err := r.db.NewSelect().Model(&addresses).Where("user_id = ?", userID).Order("id").Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get addresses: %w", err)
+	}
+
+	domainAddresses := make([]domain.Address, len(addresses))
+	for i, address := range addresses {
+		domainAddress, err := addressToDomain(address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create domain address: %w", err)
+		}
+
+		domainAddresses[i] = domainAddress
+	}
+
+	return domainAddresses, nil
+}
+
+func (r AddressRepo) CreateAddress(ctx context.Context, address domain.Address) (domain.Address, error) {
+	dbAddress := domainToAddress(address)
+
+	var insertedAddress models.Address
+	err := r.db.NewInsert().Model(&dbAddress).ExcludeColumn("id").Returning("*").Scan(ctx, &insertedAddress)
+	if err != nil {
+		return domain.Address{}, fmt.Errorf("failed to insert an address: %w", err)
+	}
+
+	domainAddress, err := addressToDomain(insertedAddress)
+	if err != nil {
+		return domain.Address{}, fmt.Errorf("failed to create domain address: %w", err)
+	}
+
+	return domainAddress, nil
+}
+
+// UpdateAddress replaces an address of the user it belongs to
+func (r AddressRepo) UpdateAddress(ctx context.Context, address domain.Address) (domain.Address, error) {
+	dbAddress := domainToAddress(address)
+	dbAddress.UpdatedAt = time.Now()
+
+	var updatedAddress models.Address
+	err := r.db.NewUpdate().
+		Model(&dbAddress).
+		Where("id = ?", dbAddress.ID).
+		Where("user_id = ?", dbAddress.UserID).
+		ExcludeColumn("created_at").
+		Returning("*").
+		Scan(ctx, &updatedAddress)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Address{}, domain.ErrNotFound
+		}
+		return domain.Address{}, fmt.Errorf("failed to update an address: %w", err)
+	}
+
+	domainAddress, err := addressToDomain(updatedAddress)
+	if err != nil {
+		return domain.Address{}, fmt.Errorf("failed to create domain address: %w", err)
+	}
+
+	return domainAddress, nil
+}
+
+// DeleteAddress deletes an address of a user, orders keep their copy of it
+func (r AddressRepo) DeleteAddress(ctx context.Context, userID, id int) error {
+	if id == 0 {
+		return fmt.Errorf("%w: id", domain.ErrRequired)
+	}
+
+	res, err := r.db.NewDelete().Model((*models.Address)(nil)).Where("id = ?", id).Where("user_id = ?", userID).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete an address: %w", err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get deleted addresses: %w", err)
+	}
+	if deleted == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
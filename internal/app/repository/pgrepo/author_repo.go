@@ -0,0 +1,372 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/northwindman/book-shop/internal/app/common/slugerrors"
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/app/repository/models"
+	"github.com/northwindman/book-shop/internal/pkg/pg"
+	"github.com/uptrace/bun"
+)
+
+type AuthorRepo struct {
+	db *pg.DB
+}
+
+func NewAuthorRepo(db *pg.DB) *AuthorRepo {
+	return &AuthorRepo{
+		db: db,
+	}
+}
+
+func (r AuthorRepo) GetAuthor(ctx context.Context, id int) (domain.Author, error) {
+	if id == 0 {
+		return domain.Author{}, fmt.Errorf("%w: id", domain.ErrRequired)
+	}
+
+	var author models.Author
+	err := r.db.NewSelect().Model(&author).Where("id = ?", id).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Author{}, domain.ErrNotFound
+		}
+		return domain.Author{}, fmt.Errorf("failed to get an author: %w", err)
+	}
+
+	domainAuthor, err := authorToDomain(author)
+	if err != nil {
+		return domain.Author{}, fmt.Errorf("failed to create domain author: %w", err)
+	}
+
+	return domainAuthor, nil
+}
+
+// GetAuthors returns the authors ordered by name
+func (r AuthorRepo) GetAuthors(ctx context.Context) ([]domain.Author, error) {
+	var authors []models.Author
+	err := r.db.NewSelect().Model(&authors).Order("name", "id").Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select authors: %w", err)
+	}
+
+	domainAuthors := make([]domain.Author, len(authors))
+	for i, author := range authors {
+		domainAuthor, err := authorToDomain(author)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create domain author: %w", err)
+		}
+		domainAuthors[i] = domainAuthor
+	}
+
+	return domainAuthors, nil
+}
+
+// CreateAuthor creates an author, an author with the same name key is a conflict
+func (r AuthorRepo) CreateAuthor(ctx context.Context, author domain.Author) (domain.Author, error) {
+	dbAuthor := domainToAuthor(author)
+
+	var insertedAuthor models.Author
+	err := r.db.NewInsert().Model(&dbAuthor).Returning("*").Scan(ctx, &insertedAuthor)
+	if err != nil {
+		if pg.IsUniqueViolation(err) {
+			return domain.Author{}, authorExistsError(author.Name())
+		}
+		return domain.Author{}, fmt.Errorf("failed to insert an author: %w", err)
+	}
+
+	domainAuthor, err := authorToDomain(insertedAuthor)
+	if err != nil {
+		return domain.Author{}, fmt.Errorf("failed to create domain author: %w", err)
+	}
+
+	return domainAuthor, nil
+}
+
+// authorColumns maps the patchable fields of an author to their columns
+var authorColumns = map[string][]string{
+	"name": {"name", "name_key"},
+	"bio":  {"bio"},
+}
+
+// UpdateAuthor updates the columns of the given fields of an author if it is still at the version of the given
+// author, the version is incremented. A stale version is a precondition failure. Renaming an author updates the
+// bylines of their books.
+func (r AuthorRepo) UpdateAuthor(ctx context.Context, author domain.Author, fields []string) (domain.Author, error) {
+	columns := []string{"version", "updated_at"}
+	for _, field := range fields {
+		fieldColumns, ok := authorColumns[field]
+		if !ok {
+			return domain.Author{}, fmt.Errorf("%w: author field %q cannot be updated", domain.ErrInvalidFormat, field)
+		}
+		columns = append(columns, fieldColumns...)
+	}
+
+	dbAuthor := domainToAuthor(author)
+	dbAuthor.UpdatedAt = time.Now()
+	dbAuthor.Version = author.Version() + 1
+
+	var updatedAuthor models.Author
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		err := tx.NewUpdate().
+			Model(&dbAuthor).
+			Column(columns...).
+			Where("id = ?", dbAuthor.ID).
+			Where("version = ?", author.Version()).
+			Returning("*").
+			Scan(ctx, &updatedAuthor)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return r.missingOrStale(ctx, author.ID())
+			}
+			if pg.IsUniqueViolation(err) {
+				return authorExistsError(author.Name())
+			}
+			return fmt.Errorf("failed to update an author: %w", err)
+		}
+
+		var bookIDs []int
+		err = tx.NewSelect().Model((*models.BookAuthor)(nil)).
+			Column("book_id").
+			Where("author_id = ?", author.ID()).
+			Scan(ctx, &bookIDs)
+		if err != nil {
+			return fmt.Errorf("failed to get books of author: %w", err)
+		}
+
+		return refreshBylines(ctx, tx, bookIDs)
+	}, r.db)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Author{}, err
+		}
+		return domain.Author{}, fmt.Errorf("failed to update an author: %w", err)
+	}
+
+	domainAuthor, err := authorToDomain(updatedAuthor)
+	if err != nil {
+		return domain.Author{}, fmt.Errorf("failed to create domain author: %w", err)
+	}
+
+	return domainAuthor, nil
+}
+
+// DeleteAuthor deletes an author if it is still at the given version, authors credited on books are a conflict
+func (r AuthorRepo) DeleteAuthor(ctx context.Context, id, version int) error {
+	if id == 0 {
+		return fmt.Errorf("%w: id", domain.ErrRequired)
+	}
+
+	res, err := r.db.NewDelete().Model((*models.Author)(nil)).Where("id = ?", id).Where("version = ?", version).Exec(ctx)
+	if err != nil {
+		if pg.IsForeignKeyViolation(err) {
+			return slugerrors.NewConflictError("the author is credited on books, credit other authors first", "author-has-books")
+		}
+		return fmt.Errorf("failed to delete an author: %w", err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get deleted authors: %w", err)
+	}
+	if deleted == 0 {
+		return r.missingOrStale(ctx, id)
+	}
+
+	return nil
+}
+
+// missingOrStale tells why an author could not be changed at a version
+func (r AuthorRepo) missingOrStale(ctx context.Context, id int) error {
+	exists, err := r.db.NewSelect().Model((*models.Author)(nil)).Where("id = ?", id).Exists(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check an author: %w", err)
+	}
+	if !exists {
+		return domain.ErrNotFound
+	}
+	return staleVersionError("author", id)
+}
+
+type authorBookRow struct {
+	models.Book `bun:",extend"`
+	Role        string
+}
+
+// GetAuthorBooks returns the books credited to an author with the role of the author, oldest first
+func (r AuthorRepo) GetAuthorBooks(ctx context.Context, id int) ([]domain.AuthorBook, error) {
+	var rows []authorBookRow
+	err := r.db.NewSelect().
+		TableExpr("book_authors AS ba").
+		ColumnExpr("b.*, ba.role").
+		Join("JOIN books AS b ON b.id = ba.book_id").
+		Where("ba.author_id = ?", id).
+		Order("b.year", "b.id", "ba.role").
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get books of author: %w", err)
+	}
+
+	bookIDs := make([]int, len(rows))
+	for i, row := range rows {
+		bookIDs[i] = row.ID
+	}
+	authors, err := getBookAuthors(ctx, r.db, bookIDs)
+	if err != nil {
+		return nil, err
+	}
+	categoryIDs, err := getBookCategories(ctx, r.db, bookIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	books := make([]domain.AuthorBook, len(rows))
+	for i, row := range rows {
+		book, err := bookWithRelationsToDomain(row.Book, authors[row.ID], categoryIDs[row.ID])
+		if err != nil {
+			return nil, fmt.Errorf("failed to create domain book: %w", err)
+		}
+		books[i] = domain.AuthorBook{Book: book, Role: row.Role}
+	}
+
+	return books, nil
+}
+
+func authorExistsError(name string) error {
+	return slugerrors.NewConflictError(fmt.Sprintf("an author named %q already exists", name), "author-exists")
+}
+
+// resolveBookAuthors returns the credits of a book with the IDs and names of their authors. Authors known by name
+// only are looked up by their name key and created when they do not exist, credits resolving to the same author in
+// the same role are merged.
+func resolveBookAuthors(ctx context.Context, db bun.IDB, credits []domain.BookAuthor) ([]domain.BookAuthor, error) {
+	resolved := make([]domain.BookAuthor, 0, len(credits))
+	for _, credit := range credits {
+		var author models.Author
+		if credit.AuthorID > 0 {
+			err := db.NewSelect().Model(&author).Where("id = ?", credit.AuthorID).Scan(ctx)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return nil, slugerrors.NewBadRequestError(fmt.Sprintf("author %d not found", credit.AuthorID), "unknown-author")
+				}
+				return nil, fmt.Errorf("failed to get author: %w", err)
+			}
+		} else {
+			author = models.Author{Name: strings.TrimSpace(credit.Name), NameKey: domain.AuthorNameKey(credit.Name)}
+			// the no-op update returns the existing author
+			err := db.NewInsert().Model(&author).
+				On("CONFLICT (name_key) DO UPDATE").
+				Set("name_key = EXCLUDED.name_key").
+				Returning("*").
+				Scan(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to insert author: %w", err)
+			}
+		}
+
+		bookAuthor := domain.BookAuthor{AuthorID: author.ID, Name: author.Name, Role: credit.Role}
+		if !slices.Contains(resolved, bookAuthor) {
+			resolved = append(resolved, bookAuthor)
+		}
+	}
+
+	return resolved, nil
+}
+
+// setBookAuthors replaces the credits of a book, they are resolved to authors and the byline of the book is updated
+func setBookAuthors(ctx context.Context, db bun.IDB, bookID int, credits []domain.BookAuthor) error {
+	resolved, err := resolveBookAuthors(ctx, db, credits)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.NewDelete().Model((*models.BookAuthor)(nil)).Where("book_id = ?", bookID).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete book authors: %w", err)
+	}
+
+	bookAuthors := make([]models.BookAuthor, len(resolved))
+	for i, credit := range resolved {
+		bookAuthors[i] = models.BookAuthor{BookID: bookID, AuthorID: credit.AuthorID, Role: credit.Role, Position: i}
+	}
+	_, err = db.NewInsert().Model(&bookAuthors).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to insert book authors: %w", err)
+	}
+
+	return refreshBylines(ctx, db, []int{bookID})
+}
+
+// refreshBylinesQuery sets the byline of books to the names of their authors, or of all their credited people when
+// none of them is credited as author
+const refreshBylinesQuery = `
+UPDATE books AS b
+SET author = s.byline
+FROM (
+    SELECT ba.book_id,
+           COALESCE(string_agg(a.name, ', ' ORDER BY ba.position) FILTER (WHERE ba.role = ?),
+                    string_agg(a.name, ', ' ORDER BY ba.position)) AS byline
+    FROM book_authors ba
+    JOIN authors a ON a.id = ba.author_id
+    WHERE ba.book_id IN (?)
+    GROUP BY ba.book_id
+) s
+WHERE b.id = s.book_id AND b.author <> s.byline
+`
+
+// refreshBylines updates the bylines of books from their credits
+func refreshBylines(ctx context.Context, db bun.IDB, bookIDs []int) error {
+	if len(bookIDs) == 0 {
+		return nil
+	}
+
+	_, err := db.ExecContext(ctx, refreshBylinesQuery, domain.AuthorRoleAuthor, bun.In(bookIDs))
+	if err != nil {
+		return fmt.Errorf("failed to update bylines: %w", err)
+	}
+
+	return nil
+}
+
+type bookAuthorRow struct {
+	BookID   int
+	AuthorID int
+	Name     string
+	Role     string
+}
+
+// getBookAuthors returns the credits of books by book ID, in the order they are credited
+func getBookAuthors(ctx context.Context, db bun.IDB, bookIDs []int) (map[int][]domain.BookAuthor, error) {
+	authors := make(map[int][]domain.BookAuthor)
+	if len(bookIDs) == 0 {
+		return authors, nil
+	}
+
+	var rows []bookAuthorRow
+	err := db.NewSelect().
+		TableExpr("book_authors AS ba").
+		ColumnExpr("ba.book_id, ba.author_id, a.name, ba.role").
+		Join("JOIN authors AS a ON a.id = ba.author_id").
+		Where("ba.book_id IN (?)", bun.In(bookIDs)).
+		Order("ba.book_id", "ba.position").
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book authors: %w", err)
+	}
+
+	for _, row := range rows {
+		authors[row.BookID] = append(authors[row.BookID], domain.BookAuthor{
+			AuthorID: row.AuthorID,
+			Name:     row.Name,
+			Role:     row.Role,
+		})
+	}
+
+	return authors, nil
+}
@@ -5,8 +5,11 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/northwindman/book-shop/internal/app/common/slugerrors"
 	"github.com/northwindman/book-shop/internal/app/domain"
 	"github.com/northwindman/book-shop/internal/app/repository/models"
 	"github.com/northwindman/book-shop/internal/pkg/pg"
@@ -37,74 +40,287 @@ func (r BookRepo) GetBook(ctx context.Context, id int) (domain.Book, error) {
 		return domain.Book{}, fmt.Errorf("failed to get a book: %w", err)
 	}
 
-	domainBook, err := bookToDomain(book)
+	return bookToDomain(ctx, r.db, book)
+}
+
+// GetBookByISBN returns the book with an ISBN-13
+func (r BookRepo) GetBookByISBN(ctx context.Context, isbn string) (domain.Book, error) {
+	var book models.Book
+	err := r.db.NewSelect().Model(&book).Where("isbn = ?", isbn).Scan(ctx)
 	if err != nil {
-		return domain.Book{}, fmt.Errorf("failed to create domain book: %w", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Book{}, domain.ErrNotFound
+		}
+		return domain.Book{}, fmt.Errorf("failed to get a book: %w", err)
 	}
 
-	return domainBook, nil
+	return bookToDomain(ctx, r.db, book)
 }
 
+// CreateBook creates a book in its categories and credits its authors, authors known by name only are looked up or
+// created. A book with the ISBN of another book is a conflict.
 func (r BookRepo) CreateBook(ctx context.Context, book domain.Book) (domain.Book, error) {
 	dbBook := domainToBook(book)
 
 	var insertedBook models.Book
-	err := r.db.NewInsert().Model(&dbBook).Returning("*").Scan(ctx, &insertedBook)
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		err := tx.NewInsert().Model(&dbBook).Returning("*").Scan(ctx, &insertedBook)
+		if err != nil {
+			if pg.IsUniqueViolation(err) {
+				return isbnExistsError(book.ISBN())
+			}
+			if pg.IsForeignKeyViolation(err) {
+				return unknownCategoryError(book.CategoryID())
+			}
+			return fmt.Errorf("failed to insert a book: %w", err)
+		}
+
+		err = setBookAuthors(ctx, tx, insertedBook.ID, bookCredits(book))
+		if err != nil {
+			return err
+		}
+		err = setBookCategories(ctx, tx, insertedBook.ID, bookCategories(book))
+		if err != nil {
+			return err
+		}
+		err = tx.NewSelect().Model(&insertedBook).WherePK().Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get inserted book: %w", err)
+		}
+
+		if insertedBook.Stock > 0 {
+			err := insertMovements(ctx, tx, []models.Book{insertedBook}, models.InventoryMovement{
+				Type:   domain.MovementRestock,
+				Delta:  insertedBook.Stock,
+				Reason: "initial stock",
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		event, err := domain.NewEvent(domain.NewEventData{
+			Type:          domain.EventBookCreated,
+			AggregateType: domain.AggregateBook,
+			AggregateID:   insertedBook.ID,
+			Payload: domain.BookCreatedPayload{
+				BookID:     insertedBook.ID,
+				Title:      insertedBook.Title,
+				Author:     insertedBook.Author,
+				Stock:      insertedBook.Stock,
+				CategoryID: insertedBook.CategoryID,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create book created event: %w", err)
+		}
+
+		return insertEvents(ctx, tx, event)
+	}, r.db)
 	if err != nil {
-		return domain.Book{}, fmt.Errorf("failed to insert a book: %w", err)
+		return domain.Book{}, fmt.Errorf("failed to create a book: %w", err)
 	}
 
-	domainBook, err := bookToDomain(insertedBook)
-	if err != nil {
-		return domain.Book{}, fmt.Errorf("failed to create domain book: %w", err)
+	return bookToDomain(ctx, r.db, insertedBook)
+}
+
+// isbnExistsError is returned when a book is given the ISBN of another book
+func isbnExistsError(isbn string) error {
+	return slugerrors.NewConflictError(fmt.Sprintf("a book with ISBN %s already exists", isbn), "isbn-exists")
+}
+
+// bookCategories returns the categories of a book, a book without categories is in its primary category
+func bookCategories(book domain.Book) []int {
+	if len(book.CategoryIDs()) > 0 {
+		return book.CategoryIDs()
 	}
+	return []int{book.CategoryID()}
+}
 
-	return domainBook, nil
+// bookCredits returns the credits of a book, a book without credits is credited to the author of its byline
+func bookCredits(book domain.Book) []domain.BookAuthor {
+	if len(book.Authors()) > 0 {
+		return book.Authors()
+	}
+	return []domain.BookAuthor{{Name: book.Author(), Role: domain.AuthorRoleAuthor}}
+}
 
+// bookColumns maps the patchable fields of a book to their columns
+var bookColumns = map[string][]string{
+	"title":             {"title"},
+	"year":              {"year"},
+	"author":            {"author"},
+	"authors":           {},
+	"price":             {"price_amount", "price_currency"},
+	"category_id":       {"category_id"},
+	"category_ids":      {"category_id"},
+	"tax_class":         {"tax_class"},
+	"weight_grams":      {"weight_grams"},
+	"reorder_threshold": {"reorder_threshold"},
+	"release_date":      {"release_date"},
+	"preorder":          {"preorder"},
+	"preorder_cap":      {"preorder_cap"},
+	"isbn":              {"isbn"},
+	"publisher":         {"publisher"},
+	"edition":           {"edition"},
+	"language":          {"language"},
+	"page_count":        {"page_count"},
+	"format":            {"format"},
+	"description":       {"description"},
 }
 
-func (r BookRepo) UpdateBook(ctx context.Context, book domain.Book) (domain.Book, error) {
+// UpdateBook updates the columns of the given fields of a book if it is still at the version of the given book,
+// the version is incremented. A stale version is a precondition failure. A changed byline or credits replace the
+// credited authors of the book, changed categories replace the categories of the book.
+func (r BookRepo) UpdateBook(ctx context.Context, book domain.Book, fields []string) (domain.Book, error) {
+	columns := []string{"version", "updated_at"}
+	for _, field := range fields {
+		fieldColumns, ok := bookColumns[field]
+		if !ok {
+			return domain.Book{}, fmt.Errorf("%w: book field %q cannot be updated", domain.ErrInvalidFormat, field)
+		}
+		for _, column := range fieldColumns {
+			// the primary category is written for both category fields
+			if !slices.Contains(columns, column) {
+				columns = append(columns, column)
+			}
+		}
+	}
+
 	dbBook := domainToBook(book)
 	dbBook.UpdatedAt = time.Now()
+	dbBook.Version = book.Version() + 1
 
 	var updatedBook models.Book
-	err := r.db.NewUpdate().
-		Model(&dbBook).
-		Where("id = ?", dbBook.ID).
-		ExcludeColumn("created_at", "stock").
-		Returning("*").
-		Scan(ctx, &updatedBook)
-	if err != nil {
-		return domain.Book{}, fmt.Errorf("failed to update a book: %w", err)
-	}
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		err := tx.NewUpdate().
+			Model(&dbBook).
+			Column(columns...).
+			Where("id = ?", dbBook.ID).
+			Where("version = ?", book.Version()).
+			Returning("*").
+			Scan(ctx, &updatedBook)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return r.missingOrStale(ctx, book.ID())
+			}
+			if pg.IsUniqueViolation(err) {
+				return isbnExistsError(book.ISBN())
+			}
+			if pg.IsForeignKeyViolation(err) {
+				return unknownCategoryError(book.CategoryID())
+			}
+			return fmt.Errorf("failed to update a book: %w", err)
+		}
+
+		if slices.Contains(fields, "category_id") || slices.Contains(fields, "category_ids") {
+			err = setBookCategories(ctx, tx, book.ID(), bookCategories(book))
+			if err != nil {
+				return err
+			}
+		}
+		if !slices.Contains(fields, "author") && !slices.Contains(fields, "authors") {
+			return nil
+		}
+		err = setBookAuthors(ctx, tx, book.ID(), bookCredits(book))
+		if err != nil {
+			return err
+		}
+		err = tx.NewSelect().Model(&updatedBook).WherePK().Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get updated book: %w", err)
+		}
 
-	domainBook, err := bookToDomain(updatedBook)
+		return nil
+	}, r.db)
 	if err != nil {
-		return domain.Book{}, fmt.Errorf("failed to create domain book: %w", err)
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Book{}, err
+		}
+		return domain.Book{}, fmt.Errorf("failed to update a book: %w", err)
 	}
 
-	return domainBook, nil
+	return bookToDomain(ctx, r.db, updatedBook)
 }
 
-func (r BookRepo) DeleteBook(ctx context.Context, id int) error {
+// DeleteBook deletes a book if it is still at the given version
+func (r BookRepo) DeleteBook(ctx context.Context, id, version int) error {
 	if id == 0 {
 		return fmt.Errorf("%w: id", domain.ErrRequired)
 	}
 
-	_, err := r.db.NewDelete().Model((*models.Book)(nil)).Where("id = ?", id).Exec(ctx)
+	res, err := r.db.NewDelete().Model((*models.Book)(nil)).Where("id = ?", id).Where("version = ?", version).Exec(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to delete a book: %w", err)
 	}
 
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get deleted books: %w", err)
+	}
+	if deleted == 0 {
+		return r.missingOrStale(ctx, id)
+	}
+
 	return nil
 }
 
-func (r BookRepo) GetBooks(ctx context.Context, categoryIDs []int, limit, offset int) ([]domain.Book, error) {
+// missingOrStale tells why a book could not be changed at a version
+func (r BookRepo) missingOrStale(ctx context.Context, id int) error {
+	exists, err := r.db.NewSelect().Model((*models.Book)(nil)).Where("id = ?", id).Exists(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check a book: %w", err)
+	}
+	if !exists {
+		return domain.ErrNotFound
+	}
+	return staleVersionError("book", id)
+}
+
+// GetBooksByIDs returns the books with the given IDs regardless of their stock, missing books are skipped
+func (r BookRepo) GetBooksByIDs(ctx context.Context, ids []int) ([]domain.Book, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var books []models.Book
+	err := r.db.NewSelect().Model(&books).Where("id IN (?)", bun.In(ids)).Order("id").Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get books: %w", err)
+	}
+
+	return booksToDomain(ctx, r.db, books)
+}
+
+// bookOrders maps the orders books can be listed in to their columns, ties are broken by ID
+var bookOrders = map[string][]string{
+	domain.BookSortID:      {"id"},
+	domain.BookSortRating:  {"rating_total::numeric / NULLIF(rating_count, 0) DESC NULLS LAST", "rating_count DESC", "id"},
+	domain.BookSortReviews: {"rating_count DESC", "id"},
+}
+
+// GetBooks returns a page of the books on sale in an order of domain.BookSorts, by ID when sort is empty. Books in
+// any of the given categories are listed, or in their descendants too with descendants.
+func (r BookRepo) GetBooks(ctx context.Context, categoryIDs []int, descendants bool, sort string, limit, offset int) ([]domain.Book, error) {
+	if sort == "" {
+		sort = domain.BookSortID
+	}
+	order, ok := bookOrders[sort]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown book order %q", domain.ErrInvalidFormat, sort)
+	}
+
 	var books []models.Book
 	query := r.db.NewSelect().Model(&books)
-	query.Where("stock > 0")
-	if len(categoryIDs) > 0 {
-		query.Where("category_id IN (?)", bun.In(categoryIDs))
+	// out of stock books are listed while they take pre-orders
+	query.Where("stock > 0 OR (preorder AND preorders < preorder_cap AND (release_date IS NULL OR release_date > current_date))")
+	switch {
+	case len(categoryIDs) > 0 && descendants:
+		query.Where("book.id IN (SELECT bc.book_id FROM book_categories AS bc WHERE bc.category_id IN ("+
+			categoryDescendantsQuery+"))", bun.In(categoryIDs))
+	case len(categoryIDs) > 0:
+		query.Where("book.id IN (SELECT bc.book_id FROM book_categories AS bc WHERE bc.category_id IN (?))",
+			bun.In(categoryIDs))
 	}
 	if limit > 0 {
 		query.Limit(limit)
@@ -112,21 +328,11 @@ func (r BookRepo) GetBooks(ctx context.Context, categoryIDs []int, limit, offset
 	if offset > 0 {
 		query.Offset(offset)
 	}
-	query.Order("id")
+	query.OrderExpr(strings.Join(order, ", "))
 	err := query.Scan(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get books: %w", err)
 	}
 
-	domainBooks := make([]domain.Book, len(books))
-	for i, book := range books {
-		domainBook, err := bookToDomain(book)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create domain book: %w", err)
-		}
-
-		domainBooks[i] = domainBook
-	}
-
-	return domainBooks, nil
+	return booksToDomain(ctx, r.db, books)
 }
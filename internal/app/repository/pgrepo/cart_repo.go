@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/northwindman/book-shop/internal/app/common/slugerrors"
@@ -34,12 +35,7 @@ func (r CartRepo) GetCart(ctx context.Context, userID int) (domain.Cart, error)
 		return domain.Cart{}, fmt.Errorf("failed to get cart: %w", err)
 	}
 
-	domainCart, err := cartToDomain(cart)
-	if err != nil {
-		return domain.Cart{}, fmt.Errorf("failed to create domain cart: %w", err)
-	}
-
-	return domainCart, nil
+	return cartToDomain(cart), nil
 }
 
 func (r CartRepo) UpdateCartAndStocks(ctx context.Context, cart domain.Cart) error {
@@ -71,40 +67,119 @@ func (r CartRepo) UpdateCartAndStocks(ctx context.Context, cart domain.Cart) err
 			return slugerrors.NewBadRequestError("some books are out of stock", "out-of-stock")
 		}
 
-		dbStocks := []models.Book{}
+		lockedBooks := make(map[int]domain.Book)
 		if cartUnion.HasBooks() {
-			err := tx.NewRaw("SELECT id, stock FROM ? where id in (?) FOR UPDATE", bun.Ident("books"), bun.In(cartUnion.BookIDs())).Scan(ctx, &dbStocks)
+			var dbBooks []models.Book
+			err := tx.NewSelect().Model(&dbBooks).Where("id IN (?)", bun.In(cartUnion.BookIDs())).For("UPDATE").Scan(ctx)
 			if err != nil {
 				return fmt.Errorf("failed to lock stocks: %w", err)
 			}
+			domainBooks, err := booksToDomain(ctx, tx, dbBooks)
+			if err != nil {
+				return err
+			}
+			for _, book := range domainBooks {
+				lockedBooks[book.ID()] = book
+			}
 		}
 
-		if cartAdd.HasBooks() {
-			_, err := tx.NewUpdate().Model((*models.Book)(nil)).Set("stock = stock - 1").Where("id in (?)", bun.In(cartAdd.BookIDs())).Exec(ctx)
+		// books in stock are reserved, out of stock books taking pre-orders are pre-ordered
+		now := time.Now()
+		var reserveIDs, preorderIDs []int
+		for _, bookID := range cartAdd.BookIDs() {
+			book, ok := lockedBooks[bookID]
+			if !ok {
+				return slugerrors.NewBadRequestError("some books are out of stock", "out-of-stock")
+			}
+			switch {
+			case book.Stock() > 0:
+				reserveIDs = append(reserveIDs, bookID)
+			case book.AcceptsPreorders(now):
+				preorderIDs = append(preorderIDs, bookID)
+			default:
+				return slugerrors.NewBadRequestError("some books are out of stock", "out-of-stock")
+			}
+		}
+		var releaseIDs, cancelIDs []int
+		for _, bookID := range cartRemove.BookIDs() {
+			if oldCart.IsPreorder(bookID) {
+				cancelIDs = append(cancelIDs, bookID)
+			} else {
+				releaseIDs = append(releaseIDs, bookID)
+			}
+		}
+
+		var events []domain.Event
+		if len(reserveIDs) > 0 {
+			var reduced []models.Book
+			err := tx.NewUpdate().Model((*models.Book)(nil)).Set("stock = stock - 1").Where("id in (?)", bun.In(reserveIDs)).Returning("id, stock").Scan(ctx, &reduced)
 			if err != nil {
 				return fmt.Errorf("failed to reduce stock: %w", err)
 			}
+			err = insertMovements(ctx, tx, reduced, models.InventoryMovement{
+				Type:   domain.MovementReservation,
+				Delta:  -1,
+				UserID: cart.UserID(),
+			})
+			if err != nil {
+				return err
+			}
+			reducedEvents, err := stockChangedEvents(reduced, -1)
+			if err != nil {
+				return err
+			}
+			events = append(events, reducedEvents...)
 		}
-		if cartRemove.HasBooks() {
-			_, err := tx.NewUpdate().Model((*models.Book)(nil)).Set("stock = stock + 1").Where("id in (?)", bun.In(cartRemove.BookIDs())).Exec(ctx)
+		if len(releaseIDs) > 0 {
+			var added []models.Book
+			err := tx.NewUpdate().Model((*models.Book)(nil)).Set("stock = stock + 1").Where("id in (?)", bun.In(releaseIDs)).Returning("id, stock").Scan(ctx, &added)
 			if err != nil {
 				return fmt.Errorf("failed to add stock: %w", err)
 			}
+			err = insertMovements(ctx, tx, added, models.InventoryMovement{
+				Type:   domain.MovementRelease,
+				Delta:  1,
+				UserID: cart.UserID(),
+			})
+			if err != nil {
+				return err
+			}
+			addedEvents, err := restockBooks(ctx, tx, added, 1)
+			if err != nil {
+				return err
+			}
+			events = append(events, addedEvents...)
+		}
+		if err := addPreorders(ctx, tx, preorderIDs, 1); err != nil {
+			return err
+		}
+		if err := addPreorders(ctx, tx, cancelIDs, -1); err != nil {
+			return err
+		}
+
+		// the pre-orders of the cart are those kept from the old cart and the new ones
+		cartPreorderIDs := []int{}
+		for _, bookID := range cart.BookIDs() {
+			if oldCart.IsPreorder(bookID) || slices.Contains(preorderIDs, bookID) {
+				cartPreorderIDs = append(cartPreorderIDs, bookID)
+			}
 		}
 
 		dbCart := domainToCart(cart)
+		dbCart.PreorderBookIDs = cartPreorderIDs
 		dbCart.UpdatedAt = time.Now()
 
 		err = tx.NewInsert().Model(&dbCart).
 			On("CONFLICT (user_id) DO UPDATE").
 			Set("book_ids = EXCLUDED.book_ids").
+			Set("preorder_book_ids = EXCLUDED.preorder_book_ids").
 			Set("updated_at = EXCLUDED.updated_at").
 			Scan(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to update cart: %w", err)
 		}
 
-		return nil
+		return insertEvents(ctx, tx, events...)
 	}, r.db)
 	if err != nil {
 		return fmt.Errorf("failed to update cart and stock: %w", err)
@@ -113,6 +188,7 @@ func (r CartRepo) UpdateCartAndStocks(ctx context.Context, cart domain.Cart) err
 	return nil
 }
 
+// CheckStocks reports whether every book of a cart is in stock or can be pre-ordered
 func (r CartRepo) CheckStocks(ctx context.Context, cart domain.Cart) (bool, error) {
 	var books []models.Book
 	err := r.db.NewSelect().Model(&books).Where("id in (?)", bun.In(cart.BookIDs())).Scan(ctx)
@@ -120,13 +196,19 @@ func (r CartRepo) CheckStocks(ctx context.Context, cart domain.Cart) (bool, erro
 		return false, fmt.Errorf("failed to get stocks: %w", err)
 	}
 
-	stockMap := make(map[int]int)
-	for _, book := range books {
-		stockMap[book.ID] = book.Stock
+	domainBooks, err := booksToDomain(ctx, r.db, books)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	available := make(map[int]bool)
+	for _, book := range domainBooks {
+		available[book.ID()] = book.Stock() > 0 || book.AcceptsPreorders(now)
 	}
 
 	for _, bookID := range cart.BookIDs() {
-		if stockMap[bookID] == 0 {
+		if !available[bookID] {
 			return false, nil
 		}
 	}
@@ -144,7 +226,214 @@ func (r CartRepo) DeleteCart(ctx context.Context, userID int) error {
 	return nil
 }
 
-func (r CartRepo) CleanExpiredCarts(ctx context.Context, ttl time.Duration) error {
+// CheckoutCart places the order for the cart of its user and deletes the cart, the books are already reserved in stock.
+// The checkout fails when the cart was changed after the order was priced.
+func (r CartRepo) CheckoutCart(ctx context.Context, cart domain.Cart, order domain.Order) (domain.Order, error) {
+	var placedOrder models.Order
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		var dbCart models.Cart
+		err := tx.NewSelect().Model(&dbCart).Where("user_id = ?", cart.UserID()).For("UPDATE").Scan(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return slugerrors.NewBadRequestError("cart is empty", "empty-cart")
+			}
+			return fmt.Errorf("failed to get cart: %w", err)
+		}
+
+		lockedCart := cartToDomain(dbCart)
+		if !lockedCart.Equal(cart) || lockedCart.CouponCode() != cart.CouponCode() {
+			return slugerrors.NewBadRequestError("cart was changed during checkout", "cart-changed")
+		}
+
+		// usage limits are checked again with the promotions locked so concurrent checkouts can't exceed them
+		for _, discount := range order.Discounts() {
+			if err := redeemPromotion(ctx, tx, discount.PromotionID, cart.UserID()); err != nil {
+				return err
+			}
+		}
+
+		placedOrder = domainToOrder(order)
+		placedOrder.CreatedAt = time.Now()
+		err = tx.NewInsert().Model(&placedOrder).Returning("id, created_at").Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to insert order: %w", err)
+		}
+
+		for i := range placedOrder.Items {
+			placedOrder.Items[i].OrderID = placedOrder.ID
+		}
+		err = tx.NewInsert().Model(&placedOrder.Items).Returning("id").Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to insert order items: %w", err)
+		}
+
+		if len(placedOrder.Discounts) > 0 {
+			for i := range placedOrder.Discounts {
+				placedOrder.Discounts[i].OrderID = placedOrder.ID
+			}
+			err = tx.NewInsert().Model(&placedOrder.Discounts).Returning("id").Scan(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to insert order discounts: %w", err)
+			}
+		}
+
+		if len(placedOrder.Taxes) > 0 {
+			for i := range placedOrder.Taxes {
+				placedOrder.Taxes[i].OrderID = placedOrder.ID
+			}
+			err = tx.NewInsert().Model(&placedOrder.Taxes).Returning("id").Scan(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to insert order taxes: %w", err)
+			}
+		}
+
+		_, err = tx.NewDelete().Model((*models.Cart)(nil)).Where("user_id = ?", cart.UserID()).Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to delete cart: %w", err)
+		}
+
+		if err := insertSales(ctx, tx, cart, placedOrder.ID); err != nil {
+			return err
+		}
+
+		// pre-orders are fulfilled at once when stock arrived since they were added to the cart
+		fulfilledEvents, err := fulfilPreorders(ctx, tx, cart.PreorderBookIDs())
+		if err != nil {
+			return err
+		}
+		if len(fulfilledEvents) > 0 {
+			err = tx.NewSelect().Model(&placedOrder).Column("status").WherePK().Scan(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get order status: %w", err)
+			}
+		}
+
+		event, err := domain.NewEvent(domain.NewEventData{
+			Type:          domain.EventCheckoutCompleted,
+			AggregateType: domain.AggregateUser,
+			AggregateID:   cart.UserID(),
+			Payload: domain.CheckoutCompletedPayload{
+				UserID:      cart.UserID(),
+				OrderID:     placedOrder.ID,
+				BookIDs:     cart.BookIDs(),
+				TotalAmount: placedOrder.TotalAmount,
+				Currency:    placedOrder.Currency,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create checkout completed event: %w", err)
+		}
+
+		return insertEvents(ctx, tx, append(fulfilledEvents, event)...)
+	}, r.db)
+	if err != nil {
+		return domain.Order{}, fmt.Errorf("failed to checkout cart: %w", err)
+	}
+
+	domainOrder, err := orderToDomain(placedOrder)
+	if err != nil {
+		return domain.Order{}, fmt.Errorf("failed to create domain order: %w", err)
+	}
+
+	return domainOrder, nil
+}
+
+// insertSales records the books of a cart sold by an order: the reservations of the cart are released and the books
+// sold, which leaves the stock as is. Pre-ordered books are sold when they are fulfilled.
+func insertSales(ctx context.Context, tx bun.Tx, cart domain.Cart, orderID int) error {
+	var reservedIDs []int
+	for _, bookID := range cart.BookIDs() {
+		if !cart.IsPreorder(bookID) {
+			reservedIDs = append(reservedIDs, bookID)
+		}
+	}
+	if len(reservedIDs) == 0 {
+		return nil
+	}
+
+	var books []models.Book
+	err := tx.NewSelect().Model(&books).Column("id", "stock").Where("id IN (?)", bun.In(reservedIDs)).Order("id").Scan(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get sold stocks: %w", err)
+	}
+
+	released := make([]models.Book, len(books))
+	for i, book := range books {
+		released[i] = book
+		released[i].Stock++
+	}
+	err = insertMovements(ctx, tx, released, models.InventoryMovement{
+		Type:    domain.MovementRelease,
+		Delta:   1,
+		OrderID: orderID,
+		UserID:  cart.UserID(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return insertMovements(ctx, tx, books, models.InventoryMovement{
+		Type:    domain.MovementSale,
+		Delta:   -1,
+		OrderID: orderID,
+		UserID:  cart.UserID(),
+	})
+}
+
+// redeemPromotion locks a promotion and checks it can still be used by a user
+func redeemPromotion(ctx context.Context, tx bun.Tx, promotionID, userID int) error {
+	var promotion models.Promotion
+	err := tx.NewSelect().Model(&promotion).Where("id = ?", promotionID).For("UPDATE").Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return slugerrors.NewBadRequestError("promotion no longer exists", "coupon-not-applicable")
+		}
+		return fmt.Errorf("failed to lock promotion: %w", err)
+	}
+
+	domainPromotion, err := promotionToDomain(promotion)
+	if err != nil {
+		return fmt.Errorf("failed to create domain promotion: %w", err)
+	}
+
+	usage, err := promotionUsage(ctx, tx, promotionID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := domainPromotion.CheckRedeemable(time.Now(), usage); err != nil {
+		return slugerrors.NewBadRequestError(err.Error(), "coupon-not-applicable")
+	}
+
+	return nil
+}
+
+// SetCoupon applies a coupon code to the cart of a user, an empty code removes the coupon
+func (r CartRepo) SetCoupon(ctx context.Context, userID int, code string) error {
+	res, err := r.db.NewUpdate().
+		Model((*models.Cart)(nil)).
+		Set("coupon_code = NULLIF(?, '')", code).
+		Where("user_id = ?", userID).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to set coupon: %w", err)
+	}
+
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get updated carts: %w", err)
+	}
+	if updated == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// CleanExpiredCarts deletes carts not updated within ttl, returns their books to stock, cancels their pre-orders and
+// reports how many carts were released
+func (r CartRepo) CleanExpiredCarts(ctx context.Context, ttl time.Duration) (int, error) {
+	var released int
 	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
 		var expiredCarts []models.Cart
 		err := tx.NewSelect().Model(&expiredCarts).Where("updated_at < ?", time.Now().Add(-ttl)).Scan(ctx)
@@ -153,23 +442,97 @@ func (r CartRepo) CleanExpiredCarts(ctx context.Context, ttl time.Duration) erro
 		}
 
 		for _, cart := range expiredCarts {
+			var returned []models.Book
 			for _, bookID := range cart.BookIDs {
-				_, err := tx.NewUpdate().Model((*models.Book)(nil)).Set("stock = stock + 1").Where("id = ?", bookID).Exec(ctx)
+				if slices.Contains(cart.PreorderBookIDs, bookID) {
+					continue
+				}
+				var book models.Book
+				err := tx.NewUpdate().Model((*models.Book)(nil)).Set("stock = stock + 1").Where("id = ?", bookID).Returning("id, stock").Scan(ctx, &book)
 				if err != nil {
 					return fmt.Errorf("failed to return stock: %w", err)
 				}
+				returned = append(returned, book)
+			}
+			err := insertMovements(ctx, tx, returned, models.InventoryMovement{
+				Type:   domain.MovementRelease,
+				Delta:  1,
+				Reason: "cart expired",
+				UserID: cart.UserID,
+			})
+			if err != nil {
+				return err
+			}
+			events, err := restockBooks(ctx, tx, returned, 1)
+			if err != nil {
+				return err
 			}
-			_, err := tx.NewDelete().Model(&cart).Where("user_id = ?", cart.UserID).Exec(ctx)
+			if err := insertEvents(ctx, tx, events...); err != nil {
+				return err
+			}
+			if err := addPreorders(ctx, tx, cart.PreorderBookIDs, -1); err != nil {
+				return err
+			}
+			_, err = tx.NewDelete().Model(&cart).Where("user_id = ?", cart.UserID).Exec(ctx)
 			if err != nil {
 				return fmt.Errorf("failed to delete cart: %w", err)
 			}
 		}
+		released = len(expiredCarts)
 
 		return nil
 	}, r.db)
 	if err != nil {
-		return fmt.Errorf("failed to clean expired carts: %w", err)
+		return 0, fmt.Errorf("failed to clean expired carts: %w", err)
 	}
 
-	return nil
+	return released, nil
+}
+
+func stockChangedEvents(books []models.Book, delta int) ([]domain.Event, error) {
+	events := make([]domain.Event, 0, len(books))
+	for _, book := range books {
+		event, err := domain.NewEvent(domain.NewEventData{
+			Type:          domain.EventBookStockChanged,
+			AggregateType: domain.AggregateBook,
+			AggregateID:   book.ID,
+			Payload: domain.StockChangedPayload{
+				BookID: book.ID,
+				Stock:  book.Stock,
+				Delta:  delta,
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stock changed event: %w", err)
+		}
+		events = append(events, event)
+
+		if delta < 0 && book.Stock == 0 {
+			event, err := domain.NewEvent(domain.NewEventData{
+				Type:          domain.EventBookOutOfStock,
+				AggregateType: domain.AggregateBook,
+				AggregateID:   book.ID,
+				Payload:       domain.OutOfStockPayload{BookID: book.ID},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create out of stock event: %w", err)
+			}
+			events = append(events, event)
+		}
+
+		if backInStock(book, delta) {
+			event, err := domain.NewEvent(domain.NewEventData{
+				Type:          domain.EventBookBackInStock,
+				AggregateType: domain.AggregateBook,
+				AggregateID:   book.ID,
+				Payload:       domain.BackInStockPayload{BookID: book.ID, Stock: book.Stock},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create back in stock event: %w", err)
+			}
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
 }
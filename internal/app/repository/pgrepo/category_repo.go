@@ -5,11 +5,14 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
+	"github.com/northwindman/book-shop/internal/app/common/slugerrors"
 	"github.com/northwindman/book-shop/internal/app/domain"
 	"github.com/northwindman/book-shop/internal/app/repository/models"
 	"github.com/northwindman/book-shop/internal/pkg/pg"
+	"github.com/uptrace/bun"
 )
 
 type CategoryRepo struct {
@@ -44,12 +47,16 @@ func (r CategoryRepo) GetCategory(ctx context.Context, id int) (domain.Category,
 	return domainCategory, nil
 }
 
+// CreateCategory creates a category under its parent, which must exist
 func (r CategoryRepo) CreateCategory(ctx context.Context, category domain.Category) (domain.Category, error) {
 	dbCategory := domainToCategory(category)
 
 	var insertedCategory models.Category
 	err := r.db.NewInsert().Model(&dbCategory).Returning("*").Scan(ctx, &insertedCategory)
 	if err != nil {
+		if pg.IsForeignKeyViolation(err) {
+			return domain.Category{}, unknownCategoryError(category.ParentID())
+		}
 		return domain.Category{}, fmt.Errorf("failed to insert a category: %w", err)
 	}
 
@@ -62,18 +69,65 @@ func (r CategoryRepo) CreateCategory(ctx context.Context, category domain.Catego
 
 }
 
-func (r CategoryRepo) UpdateCategory(ctx context.Context, category domain.Category) (domain.Category, error) {
+// UpdateCategory updates the columns of the given fields of a category if it is still at the version of the given
+// category, the version is incremented. A stale version is a precondition failure. A category cannot be moved under
+// itself or its descendants.
+func (r CategoryRepo) UpdateCategory(ctx context.Context, category domain.Category, fields []string) (domain.Category, error) {
+	columns := []string{"version", "updated_at"}
+	for _, field := range fields {
+		if field != "name" && field != "parent_id" {
+			return domain.Category{}, fmt.Errorf("%w: category field %q cannot be updated", domain.ErrInvalidFormat, field)
+		}
+		columns = append(columns, field)
+	}
+
 	dbCategory := domainToCategory(category)
 	dbCategory.UpdatedAt = time.Now()
+	dbCategory.Version = category.Version() + 1
 
 	var updatedCategory models.Category
-	err := r.db.NewUpdate().
-		Model(&dbCategory).
-		Where("id = ?", dbCategory.ID).
-		ExcludeColumn("created_at").
-		Returning("*").
-		Scan(ctx, &updatedCategory)
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		if category.ParentID() != 0 && slices.Contains(fields, "parent_id") {
+			// moves are serialized so that two concurrent moves cannot close a cycle together
+			_, err := tx.ExecContext(ctx, "LOCK TABLE categories IN SHARE ROW EXCLUSIVE MODE")
+			if err != nil {
+				return fmt.Errorf("failed to lock categories: %w", err)
+			}
+
+			var cycle bool
+			err = tx.NewRaw("SELECT ? IN ("+categoryAncestorsQuery+")", category.ID(), category.ParentID()).Scan(ctx, &cycle)
+			if err != nil {
+				return fmt.Errorf("failed to check category ancestors: %w", err)
+			}
+			if cycle {
+				return slugerrors.NewConflictError(fmt.Sprintf("category %d cannot be moved under its descendant %d",
+					category.ID(), category.ParentID()), "category-cycle")
+			}
+		}
+
+		err := tx.NewUpdate().
+			Model(&dbCategory).
+			Column(columns...).
+			Where("id = ?", dbCategory.ID).
+			Where("version = ?", category.Version()).
+			Returning("*").
+			Scan(ctx, &updatedCategory)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return r.missingOrStale(ctx, category.ID())
+			}
+			if pg.IsForeignKeyViolation(err) {
+				return unknownCategoryError(category.ParentID())
+			}
+			return fmt.Errorf("failed to update a category: %w", err)
+		}
+
+		return nil
+	}, r.db)
 	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Category{}, err
+		}
 		return domain.Category{}, fmt.Errorf("failed to update a category: %w", err)
 	}
 
@@ -85,19 +139,45 @@ func (r CategoryRepo) UpdateCategory(ctx context.Context, category domain.Catego
 	return domainCategory, nil
 }
 
-func (r CategoryRepo) DeleteCategory(ctx context.Context, id int) error {
+// DeleteCategory deletes a category if it is still at the given version, categories with books or subcategories
+// cannot be deleted
+func (r CategoryRepo) DeleteCategory(ctx context.Context, id, version int) error {
 	if id == 0 {
 		return fmt.Errorf("%w: id", domain.ErrRequired)
 	}
 
-	_, err := r.db.NewDelete().Model((*models.Category)(nil)).Where("id = ?", id).Exec(ctx)
+	res, err := r.db.NewDelete().Model((*models.Category)(nil)).Where("id = ?", id).Where("version = ?", version).Exec(ctx)
 	if err != nil {
+		if pg.IsForeignKeyViolation(err) {
+			return slugerrors.NewConflictError(fmt.Sprintf("category %d has books, subcategories or promotions", id), "category-in-use")
+		}
 		return fmt.Errorf("failed to delete a category: %w", err)
 	}
 
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get deleted categories: %w", err)
+	}
+	if deleted == 0 {
+		return r.missingOrStale(ctx, id)
+	}
+
 	return nil
 }
 
+// missingOrStale tells why a category could not be changed at a version
+func (r CategoryRepo) missingOrStale(ctx context.Context, id int) error {
+	exists, err := r.db.NewSelect().Model((*models.Category)(nil)).Where("id = ?", id).Exists(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check a category: %w", err)
+	}
+	if !exists {
+		return domain.ErrNotFound
+	}
+	return staleVersionError("category", id)
+}
+
+// GetCategories returns every category, see domain.CategoryTree to arrange them under their parents
 func (r CategoryRepo) GetCategories(ctx context.Context) ([]domain.Category, error) {
 	var categories []models.Category
 	err := r.db.NewSelect().Model(&categories).Order("id").Scan(ctx)
@@ -117,3 +197,73 @@ func (r CategoryRepo) GetCategories(ctx context.Context) ([]domain.Category, err
 
 	return domainCategories, nil
 }
+
+// categoryAncestorsQuery selects a category and its ancestors
+const categoryAncestorsQuery = `
+WITH RECURSIVE ancestors AS (
+    SELECT id, parent_id FROM categories WHERE id = ?
+    UNION
+    SELECT c.id, c.parent_id FROM categories c JOIN ancestors a ON c.id = a.parent_id
+)
+SELECT id FROM ancestors
+`
+
+// categoryDescendantsQuery selects categories and their descendants
+const categoryDescendantsQuery = `
+WITH RECURSIVE descendants AS (
+    SELECT id FROM categories WHERE id IN (?)
+    UNION
+    SELECT c.id FROM categories c JOIN descendants d ON c.parent_id = d.id
+)
+SELECT id FROM descendants
+`
+
+func unknownCategoryError(id int) error {
+	return slugerrors.NewBadRequestError(fmt.Sprintf("category %d does not exist", id), "unknown-category")
+}
+
+// setBookCategories replaces the categories of a book, the first is its primary category
+func setBookCategories(ctx context.Context, db bun.IDB, bookID int, categoryIDs []int) error {
+	_, err := db.NewDelete().Model((*models.BookCategory)(nil)).Where("book_id = ?", bookID).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete book categories: %w", err)
+	}
+
+	bookCategories := make([]models.BookCategory, len(categoryIDs))
+	for i, categoryID := range categoryIDs {
+		bookCategories[i] = models.BookCategory{BookID: bookID, CategoryID: categoryID, Position: i}
+	}
+	_, err = db.NewInsert().Model(&bookCategories).Exec(ctx)
+	if err != nil {
+		if pg.IsForeignKeyViolation(err) {
+			return slugerrors.NewBadRequestError(fmt.Sprintf("categories %v do not all exist", categoryIDs), "unknown-category")
+		}
+		return fmt.Errorf("failed to insert book categories: %w", err)
+	}
+
+	return nil
+}
+
+// getBookCategories returns the categories of books by book ID, their primary category first
+func getBookCategories(ctx context.Context, db bun.IDB, bookIDs []int) (map[int][]int, error) {
+	categoryIDs := make(map[int][]int)
+	if len(bookIDs) == 0 {
+		return categoryIDs, nil
+	}
+
+	var bookCategories []models.BookCategory
+	err := db.NewSelect().
+		Model(&bookCategories).
+		Where("book_id IN (?)", bun.In(bookIDs)).
+		Order("book_id", "position").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book categories: %w", err)
+	}
+
+	for _, bookCategory := range bookCategories {
+		categoryIDs[bookCategory.BookID] = append(categoryIDs[bookCategory.BookID], bookCategory.CategoryID)
+	}
+
+	return categoryIDs, nil
+}
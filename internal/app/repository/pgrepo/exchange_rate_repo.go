@@ -0,0 +1,120 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/app/repository/models"
+	"github.com/northwindman/book-shop/internal/pkg/pg"
+	"github.com/uptrace/bun"
+)
+
+type ExchangeRateRepo struct {
+	db *pg.DB
+}
+
+func NewExchangeRateRepo(db *pg.DB) *ExchangeRateRepo {
+	return &ExchangeRateRepo{
+		db: db,
+	}
+}
+
+// GetRate returns the exchange rate of a display currency
+func (r ExchangeRateRepo) GetRate(ctx context.Context, currency string) (domain.ExchangeRate, error) {
+	var rate models.ExchangeRate
+	err := r.db.NewSelect().Model(&rate).Where("currency = ?", currency).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ExchangeRate{}, domain.ErrNotFound
+		}
+		return domain.ExchangeRate{}, fmt.Errorf("failed to get an exchange rate: %w", err)
+	}
+
+	domainRate, err := exchangeRateToDomain(rate)
+	if err != nil {
+		return domain.ExchangeRate{}, fmt.Errorf("failed to create domain exchange rate: %w", err)
+	}
+
+	return domainRate, nil
+}
+
+// GetRates returns all exchange rates ordered by currency
+func (r ExchangeRateRepo) GetRates(ctx context.Context) ([]domain.ExchangeRate, error) {
+	var rates []models.ExchangeRate
+	err := r.db.NewSelect().Model(&rates).Order("currency").Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rates: %w", err)
+	}
+
+	domainRates := make([]domain.ExchangeRate, len(rates))
+	for i, rate := range rates {
+		domainRate, err := exchangeRateToDomain(rate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create domain exchange rate: %w", err)
+		}
+
+		domainRates[i] = domainRate
+	}
+
+	return domainRates, nil
+}
+
+// SetRates inserts or replaces exchange rates in a single transaction
+func (r ExchangeRateRepo) SetRates(ctx context.Context, rates ...domain.ExchangeRate) ([]domain.ExchangeRate, error) {
+	if len(rates) == 0 {
+		return nil, nil
+	}
+
+	dbRates := make([]models.ExchangeRate, len(rates))
+	for i, rate := range rates {
+		dbRates[i] = domainToExchangeRate(rate)
+		dbRates[i].UpdatedAt = time.Now()
+	}
+
+	var savedRates []models.ExchangeRate
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		return tx.NewInsert().Model(&dbRates).
+			On("CONFLICT (currency) DO UPDATE").
+			Set("rate = EXCLUDED.rate").
+			Set("updated_at = EXCLUDED.updated_at").
+			Returning("*").
+			Scan(ctx, &savedRates)
+	}, r.db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set exchange rates: %w", err)
+	}
+
+	domainRates := make([]domain.ExchangeRate, len(savedRates))
+	for i, rate := range savedRates {
+		domainRate, err := exchangeRateToDomain(rate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create domain exchange rate: %w", err)
+		}
+
+		domainRates[i] = domainRate
+	}
+
+	return domainRates, nil
+}
+
+// DeleteRate deletes the exchange rate of a display currency
+func (r ExchangeRateRepo) DeleteRate(ctx context.Context, currency string) error {
+	res, err := r.db.NewDelete().Model((*models.ExchangeRate)(nil)).Where("currency = ?", currency).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete an exchange rate: %w", err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get deleted exchange rates: %w", err)
+	}
+	if deleted == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package pgrepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/app/repository/models"
+	"github.com/northwindman/book-shop/internal/pkg/pg"
+)
+
+// idempotencyLockTimeout is after how long a request that never completed, e.g. because the server crashed,
+// is considered abandoned and a retry may take over its key
+const idempotencyLockTimeout = time.Minute
+
+type IdempotencyRepo struct {
+	db *pg.DB
+}
+
+func NewIdempotencyRepo(db *pg.DB) *IdempotencyRepo {
+	return &IdempotencyRepo{
+		db: db,
+	}
+}
+
+// Begin reserves the key of a request. It returns the stored response and true when the request was already completed,
+// ErrIdempotencyKeyReused when the key was used for another request and ErrIdempotencyKeyInProgress while
+// the first request with the key is still running.
+func (r IdempotencyRepo) Begin(ctx context.Context, request domain.IdempotentRequest) (domain.IdempotentResponse, bool, error) {
+	now := time.Now()
+	key := models.IdempotencyKey{
+		UserID:      request.UserID(),
+		Key:         request.Key(),
+		Fingerprint: request.Fingerprint(),
+		LockedAt:    now,
+	}
+
+	// take over abandoned reservations of the same request
+	res, err := r.db.NewInsert().Model(&key).
+		ExcludeColumn("created_at").
+		On("CONFLICT (user_id, key) DO UPDATE").
+		Set("locked_at = EXCLUDED.locked_at").
+		Where("idempotency_key.status_code IS NULL").
+		Where("idempotency_key.fingerprint = EXCLUDED.fingerprint").
+		Where("idempotency_key.locked_at < ?", now.Add(-idempotencyLockTimeout)).
+		Exec(ctx)
+	if err != nil {
+		return domain.IdempotentResponse{}, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	reserved, err := res.RowsAffected()
+	if err != nil {
+		return domain.IdempotentResponse{}, false, fmt.Errorf("failed to get reserved idempotency keys: %w", err)
+	}
+	if reserved > 0 {
+		return domain.IdempotentResponse{}, false, nil
+	}
+
+	var existing models.IdempotencyKey
+	err = r.db.NewSelect().Model(&existing).
+		Where("user_id = ?", request.UserID()).
+		Where("key = ?", request.Key()).
+		Scan(ctx)
+	if err != nil {
+		return domain.IdempotentResponse{}, false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	switch {
+	case existing.Fingerprint != request.Fingerprint():
+		return domain.IdempotentResponse{}, false, domain.ErrIdempotencyKeyReused
+	case existing.StatusCode == 0:
+		return domain.IdempotentResponse{}, false, domain.ErrIdempotencyKeyInProgress
+	}
+
+	return domain.IdempotentResponse{
+		StatusCode:  existing.StatusCode,
+		ContentType: existing.ContentType,
+		Body:        existing.Body,
+	}, true, nil
+}
+
+// Complete stores the response of a reserved request for replay
+func (r IdempotencyRepo) Complete(ctx context.Context, request domain.IdempotentRequest, response domain.IdempotentResponse) error {
+	_, err := r.db.NewUpdate().Model((*models.IdempotencyKey)(nil)).
+		Set("status_code = ?", response.StatusCode).
+		Set("content_type = ?", response.ContentType).
+		Set("body = ?", response.Body).
+		Where("user_id = ?", request.UserID()).
+		Where("key = ?", request.Key()).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// Release frees the key of a reserved request that failed, so it can be retried
+func (r IdempotencyRepo) Release(ctx context.Context, request domain.IdempotentRequest) error {
+	_, err := r.db.NewDelete().Model((*models.IdempotencyKey)(nil)).
+		Where("user_id = ?", request.UserID()).
+		Where("key = ?", request.Key()).
+		Where("status_code IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired deletes the keys older than ttl and returns how many were deleted
+func (r IdempotencyRepo) DeleteExpired(ctx context.Context, ttl time.Duration) (int, error) {
+	res, err := r.db.NewDelete().Model((*models.IdempotencyKey)(nil)).
+		Where("created_at < ?", time.Now().Add(-ttl)).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get deleted idempotency keys: %w", err)
+	}
+
+	return int(deleted), nil
+}
@@ -0,0 +1,479 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/northwindman/book-shop/internal/app/common/slugerrors"
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/app/repository/models"
+	"github.com/northwindman/book-shop/internal/pkg/pg"
+	"github.com/uptrace/bun"
+)
+
+type InventoryRepo struct {
+	db *pg.DB
+}
+
+func NewInventoryRepo(db *pg.DB) *InventoryRepo {
+	return &InventoryRepo{
+		db: db,
+	}
+}
+
+// AdjustStock applies a stock adjustment to its book and records it in the ledger, it fails with a bad request
+// when the stock would become negative
+func (r InventoryRepo) AdjustStock(ctx context.Context, movement domain.InventoryMovement) (domain.InventoryMovement, error) {
+	var recorded models.InventoryMovement
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		var book models.Book
+		err := tx.NewSelect().Model(&book).Column("id", "stock").Where("id = ?", movement.BookID()).For("UPDATE").Scan(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrNotFound
+			}
+			return fmt.Errorf("failed to lock stock: %w", err)
+		}
+
+		applied, err := movement.Apply(book.Stock)
+		if err != nil {
+			return slugerrors.NewBadRequestError(err.Error(), "insufficient-stock")
+		}
+
+		err = tx.NewUpdate().Model((*models.Book)(nil)).Set("stock = ?", applied.StockAfter()).Where("id = ?", book.ID).Returning("id, stock").Scan(ctx, &book)
+		if err != nil {
+			return fmt.Errorf("failed to adjust stock: %w", err)
+		}
+
+		recorded = domainToInventoryMovement(applied)
+		err = tx.NewInsert().Model(&recorded).Returning("id, created_at").Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to insert inventory movement: %w", err)
+		}
+
+		events, err := restockBooks(ctx, tx, []models.Book{book}, applied.Delta())
+		if err != nil {
+			return err
+		}
+
+		return insertEvents(ctx, tx, events...)
+	}, r.db)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.InventoryMovement{}, err
+		}
+		return domain.InventoryMovement{}, fmt.Errorf("failed to adjust stock: %w", err)
+	}
+
+	domainMovement, err := inventoryMovementToDomain(recorded)
+	if err != nil {
+		return domain.InventoryMovement{}, fmt.Errorf("failed to create domain inventory movement: %w", err)
+	}
+
+	return domainMovement, nil
+}
+
+// GetMovements returns the inventory movements of a book, newest first
+func (r InventoryRepo) GetMovements(ctx context.Context, bookID, limit, offset int) ([]domain.InventoryMovement, error) {
+	var movements []models.InventoryMovement
+	query := r.db.NewSelect().Model(&movements).Where("book_id = ?", bookID).Order("id DESC")
+	if limit > 0 {
+		query.Limit(limit)
+	}
+	if offset > 0 {
+		query.Offset(offset)
+	}
+	err := query.Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inventory movements: %w", err)
+	}
+
+	domainMovements := make([]domain.InventoryMovement, 0, len(movements))
+	for _, movement := range movements {
+		domainMovement, err := inventoryMovementToDomain(movement)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create domain inventory movement: %w", err)
+		}
+		domainMovements = append(domainMovements, domainMovement)
+	}
+
+	return domainMovements, nil
+}
+
+// stockCountsQuery counts the stock every book should have: the stock received since its ledger was opened, less the
+// books sold by orders placed since then and the books reserved by carts. Pre-ordered books count as sold once they
+// are fulfilled from stock since the ledger was opened, whenever they were ordered: books created without stock have
+// no ledger until their first restock, their pre-orders are older.
+const stockCountsQuery = `
+SELECT b.id AS book_id, b.title, b.stock,
+       COALESCE(m.received, 0) AS received,
+       (SELECT count(*)
+        FROM order_items oi
+        JOIN orders o ON o.id = oi.order_id
+        WHERE oi.book_id = b.id AND o.status IN (?)
+          AND CASE WHEN oi.preorder THEN oi.fulfilled_at >= m.opened_at ELSE o.created_at >= m.opened_at END) AS sold,
+       (SELECT count(*) FROM carts c WHERE b.id = ANY (c.book_ids) AND NOT b.id = ANY (c.preorder_book_ids)) AS reserved
+FROM books b
+LEFT JOIN (
+    SELECT book_id,
+           sum(delta) FILTER (WHERE type IN (?)) AS received,
+           min(created_at) AS opened_at
+    FROM inventory_movements
+    GROUP BY book_id
+) m ON m.book_id = b.id
+`
+
+type stockCountRow struct {
+	BookID   int
+	Title    string
+	Stock    int
+	Received int
+	Sold     int
+	Reserved int
+}
+
+// receivingMovements are the movements that bring stock in, reservations and sales are counted from carts and orders
+// and reconciliations only align the stock with the count
+var receivingMovements = []string{domain.MovementRestock, domain.MovementReturn, domain.MovementCorrection}
+
+func getStockCounts(ctx context.Context, db bun.IDB, bookID int) ([]domain.StockCount, error) {
+	query := stockCountsQuery
+	args := []any{bun.In(domain.OrderStatuses), bun.In(receivingMovements)}
+	if bookID > 0 {
+		query += "WHERE b.id = ?\n"
+		args = append(args, bookID)
+	}
+	query += "ORDER BY b.id"
+
+	var rows []stockCountRow
+	err := db.NewRaw(query, args...).Scan(ctx, &rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count stocks: %w", err)
+	}
+
+	counts := make([]domain.StockCount, len(rows))
+	for i, row := range rows {
+		counts[i] = domain.StockCount(row)
+	}
+
+	return counts, nil
+}
+
+// GetStockCounts returns the stock of every book with the stock it should have, counted in a single snapshot
+func (r InventoryRepo) GetStockCounts(ctx context.Context) ([]domain.StockCount, error) {
+	return getStockCounts(ctx, r.db, 0)
+}
+
+// RepairStock counts the stock of a book again with the book locked and records a reconciliation movement that
+// corrects any drift. It reports whether the stock was repaired, stock expected below zero cannot be repaired.
+func (r InventoryRepo) RepairStock(ctx context.Context, bookID int) (domain.InventoryMovement, bool, error) {
+	var recorded models.InventoryMovement
+	var repaired bool
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		var book models.Book
+		err := tx.NewSelect().Model(&book).Column("id").Where("id = ?", bookID).For("UPDATE").Scan(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrNotFound
+			}
+			return fmt.Errorf("failed to lock stock: %w", err)
+		}
+
+		counts, err := getStockCounts(ctx, tx, bookID)
+		if err != nil {
+			return err
+		}
+		if len(counts) == 0 || counts[0].Drift() == 0 {
+			return nil
+		}
+
+		repair, err := counts[0].Repair()
+		if err != nil {
+			return err
+		}
+
+		err = tx.NewUpdate().Model((*models.Book)(nil)).Set("stock = ?", repair.StockAfter()).Where("id = ?", bookID).Returning("id, stock").Scan(ctx, &book)
+		if err != nil {
+			return fmt.Errorf("failed to repair stock: %w", err)
+		}
+
+		recorded = domainToInventoryMovement(repair)
+		err = tx.NewInsert().Model(&recorded).Returning("id, created_at").Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to insert inventory movement: %w", err)
+		}
+		repaired = true
+
+		events, err := restockBooks(ctx, tx, []models.Book{book}, repair.Delta())
+		if err != nil {
+			return err
+		}
+
+		return insertEvents(ctx, tx, events...)
+	}, r.db)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.InventoryMovement{}, false, err
+		}
+		return domain.InventoryMovement{}, false, fmt.Errorf("failed to repair stock: %w", err)
+	}
+	if !repaired {
+		return domain.InventoryMovement{}, false, nil
+	}
+
+	domainMovement, err := inventoryMovementToDomain(recorded)
+	if err != nil {
+		return domain.InventoryMovement{}, false, fmt.Errorf("failed to create domain inventory movement: %w", err)
+	}
+
+	return domainMovement, true, nil
+}
+
+type lowStockRow struct {
+	BookID           int
+	Title            string
+	Stock            int
+	ReorderThreshold int
+	SoldRecently     int
+	AlertedAt        time.Time
+}
+
+// GetLowStock returns the books at or below their reorder threshold with the books sold since a time,
+// lowest stock first
+func (r InventoryRepo) GetLowStock(ctx context.Context, soldSince time.Time) ([]domain.LowStockItem, error) {
+	var rows []lowStockRow
+	err := r.db.NewRaw(`
+SELECT b.id AS book_id, b.title, b.stock, b.reorder_threshold,
+       (SELECT COALESCE(-sum(m.delta), 0)
+        FROM inventory_movements m
+        WHERE m.book_id = b.id AND m.type = ? AND m.created_at >= ?) AS sold_recently,
+       b.low_stock_alerted_at AS alerted_at
+FROM books b
+WHERE b.stock <= b.reorder_threshold
+ORDER BY b.stock, b.id`, domain.MovementSale, soldSince).Scan(ctx, &rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get low stock books: %w", err)
+	}
+
+	items := make([]domain.LowStockItem, len(rows))
+	for i, row := range rows {
+		items[i] = domain.LowStockItem(row)
+	}
+
+	return items, nil
+}
+
+// MarkLowStockAlerted records when the staff was alerted of a book being low on stock
+func (r InventoryRepo) MarkLowStockAlerted(ctx context.Context, bookID int, alertedAt time.Time) error {
+	_, err := r.db.NewUpdate().Model((*models.Book)(nil)).
+		Set("low_stock_alerted_at = ?", alertedAt).
+		Where("id = ?", bookID).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to mark low stock alerted: %w", err)
+	}
+
+	return nil
+}
+
+// ClearRecoveredLowStockAlerts forgets the low stock alerts of the books back above their reorder threshold,
+// so they are alerted again when their stock runs low again
+func (r InventoryRepo) ClearRecoveredLowStockAlerts(ctx context.Context) error {
+	_, err := r.db.NewUpdate().Model((*models.Book)(nil)).
+		Set("low_stock_alerted_at = NULL").
+		Where("low_stock_alerted_at IS NOT NULL").
+		Where("stock > reorder_threshold").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to clear recovered low stock alerts: %w", err)
+	}
+
+	return nil
+}
+
+// insertMovements records a movement of every book, the stock after it is the stock of the book.
+// The type, delta, reason, order and user are copied from movement.
+func insertMovements(ctx context.Context, db bun.IDB, books []models.Book, movement models.InventoryMovement) error {
+	if len(books) == 0 {
+		return nil
+	}
+
+	movements := make([]models.InventoryMovement, len(books))
+	for i, book := range books {
+		movements[i] = movement
+		movements[i].BookID = book.ID
+		movements[i].StockAfter = book.Stock
+	}
+
+	_, err := db.NewInsert().Model(&movements).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to insert inventory movements: %w", err)
+	}
+
+	return nil
+}
+
+// addPreorders changes the number of copies pre-ordered of books by delta
+func addPreorders(ctx context.Context, db bun.IDB, bookIDs []int, delta int) error {
+	if len(bookIDs) == 0 {
+		return nil
+	}
+
+	_, err := db.NewUpdate().Model((*models.Book)(nil)).Set("preorders = preorders + ?", delta).Where("id IN (?)", bun.In(bookIDs)).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update pre-orders: %w", err)
+	}
+
+	return nil
+}
+
+// restockBooks fulfils the pending pre-orders of books whose stock changed by delta, then queues back-in-stock
+// subscriptions and creates stock events from the stock left after the pre-orders, so the books pre-orders took
+// back are not announced as available.
+func restockBooks(ctx context.Context, db bun.IDB, books []models.Book, delta int) ([]domain.Event, error) {
+	if delta <= 0 || len(books) == 0 {
+		return stockChangedEvents(books, delta)
+	}
+
+	bookIDs := make([]int, 0, len(books))
+	for _, book := range books {
+		bookIDs = append(bookIDs, book.ID)
+	}
+
+	events, err := fulfilPreorders(ctx, db, bookIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var left []models.Book
+	err = db.NewSelect().Model(&left).Column("id", "stock").Where("id IN (?)", bun.In(bookIDs)).Order("id").Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock left: %w", err)
+	}
+
+	if err := queueBackInStock(ctx, db, left, delta); err != nil {
+		return nil, err
+	}
+	restockedEvents, err := stockChangedEvents(left, delta)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(events, restockedEvents...), nil
+}
+
+type pendingPreorderRow struct {
+	ID      int
+	OrderID int
+	BookID  int
+}
+
+// fulfilPreorders fulfils the pending pre-orders of books from their stock in the order they were placed, every
+// fulfilled book is sold to its order. Orders whose pre-ordered books are all fulfilled are completed.
+func fulfilPreorders(ctx context.Context, db bun.IDB, bookIDs []int) ([]domain.Event, error) {
+	if len(bookIDs) == 0 {
+		return nil, nil
+	}
+
+	var lockedIDs []int
+	err := db.NewSelect().Model((*models.Book)(nil)).Column("id").
+		Where("id IN (?) AND stock > 0 AND preorders > 0", bun.In(bookIDs)).
+		Order("id").For("UPDATE").
+		Scan(ctx, &lockedIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock pre-ordered books: %w", err)
+	}
+	if len(lockedIDs) == 0 {
+		return nil, nil
+	}
+
+	var pending []pendingPreorderRow
+	err = db.NewRaw(`
+SELECT id, order_id, book_id
+FROM (
+    SELECT oi.id, oi.order_id, oi.book_id, b.stock,
+           row_number() OVER (PARTITION BY oi.book_id ORDER BY o.created_at, oi.order_id, oi.id) AS position
+    FROM order_items oi
+    JOIN orders o ON o.id = oi.order_id
+    JOIN books b ON b.id = oi.book_id
+    WHERE oi.book_id IN (?) AND oi.preorder AND oi.fulfilled_at IS NULL
+) due
+WHERE position <= stock
+ORDER BY position, book_id`, bun.In(lockedIDs)).Scan(ctx, &pending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending pre-orders: %w", err)
+	}
+
+	var events []domain.Event
+	var orderIDs []int
+	for _, item := range pending {
+		_, err := db.NewUpdate().Model((*models.OrderItem)(nil)).Set("fulfilled_at = now()").Where("id = ?", item.ID).Exec(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fulfil pre-order: %w", err)
+		}
+
+		var book models.Book
+		err = db.NewUpdate().Model((*models.Book)(nil)).
+			Set("stock = stock - 1").
+			Set("preorders = preorders - 1").
+			Where("id = ?", item.BookID).
+			Returning("id, stock").
+			Scan(ctx, &book)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reduce stock: %w", err)
+		}
+
+		err = insertMovements(ctx, db, []models.Book{book}, models.InventoryMovement{
+			Type:    domain.MovementSale,
+			Delta:   -1,
+			Reason:  "pre-order fulfilled",
+			OrderID: item.OrderID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		soldEvents, err := stockChangedEvents([]models.Book{book}, -1)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, soldEvents...)
+
+		if !slices.Contains(orderIDs, item.OrderID) {
+			orderIDs = append(orderIDs, item.OrderID)
+		}
+	}
+	if len(orderIDs) == 0 {
+		return events, nil
+	}
+
+	var released []models.Order
+	err = db.NewUpdate().Model((*models.Order)(nil)).
+		Set("status = ?", domain.OrderStatusCompleted).
+		Set("updated_at = now()").
+		Where("id IN (?) AND status = ?", bun.In(orderIDs), domain.OrderStatusAwaitingRelease).
+		Where("NOT EXISTS (SELECT 1 FROM order_items oi WHERE oi.order_id = \"order\".id AND oi.preorder AND oi.fulfilled_at IS NULL)").
+		Returning("id, user_id").
+		Scan(ctx, &released)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release orders: %w", err)
+	}
+
+	for _, order := range released {
+		event, err := domain.NewEvent(domain.NewEventData{
+			Type:          domain.EventOrderReleased,
+			AggregateType: domain.AggregateUser,
+			AggregateID:   order.UserID,
+			Payload:       domain.OrderReleasedPayload{UserID: order.UserID, OrderID: order.ID},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create order released event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
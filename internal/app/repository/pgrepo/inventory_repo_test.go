@@ -0,0 +1,90 @@
+package pgrepo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/app/repository/models"
+)
+
+func TestInventoryRepo_GetStockCounts_PreorderBeforeFirstRestock(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	book := createTestBook(t, db, "Announced", 0)
+
+	// the book is pre-ordered while it has no stock and so no ledger yet
+	order := models.Order{
+		UserID:         createTestUser(t, db),
+		Status:         domain.OrderStatusAwaitingRelease,
+		SubtotalAmount: 1000,
+		TotalAmount:    1000,
+		Currency:       domain.SettlementCurrency,
+	}
+	err := db.NewInsert().Model(&order).Returning("id").Scan(ctx)
+	require.NoError(t, err)
+	item := models.OrderItem{
+		OrderID:       order.ID,
+		BookID:        book.ID(),
+		Title:         book.Title(),
+		PriceAmount:   1000,
+		PriceCurrency: domain.SettlementCurrency,
+		Preorder:      true,
+	}
+	_, err = db.NewInsert().Model(&item).Exec(ctx)
+	require.NoError(t, err)
+	_, err = db.NewUpdate().Model((*models.Book)(nil)).Set("preorders = 1").Where("id = ?", book.ID()).Exec(ctx)
+	require.NoError(t, err)
+
+	// the first restock opens the ledger and fulfils the pre-order
+	restock, err := domain.NewStockAdjustment(book.ID(), domain.MovementRestock, 3, "", 0, 0)
+	require.NoError(t, err)
+	_, err = NewInventoryRepo(db).AdjustStock(ctx, restock)
+	require.NoError(t, err)
+
+	counts, err := getStockCounts(ctx, db, book.ID())
+	require.NoError(t, err)
+	require.Len(t, counts, 1)
+	require.Equal(t, 2, counts[0].Stock)
+	require.Equal(t, 1, counts[0].Sold)
+	require.Zero(t, counts[0].Drift())
+}
+
+func TestInventoryRepo_LowStockAlerts(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	repo := NewInventoryRepo(db)
+
+	book := createTestBook(t, db, "Sold Out", 0)
+
+	alertedAt := func() time.Time {
+		items, err := repo.GetLowStock(ctx, time.Now().Add(-domain.SalesVelocityWindow))
+		require.NoError(t, err)
+		for _, item := range items {
+			if item.BookID == book.ID() {
+				return item.AlertedAt
+			}
+		}
+		t.Fatalf("book %d is not low on stock", book.ID())
+		return time.Time{}
+	}
+	require.True(t, alertedAt().IsZero())
+
+	now := time.Now()
+	require.NoError(t, repo.MarkLowStockAlerted(ctx, book.ID(), now))
+	require.WithinDuration(t, now, alertedAt(), time.Millisecond)
+
+	// the alert is kept while the book is low on stock and forgotten once it recovers
+	require.NoError(t, repo.ClearRecoveredLowStockAlerts(ctx))
+	require.False(t, alertedAt().IsZero())
+	_, err := db.NewUpdate().Model((*models.Book)(nil)).Set("stock = 1").Where("id = ?", book.ID()).Exec(ctx)
+	require.NoError(t, err)
+	require.NoError(t, repo.ClearRecoveredLowStockAlerts(ctx))
+	_, err = db.NewUpdate().Model((*models.Book)(nil)).Set("stock = 0").Where("id = ?", book.ID()).Exec(ctx)
+	require.NoError(t, err)
+	require.True(t, alertedAt().IsZero())
+}
@@ -0,0 +1,54 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/northwindman/book-shop/internal/app/repository/models"
+	"github.com/northwindman/book-shop/internal/pkg/pg"
+)
+
+// JobRunRepo remembers when background jobs last ran on any instance
+type JobRunRepo struct {
+	db *pg.DB
+}
+
+func NewJobRunRepo(db *pg.DB) *JobRunRepo {
+	return &JobRunRepo{
+		db: db,
+	}
+}
+
+// LastRun returns when the job last ran, or the zero time when it never ran
+func (r JobRunRepo) LastRun(ctx context.Context, name string) (time.Time, error) {
+	var run models.JobRun
+	err := r.db.NewSelect().Model(&run).Where("name = ?", name).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("failed to select last run of job %s: %w", name, err)
+	}
+
+	return run.LastRunAt, nil
+}
+
+// RecordRun records that the job ran at the given time
+func (r JobRunRepo) RecordRun(ctx context.Context, name string, at time.Time) error {
+	run := models.JobRun{
+		Name:      name,
+		LastRunAt: at,
+	}
+	_, err := r.db.NewInsert().Model(&run).
+		On("CONFLICT (name) DO UPDATE").
+		Set("last_run_at = EXCLUDED.last_run_at").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to record run of job %s: %w", name, err)
+	}
+
+	return nil
+}
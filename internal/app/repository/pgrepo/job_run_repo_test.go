@@ -0,0 +1,29 @@
+package pgrepo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobRunRepo_RecordRun(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	repo := NewJobRunRepo(db)
+	name := uniqueName("job")
+
+	lastRunAt, err := repo.LastRun(ctx, name)
+	require.NoError(t, err)
+	require.True(t, lastRunAt.IsZero())
+
+	first := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+	require.NoError(t, repo.RecordRun(ctx, name, first))
+	second := first.Add(time.Minute)
+	require.NoError(t, repo.RecordRun(ctx, name, second))
+
+	lastRunAt, err = repo.LastRun(ctx, name)
+	require.NoError(t, err)
+	require.True(t, second.Equal(lastRunAt))
+}
@@ -0,0 +1,80 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/app/repository/models"
+	"github.com/northwindman/book-shop/internal/pkg/pg"
+	"github.com/uptrace/bun"
+)
+
+type OrderRepo struct {
+	db *pg.DB
+}
+
+func NewOrderRepo(db *pg.DB) *OrderRepo {
+	return &OrderRepo{
+		db: db,
+	}
+}
+
+// GetOrder returns an order with its items
+func (r OrderRepo) GetOrder(ctx context.Context, id int) (domain.Order, error) {
+	if id == 0 {
+		return domain.Order{}, fmt.Errorf("%w: id", domain.ErrRequired)
+	}
+
+	var order models.Order
+	err := r.db.NewSelect().Model(&order).Relation("Items", orderByID).Relation("Discounts", orderByID).Relation("Taxes", orderByID).Where("?TableAlias.id = ?", id).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Order{}, domain.ErrNotFound
+		}
+		return domain.Order{}, fmt.Errorf("failed to get an order: %w", err)
+	}
+
+	domainOrder, err := orderToDomain(order)
+	if err != nil {
+		return domain.Order{}, fmt.Errorf("failed to create domain order: %w", err)
+	}
+
+	return domainOrder, nil
+}
+
+// GetOrders returns the orders of a user, newest first
+func (r OrderRepo) GetOrders(ctx context.Context, userID int, limit, offset int) ([]domain.Order, error) {
+	var orders []models.Order
+	query := r.db.NewSelect().Model(&orders).Relation("Items", orderByID).Relation("Discounts", orderByID).Relation("Taxes", orderByID).Where("?TableAlias.user_id = ?", userID)
+	if limit > 0 {
+		query.Limit(limit)
+	}
+	if offset > 0 {
+		query.Offset(offset)
+	}
+	query.Order("id DESC")
+	err := query.Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orders: %w", err)
+	}
+
+	domainOrders := make([]domain.Order, len(orders))
+	for i, order := range orders {
+		domainOrder, err := orderToDomain(order)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create domain order: %w", err)
+		}
+
+		domainOrders[i] = domainOrder
+	}
+
+	return domainOrders, nil
+}
+
+// orderByID orders the items and discounts of an order the way they were placed
+func orderByID(query *bun.SelectQuery) *bun.SelectQuery {
+	return query.Order("id")
+}
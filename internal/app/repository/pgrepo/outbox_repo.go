@@ -0,0 +1,140 @@
+package pgrepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/app/repository/models"
+	"github.com/northwindman/book-shop/internal/pkg/pg"
+	"github.com/uptrace/bun"
+)
+
+type OutboxRepo struct {
+	db *pg.DB
+}
+
+func NewOutboxRepo(db *pg.DB) *OutboxRepo {
+	return &OutboxRepo{
+		db: db,
+	}
+}
+
+type outboxDeliveryRow struct {
+	models.OutboxEvent `bun:",extend"`
+	Attempts           int
+}
+
+// GetDueDeliveries returns up to limit unpublished events a sink has neither received nor given up on and whose next
+// attempt is due, oldest first. The relay runs exclusively, so the events are not locked.
+func (r OutboxRepo) GetDueDeliveries(ctx context.Context, sink string, limit int) ([]domain.OutboxDelivery, error) {
+	var rows []outboxDeliveryRow
+	err := r.db.NewSelect().
+		TableExpr("outbox AS o").
+		ColumnExpr("o.*, COALESCE(d.attempts, 0) AS attempts").
+		Join("LEFT JOIN outbox_deliveries AS d ON d.event_id = o.id AND d.sink = ?", sink).
+		Where("o.published_at IS NULL").
+		Where("d.delivered_at IS NULL AND d.dead_at IS NULL").
+		Where("d.next_attempt_at IS NULL OR d.next_attempt_at <= now()").
+		OrderExpr("o.id").
+		Limit(limit).
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select outbox deliveries: %w", err)
+	}
+
+	deliveries := make([]domain.OutboxDelivery, len(rows))
+	for i, row := range rows {
+		event, err := outboxEventToDomain(row.OutboxEvent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create domain event: %w", err)
+		}
+		deliveries[i] = domain.OutboxDelivery{Event: event, Sink: sink, Attempts: row.Attempts}
+	}
+
+	return deliveries, nil
+}
+
+// RecordDeliveries stores the outcome of the latest attempt of deliveries
+func (r OutboxRepo) RecordDeliveries(ctx context.Context, deliveries []domain.OutboxDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	dbDeliveries := make([]models.OutboxDelivery, len(deliveries))
+	for i, delivery := range deliveries {
+		dbDeliveries[i] = models.OutboxDelivery{
+			EventID:       delivery.Event.ID(),
+			Sink:          delivery.Sink,
+			Attempts:      delivery.Attempts,
+			LastError:     delivery.LastError,
+			NextAttemptAt: delivery.NextAttemptAt,
+			DeliveredAt:   delivery.DeliveredAt,
+			DeadAt:        delivery.DeadAt,
+		}
+	}
+
+	_, err := r.db.NewInsert().Model(&dbDeliveries).
+		On("CONFLICT (event_id, sink) DO UPDATE").
+		Set("attempts = EXCLUDED.attempts").
+		Set("last_error = EXCLUDED.last_error").
+		Set("next_attempt_at = EXCLUDED.next_attempt_at").
+		Set("delivered_at = EXCLUDED.delivered_at").
+		Set("dead_at = EXCLUDED.dead_at").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to record outbox deliveries: %w", err)
+	}
+
+	return nil
+}
+
+// MarkPublished marks published the events every sink received or gave up on and reports how many were marked
+func (r OutboxRepo) MarkPublished(ctx context.Context, sinks []string) (int, error) {
+	if len(sinks) == 0 {
+		return 0, nil
+	}
+
+	res, err := r.db.NewUpdate().
+		Model((*models.OutboxEvent)(nil)).
+		Set("published_at = ?", time.Now()).
+		Where("published_at IS NULL").
+		Where("(SELECT count(*) FROM outbox_deliveries AS d WHERE d.event_id = outbox_event.id AND d.sink IN (?) "+
+			"AND (d.delivered_at IS NOT NULL OR d.dead_at IS NOT NULL)) = ?", bun.In(sinks), len(sinks)).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark outbox events as published: %w", err)
+	}
+
+	published, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get published outbox events: %w", err)
+	}
+
+	return int(published), nil
+}
+
+// Publish adds events to the outbox
+func (r OutboxRepo) Publish(ctx context.Context, events ...domain.Event) error {
+	return insertEvents(ctx, r.db, events...)
+}
+
+// insertEvents writes events to the outbox, it must be called inside the transaction making the change
+func insertEvents(ctx context.Context, db bun.IDB, events ...domain.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	dbEvents := make([]models.OutboxEvent, len(events))
+	for i, event := range events {
+		dbEvents[i] = domainToOutboxEvent(event)
+	}
+
+	_, err := db.NewInsert().Model(&dbEvents).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to insert outbox events: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package pgrepo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/app/repository/models"
+)
+
+func TestOutboxRepo_Deliveries(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	repo := NewOutboxRepo(db)
+	healthy, failing := uniqueName("healthy"), uniqueName("failing")
+	aggregateID := int(time.Now().UnixNano() % 1_000_000_000)
+
+	event, err := domain.NewEvent(domain.NewEventData{
+		Type:          domain.EventBookCreated,
+		AggregateType: domain.AggregateBook,
+		AggregateID:   aggregateID,
+		Payload:       domain.BookCreatedPayload{BookID: aggregateID},
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.Publish(ctx, event))
+
+	due := func(sink string) *domain.OutboxDelivery {
+		deliveries, err := repo.GetDueDeliveries(ctx, sink, 1000)
+		require.NoError(t, err)
+		for _, delivery := range deliveries {
+			if delivery.Event.AggregateID() == aggregateID {
+				latest := delivery
+				return &latest
+			}
+		}
+		return nil
+	}
+	delivery := due(healthy)
+	require.NotNil(t, delivery)
+	require.Zero(t, delivery.Attempts)
+
+	now := time.Now()
+	require.NoError(t, repo.RecordDeliveries(ctx, []domain.OutboxDelivery{
+		delivery.WithAttempt(nil, now, time.Time{}),
+		domain.OutboxDelivery{Event: delivery.Event, Sink: failing}.WithAttempt(errors.New("sink unavailable"), now, now.Add(time.Hour)),
+	}))
+
+	// the event is published once every sink is done with it
+	require.Nil(t, due(healthy))
+	published, err := repo.MarkPublished(ctx, []string{healthy, failing})
+	require.NoError(t, err)
+	require.Zero(t, published)
+
+	require.NoError(t, repo.RecordDeliveries(ctx, []domain.OutboxDelivery{
+		domain.OutboxDelivery{Event: delivery.Event, Sink: failing, Attempts: 1}.WithAttempt(errors.New("sink unavailable"), now, time.Time{}),
+	}))
+	published, err = repo.MarkPublished(ctx, []string{healthy, failing})
+	require.NoError(t, err)
+	require.Equal(t, 1, published)
+
+	var stored models.OutboxDelivery
+	err = db.NewSelect().Model(&stored).Where("event_id = ? AND sink = ?", delivery.Event.ID(), failing).Scan(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, stored.Attempts)
+	require.False(t, stored.DeadAt.IsZero())
+}
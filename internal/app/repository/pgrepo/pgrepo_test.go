@@ -0,0 +1,88 @@
+package pgrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/stretchr/testify/require"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/pkg/pg"
+)
+
+// testDB connects to the database of TEST_DSN with every migration applied, tests of the repositories are skipped
+// without one. Tests share the database, so they create their own rows and don't count rows they didn't create.
+func testDB(t *testing.T) *pg.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DSN is not set")
+	}
+
+	m, err := migrate.New("file://../../migrations", dsn)
+	require.NoError(t, err)
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		require.NoError(t, err)
+	}
+	_, _ = m.Close()
+
+	db, err := pg.Dial(dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	return db
+}
+
+// uniqueName returns a name no other test run uses
+func uniqueName(prefix string) string {
+	return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
+}
+
+// createTestBook creates a book with the given stock in a category of its own
+func createTestBook(t *testing.T, db *pg.DB, title string, stock int) domain.Book {
+	t.Helper()
+	ctx := context.Background()
+
+	category, err := domain.NewCategory(domain.NewCategoryData{Name: uniqueName(title)})
+	require.NoError(t, err)
+	category, err = NewCategoryRepo(db).CreateCategory(ctx, category)
+	require.NoError(t, err)
+
+	price, err := domain.NewMoney(1000, domain.SettlementCurrency)
+	require.NoError(t, err)
+	book, err := domain.NewBook(domain.NewBookData{
+		Title:      title,
+		Year:       2026,
+		Author:     "Ann Author",
+		Price:      price,
+		Stock:      stock,
+		CategoryID: category.ID(),
+	})
+	require.NoError(t, err)
+	book, err = NewBookRepo(db).CreateBook(ctx, book)
+	require.NoError(t, err)
+
+	return book
+}
+
+// createTestUser creates a user and returns its ID
+func createTestUser(t *testing.T, db *pg.DB) int {
+	t.Helper()
+
+	var userID int
+	err := db.NewRaw("INSERT INTO users (username, password) VALUES (?, 'x') RETURNING id", uniqueName("reader")).
+		Scan(context.Background(), &userID)
+	require.NoError(t, err)
+
+	return userID
+}
@@ -0,0 +1,168 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/northwindman/book-shop/internal/app/common/slugerrors"
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/app/repository/models"
+	"github.com/northwindman/book-shop/internal/pkg/pg"
+	"github.com/uptrace/bun"
+)
+
+type PromotionRepo struct {
+	db *pg.DB
+}
+
+func NewPromotionRepo(db *pg.DB) *PromotionRepo {
+	return &PromotionRepo{
+		db: db,
+	}
+}
+
+func (r PromotionRepo) GetPromotion(ctx context.Context, id int) (domain.Promotion, error) {
+	if id == 0 {
+		return domain.Promotion{}, fmt.Errorf("%w: id", domain.ErrRequired)
+	}
+
+	return r.getPromotion(ctx, r.db.NewSelect().Where("id = ?", id))
+}
+
+// GetPromotionByCode returns the promotion of a coupon code
+func (r PromotionRepo) GetPromotionByCode(ctx context.Context, code string) (domain.Promotion, error) {
+	code = domain.NormalizeCouponCode(code)
+	if code == "" {
+		return domain.Promotion{}, fmt.Errorf("%w: code", domain.ErrRequired)
+	}
+
+	return r.getPromotion(ctx, r.db.NewSelect().Where("code = ?", code))
+}
+
+func (r PromotionRepo) getPromotion(ctx context.Context, query *bun.SelectQuery) (domain.Promotion, error) {
+	var promotion models.Promotion
+	err := query.Model(&promotion).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Promotion{}, domain.ErrNotFound
+		}
+		return domain.Promotion{}, fmt.Errorf("failed to get a promotion: %w", err)
+	}
+
+	domainPromotion, err := promotionToDomain(promotion)
+	if err != nil {
+		return domain.Promotion{}, fmt.Errorf("failed to create domain promotion: %w", err)
+	}
+
+	return domainPromotion, nil
+}
+
+// GetPromotions returns all promotions, automaticOnly limits them to the active promotions without a coupon code
+func (r PromotionRepo) GetPromotions(ctx context.Context, automaticOnly bool) ([]domain.Promotion, error) {
+	var promotions []models.Promotion
+	query := r.db.NewSelect().Model(&promotions)
+	if automaticOnly {
+		query.Where("code IS NULL").Where("active")
+	}
+	err := query.Order("id").Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get promotions: %w", err)
+	}
+
+	domainPromotions := make([]domain.Promotion, len(promotions))
+	for i, promotion := range promotions {
+		domainPromotion, err := promotionToDomain(promotion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create domain promotion: %w", err)
+		}
+
+		domainPromotions[i] = domainPromotion
+	}
+
+	return domainPromotions, nil
+}
+
+func (r PromotionRepo) CreatePromotion(ctx context.Context, promotion domain.Promotion) (domain.Promotion, error) {
+	dbPromotion := domainToPromotion(promotion)
+
+	var insertedPromotion models.Promotion
+	err := r.db.NewInsert().Model(&dbPromotion).ExcludeColumn("id").Returning("*").Scan(ctx, &insertedPromotion)
+	if err != nil {
+		if pg.IsUniqueViolation(err) {
+			return domain.Promotion{}, slugerrors.NewBadRequestError("coupon code is already used", "duplicate-coupon-code")
+		}
+		return domain.Promotion{}, fmt.Errorf("failed to insert a promotion: %w", err)
+	}
+
+	domainPromotion, err := promotionToDomain(insertedPromotion)
+	if err != nil {
+		return domain.Promotion{}, fmt.Errorf("failed to create domain promotion: %w", err)
+	}
+
+	return domainPromotion, nil
+}
+
+func (r PromotionRepo) UpdatePromotion(ctx context.Context, promotion domain.Promotion) (domain.Promotion, error) {
+	dbPromotion := domainToPromotion(promotion)
+	dbPromotion.UpdatedAt = time.Now()
+
+	var updatedPromotion models.Promotion
+	err := r.db.NewUpdate().
+		Model(&dbPromotion).
+		Where("id = ?", dbPromotion.ID).
+		ExcludeColumn("created_at").
+		Returning("*").
+		Scan(ctx, &updatedPromotion)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Promotion{}, domain.ErrNotFound
+		}
+		if pg.IsUniqueViolation(err) {
+			return domain.Promotion{}, slugerrors.NewBadRequestError("coupon code is already used", "duplicate-coupon-code")
+		}
+		return domain.Promotion{}, fmt.Errorf("failed to update a promotion: %w", err)
+	}
+
+	domainPromotion, err := promotionToDomain(updatedPromotion)
+	if err != nil {
+		return domain.Promotion{}, fmt.Errorf("failed to create domain promotion: %w", err)
+	}
+
+	return domainPromotion, nil
+}
+
+func (r PromotionRepo) DeletePromotion(ctx context.Context, id int) error {
+	if id == 0 {
+		return fmt.Errorf("%w: id", domain.ErrRequired)
+	}
+
+	_, err := r.db.NewDelete().Model((*models.Promotion)(nil)).Where("id = ?", id).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete a promotion: %w", err)
+	}
+
+	return nil
+}
+
+// GetUsage returns the number of orders a promotion was used in, in total and by a user
+func (r PromotionRepo) GetUsage(ctx context.Context, promotionID, userID int) (domain.PromotionUsage, error) {
+	return promotionUsage(ctx, r.db, promotionID, userID)
+}
+
+func promotionUsage(ctx context.Context, db bun.IDB, promotionID, userID int) (domain.PromotionUsage, error) {
+	var usage domain.PromotionUsage
+	err := db.NewSelect().
+		Model((*models.OrderDiscount)(nil)).
+		ColumnExpr("count(*)").
+		ColumnExpr("count(*) FILTER (WHERE user_id = ?)", userID).
+		Where("promotion_id = ?", promotionID).
+		Scan(ctx, &usage.Total, &usage.ByUser)
+	if err != nil {
+		return domain.PromotionUsage{}, fmt.Errorf("failed to count promotion usage: %w", err)
+	}
+
+	return usage, nil
+}
@@ -0,0 +1,172 @@
+package pgrepo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/app/repository/models"
+	"github.com/northwindman/book-shop/internal/pkg/pg"
+	"github.com/uptrace/bun"
+)
+
+// recommendationsPerBook is the number of recommendations kept per book, more than are served so that books out of
+// stock or in the cart can be skipped
+const recommendationsPerBook = 50
+
+type RecommendationRepo struct {
+	db *pg.DB
+}
+
+func NewRecommendationRepo(db *pg.DB) *RecommendationRepo {
+	return &RecommendationRepo{
+		db: db,
+	}
+}
+
+// computeRecommendationsQuery scores every pair of books bought by the same customers with the cosine similarity of
+// their customers, and keeps the best pairs of every book
+const computeRecommendationsQuery = `
+WITH purchases AS (
+    SELECT DISTINCT o.user_id, oi.book_id
+    FROM order_items oi
+    JOIN orders o ON o.id = oi.order_id
+    WHERE o.status IN (?)
+),
+book_customers AS (
+    SELECT book_id, count(*) AS customers FROM purchases GROUP BY book_id
+),
+pairs AS (
+    SELECT a.book_id, b.book_id AS recommended_book_id, count(*) AS customers
+    FROM purchases a
+    JOIN purchases b ON b.user_id = a.user_id AND b.book_id <> a.book_id
+    GROUP BY a.book_id, b.book_id
+),
+ranked AS (
+    SELECT p.book_id, p.recommended_book_id, p.customers,
+           p.customers / sqrt((ca.customers * cb.customers)::double precision) AS score
+    FROM pairs p
+    JOIN book_customers ca ON ca.book_id = p.book_id
+    JOIN book_customers cb ON cb.book_id = p.recommended_book_id
+)
+INSERT INTO book_recommendations (book_id, recommended_book_id, customers, score)
+SELECT book_id, recommended_book_id, customers, score
+FROM (
+    SELECT *, row_number() OVER (PARTITION BY book_id ORDER BY score DESC, customers DESC, recommended_book_id) AS rank
+    FROM ranked
+) r
+WHERE rank <= ?
+`
+
+// ComputeRecommendations replaces the recommendations of every book with the co-purchases of the placed orders and
+// returns the number of recommendations stored
+func (r RecommendationRepo) ComputeRecommendations(ctx context.Context) (int, error) {
+	var stored int64
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		_, err := tx.ExecContext(ctx, "DELETE FROM book_recommendations")
+		if err != nil {
+			return fmt.Errorf("failed to delete recommendations: %w", err)
+		}
+
+		res, err := tx.ExecContext(ctx, computeRecommendationsQuery, bun.In(domain.OrderStatuses), recommendationsPerBook)
+		if err != nil {
+			return fmt.Errorf("failed to insert recommendations: %w", err)
+		}
+		stored, err = res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get inserted recommendations: %w", err)
+		}
+
+		return nil
+	}, r.db)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compute recommendations: %w", err)
+	}
+
+	return int(stored), nil
+}
+
+type recommendationRow struct {
+	models.Book `bun:",extend"`
+	Score       float64
+}
+
+// GetCoPurchased returns up to limit books in stock bought by the customers of a book, best scored first.
+// Excluded books, the book itself among them, are never recommended.
+func (r RecommendationRepo) GetCoPurchased(ctx context.Context, bookID int, excludeIDs []int, limit int) ([]domain.Recommendation, error) {
+	exists, err := r.db.NewSelect().Model((*models.Book)(nil)).Where("id = ?", bookID).Exists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check book: %w", err)
+	}
+	if !exists {
+		return nil, domain.ErrNotFound
+	}
+
+	var rows []recommendationRow
+	err = r.db.NewSelect().
+		TableExpr("book_recommendations AS br").
+		ColumnExpr("b.*, br.score").
+		Join("JOIN books AS b ON b.id = br.recommended_book_id").
+		Where("br.book_id = ?", bookID).
+		Where("b.stock > 0").
+		Where("b.id NOT IN (?)", bun.In(excludeIDs)).
+		OrderExpr("br.score DESC, br.customers DESC, b.id").
+		Limit(limit).
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recommendations: %w", err)
+	}
+
+	coPurchased := make([]models.Book, len(rows))
+	for i, row := range rows {
+		coPurchased[i] = row.Book
+	}
+	domainBooks, err := booksToDomain(ctx, r.db, coPurchased)
+	if err != nil {
+		return nil, err
+	}
+
+	recommendations := make([]domain.Recommendation, len(rows))
+	for i, row := range rows {
+		recommendations[i] = domain.Recommendation{
+			Book:   domainBooks[i],
+			Score:  row.Score,
+			Source: domain.RecommendationCoPurchase,
+		}
+	}
+
+	return recommendations, nil
+}
+
+// GetCategoryBestsellers returns up to limit books in stock among the best sellers of the categories of a book.
+// Excluded books, the book itself among them, are never recommended.
+func (r RecommendationRepo) GetCategoryBestsellers(ctx context.Context, bookID int, excludeIDs []int, limit int) ([]domain.Recommendation, error) {
+	var books []models.Book
+	err := r.db.NewSelect().
+		Model(&books).
+		Where("id IN (SELECT bc.book_id FROM book_categories AS bc WHERE bc.category_id IN "+
+			"(SELECT category_id FROM book_categories WHERE book_id = ?))", bookID).
+		Where("stock > 0").
+		Where("id NOT IN (?)", bun.In(excludeIDs)).
+		OrderExpr("(SELECT count(*) FROM order_items oi WHERE oi.book_id = book.id) DESC, rating_count DESC, id").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category recommendations: %w", err)
+	}
+
+	domainBooks, err := booksToDomain(ctx, r.db, books)
+	if err != nil {
+		return nil, err
+	}
+
+	recommendations := make([]domain.Recommendation, len(domainBooks))
+	for i, book := range domainBooks {
+		recommendations[i] = domain.Recommendation{
+			Book:   book,
+			Source: domain.RecommendationCategory,
+		}
+	}
+
+	return recommendations, nil
+}
@@ -0,0 +1,206 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/northwindman/book-shop/internal/app/common/slugerrors"
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/app/repository/models"
+	"github.com/northwindman/book-shop/internal/pkg/pg"
+	"github.com/uptrace/bun"
+)
+
+type ReviewRepo struct {
+	db *pg.DB
+}
+
+func NewReviewRepo(db *pg.DB) *ReviewRepo {
+	return &ReviewRepo{
+		db: db,
+	}
+}
+
+// CreateReview submits the review of a user for moderation. Only users who purchased the book may review it, and only
+// once: a review is replaced only when it was rejected.
+func (r ReviewRepo) CreateReview(ctx context.Context, review domain.Review) (domain.Review, error) {
+	dbReview := domainToReview(review)
+
+	var inserted models.Review
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		exists, err := tx.NewSelect().Model((*models.Book)(nil)).Where("id = ?", review.BookID()).Exists(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to check book: %w", err)
+		}
+		if !exists {
+			return domain.ErrNotFound
+		}
+
+		// pre-ordered books are purchased once they are fulfilled
+		purchased, err := tx.NewSelect().
+			TableExpr("order_items AS oi").
+			Join("JOIN orders AS o ON o.id = oi.order_id").
+			Where("o.user_id = ? AND oi.book_id = ?", review.UserID(), review.BookID()).
+			Where("o.status IN (?)", bun.In(domain.OrderStatuses)).
+			Where("NOT oi.preorder OR oi.fulfilled_at IS NOT NULL").
+			Exists(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to check purchase: %w", err)
+		}
+		if !purchased {
+			return slugerrors.NewForbiddenError(domain.ErrNotPurchased.Error(), "not-purchased")
+		}
+
+		dbReview.Status = domain.ReviewStatusPending
+		dbReview.CreatedAt = time.Now()
+		dbReview.UpdatedAt = dbReview.CreatedAt
+		err = tx.NewInsert().Model(&dbReview).
+			On("CONFLICT (book_id, user_id) DO UPDATE").
+			Set("rating = EXCLUDED.rating").
+			Set("body = EXCLUDED.body").
+			Set("status = EXCLUDED.status").
+			Set("reason = ''").
+			Set("moderated_by = NULL").
+			Set("moderated_at = NULL").
+			Set("created_at = EXCLUDED.created_at").
+			Set("updated_at = EXCLUDED.updated_at").
+			Where("review.status = ?", domain.ReviewStatusRejected).
+			Returning("*").
+			Scan(ctx, &inserted)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return slugerrors.NewConflictError("the book was already reviewed by the user", "review-exists")
+			}
+			return fmt.Errorf("failed to insert review: %w", err)
+		}
+
+		return nil
+	}, r.db)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Review{}, err
+		}
+		return domain.Review{}, fmt.Errorf("failed to create review: %w", err)
+	}
+
+	domainReview, err := reviewToDomain(inserted)
+	if err != nil {
+		return domain.Review{}, fmt.Errorf("failed to create domain review: %w", err)
+	}
+
+	return domainReview, nil
+}
+
+// GetBookReviews returns the approved reviews of a book, newest first
+func (r ReviewRepo) GetBookReviews(ctx context.Context, bookID, limit, offset int) ([]domain.Review, error) {
+	var reviews []models.Review
+	query := r.db.NewSelect().Model(&reviews).
+		Where("book_id = ?", bookID).
+		Where("status = ?", domain.ReviewStatusApproved).
+		Order("created_at DESC", "id DESC")
+	if limit > 0 {
+		query.Limit(limit)
+	}
+	if offset > 0 {
+		query.Offset(offset)
+	}
+	err := query.Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviews: %w", err)
+	}
+
+	return reviewsToDomain(reviews)
+}
+
+// GetReviews returns the reviews with a status, oldest first so the moderation queue is worked in order
+func (r ReviewRepo) GetReviews(ctx context.Context, status string, limit, offset int) ([]domain.Review, error) {
+	var reviews []models.Review
+	query := r.db.NewSelect().Model(&reviews).Where("status = ?", status).Order("created_at", "id")
+	if limit > 0 {
+		query.Limit(limit)
+	}
+	if offset > 0 {
+		query.Offset(offset)
+	}
+	err := query.Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviews: %w", err)
+	}
+
+	return reviewsToDomain(reviews)
+}
+
+// ModerateReview approves or rejects a pending review, the rating of an approved review is added to its book
+func (r ReviewRepo) ModerateReview(ctx context.Context, id int, status, reason string, adminID int) (domain.Review, error) {
+	var moderated domain.Review
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		var review models.Review
+		err := tx.NewSelect().Model(&review).Where("id = ?", id).For("UPDATE").Scan(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrNotFound
+			}
+			return fmt.Errorf("failed to get review: %w", err)
+		}
+
+		domainReview, err := reviewToDomain(review)
+		if err != nil {
+			return fmt.Errorf("failed to create domain review: %w", err)
+		}
+		moderated, err = domainReview.Moderate(status, reason, adminID, time.Now())
+		if err != nil {
+			if errors.Is(err, domain.ErrReviewModerated) {
+				return slugerrors.NewConflictError(err.Error(), "review-moderated")
+			}
+			return err
+		}
+
+		dbReview := domainToReview(moderated)
+		dbReview.UpdatedAt = moderated.ModeratedAt()
+		_, err = tx.NewUpdate().Model(&dbReview).
+			Column("status", "reason", "moderated_by", "moderated_at", "updated_at").
+			WherePK().
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to update review: %w", err)
+		}
+
+		if moderated.Status() != domain.ReviewStatusApproved {
+			return nil
+		}
+		_, err = tx.NewUpdate().Model((*models.Book)(nil)).
+			Set("rating_count = rating_count + 1").
+			Set("rating_total = rating_total + ?", moderated.Rating()).
+			Where("id = ?", moderated.BookID()).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to update book rating: %w", err)
+		}
+
+		return nil
+	}, r.db)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Review{}, err
+		}
+		return domain.Review{}, fmt.Errorf("failed to moderate review: %w", err)
+	}
+
+	return moderated, nil
+}
+
+func reviewsToDomain(reviews []models.Review) ([]domain.Review, error) {
+	domainReviews := make([]domain.Review, len(reviews))
+	for i, review := range reviews {
+		domainReview, err := reviewToDomain(review)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create domain review: %w", err)
+		}
+		domainReviews[i] = domainReview
+	}
+
+	return domainReviews, nil
+}
@@ -0,0 +1,96 @@
+package pgrepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/app/repository/models"
+	"github.com/northwindman/book-shop/internal/pkg/pg"
+)
+
+type ShippingRateRepo struct {
+	db *pg.DB
+}
+
+func NewShippingRateRepo(db *pg.DB) *ShippingRateRepo {
+	return &ShippingRateRepo{
+		db: db,
+	}
+}
+
+// GetRates returns the shipping rates of a country and the fallback rates, all rates when country is empty
+func (r ShippingRateRepo) GetRates(ctx context.Context, country string) ([]domain.ShippingRate, error) {
+	var rates []models.ShippingRate
+	query := r.db.NewSelect().Model(&rates)
+	if country != "" {
+		query.Where("country IN (?, '')", country)
+	}
+	err := query.Order("method", "country").Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shipping rates: %w", err)
+	}
+
+	domainRates := make([]domain.ShippingRate, len(rates))
+	for i, rate := range rates {
+		domainRate, err := shippingRateToDomain(rate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create domain shipping rate: %w", err)
+		}
+
+		domainRates[i] = domainRate
+	}
+
+	return domainRates, nil
+}
+
+// SetRate inserts or replaces the rate of a shipping method to a country
+func (r ShippingRateRepo) SetRate(ctx context.Context, rate domain.ShippingRate) (domain.ShippingRate, error) {
+	dbRate := domainToShippingRate(rate)
+	dbRate.UpdatedAt = time.Now()
+
+	var savedRate models.ShippingRate
+	err := r.db.NewInsert().Model(&dbRate).
+		ExcludeColumn("id").
+		On("CONFLICT (method, country) DO UPDATE").
+		Set("name = EXCLUDED.name").
+		Set("base_amount = EXCLUDED.base_amount").
+		Set("per_item_amount = EXCLUDED.per_item_amount").
+		Set("per_kg_amount = EXCLUDED.per_kg_amount").
+		Set("currency = EXCLUDED.currency").
+		Set("updated_at = EXCLUDED.updated_at").
+		Returning("*").
+		Scan(ctx, &savedRate)
+	if err != nil {
+		return domain.ShippingRate{}, fmt.Errorf("failed to set a shipping rate: %w", err)
+	}
+
+	domainRate, err := shippingRateToDomain(savedRate)
+	if err != nil {
+		return domain.ShippingRate{}, fmt.Errorf("failed to create domain shipping rate: %w", err)
+	}
+
+	return domainRate, nil
+}
+
+func (r ShippingRateRepo) DeleteRate(ctx context.Context, id int) error {
+	if id == 0 {
+		return fmt.Errorf("%w: id", domain.ErrRequired)
+	}
+
+	res, err := r.db.NewDelete().Model((*models.ShippingRate)(nil)).Where("id = ?", id).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete a shipping rate: %w", err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get deleted shipping rates: %w", err)
+	}
+	if deleted == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,197 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/northwindman/book-shop/internal/app/common/slugerrors"
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/app/repository/models"
+	"github.com/northwindman/book-shop/internal/pkg/pg"
+	"github.com/uptrace/bun"
+)
+
+type StockSubscriptionRepo struct {
+	db *pg.DB
+}
+
+func NewStockSubscriptionRepo(db *pg.DB) *StockSubscriptionRepo {
+	return &StockSubscriptionRepo{
+		db: db,
+	}
+}
+
+// Subscribe subscribes a user to a sold-out book, it fails with a conflict when the book is in stock.
+// Subscribing again keeps the place of the user in the queue unless the user was already notified.
+func (r StockSubscriptionRepo) Subscribe(ctx context.Context, userID, bookID int) (domain.StockSubscription, error) {
+	var subscription models.StockSubscription
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		var book models.Book
+		err := tx.NewSelect().Model(&book).Column("id", "stock").Where("id = ?", bookID).For("SHARE").Scan(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrNotFound
+			}
+			return fmt.Errorf("failed to get book: %w", err)
+		}
+		if book.Stock > 0 {
+			return slugerrors.NewConflictError(domain.ErrBookInStock.Error(), "book-in-stock")
+		}
+
+		subscription = models.StockSubscription{UserID: userID, BookID: bookID, CreatedAt: time.Now()}
+		err = tx.NewInsert().Model(&subscription).
+			On("CONFLICT (user_id, book_id) DO UPDATE").
+			Set("created_at = CASE WHEN stock_subscription.notified_at IS NULL THEN stock_subscription.created_at ELSE EXCLUDED.created_at END").
+			Set("queued_at = CASE WHEN stock_subscription.notified_at IS NULL THEN stock_subscription.queued_at END").
+			Set("notified_at = NULL").
+			Returning("*").
+			Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to insert stock subscription: %w", err)
+		}
+
+		return nil
+	}, r.db)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.StockSubscription{}, err
+		}
+		return domain.StockSubscription{}, fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	domainSubscription, err := stockSubscriptionToDomain(subscription)
+	if err != nil {
+		return domain.StockSubscription{}, fmt.Errorf("failed to create domain stock subscription: %w", err)
+	}
+
+	return domainSubscription, nil
+}
+
+// Unsubscribe removes the subscription of a user to a book
+func (r StockSubscriptionRepo) Unsubscribe(ctx context.Context, userID, bookID int) error {
+	res, err := r.db.NewDelete().Model((*models.StockSubscription)(nil)).Where("user_id = ? AND book_id = ?", userID, bookID).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete stock subscription: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+type backInStockRow struct {
+	UserID           int
+	BookID           int
+	CreatedAt        time.Time
+	QueuedAt         time.Time
+	Title            string
+	Stock            int
+	Username         string
+	RecentlyNotified int
+}
+
+// GetDueBackInStock returns the queued subscriptions of books in stock, oldest first. Every book gets no more
+// subscriptions than it has in stock, less the subscribers already notified since it was queued, and users who
+// received the rate limit of notifications since rateSince are skipped, their subscriptions stay queued.
+func (r StockSubscriptionRepo) GetDueBackInStock(ctx context.Context, rateSince time.Time, limit int) ([]domain.BackInStockNotice, error) {
+	var rows []backInStockRow
+	err := r.db.NewRaw(`
+SELECT user_id, book_id, created_at, queued_at, title, stock, username, recently_notified
+FROM (
+    SELECT s.user_id, s.book_id, s.created_at, s.queued_at, b.title, b.stock, u.username, n.recently_notified,
+           q.already_notified,
+           row_number() OVER (PARTITION BY s.book_id ORDER BY s.created_at, s.user_id) AS position
+    FROM stock_subscriptions s
+    JOIN books b ON b.id = s.book_id
+    JOIN users u ON u.id = s.user_id
+    CROSS JOIN LATERAL (
+        SELECT count(*) AS recently_notified
+        FROM stock_subscriptions r
+        WHERE r.user_id = s.user_id AND r.notified_at >= ?
+    ) n
+    -- the subscribers notified since the book was queued already took their share of its stock
+    CROSS JOIN LATERAL (
+        SELECT count(*) AS already_notified
+        FROM stock_subscriptions o
+        WHERE o.book_id = s.book_id AND o.notified_at >= (
+            SELECT min(p.queued_at)
+            FROM stock_subscriptions p
+            WHERE p.book_id = s.book_id AND p.queued_at IS NOT NULL AND p.notified_at IS NULL)
+    ) q
+    WHERE s.queued_at IS NOT NULL AND s.notified_at IS NULL AND b.stock > 0 AND n.recently_notified < ?
+) due
+WHERE position <= stock - already_notified
+ORDER BY created_at, user_id
+LIMIT ?`, rateSince, domain.BackInStockRateLimit, limit).Scan(ctx, &rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due back in stock subscriptions: %w", err)
+	}
+
+	notices := make([]domain.BackInStockNotice, 0, len(rows))
+	for _, row := range rows {
+		subscription, err := domain.NewStockSubscription(domain.NewStockSubscriptionData{
+			UserID:    row.UserID,
+			BookID:    row.BookID,
+			CreatedAt: row.CreatedAt,
+			QueuedAt:  row.QueuedAt,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create domain stock subscription: %w", err)
+		}
+		user := domain.RestoreUser(domain.NewUserData{ID: row.UserID, Username: row.Username})
+		notices = append(notices, domain.BackInStockNotice{
+			Subscription:     subscription,
+			Title:            row.Title,
+			Stock:            row.Stock,
+			Email:            user.Email(),
+			RecentlyNotified: row.RecentlyNotified,
+		})
+	}
+
+	return notices, nil
+}
+
+// MarkNotified records that a user was notified of a book back in stock
+func (r StockSubscriptionRepo) MarkNotified(ctx context.Context, userID, bookID int, notifiedAt time.Time) error {
+	_, err := r.db.NewUpdate().Model((*models.StockSubscription)(nil)).
+		Set("notified_at = ?", notifiedAt).
+		Where("user_id = ? AND book_id = ?", userID, bookID).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to mark stock subscription notified: %w", err)
+	}
+
+	return nil
+}
+
+// queueBackInStock queues the waiting subscriptions of the books whose stock rose above zero by delta
+func queueBackInStock(ctx context.Context, db bun.IDB, books []models.Book, delta int) error {
+	var bookIDs []int
+	for _, book := range books {
+		if backInStock(book, delta) {
+			bookIDs = append(bookIDs, book.ID)
+		}
+	}
+	if len(bookIDs) == 0 {
+		return nil
+	}
+
+	_, err := db.NewUpdate().Model((*models.StockSubscription)(nil)).
+		Set("queued_at = now()").
+		Where("book_id IN (?) AND queued_at IS NULL AND notified_at IS NULL", bun.In(bookIDs)).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to queue stock subscriptions: %w", err)
+	}
+
+	return nil
+}
+
+// backInStock reports whether the stock of a book rose from zero by delta
+func backInStock(book models.Book, delta int) bool {
+	return delta > 0 && book.Stock > 0 && book.Stock-delta <= 0
+}
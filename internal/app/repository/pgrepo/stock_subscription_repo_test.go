@@ -0,0 +1,46 @@
+package pgrepo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+func TestStockSubscriptionRepo_GetDueBackInStock_CountsNotifiedSubscribers(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	repo := NewStockSubscriptionRepo(db)
+
+	book := createTestBook(t, db, "Awaited", 0)
+	userIDs := []int{createTestUser(t, db), createTestUser(t, db), createTestUser(t, db)}
+	for _, userID := range userIDs {
+		_, err := repo.Subscribe(ctx, userID, book.ID())
+		require.NoError(t, err)
+	}
+
+	restock, err := domain.NewStockAdjustment(book.ID(), domain.MovementRestock, 2, "", 0, 0)
+	require.NoError(t, err)
+	_, err = NewInventoryRepo(db).AdjustStock(ctx, restock)
+	require.NoError(t, err)
+
+	due := func() []int {
+		notices, err := repo.GetDueBackInStock(ctx, time.Now().Add(-time.Hour), 1000)
+		require.NoError(t, err)
+		var due []int
+		for _, notice := range notices {
+			if notice.Subscription.BookID() == book.ID() {
+				due = append(due, notice.Subscription.UserID())
+			}
+		}
+		return due
+	}
+	require.Equal(t, userIDs[:2], due())
+
+	// the notified subscriber keeps its share of the stock
+	require.NoError(t, repo.MarkNotified(ctx, userIDs[0], book.ID(), time.Now()))
+	require.Equal(t, userIDs[1:2], due())
+}
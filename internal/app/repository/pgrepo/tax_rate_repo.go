@@ -0,0 +1,93 @@
+package pgrepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/app/repository/models"
+	"github.com/northwindman/book-shop/internal/pkg/pg"
+)
+
+type TaxRateRepo struct {
+	db *pg.DB
+}
+
+func NewTaxRateRepo(db *pg.DB) *TaxRateRepo {
+	return &TaxRateRepo{
+		db: db,
+	}
+}
+
+// GetRates returns the tax rates of a country, all rates when country is empty
+func (r TaxRateRepo) GetRates(ctx context.Context, country string) ([]domain.TaxRate, error) {
+	var rates []models.TaxRate
+	query := r.db.NewSelect().Model(&rates)
+	if country != "" {
+		query.Where("country = ?", country)
+	}
+	err := query.Order("country", "state", "tax_class").Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tax rates: %w", err)
+	}
+
+	domainRates := make([]domain.TaxRate, len(rates))
+	for i, rate := range rates {
+		domainRate, err := taxRateToDomain(rate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create domain tax rate: %w", err)
+		}
+
+		domainRates[i] = domainRate
+	}
+
+	return domainRates, nil
+}
+
+// SetRate inserts or replaces the rate of a tax class in a region
+func (r TaxRateRepo) SetRate(ctx context.Context, rate domain.TaxRate) (domain.TaxRate, error) {
+	dbRate := domainToTaxRate(rate)
+	dbRate.UpdatedAt = time.Now()
+
+	var savedRate models.TaxRate
+	err := r.db.NewInsert().Model(&dbRate).
+		ExcludeColumn("id").
+		On("CONFLICT (country, state, tax_class) DO UPDATE").
+		Set("rate = EXCLUDED.rate").
+		Set("name = EXCLUDED.name").
+		Set("updated_at = EXCLUDED.updated_at").
+		Returning("*").
+		Scan(ctx, &savedRate)
+	if err != nil {
+		return domain.TaxRate{}, fmt.Errorf("failed to set a tax rate: %w", err)
+	}
+
+	domainRate, err := taxRateToDomain(savedRate)
+	if err != nil {
+		return domain.TaxRate{}, fmt.Errorf("failed to create domain tax rate: %w", err)
+	}
+
+	return domainRate, nil
+}
+
+func (r TaxRateRepo) DeleteRate(ctx context.Context, id int) error {
+	if id == 0 {
+		return fmt.Errorf("%w: id", domain.ErrRequired)
+	}
+
+	res, err := r.db.NewDelete().Model((*models.TaxRate)(nil)).Where("id = ?", id).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete a tax rate: %w", err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get deleted tax rates: %w", err)
+	}
+	if deleted == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/northwindman/book-shop/internal/app/domain"
 	"github.com/northwindman/book-shop/internal/app/repository/models"
@@ -30,12 +31,7 @@ func (r UserRepo) CreateUser(ctx context.Context, user domain.User) (domain.User
 		return domain.User{}, fmt.Errorf("failed to insert a user: %w", err)
 	}
 
-	domainUser, err := userToDomain(insertedUser)
-	if err != nil {
-		return domain.User{}, fmt.Errorf("failed to create domain user: %w", err)
-	}
-
-	return domainUser, nil
+	return userToDomain(insertedUser), nil
 }
 
 func (r UserRepo) GetUser(ctx context.Context, username string) (domain.User, error) {
@@ -48,12 +44,7 @@ func (r UserRepo) GetUser(ctx context.Context, username string) (domain.User, er
 		return domain.User{}, fmt.Errorf("failed to get a user: %w", err)
 	}
 
-	user, err := userToDomain(dbUser)
-	if err != nil {
-		return domain.User{}, fmt.Errorf("failed to create domain user: %w", err)
-	}
-
-	return user, nil
+	return userToDomain(dbUser), nil
 }
 
 func (r UserRepo) GetUserByID(ctx context.Context, id int) (domain.User, error) {
@@ -66,10 +57,25 @@ func (r UserRepo) GetUserByID(ctx context.Context, id int) (domain.User, error)
 		return domain.User{}, fmt.Errorf("failed to get a user: %w", err)
 	}
 
-	user, err := userToDomain(dbUser)
+	return userToDomain(dbUser), nil
+}
+
+// SetAdmin grants or revokes the admin flag of a user
+func (r UserRepo) SetAdmin(ctx context.Context, username string, admin bool) (domain.User, error) {
+	var updatedUser models.User
+	err := r.db.NewUpdate().
+		Model((*models.User)(nil)).
+		Set("admin = ?", admin).
+		Set("updated_at = ?", time.Now()).
+		Where("username = ?", username).
+		Returning("*").
+		Scan(ctx, &updatedUser)
 	if err != nil {
-		return domain.User{}, fmt.Errorf("failed to create domain user: %w", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.User{}, domain.ErrNotFound
+		}
+		return domain.User{}, fmt.Errorf("failed to update a user: %w", err)
 	}
 
-	return user, nil
+	return userToDomain(updatedUser), nil
 }
@@ -1,45 +1,161 @@
 package pgrepo
 
 import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/northwindman/book-shop/internal/app/common/slugerrors"
 	"github.com/northwindman/book-shop/internal/app/domain"
 	"github.com/northwindman/book-shop/internal/app/repository/models"
+	"github.com/uptrace/bun"
 )
 
 func domainToBook(book domain.Book) models.Book {
 	return models.Book{
-		ID:         book.ID(),
-		Title:      book.Title(),
-		Year:       book.Year(),
-		Author:     book.Author(),
-		Price:      book.Price(),
-		Stock:      book.Stock(),
-		CategoryID: book.CategoryID(),
+		ID:               book.ID(),
+		Title:            book.Title(),
+		Year:             book.Year(),
+		Author:           book.Author(),
+		PriceAmount:      book.Price().Amount(),
+		PriceCurrency:    book.Price().Currency(),
+		Stock:            book.Stock(),
+		CategoryID:       book.CategoryID(),
+		TaxClass:         book.TaxClass(),
+		WeightGrams:      book.WeightGrams(),
+		ReorderThreshold: book.ReorderThreshold(),
+		ReleaseDate:      book.ReleaseDate(),
+		Preorder:         book.Preorder(),
+		PreorderCap:      book.PreorderCap(),
+		Preorders:        book.Preorders(),
+		RatingCount:      book.RatingCount(),
+		RatingTotal:      book.RatingTotal(),
+		ISBN:             book.ISBN(),
+		Publisher:        book.Publisher(),
+		Edition:          book.Edition(),
+		Language:         book.Language(),
+		PageCount:        book.PageCount(),
+		Format:           book.Format(),
+		Description:      book.Description(),
+		Version:          book.Version(),
+	}
+}
+
+// bookToDomain converts a book with its credited authors and its categories
+func bookToDomain(ctx context.Context, db bun.IDB, book models.Book) (domain.Book, error) {
+	books, err := booksToDomain(ctx, db, []models.Book{book})
+	if err != nil {
+		return domain.Book{}, err
+	}
+	return books[0], nil
+}
+
+// booksToDomain converts books with their credited authors and their categories
+func booksToDomain(ctx context.Context, db bun.IDB, books []models.Book) ([]domain.Book, error) {
+	bookIDs := make([]int, len(books))
+	for i, book := range books {
+		bookIDs[i] = book.ID
+	}
+	authors, err := getBookAuthors(ctx, db, bookIDs)
+	if err != nil {
+		return nil, err
 	}
+	categoryIDs, err := getBookCategories(ctx, db, bookIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	domainBooks := make([]domain.Book, len(books))
+	for i, book := range books {
+		domainBook, err := bookWithRelationsToDomain(book, authors[book.ID], categoryIDs[book.ID])
+		if err != nil {
+			return nil, fmt.Errorf("failed to create domain book: %w", err)
+		}
+		domainBooks[i] = domainBook
+	}
+
+	return domainBooks, nil
+}
+
+// bookWithRelationsToDomain converts a book with its credited authors and its categories, nil when they are not
+// loaded
+func bookWithRelationsToDomain(book models.Book, authors []domain.BookAuthor, categoryIDs []int) (domain.Book, error) {
+	price, err := domain.NewMoney(book.PriceAmount, book.PriceCurrency)
+	if err != nil {
+		return domain.Book{}, fmt.Errorf("failed to create book price: %w", err)
+	}
+
+	// stored books are restored as they are, books are validated when they are created or changed
+	return domain.RestoreBook(domain.NewBookData{
+		ID:               book.ID,
+		Title:            book.Title,
+		Year:             book.Year,
+		Author:           book.Author,
+		Price:            price,
+		Stock:            book.Stock,
+		CategoryID:       book.CategoryID,
+		TaxClass:         book.TaxClass,
+		WeightGrams:      book.WeightGrams,
+		ReorderThreshold: book.ReorderThreshold,
+		ReleaseDate:      book.ReleaseDate,
+		Preorder:         book.Preorder,
+		PreorderCap:      book.PreorderCap,
+		Preorders:        book.Preorders,
+		RatingCount:      book.RatingCount,
+		RatingTotal:      book.RatingTotal,
+		Authors:          authors,
+		ISBN:             book.ISBN,
+		Publisher:        book.Publisher,
+		Edition:          book.Edition,
+		Language:         book.Language,
+		PageCount:        book.PageCount,
+		Format:           book.Format,
+		Description:      book.Description,
+		CategoryIDs:      categoryIDs,
+		Version:          book.Version,
+	}), nil
 }
 
-func bookToDomain(book models.Book) (domain.Book, error) {
-	return domain.NewBook(domain.NewBookData{
-		ID:         book.ID,
-		Title:      book.Title,
-		Year:       book.Year,
-		Author:     book.Author,
-		Price:      book.Price,
-		Stock:      book.Stock,
-		CategoryID: book.CategoryID,
+// staleVersionError is returned when a resource was changed since the version an edit was based on
+func staleVersionError(resource string, id int) error {
+	return slugerrors.NewPreconditionFailedError(
+		fmt.Sprintf("%s %d was modified since it was read, fetch it again and retry", resource, id), "version-conflict")
+}
+
+func domainToAuthor(author domain.Author) models.Author {
+	return models.Author{
+		ID:      author.ID(),
+		Name:    author.Name(),
+		NameKey: author.NameKey(),
+		Bio:     author.Bio(),
+		Version: author.Version(),
+	}
+}
+
+func authorToDomain(author models.Author) (domain.Author, error) {
+	return domain.NewAuthor(domain.NewAuthorData{
+		ID:      author.ID,
+		Name:    author.Name,
+		Bio:     author.Bio,
+		Version: author.Version,
 	})
 }
 
 func domainToCategory(category domain.Category) models.Category {
 	return models.Category{
-		ID:   category.ID(),
-		Name: category.Name(),
+		ID:       category.ID(),
+		Name:     category.Name(),
+		ParentID: category.ParentID(),
+		Version:  category.Version(),
 	}
 }
 
 func categoryToDomain(category models.Category) (domain.Category, error) {
 	return domain.NewCategory(domain.NewCategoryData{
-		ID:   category.ID,
-		Name: category.Name,
+		ID:       category.ID,
+		Name:     category.Name,
+		ParentID: category.ParentID,
+		Version:  category.Version,
 	})
 }
 
@@ -52,8 +168,9 @@ func domainToUser(user domain.User) models.User {
 	}
 }
 
-func userToDomain(user models.User) (domain.User, error) {
-	return domain.NewUser(domain.NewUserData{
+// userToDomain restores a stored user, usernames are validated when users sign up
+func userToDomain(user models.User) domain.User {
+	return domain.RestoreUser(domain.NewUserData{
 		ID:       user.ID,
 		Username: user.Username,
 		Password: user.Password,
@@ -63,14 +180,513 @@ func userToDomain(user models.User) (domain.User, error) {
 
 func domainToCart(cart domain.Cart) models.Cart {
 	return models.Cart{
-		UserID:  cart.UserID(),
-		BookIDs: cart.BookIDs(),
+		UserID:          cart.UserID(),
+		BookIDs:         cart.BookIDs(),
+		PreorderBookIDs: cart.PreorderBookIDs(),
+		CouponCode:      cart.CouponCode(),
+	}
+}
+
+// cartToDomain restores a stored cart, carts are validated when they are changed
+func cartToDomain(cart models.Cart) domain.Cart {
+	return domain.RestoreCart(domain.NewCartData{
+		UserID:          cart.UserID,
+		BookIDs:         cart.BookIDs,
+		PreorderBookIDs: cart.PreorderBookIDs,
+		CouponCode:      cart.CouponCode,
+	})
+}
+
+func domainToOutboxEvent(event domain.Event) models.OutboxEvent {
+	return models.OutboxEvent{
+		ID:            event.ID(),
+		EventType:     event.Type(),
+		AggregateType: event.AggregateType(),
+		AggregateID:   event.AggregateID(),
+		Payload:       event.Payload(),
+		CreatedAt:     event.OccurredAt(),
+	}
+}
+
+func outboxEventToDomain(event models.OutboxEvent) (domain.Event, error) {
+	return domain.NewEvent(domain.NewEventData{
+		ID:            event.ID,
+		Type:          event.EventType,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		Payload:       event.Payload,
+		OccurredAt:    event.CreatedAt,
+	})
+}
+
+func domainToWebhookSubscription(subscription domain.WebhookSubscription) models.WebhookSubscription {
+	return models.WebhookSubscription{
+		ID:         subscription.ID(),
+		URL:        subscription.URL(),
+		Secret:     subscription.Secret(),
+		EventTypes: subscription.EventTypes(),
+		Active:     subscription.Active(),
+	}
+}
+
+func webhookSubscriptionToDomain(subscription models.WebhookSubscription) (domain.WebhookSubscription, error) {
+	return domain.NewWebhookSubscription(domain.NewWebhookSubscriptionData{
+		ID:         subscription.ID,
+		URL:        subscription.URL,
+		Secret:     subscription.Secret,
+		EventTypes: subscription.EventTypes,
+		Active:     subscription.Active,
+	})
+}
+
+func domainToWebhookDelivery(delivery domain.WebhookDelivery) models.WebhookDelivery {
+	return models.WebhookDelivery{
+		ID:             delivery.ID(),
+		SubscriptionID: delivery.SubscriptionID(),
+		EventID:        delivery.EventID(),
+		EventType:      delivery.EventType(),
+		Payload:        delivery.Payload(),
+		Status:         delivery.Status(),
+		Attempts:       delivery.Attempts(),
+		NextAttemptAt:  delivery.NextAttemptAt(),
+		LastStatusCode: delivery.LastStatusCode(),
+		LastError:      delivery.LastError(),
+	}
+}
+
+func webhookDeliveryToDomain(delivery models.WebhookDelivery, attemptLog []domain.WebhookAttempt) (domain.WebhookDelivery, error) {
+	return domain.NewWebhookDelivery(domain.NewWebhookDeliveryData{
+		ID:             delivery.ID,
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Payload:        delivery.Payload,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+		AttemptLog:     attemptLog,
+	})
+}
+
+func domainToWebhookAttempt(attempt domain.WebhookAttempt) models.WebhookDeliveryAttempt {
+	return models.WebhookDeliveryAttempt{
+		DeliveryID:  attempt.DeliveryID,
+		AttemptedAt: attempt.AttemptedAt,
+		StatusCode:  attempt.StatusCode,
+		Error:       attempt.Error,
+		DurationMs:  int(attempt.Duration.Milliseconds()),
+	}
+}
+
+func webhookAttemptToDomain(attempt models.WebhookDeliveryAttempt) domain.WebhookAttempt {
+	return domain.WebhookAttempt{
+		DeliveryID:  attempt.DeliveryID,
+		AttemptedAt: attempt.AttemptedAt,
+		StatusCode:  attempt.StatusCode,
+		Error:       attempt.Error,
+		Duration:    time.Duration(attempt.DurationMs) * time.Millisecond,
+	}
+}
+
+func domainToOrder(order domain.Order) models.Order {
+	items := make([]models.OrderItem, len(order.Lines()))
+	for i, line := range order.Lines() {
+		items[i] = models.OrderItem{
+			OrderID:       order.ID(),
+			BookID:        line.BookID,
+			Title:         line.Title,
+			PriceAmount:   line.Price.Amount(),
+			PriceCurrency: line.Price.Currency(),
+			Preorder:      line.Preorder,
+		}
+	}
+
+	discounts := make([]models.OrderDiscount, len(order.Discounts()))
+	for i, discount := range order.Discounts() {
+		discounts[i] = models.OrderDiscount{
+			OrderID:     order.ID(),
+			PromotionID: discount.PromotionID,
+			UserID:      order.UserID(),
+			Code:        discount.Code,
+			Description: discount.Description,
+			Amount:      discount.Amount.Amount(),
+			Currency:    discount.Amount.Currency(),
+		}
+	}
+
+	taxes := make([]models.OrderTax, len(order.Taxes()))
+	for i, tax := range order.Taxes() {
+		taxes[i] = models.OrderTax{
+			OrderID:       order.ID(),
+			Name:          tax.Name,
+			TaxClass:      tax.TaxClass,
+			Rate:          tax.Rate,
+			TaxableAmount: tax.Taxable.Amount(),
+			Amount:        tax.Amount.Amount(),
+			Currency:      tax.Amount.Currency(),
+		}
+	}
+
+	address := order.Address()
+
+	return models.Order{
+		ID:               order.ID(),
+		UserID:           order.UserID(),
+		Status:           order.Status(),
+		SubtotalAmount:   order.Subtotal().Amount(),
+		DiscountAmount:   order.DiscountTotal().Amount(),
+		ShippingMethod:   order.Shipping().Method,
+		ShippingName:     order.Shipping().Name,
+		ShippingAmount:   order.Shipping().Cost.Amount(),
+		ShipToName:       address.Name(),
+		ShipToLine1:      address.Line1(),
+		ShipToLine2:      address.Line2(),
+		ShipToCity:       address.City(),
+		ShipToState:      address.State(),
+		ShipToPostalCode: address.PostalCode(),
+		ShipToCountry:    address.Country(),
+		ShipToPhone:      address.Phone(),
+		TaxAmount:        order.TaxTotal().Amount(),
+		TaxCountry:       order.TaxRegion().Country,
+		TaxState:         order.TaxRegion().State,
+		TotalAmount:      order.Total().Amount(),
+		Currency:         order.Total().Currency(),
+		CreatedAt:        order.CreatedAt(),
+		Items:            items,
+		Discounts:        discounts,
+		Taxes:            taxes,
+	}
+}
+
+func orderToDomain(order models.Order) (domain.Order, error) {
+	lines := make([]domain.QuoteLine, len(order.Items))
+	for i, item := range order.Items {
+		price, err := domain.NewMoney(item.PriceAmount, item.PriceCurrency)
+		if err != nil {
+			return domain.Order{}, fmt.Errorf("failed to create order item price: %w", err)
+		}
+		lines[i] = domain.QuoteLine{
+			BookID:   item.BookID,
+			Title:    item.Title,
+			Price:    price,
+			Preorder: item.Preorder,
+		}
+	}
+
+	discounts := make([]domain.QuoteDiscount, len(order.Discounts))
+	for i, discount := range order.Discounts {
+		amount, err := domain.NewMoney(discount.Amount, discount.Currency)
+		if err != nil {
+			return domain.Order{}, fmt.Errorf("failed to create order discount amount: %w", err)
+		}
+		discounts[i] = domain.QuoteDiscount{
+			PromotionID: discount.PromotionID,
+			Code:        discount.Code,
+			Description: discount.Description,
+			Amount:      amount,
+		}
+	}
+
+	taxes := make([]domain.TaxLine, len(order.Taxes))
+	for i, tax := range order.Taxes {
+		taxable, err := domain.NewMoney(tax.TaxableAmount, tax.Currency)
+		if err != nil {
+			return domain.Order{}, fmt.Errorf("failed to create order taxable amount: %w", err)
+		}
+		amount, err := domain.NewMoney(tax.Amount, tax.Currency)
+		if err != nil {
+			return domain.Order{}, fmt.Errorf("failed to create order tax amount: %w", err)
+		}
+		taxes[i] = domain.TaxLine{
+			Name:     tax.Name,
+			TaxClass: tax.TaxClass,
+			Rate:     tax.Rate,
+			Taxable:  taxable,
+			Amount:   amount,
+		}
+	}
+
+	subtotal, err := domain.NewMoney(order.SubtotalAmount, order.Currency)
+	if err != nil {
+		return domain.Order{}, fmt.Errorf("failed to create order subtotal: %w", err)
+	}
+	total, err := domain.NewMoney(order.TotalAmount, order.Currency)
+	if err != nil {
+		return domain.Order{}, fmt.Errorf("failed to create order total: %w", err)
+	}
+
+	var shipping domain.ShippingOption
+	if order.ShippingMethod != "" {
+		cost, err := domain.NewMoney(order.ShippingAmount, order.Currency)
+		if err != nil {
+			return domain.Order{}, fmt.Errorf("failed to create order shipping cost: %w", err)
+		}
+		shipping = domain.ShippingOption{Method: order.ShippingMethod, Name: order.ShippingName, Cost: cost}
+	}
+
+	// orders placed before addresses were collected have none
+	var address domain.Address
+	if order.ShipToCountry != "" {
+		address, err = domain.NewAddress(domain.NewAddressData{
+			Name:       order.ShipToName,
+			Line1:      order.ShipToLine1,
+			Line2:      order.ShipToLine2,
+			City:       order.ShipToCity,
+			State:      order.ShipToState,
+			PostalCode: order.ShipToPostalCode,
+			Country:    order.ShipToCountry,
+			Phone:      order.ShipToPhone,
+		})
+		if err != nil {
+			return domain.Order{}, fmt.Errorf("failed to create order address: %w", err)
+		}
+	}
+
+	return domain.NewOrder(domain.NewOrderData{
+		ID:        order.ID,
+		UserID:    order.UserID,
+		Status:    order.Status,
+		Lines:     lines,
+		Discounts: discounts,
+		Address:   address,
+		Shipping:  shipping,
+		TaxRegion: domain.TaxRegion{Country: order.TaxCountry, State: order.TaxState},
+		Taxes:     taxes,
+		Subtotal:  subtotal,
+		Total:     total,
+		CreatedAt: order.CreatedAt,
+	})
+}
+
+func domainToExchangeRate(rate domain.ExchangeRate) models.ExchangeRate {
+	return models.ExchangeRate{
+		Currency:  rate.Currency(),
+		Rate:      rate.Rate(),
+		UpdatedAt: rate.UpdatedAt(),
+	}
+}
+
+func exchangeRateToDomain(rate models.ExchangeRate) (domain.ExchangeRate, error) {
+	return domain.NewExchangeRate(domain.NewExchangeRateData{
+		Currency:  rate.Currency,
+		Rate:      rate.Rate,
+		UpdatedAt: rate.UpdatedAt,
+	})
+}
+
+func domainToPromotion(promotion domain.Promotion) models.Promotion {
+	return models.Promotion{
+		ID:             promotion.ID(),
+		Code:           promotion.Code(),
+		Description:    promotion.Description(),
+		Kind:           promotion.Kind(),
+		Percent:        promotion.Percent(),
+		Amount:         promotion.Amount().Amount(),
+		MinSubtotal:    promotion.MinSubtotal().Amount(),
+		Currency:       promotion.MinSubtotal().Currency(),
+		Scope:          promotion.Scope(),
+		CategoryID:     promotion.CategoryID(),
+		BookIDs:        promotion.BookIDs(),
+		StartsAt:       promotion.StartsAt(),
+		EndsAt:         promotion.EndsAt(),
+		MaxUses:        promotion.MaxUses(),
+		MaxUsesPerUser: promotion.MaxUsesPerUser(),
+		Active:         promotion.Active(),
+	}
+}
+
+func promotionToDomain(promotion models.Promotion) (domain.Promotion, error) {
+	var amount domain.Money
+	if promotion.Kind == domain.PromotionFixed {
+		var err error
+		amount, err = domain.NewMoney(promotion.Amount, promotion.Currency)
+		if err != nil {
+			return domain.Promotion{}, fmt.Errorf("failed to create promotion amount: %w", err)
+		}
+	}
+	minSubtotal, err := domain.NewMoney(promotion.MinSubtotal, promotion.Currency)
+	if err != nil {
+		return domain.Promotion{}, fmt.Errorf("failed to create promotion minimum subtotal: %w", err)
+	}
+
+	return domain.NewPromotion(domain.NewPromotionData{
+		ID:             promotion.ID,
+		Code:           promotion.Code,
+		Description:    promotion.Description,
+		Kind:           promotion.Kind,
+		Percent:        promotion.Percent,
+		Amount:         amount,
+		MinSubtotal:    minSubtotal,
+		Scope:          promotion.Scope,
+		CategoryID:     promotion.CategoryID,
+		BookIDs:        promotion.BookIDs,
+		StartsAt:       promotion.StartsAt,
+		EndsAt:         promotion.EndsAt,
+		MaxUses:        promotion.MaxUses,
+		MaxUsesPerUser: promotion.MaxUsesPerUser,
+		Active:         promotion.Active,
+	})
+}
+
+func domainToTaxRate(rate domain.TaxRate) models.TaxRate {
+	return models.TaxRate{
+		ID:        rate.ID(),
+		Country:   rate.Country(),
+		State:     rate.State(),
+		TaxClass:  rate.TaxClass(),
+		Rate:      rate.Rate(),
+		Name:      rate.Name(),
+		UpdatedAt: rate.UpdatedAt(),
+	}
+}
+
+func taxRateToDomain(rate models.TaxRate) (domain.TaxRate, error) {
+	return domain.NewTaxRate(domain.NewTaxRateData{
+		ID:        rate.ID,
+		Country:   rate.Country,
+		State:     rate.State,
+		TaxClass:  rate.TaxClass,
+		Rate:      rate.Rate,
+		Name:      rate.Name,
+		UpdatedAt: rate.UpdatedAt,
+	})
+}
+
+func domainToAddress(address domain.Address) models.Address {
+	return models.Address{
+		ID:         address.ID(),
+		UserID:     address.UserID(),
+		Name:       address.Name(),
+		Line1:      address.Line1(),
+		Line2:      address.Line2(),
+		City:       address.City(),
+		State:      address.State(),
+		PostalCode: address.PostalCode(),
+		Country:    address.Country(),
+		Phone:      address.Phone(),
+		CreatedAt:  address.CreatedAt(),
+	}
+}
+
+func addressToDomain(address models.Address) (domain.Address, error) {
+	return domain.NewAddress(domain.NewAddressData{
+		ID:         address.ID,
+		UserID:     address.UserID,
+		Name:       address.Name,
+		Line1:      address.Line1,
+		Line2:      address.Line2,
+		City:       address.City,
+		State:      address.State,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+		Phone:      address.Phone,
+		CreatedAt:  address.CreatedAt,
+	})
+}
+
+func domainToShippingRate(rate domain.ShippingRate) models.ShippingRate {
+	return models.ShippingRate{
+		ID:            rate.ID(),
+		Method:        rate.Method(),
+		Name:          rate.Name(),
+		Country:       rate.Country(),
+		BaseAmount:    rate.BaseAmount().Amount(),
+		PerItemAmount: rate.PerItemAmount().Amount(),
+		PerKgAmount:   rate.PerKgAmount().Amount(),
+		Currency:      rate.BaseAmount().Currency(),
+		UpdatedAt:     rate.UpdatedAt(),
+	}
+}
+
+func shippingRateToDomain(rate models.ShippingRate) (domain.ShippingRate, error) {
+	amounts := make([]domain.Money, 3)
+	for i, amount := range []int64{rate.BaseAmount, rate.PerItemAmount, rate.PerKgAmount} {
+		var err error
+		amounts[i], err = domain.NewMoney(amount, rate.Currency)
+		if err != nil {
+			return domain.ShippingRate{}, fmt.Errorf("failed to create shipping rate amount: %w", err)
+		}
+	}
+
+	return domain.NewShippingRate(domain.NewShippingRateData{
+		ID:            rate.ID,
+		Method:        rate.Method,
+		Name:          rate.Name,
+		Country:       rate.Country,
+		BaseAmount:    amounts[0],
+		PerItemAmount: amounts[1],
+		PerKgAmount:   amounts[2],
+		UpdatedAt:     rate.UpdatedAt,
+	})
+}
+
+func domainToInventoryMovement(movement domain.InventoryMovement) models.InventoryMovement {
+	return models.InventoryMovement{
+		ID:         movement.ID(),
+		BookID:     movement.BookID(),
+		Type:       movement.Type(),
+		Delta:      movement.Delta(),
+		StockAfter: movement.StockAfter(),
+		Reason:     movement.Reason(),
+		OrderID:    movement.OrderID(),
+		UserID:     movement.UserID(),
+		CreatedAt:  movement.CreatedAt(),
+	}
+}
+
+func inventoryMovementToDomain(movement models.InventoryMovement) (domain.InventoryMovement, error) {
+	return domain.NewInventoryMovement(domain.NewInventoryMovementData{
+		ID:         movement.ID,
+		BookID:     movement.BookID,
+		Type:       movement.Type,
+		Delta:      movement.Delta,
+		StockAfter: movement.StockAfter,
+		Reason:     movement.Reason,
+		OrderID:    movement.OrderID,
+		UserID:     movement.UserID,
+		CreatedAt:  movement.CreatedAt,
+	})
+}
+
+func stockSubscriptionToDomain(subscription models.StockSubscription) (domain.StockSubscription, error) {
+	return domain.NewStockSubscription(domain.NewStockSubscriptionData{
+		UserID:     subscription.UserID,
+		BookID:     subscription.BookID,
+		CreatedAt:  subscription.CreatedAt,
+		QueuedAt:   subscription.QueuedAt,
+		NotifiedAt: subscription.NotifiedAt,
+	})
+}
+
+func domainToReview(review domain.Review) models.Review {
+	return models.Review{
+		ID:          review.ID(),
+		BookID:      review.BookID(),
+		UserID:      review.UserID(),
+		Rating:      review.Rating(),
+		Body:        review.Body(),
+		Status:      review.Status(),
+		Reason:      review.Reason(),
+		ModeratedBy: review.ModeratedBy(),
+		ModeratedAt: review.ModeratedAt(),
+		CreatedAt:   review.CreatedAt(),
 	}
 }
 
-func cartToDomain(cart models.Cart) (domain.Cart, error) {
-	return domain.NewCart(domain.NewCartData{
-		UserID:  cart.UserID,
-		BookIDs: cart.BookIDs,
+func reviewToDomain(review models.Review) (domain.Review, error) {
+	return domain.NewReview(domain.NewReviewData{
+		ID:          review.ID,
+		BookID:      review.BookID,
+		UserID:      review.UserID,
+		Rating:      review.Rating,
+		Body:        review.Body,
+		Status:      review.Status,
+		Reason:      review.Reason,
+		ModeratedBy: review.ModeratedBy,
+		ModeratedAt: review.ModeratedAt,
+		CreatedAt:   review.CreatedAt,
 	})
 }
@@ -0,0 +1,275 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/app/repository/models"
+	"github.com/northwindman/book-shop/internal/pkg/pg"
+	"github.com/uptrace/bun"
+)
+
+type WebhookRepo struct {
+	db *pg.DB
+}
+
+func NewWebhookRepo(db *pg.DB) *WebhookRepo {
+	return &WebhookRepo{
+		db: db,
+	}
+}
+
+func (r WebhookRepo) GetSubscription(ctx context.Context, id int) (domain.WebhookSubscription, error) {
+	if id == 0 {
+		return domain.WebhookSubscription{}, fmt.Errorf("%w: id", domain.ErrRequired)
+	}
+
+	var subscription models.WebhookSubscription
+	err := r.db.NewSelect().Model(&subscription).Where("id = ?", id).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.WebhookSubscription{}, domain.ErrNotFound
+		}
+		return domain.WebhookSubscription{}, fmt.Errorf("failed to get a webhook subscription: %w", err)
+	}
+
+	domainSubscription, err := webhookSubscriptionToDomain(subscription)
+	if err != nil {
+		return domain.WebhookSubscription{}, fmt.Errorf("failed to create domain webhook subscription: %w", err)
+	}
+
+	return domainSubscription, nil
+}
+
+func (r WebhookRepo) GetSubscriptions(ctx context.Context, activeOnly bool) ([]domain.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	query := r.db.NewSelect().Model(&subscriptions)
+	if activeOnly {
+		query.Where("active")
+	}
+	err := query.Order("id").Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select webhook subscriptions: %w", err)
+	}
+
+	domainSubscriptions := make([]domain.WebhookSubscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		domainSubscription, err := webhookSubscriptionToDomain(subscription)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create domain webhook subscription: %w", err)
+		}
+		domainSubscriptions = append(domainSubscriptions, domainSubscription)
+	}
+
+	return domainSubscriptions, nil
+}
+
+func (r WebhookRepo) CreateSubscription(ctx context.Context, subscription domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	dbSubscription := domainToWebhookSubscription(subscription)
+
+	var insertedSubscription models.WebhookSubscription
+	err := r.db.NewInsert().Model(&dbSubscription).Returning("*").Scan(ctx, &insertedSubscription)
+	if err != nil {
+		return domain.WebhookSubscription{}, fmt.Errorf("failed to insert a webhook subscription: %w", err)
+	}
+
+	domainSubscription, err := webhookSubscriptionToDomain(insertedSubscription)
+	if err != nil {
+		return domain.WebhookSubscription{}, fmt.Errorf("failed to create domain webhook subscription: %w", err)
+	}
+
+	return domainSubscription, nil
+}
+
+func (r WebhookRepo) UpdateSubscription(ctx context.Context, subscription domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	dbSubscription := domainToWebhookSubscription(subscription)
+	dbSubscription.UpdatedAt = time.Now()
+
+	var updatedSubscription models.WebhookSubscription
+	err := r.db.NewUpdate().
+		Model(&dbSubscription).
+		Where("id = ?", dbSubscription.ID).
+		ExcludeColumn("created_at").
+		Returning("*").
+		Scan(ctx, &updatedSubscription)
+	if err != nil {
+		return domain.WebhookSubscription{}, fmt.Errorf("failed to update a webhook subscription: %w", err)
+	}
+
+	domainSubscription, err := webhookSubscriptionToDomain(updatedSubscription)
+	if err != nil {
+		return domain.WebhookSubscription{}, fmt.Errorf("failed to create domain webhook subscription: %w", err)
+	}
+
+	return domainSubscription, nil
+}
+
+func (r WebhookRepo) DeleteSubscription(ctx context.Context, id int) error {
+	if id == 0 {
+		return fmt.Errorf("%w: id", domain.ErrRequired)
+	}
+
+	_, err := r.db.NewDelete().Model((*models.WebhookSubscription)(nil)).Where("id = ?", id).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete a webhook subscription: %w", err)
+	}
+
+	return nil
+}
+
+// EnqueueDeliveries stores pending deliveries, deliveries already enqueued for the same event are skipped
+func (r WebhookRepo) EnqueueDeliveries(ctx context.Context, deliveries []domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	dbDeliveries := make([]models.WebhookDelivery, len(deliveries))
+	for i, delivery := range deliveries {
+		dbDeliveries[i] = domainToWebhookDelivery(delivery)
+		dbDeliveries[i].NextAttemptAt = time.Now()
+	}
+
+	_, err := r.db.NewInsert().
+		Model(&dbDeliveries).
+		On("CONFLICT (subscription_id, event_id) DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+
+	return nil
+}
+
+// GetDueDeliveries returns pending deliveries of active subscriptions whose next attempt is due
+func (r WebhookRepo) GetDueDeliveries(ctx context.Context, limit int) ([]domain.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.NewSelect().
+		Model(&deliveries).
+		Where("status = ?", domain.WebhookDeliveryPending).
+		Where("next_attempt_at <= ?", time.Now()).
+		Where("subscription_id IN (SELECT id FROM webhook_subscriptions WHERE active)").
+		Order("next_attempt_at", "id").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select due webhook deliveries: %w", err)
+	}
+
+	return webhookDeliveriesToDomain(deliveries, nil)
+}
+
+// RecordDeliveryAttempt stores an attempt together with the resulting delivery state
+func (r WebhookRepo) RecordDeliveryAttempt(ctx context.Context, delivery domain.WebhookDelivery, attempt domain.WebhookAttempt) error {
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		dbAttempt := domainToWebhookAttempt(attempt)
+		_, err := tx.NewInsert().Model(&dbAttempt).Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to insert webhook delivery attempt: %w", err)
+		}
+
+		dbDelivery := domainToWebhookDelivery(delivery)
+		dbDelivery.UpdatedAt = time.Now()
+		_, err = tx.NewUpdate().
+			Model(&dbDelivery).
+			Column("status", "attempts", "next_attempt_at", "last_status_code", "last_error", "updated_at").
+			WherePK().
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to update webhook delivery: %w", err)
+		}
+
+		return nil
+	}, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery attempt: %w", err)
+	}
+
+	return nil
+}
+
+// GetDeliveries returns the latest deliveries of a subscription together with their attempts
+func (r WebhookRepo) GetDeliveries(ctx context.Context, subscriptionID int, limit, offset int) ([]domain.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	query := r.db.NewSelect().
+		Model(&deliveries).
+		Where("subscription_id = ?", subscriptionID).
+		Order("id DESC")
+	if limit > 0 {
+		query.Limit(limit)
+	}
+	if offset > 0 {
+		query.Offset(offset)
+	}
+	if err := query.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("failed to select webhook deliveries: %w", err)
+	}
+	if len(deliveries) == 0 {
+		return []domain.WebhookDelivery{}, nil
+	}
+
+	deliveryIDs := make([]int64, len(deliveries))
+	for i, delivery := range deliveries {
+		deliveryIDs[i] = delivery.ID
+	}
+
+	var attempts []models.WebhookDeliveryAttempt
+	err := r.db.NewSelect().
+		Model(&attempts).
+		Where("delivery_id IN (?)", bun.In(deliveryIDs)).
+		Order("id").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select webhook delivery attempts: %w", err)
+	}
+
+	return webhookDeliveriesToDomain(deliveries, attempts)
+}
+
+// RetryDelivery moves a dead delivery of a subscription back to pending with a fresh attempt budget
+func (r WebhookRepo) RetryDelivery(ctx context.Context, subscriptionID int, deliveryID int64) error {
+	res, err := r.db.NewUpdate().
+		Model((*models.WebhookDelivery)(nil)).
+		Set("status = ?", domain.WebhookDeliveryPending).
+		Set("attempts = 0").
+		Set("next_attempt_at = ?", time.Now()).
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", deliveryID).
+		Where("subscription_id = ?", subscriptionID).
+		Where("status = ?", domain.WebhookDeliveryDead).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retry webhook delivery: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to retry webhook delivery: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func webhookDeliveriesToDomain(deliveries []models.WebhookDelivery, attempts []models.WebhookDeliveryAttempt) ([]domain.WebhookDelivery, error) {
+	attemptLogs := map[int64][]domain.WebhookAttempt{}
+	for _, attempt := range attempts {
+		attemptLogs[attempt.DeliveryID] = append(attemptLogs[attempt.DeliveryID], webhookAttemptToDomain(attempt))
+	}
+
+	domainDeliveries := make([]domain.WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		domainDelivery, err := webhookDeliveryToDomain(delivery, attemptLogs[delivery.ID])
+		if err != nil {
+			return nil, fmt.Errorf("failed to create domain webhook delivery: %w", err)
+		}
+		domainDeliveries = append(domainDeliveries, domainDelivery)
+	}
+
+	return domainDeliveries, nil
+}
@@ -0,0 +1,177 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/northwindman/book-shop/internal/app/common/slugerrors"
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/app/repository/models"
+	"github.com/northwindman/book-shop/internal/pkg/pg"
+	"github.com/uptrace/bun"
+)
+
+type WishlistRepo struct {
+	db *pg.DB
+}
+
+func NewWishlistRepo(db *pg.DB) *WishlistRepo {
+	return &WishlistRepo{
+		db: db,
+	}
+}
+
+// GetWishlist returns the wishlist of a user, a user without one has an empty wishlist
+func (r WishlistRepo) GetWishlist(ctx context.Context, userID int) (domain.Wishlist, error) {
+	wishlist := models.Wishlist{UserID: userID}
+	err := r.db.NewSelect().Model(&wishlist).WherePK().Scan(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return domain.Wishlist{}, fmt.Errorf("failed to get wishlist: %w", err)
+	}
+
+	return r.wishlistToDomain(ctx, wishlist)
+}
+
+// GetSharedWishlist returns the wishlist shared with a token
+func (r WishlistRepo) GetSharedWishlist(ctx context.Context, token string) (domain.Wishlist, error) {
+	var wishlist models.Wishlist
+	err := r.db.NewSelect().Model(&wishlist).Where("share_token = ?", token).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Wishlist{}, domain.ErrNotFound
+		}
+		return domain.Wishlist{}, fmt.Errorf("failed to get shared wishlist: %w", err)
+	}
+
+	return r.wishlistToDomain(ctx, wishlist)
+}
+
+type wishlistItemRow struct {
+	models.Book `bun:",extend"`
+	AddedAt     time.Time
+}
+
+func (r WishlistRepo) wishlistToDomain(ctx context.Context, wishlist models.Wishlist) (domain.Wishlist, error) {
+	var rows []wishlistItemRow
+	err := r.db.NewSelect().
+		TableExpr("wishlist_items AS wi").
+		ColumnExpr("b.*, wi.added_at").
+		Join("JOIN books AS b ON b.id = wi.book_id").
+		Where("wi.user_id = ?", wishlist.UserID).
+		Order("wi.added_at", "wi.book_id").
+		Scan(ctx, &rows)
+	if err != nil {
+		return domain.Wishlist{}, fmt.Errorf("failed to get wishlist items: %w", err)
+	}
+
+	books := make([]models.Book, len(rows))
+	for i, row := range rows {
+		books[i] = row.Book
+	}
+	domainBooks, err := booksToDomain(ctx, r.db, books)
+	if err != nil {
+		return domain.Wishlist{}, err
+	}
+
+	items := make([]domain.WishlistItem, len(rows))
+	for i, row := range rows {
+		items[i] = domain.WishlistItem{Book: domainBooks[i], AddedAt: row.AddedAt}
+	}
+
+	domainWishlist, err := domain.NewWishlist(domain.NewWishlistData{
+		UserID:     wishlist.UserID,
+		Items:      items,
+		ShareToken: wishlist.ShareToken,
+	})
+	if err != nil {
+		return domain.Wishlist{}, fmt.Errorf("failed to create domain wishlist: %w", err)
+	}
+
+	return domainWishlist, nil
+}
+
+// AddItem adds a book to the wishlist of a user, books already on it keep their place
+func (r WishlistRepo) AddItem(ctx context.Context, userID, bookID int) error {
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		exists, err := tx.NewSelect().Model((*models.Book)(nil)).Where("id = ?", bookID).Exists(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get book: %w", err)
+		}
+		if !exists {
+			return domain.ErrNotFound
+		}
+
+		wishlist := models.Wishlist{UserID: userID, UpdatedAt: time.Now()}
+		_, err = tx.NewInsert().Model(&wishlist).
+			On("CONFLICT (user_id) DO UPDATE").
+			Set("updated_at = EXCLUDED.updated_at").
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to insert wishlist: %w", err)
+		}
+
+		// the wishlist row is locked by the upsert, concurrent additions can't exceed the limit
+		count, err := tx.NewSelect().Model((*models.WishlistItem)(nil)).Where("user_id = ? AND book_id <> ?", userID, bookID).Count(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to count wishlist items: %w", err)
+		}
+		if count >= domain.MaxWishlistItems {
+			return slugerrors.NewBadRequestError(
+				fmt.Sprintf("%s: at most %d books", domain.ErrWishlistFull, domain.MaxWishlistItems), "wishlist-full")
+		}
+
+		_, err = tx.NewInsert().Model(&models.WishlistItem{UserID: userID, BookID: bookID, AddedAt: time.Now()}).
+			On("CONFLICT (user_id, book_id) DO NOTHING").
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to insert wishlist item: %w", err)
+		}
+
+		return nil
+	}, r.db)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to add wishlist item: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveItems removes books from the wishlist of a user and reports how many were removed
+func (r WishlistRepo) RemoveItems(ctx context.Context, userID int, bookIDs []int) (int, error) {
+	if len(bookIDs) == 0 {
+		return 0, nil
+	}
+
+	res, err := r.db.NewDelete().Model((*models.WishlistItem)(nil)).Where("user_id = ? AND book_id IN (?)", userID, bun.In(bookIDs)).Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete wishlist items: %w", err)
+	}
+
+	removed, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get deleted wishlist items: %w", err)
+	}
+
+	return int(removed), nil
+}
+
+// SetShareToken sets the token of the public link to the wishlist of a user, an empty token stops sharing it
+func (r WishlistRepo) SetShareToken(ctx context.Context, userID int, token string) error {
+	wishlist := models.Wishlist{UserID: userID, ShareToken: token, UpdatedAt: time.Now()}
+	_, err := r.db.NewInsert().Model(&wishlist).
+		On("CONFLICT (user_id) DO UPDATE").
+		Set("share_token = EXCLUDED.share_token").
+		Set("updated_at = EXCLUDED.updated_at").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to set wishlist share token: %w", err)
+	}
+
+	return nil
+}
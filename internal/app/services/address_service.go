@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// AddressService manages the address books of users
+type AddressService struct {
+	repo AddressRepository
+}
+
+// NewAddressService creates a new address service
+func NewAddressService(repo AddressRepository) AddressService {
+	return AddressService{
+		repo: repo,
+	}
+}
+
+// GetAddress returns an address of a user, addresses of other users are not found
+func (s AddressService) GetAddress(ctx context.Context, userID, id int) (domain.Address, error) {
+	return s.repo.GetAddress(ctx, userID, id)
+}
+
+func (s AddressService) GetAddresses(ctx context.Context, userID int) ([]domain.Address, error) {
+	return s.repo.GetAddresses(ctx, userID)
+}
+
+func (s AddressService) CreateAddress(ctx context.Context, address domain.Address) (domain.Address, error) {
+	return s.repo.CreateAddress(ctx, address)
+}
+
+func (s AddressService) UpdateAddress(ctx context.Context, address domain.Address) (domain.Address, error) {
+	return s.repo.UpdateAddress(ctx, address)
+}
+
+func (s AddressService) DeleteAddress(ctx context.Context, userID, id int) error {
+	return s.repo.DeleteAddress(ctx, userID, id)
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// AuthorService manages the authors credited on books
+type AuthorService struct {
+	repo AuthorRepository
+}
+
+// NewAuthorService creates a new author service
+func NewAuthorService(repo AuthorRepository) AuthorService {
+	return AuthorService{
+		repo: repo,
+	}
+}
+
+func (s AuthorService) GetAuthor(ctx context.Context, id int) (domain.Author, error) {
+	return s.repo.GetAuthor(ctx, id)
+}
+
+// GetAuthors returns the authors ordered by name
+func (s AuthorService) GetAuthors(ctx context.Context) ([]domain.Author, error) {
+	return s.repo.GetAuthors(ctx)
+}
+
+// GetAuthorBooks returns the books credited to an author, oldest first
+func (s AuthorService) GetAuthorBooks(ctx context.Context, id int) ([]domain.AuthorBook, error) {
+	return s.repo.GetAuthorBooks(ctx, id)
+}
+
+func (s AuthorService) CreateAuthor(ctx context.Context, author domain.Author) (domain.Author, error) {
+	return s.repo.CreateAuthor(ctx, author)
+}
+
+// UpdateAuthor updates the given fields of an author if it is still at the version of the given author
+func (s AuthorService) UpdateAuthor(ctx context.Context, author domain.Author, fields []string) (domain.Author, error) {
+	return s.repo.UpdateAuthor(ctx, author, fields)
+}
+
+// DeleteAuthor deletes an author credited on no book if it is still at the given version
+func (s AuthorService) DeleteAuthor(ctx context.Context, id, version int) error {
+	return s.repo.DeleteAuthor(ctx, id, version)
+}
@@ -22,18 +22,31 @@ func (s BookService) GetBook(ctx context.Context, id int) (domain.Book, error) {
 	return s.repo.GetBook(ctx, id)
 }
 
+// GetBookByISBN returns the book with an ISBN-10 or ISBN-13, domain.ErrInvalidISBN when it is not an ISBN
+func (s BookService) GetBookByISBN(ctx context.Context, isbn string) (domain.Book, error) {
+	isbn, err := domain.NormalizeISBN(isbn)
+	if err != nil {
+		return domain.Book{}, err
+	}
+	return s.repo.GetBookByISBN(ctx, isbn)
+}
+
 func (s BookService) CreateBook(ctx context.Context, book domain.Book) (domain.Book, error) {
 	return s.repo.CreateBook(ctx, book)
 }
 
-func (s BookService) UpdateBook(ctx context.Context, book domain.Book) (domain.Book, error) {
-	return s.repo.UpdateBook(ctx, book)
+// UpdateBook updates the given fields of a book if it is still at the version of the given book
+func (s BookService) UpdateBook(ctx context.Context, book domain.Book, fields []string) (domain.Book, error) {
+	return s.repo.UpdateBook(ctx, book, fields)
 }
 
-func (s BookService) DeleteBook(ctx context.Context, id int) error {
-	return s.repo.DeleteBook(ctx, id)
+// DeleteBook deletes a book if it is still at the given version
+func (s BookService) DeleteBook(ctx context.Context, id, version int) error {
+	return s.repo.DeleteBook(ctx, id, version)
 }
 
-func (s BookService) GetBooks(ctx context.Context, categoryIDs []int, limit, offset int) ([]domain.Book, error) {
-	return s.repo.GetBooks(ctx, categoryIDs, limit, offset)
+// GetBooks returns a page of the books on sale in an order of domain.BookSorts, in the given categories or their
+// descendants too with descendants
+func (s BookService) GetBooks(ctx context.Context, categoryIDs []int, descendants bool, sort string, limit, offset int) ([]domain.Book, error) {
+	return s.repo.GetBooks(ctx, categoryIDs, descendants, sort, limit, offset)
 }
@@ -2,21 +2,35 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/northwindman/book-shop/internal/app/common/slugerrors"
 	"github.com/northwindman/book-shop/internal/app/domain"
 )
 
 // CartService is a cart service
 type CartService struct {
-	cartRepo CartRepository
+	cartRepo         CartRepository
+	bookRepo         BookRepository
+	promotionRepo    PromotionRepository
+	addressRepo      AddressRepository
+	shippingRateRepo ShippingRateRepository
+	taxCalculator    TaxCalculator
 }
 
 // NewCartService creates a new cart service
-func NewCartService(repo CartRepository) CartService {
+func NewCartService(repo CartRepository, bookRepo BookRepository, promotionRepo PromotionRepository,
+	addressRepo AddressRepository, shippingRateRepo ShippingRateRepository, taxCalculator TaxCalculator) CartService {
 	return CartService{
-		cartRepo: repo,
+		cartRepo:         repo,
+		bookRepo:         bookRepo,
+		promotionRepo:    promotionRepo,
+		addressRepo:      addressRepo,
+		shippingRateRepo: shippingRateRepo,
+		taxCalculator:    taxCalculator,
 	}
 }
 
@@ -36,7 +50,266 @@ func (s CartService) UpdateCartAndStocks(ctx context.Context, cart domain.Cart)
 	return updatedCart, nil
 }
 
-// Checkout does nothing but cleans up the cart as per the spec
-func (s CartService) Checkout(ctx context.Context, userID int) error {
-	return s.cartRepo.DeleteCart(ctx, userID)
+// GetCart returns the cart of a user priced at the current book prices and taxed for a region, a missing cart is empty
+func (s CartService) GetCart(ctx context.Context, userID int, region domain.TaxRegion) (domain.Cart, domain.Quote, error) {
+	cart, err := s.cartRepo.GetCart(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, domain.ErrNotFound) {
+			return domain.Cart{}, domain.Quote{}, fmt.Errorf("failed to get cart: %w", err)
+		}
+		cart, err = domain.NewCart(domain.NewCartData{UserID: userID})
+		if err != nil {
+			return domain.Cart{}, domain.Quote{}, fmt.Errorf("failed to create empty cart: %w", err)
+		}
+	}
+
+	quote, err := s.Quote(ctx, cart, region)
+	if err != nil {
+		return domain.Cart{}, domain.Quote{}, err
+	}
+
+	return cart, quote, nil
+}
+
+// ApplyCoupon applies a coupon code to the cart of a user, coupons that don't apply to the cart are rejected
+func (s CartService) ApplyCoupon(ctx context.Context, userID int, code string, region domain.TaxRegion) (domain.Cart, domain.Quote, error) {
+	cart, err := s.cartRepo.GetCart(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Cart{}, domain.Quote{}, slugerrors.NewBadRequestError("cart is empty", "empty-cart")
+		}
+		return domain.Cart{}, domain.Quote{}, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	cart, err = domain.NewCart(domain.NewCartData{
+		UserID:          cart.UserID(),
+		BookIDs:         cart.BookIDs(),
+		PreorderBookIDs: cart.PreorderBookIDs(),
+		CouponCode:      code,
+	})
+	if err != nil {
+		return domain.Cart{}, domain.Quote{}, fmt.Errorf("failed to create domain cart: %w", err)
+	}
+	if cart.CouponCode() == "" {
+		return domain.Cart{}, domain.Quote{}, slugerrors.NewBadRequestError("coupon code is required", "invalid-coupon")
+	}
+
+	quote, err := s.Quote(ctx, cart, region)
+	if err != nil {
+		return domain.Cart{}, domain.Quote{}, err
+	}
+	if err := quote.CouponError(); err != nil {
+		return domain.Cart{}, domain.Quote{}, err
+	}
+
+	if err := s.cartRepo.SetCoupon(ctx, userID, cart.CouponCode()); err != nil {
+		return domain.Cart{}, domain.Quote{}, fmt.Errorf("failed to apply coupon: %w", err)
+	}
+
+	return cart, quote, nil
+}
+
+// RemoveCoupon removes the coupon from the cart of a user
+func (s CartService) RemoveCoupon(ctx context.Context, userID int, region domain.TaxRegion) (domain.Cart, domain.Quote, error) {
+	err := s.cartRepo.SetCoupon(ctx, userID, "")
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return domain.Cart{}, domain.Quote{}, fmt.Errorf("failed to remove coupon: %w", err)
+	}
+
+	return s.GetCart(ctx, userID, region)
+}
+
+// Quote prices the books of a cart in cart order, applies the automatic promotions and the coupon of the cart
+// and adds the sales tax of the region the books are shipped to.
+// A coupon that no longer applies does not fail the quote, the reason is reported by the quote instead.
+func (s CartService) Quote(ctx context.Context, cart domain.Cart, region domain.TaxRegion) (domain.Quote, error) {
+	quote, err := s.discountedQuote(ctx, cart)
+	if err != nil {
+		return domain.Quote{}, err
+	}
+
+	return s.withTaxes(ctx, quote, region)
+}
+
+// ShippingOptions returns the shipping methods available to a region priced for the cart of a user, cheapest first
+func (s CartService) ShippingOptions(ctx context.Context, userID int, region domain.TaxRegion) ([]domain.ShippingOption, error) {
+	cart, err := s.cartRepo.GetCart(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, slugerrors.NewBadRequestError("cart is empty", "empty-cart")
+		}
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	quote, err := s.discountedQuote(ctx, cart)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := s.shippingRateRepo.GetRates(ctx, region.Country)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shipping rates: %w", err)
+	}
+
+	return domain.ShippingTable(rates).Options(region.Country, quote), nil
+}
+
+func (s CartService) withTaxes(ctx context.Context, quote domain.Quote, region domain.TaxRegion) (domain.Quote, error) {
+	taxes, err := s.taxCalculator.Calculate(ctx, region, quote)
+	if err != nil {
+		return domain.Quote{}, fmt.Errorf("failed to calculate taxes: %w", err)
+	}
+
+	return quote.WithTaxes(region, taxes)
+}
+
+func (s CartService) discountedQuote(ctx context.Context, cart domain.Cart) (domain.Quote, error) {
+	books, err := s.bookRepo.GetBooksByIDs(ctx, cart.BookIDs())
+	if err != nil {
+		return domain.Quote{}, fmt.Errorf("failed to get cart books: %w", err)
+	}
+
+	booksByID := make(map[int]domain.Book, len(books))
+	for _, book := range books {
+		booksByID[book.ID()] = book
+	}
+
+	cartBooks := make([]domain.Book, 0, len(cart.BookIDs()))
+	for _, bookID := range cart.BookIDs() {
+		book, ok := booksByID[bookID]
+		if !ok {
+			return domain.Quote{}, slugerrors.NewBadRequestError(fmt.Sprintf("book %d is no longer available", bookID), "book-unavailable")
+		}
+		cartBooks = append(cartBooks, book)
+	}
+
+	quote, err := domain.QuoteFromBooks(cartBooks)
+	if err != nil {
+		return domain.Quote{}, fmt.Errorf("failed to quote cart: %w", err)
+	}
+	quote = quote.WithPreorders(cart.PreorderBookIDs())
+	if !cart.HasBooks() {
+		return quote, nil
+	}
+
+	now := time.Now()
+
+	automaticPromotions, err := s.promotionRepo.GetPromotions(ctx, true)
+	if err != nil {
+		return domain.Quote{}, fmt.Errorf("failed to get automatic promotions: %w", err)
+	}
+	for _, promotion := range automaticPromotions {
+		discounted, err := s.applyPromotion(ctx, quote, promotion, cart.UserID(), now)
+		if err != nil {
+			if isPromotionRejection(err) {
+				// automatic promotions silently apply only to the carts they match
+				continue
+			}
+			return domain.Quote{}, err
+		}
+		quote = discounted
+	}
+
+	if cart.CouponCode() == "" {
+		return quote, nil
+	}
+
+	promotion, err := s.promotionRepo.GetPromotionByCode(ctx, cart.CouponCode())
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return quote.WithCouponError(slugerrors.NewBadRequestError(
+				fmt.Sprintf("coupon %q does not exist", cart.CouponCode()), "invalid-coupon")), nil
+		}
+		return domain.Quote{}, fmt.Errorf("failed to get coupon promotion: %w", err)
+	}
+
+	discounted, err := s.applyPromotion(ctx, quote, promotion, cart.UserID(), now)
+	if err != nil {
+		if isPromotionRejection(err) {
+			return quote.WithCouponError(slugerrors.NewBadRequestError(
+				fmt.Sprintf("coupon %q: %s", cart.CouponCode(), err), "coupon-not-applicable")), nil
+		}
+		return domain.Quote{}, err
+	}
+
+	return discounted, nil
+}
+
+func (s CartService) applyPromotion(ctx context.Context, quote domain.Quote, promotion domain.Promotion, userID int, now time.Time) (domain.Quote, error) {
+	var usage domain.PromotionUsage
+	if promotion.MaxUses() > 0 || promotion.MaxUsesPerUser() > 0 {
+		var err error
+		usage, err = s.promotionRepo.GetUsage(ctx, promotion.ID(), userID)
+		if err != nil {
+			return domain.Quote{}, fmt.Errorf("failed to get promotion usage: %w", err)
+		}
+	}
+
+	if err := promotion.CheckRedeemable(now, usage); err != nil {
+		return domain.Quote{}, err
+	}
+
+	return quote.WithPromotion(promotion)
+}
+
+func isPromotionRejection(err error) bool {
+	return errors.Is(err, domain.ErrPromotionNotActive) ||
+		errors.Is(err, domain.ErrPromotionExhausted) ||
+		errors.Is(err, domain.ErrPromotionNotApplicable)
+}
+
+// Checkout places an order for the cart at the current book prices shipped to an address of the user with a shipping method
+// and cleans up the cart as per the spec. Books are taxed in the region of the address.
+func (s CartService) Checkout(ctx context.Context, userID, addressID int, shippingMethod string) (domain.Order, error) {
+	address, err := s.addressRepo.GetAddress(ctx, userID, addressID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Order{}, slugerrors.NewBadRequestError(fmt.Sprintf("address %d does not exist", addressID), "unknown-address")
+		}
+		return domain.Order{}, fmt.Errorf("failed to get address: %w", err)
+	}
+
+	cart, err := s.cartRepo.GetCart(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Order{}, slugerrors.NewBadRequestError("cart is empty", "empty-cart")
+		}
+		return domain.Order{}, fmt.Errorf("failed to get cart: %w", err)
+	}
+	if !cart.HasBooks() {
+		return domain.Order{}, slugerrors.NewBadRequestError("cart is empty", "empty-cart")
+	}
+
+	quote, err := s.discountedQuote(ctx, cart)
+	if err != nil {
+		return domain.Order{}, err
+	}
+	if err := quote.CouponError(); err != nil {
+		return domain.Order{}, err
+	}
+
+	rates, err := s.shippingRateRepo.GetRates(ctx, address.Country())
+	if err != nil {
+		return domain.Order{}, fmt.Errorf("failed to get shipping rates: %w", err)
+	}
+	shipping, err := domain.ShippingTable(rates).Option(shippingMethod, address.Country(), quote)
+	if err != nil {
+		return domain.Order{}, slugerrors.NewBadRequestError(err.Error(), "shipping-method-unavailable")
+	}
+
+	quote, err = quote.WithShipping(shipping)
+	if err != nil {
+		return domain.Order{}, fmt.Errorf("failed to add shipping: %w", err)
+	}
+	quote, err = s.withTaxes(ctx, quote, address.TaxRegion())
+	if err != nil {
+		return domain.Order{}, err
+	}
+
+	order, err := domain.NewOrderFromQuote(userID, quote, address)
+	if err != nil {
+		return domain.Order{}, fmt.Errorf("failed to create order: %w", err)
+	}
+
+	return s.cartRepo.CheckoutCart(ctx, cart, order)
 }
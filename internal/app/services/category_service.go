@@ -24,12 +24,13 @@ func (s CategoryService) CreateCategory(ctx context.Context, category domain.Cat
 	return s.repo.CreateCategory(ctx, category)
 }
 
-func (s CategoryService) UpdateCategory(ctx context.Context, category domain.Category) (domain.Category, error) {
-	return s.repo.UpdateCategory(ctx, category)
+// UpdateCategory updates the given fields of a category if it is still at the version of the given category
+func (s CategoryService) UpdateCategory(ctx context.Context, category domain.Category, fields []string) (domain.Category, error) {
+	return s.repo.UpdateCategory(ctx, category, fields)
 }
 
-func (s CategoryService) DeleteCategory(ctx context.Context, id int) error {
-	return s.repo.DeleteCategory(ctx, id)
+func (s CategoryService) DeleteCategory(ctx context.Context, id, version int) error {
+	return s.repo.DeleteCategory(ctx, id, version)
 }
 
 func (s CategoryService) GetCategories(ctx context.Context) ([]domain.Category, error) {
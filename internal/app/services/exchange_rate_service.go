@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/northwindman/book-shop/internal/app/common/slugerrors"
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// ExchangeRateService manages the exchange rates used to display prices in other currencies
+type ExchangeRateService struct {
+	repo ExchangeRateRepository
+}
+
+// NewExchangeRateService creates a new exchange rate service
+func NewExchangeRateService(repo ExchangeRateRepository) ExchangeRateService {
+	return ExchangeRateService{
+		repo: repo,
+	}
+}
+
+// GetRate returns the exchange rate of a display currency, currencies without a rate are rejected
+func (s ExchangeRateService) GetRate(ctx context.Context, currency string) (domain.ExchangeRate, error) {
+	rate, err := s.repo.GetRate(ctx, strings.ToUpper(currency))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ExchangeRate{}, slugerrors.NewBadRequestError(
+				fmt.Sprintf("prices are not available in %q", currency), "unsupported-currency")
+		}
+		return domain.ExchangeRate{}, err
+	}
+
+	return rate, nil
+}
+
+func (s ExchangeRateService) GetRates(ctx context.Context) ([]domain.ExchangeRate, error) {
+	return s.repo.GetRates(ctx)
+}
+
+func (s ExchangeRateService) SetRates(ctx context.Context, rates ...domain.ExchangeRate) ([]domain.ExchangeRate, error) {
+	return s.repo.SetRates(ctx, rates...)
+}
+
+func (s ExchangeRateService) DeleteRate(ctx context.Context, currency string) error {
+	return s.repo.DeleteRate(ctx, strings.ToUpper(currency))
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// IdempotencyService stores the responses of requests sent with idempotency keys
+type IdempotencyService struct {
+	repo IdempotencyRepository
+}
+
+// NewIdempotencyService creates a new idempotency service
+func NewIdempotencyService(repo IdempotencyRepository) IdempotencyService {
+	return IdempotencyService{
+		repo: repo,
+	}
+}
+
+// Begin reserves the key of a request, it returns the stored response and true when the request is a retry
+func (s IdempotencyService) Begin(ctx context.Context, request domain.IdempotentRequest) (domain.IdempotentResponse, bool, error) {
+	return s.repo.Begin(ctx, request)
+}
+
+func (s IdempotencyService) Complete(ctx context.Context, request domain.IdempotentRequest, response domain.IdempotentResponse) error {
+	return s.repo.Complete(ctx, request, response)
+}
+
+func (s IdempotencyService) Release(ctx context.Context, request domain.IdempotentRequest) error {
+	return s.repo.Release(ctx, request)
+}
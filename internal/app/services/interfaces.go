@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"github.com/northwindman/book-shop/internal/app/domain"
 )
@@ -14,23 +15,137 @@ type UserRepository interface {
 
 type BookRepository interface {
 	GetBook(ctx context.Context, id int) (domain.Book, error)
-	GetBooks(ctx context.Context, categoryIDs []int, limit, offset int) ([]domain.Book, error)
+	GetBookByISBN(ctx context.Context, isbn string) (domain.Book, error)
+	GetBooks(ctx context.Context, categoryIDs []int, descendants bool, sort string, limit, offset int) ([]domain.Book, error)
+	GetBooksByIDs(ctx context.Context, ids []int) ([]domain.Book, error)
 	CreateBook(ctx context.Context, book domain.Book) (domain.Book, error)
-	UpdateBook(ctx context.Context, book domain.Book) (domain.Book, error)
-	DeleteBook(ctx context.Context, id int) error
+	UpdateBook(ctx context.Context, book domain.Book, fields []string) (domain.Book, error)
+	DeleteBook(ctx context.Context, id, version int) error
 }
 
 type CategoryRepository interface {
 	GetCategory(ctx context.Context, id int) (domain.Category, error)
 	GetCategories(ctx context.Context) ([]domain.Category, error)
 	CreateCategory(ctx context.Context, category domain.Category) (domain.Category, error)
-	UpdateCategory(ctx context.Context, category domain.Category) (domain.Category, error)
-	DeleteCategory(ctx context.Context, id int) error
+	UpdateCategory(ctx context.Context, category domain.Category, fields []string) (domain.Category, error)
+	DeleteCategory(ctx context.Context, id, version int) error
+}
+
+type AuthorRepository interface {
+	GetAuthor(ctx context.Context, id int) (domain.Author, error)
+	GetAuthors(ctx context.Context) ([]domain.Author, error)
+	GetAuthorBooks(ctx context.Context, id int) ([]domain.AuthorBook, error)
+	CreateAuthor(ctx context.Context, author domain.Author) (domain.Author, error)
+	UpdateAuthor(ctx context.Context, author domain.Author, fields []string) (domain.Author, error)
+	DeleteAuthor(ctx context.Context, id, version int) error
 }
 
 type CartRepository interface {
 	GetCart(ctx context.Context, userID int) (domain.Cart, error)
 	DeleteCart(ctx context.Context, userID int) error
+	CheckoutCart(ctx context.Context, cart domain.Cart, order domain.Order) (domain.Order, error)
 	UpdateCartAndStocks(ctx context.Context, cart domain.Cart) error
 	CheckStocks(ctx context.Context, cart domain.Cart) (bool, error)
+	SetCoupon(ctx context.Context, userID int, code string) error
+}
+
+type PromotionRepository interface {
+	GetPromotion(ctx context.Context, id int) (domain.Promotion, error)
+	GetPromotionByCode(ctx context.Context, code string) (domain.Promotion, error)
+	GetPromotions(ctx context.Context, automaticOnly bool) ([]domain.Promotion, error)
+	CreatePromotion(ctx context.Context, promotion domain.Promotion) (domain.Promotion, error)
+	UpdatePromotion(ctx context.Context, promotion domain.Promotion) (domain.Promotion, error)
+	DeletePromotion(ctx context.Context, id int) error
+	GetUsage(ctx context.Context, promotionID, userID int) (domain.PromotionUsage, error)
+}
+
+type OrderRepository interface {
+	GetOrder(ctx context.Context, id int) (domain.Order, error)
+	GetOrders(ctx context.Context, userID int, limit, offset int) ([]domain.Order, error)
+}
+
+type ExchangeRateRepository interface {
+	GetRate(ctx context.Context, currency string) (domain.ExchangeRate, error)
+	GetRates(ctx context.Context) ([]domain.ExchangeRate, error)
+	SetRates(ctx context.Context, rates ...domain.ExchangeRate) ([]domain.ExchangeRate, error)
+	DeleteRate(ctx context.Context, currency string) error
+}
+
+type TaxRateRepository interface {
+	GetRates(ctx context.Context, country string) ([]domain.TaxRate, error)
+	SetRate(ctx context.Context, rate domain.TaxRate) (domain.TaxRate, error)
+	DeleteRate(ctx context.Context, id int) error
+}
+
+type AddressRepository interface {
+	GetAddress(ctx context.Context, userID, id int) (domain.Address, error)
+	GetAddresses(ctx context.Context, userID int) ([]domain.Address, error)
+	CreateAddress(ctx context.Context, address domain.Address) (domain.Address, error)
+	UpdateAddress(ctx context.Context, address domain.Address) (domain.Address, error)
+	DeleteAddress(ctx context.Context, userID, id int) error
+}
+
+type ShippingRateRepository interface {
+	GetRates(ctx context.Context, country string) ([]domain.ShippingRate, error)
+	SetRate(ctx context.Context, rate domain.ShippingRate) (domain.ShippingRate, error)
+	DeleteRate(ctx context.Context, id int) error
+}
+
+type IdempotencyRepository interface {
+	Begin(ctx context.Context, request domain.IdempotentRequest) (domain.IdempotentResponse, bool, error)
+	Complete(ctx context.Context, request domain.IdempotentRequest, response domain.IdempotentResponse) error
+	Release(ctx context.Context, request domain.IdempotentRequest) error
+}
+
+type WebhookRepository interface {
+	GetSubscription(ctx context.Context, id int) (domain.WebhookSubscription, error)
+	GetSubscriptions(ctx context.Context, activeOnly bool) ([]domain.WebhookSubscription, error)
+	CreateSubscription(ctx context.Context, subscription domain.WebhookSubscription) (domain.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, subscription domain.WebhookSubscription) (domain.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int) error
+	GetDeliveries(ctx context.Context, subscriptionID int, limit, offset int) ([]domain.WebhookDelivery, error)
+	RetryDelivery(ctx context.Context, subscriptionID int, deliveryID int64) error
+}
+
+type InventoryRepository interface {
+	AdjustStock(ctx context.Context, movement domain.InventoryMovement) (domain.InventoryMovement, error)
+	GetMovements(ctx context.Context, bookID, limit, offset int) ([]domain.InventoryMovement, error)
+	GetStockCounts(ctx context.Context) ([]domain.StockCount, error)
+	RepairStock(ctx context.Context, bookID int) (domain.InventoryMovement, bool, error)
+	GetLowStock(ctx context.Context, soldSince time.Time) ([]domain.LowStockItem, error)
+	MarkLowStockAlerted(ctx context.Context, bookID int, alertedAt time.Time) error
+	ClearRecoveredLowStockAlerts(ctx context.Context) error
+}
+
+type StockSubscriptionRepository interface {
+	Subscribe(ctx context.Context, userID, bookID int) (domain.StockSubscription, error)
+	Unsubscribe(ctx context.Context, userID, bookID int) error
+	GetDueBackInStock(ctx context.Context, rateSince time.Time, limit int) ([]domain.BackInStockNotice, error)
+	MarkNotified(ctx context.Context, userID, bookID int, notifiedAt time.Time) error
+}
+
+type WishlistRepository interface {
+	GetWishlist(ctx context.Context, userID int) (domain.Wishlist, error)
+	GetSharedWishlist(ctx context.Context, token string) (domain.Wishlist, error)
+	AddItem(ctx context.Context, userID, bookID int) error
+	RemoveItems(ctx context.Context, userID int, bookIDs []int) (int, error)
+	SetShareToken(ctx context.Context, userID int, token string) error
+}
+
+type ReviewRepository interface {
+	CreateReview(ctx context.Context, review domain.Review) (domain.Review, error)
+	GetBookReviews(ctx context.Context, bookID, limit, offset int) ([]domain.Review, error)
+	GetReviews(ctx context.Context, status string, limit, offset int) ([]domain.Review, error)
+	ModerateReview(ctx context.Context, id int, status, reason string, adminID int) (domain.Review, error)
+}
+
+type RecommendationRepository interface {
+	ComputeRecommendations(ctx context.Context) (int, error)
+	GetCoPurchased(ctx context.Context, bookID int, excludeIDs []int, limit int) ([]domain.Recommendation, error)
+	GetCategoryBestsellers(ctx context.Context, bookID int, excludeIDs []int, limit int) ([]domain.Recommendation, error)
+}
+
+// Notifier sends notifications to the shop staff or to customers
+type Notifier interface {
+	Notify(ctx context.Context, notification domain.Notification) error
 }
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// InventoryService adjusts the stock of books through the inventory ledger
+type InventoryService struct {
+	repo InventoryRepository
+}
+
+// NewInventoryService creates a new inventory service
+func NewInventoryService(repo InventoryRepository) InventoryService {
+	return InventoryService{
+		repo: repo,
+	}
+}
+
+// AdjustStock applies a stock adjustment of an admin and records it in the ledger
+func (s InventoryService) AdjustStock(ctx context.Context, movement domain.InventoryMovement) (domain.InventoryMovement, error) {
+	return s.repo.AdjustStock(ctx, movement)
+}
+
+// GetMovements returns the inventory movements of a book, newest first
+func (s InventoryService) GetMovements(ctx context.Context, bookID, limit, offset int) ([]domain.InventoryMovement, error) {
+	return s.repo.GetMovements(ctx, bookID, limit, offset)
+}
+
+// GetLowStock returns the books at or below their reorder threshold with their sales in the sales velocity window
+func (s InventoryService) GetLowStock(ctx context.Context) ([]domain.LowStockItem, error) {
+	return s.repo.GetLowStock(ctx, time.Now().Add(-domain.SalesVelocityWindow))
+}
+
+// AlertLowStock notifies the shop staff of the books at or below their reorder threshold and reports how many
+// alerts were sent, a failed alert doesn't stop the others. A book is alerted once when its stock runs low and again
+// every domain.LowStockRealertInterval until its stock recovers.
+func (s InventoryService) AlertLowStock(ctx context.Context, notifier Notifier) (int, error) {
+	if err := s.repo.ClearRecoveredLowStockAlerts(ctx); err != nil {
+		return 0, err
+	}
+
+	items, err := s.GetLowStock(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var sent int
+	var errs []error
+	now := time.Now()
+	for _, item := range items {
+		if !item.AlertDue(now) {
+			continue
+		}
+		if err := notifier.Notify(ctx, item.Notification()); err != nil {
+			errs = append(errs, fmt.Errorf("failed to alert low stock of book %d: %w", item.BookID, err))
+			continue
+		}
+		sent++
+		if err := s.repo.MarkLowStockAlerted(ctx, item.BookID, now); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+// Reconcile counts the stock of every book and returns the books whose stock drifted from the expected stock.
+// With repair the drift is corrected through the ledger, the recorded movements are returned; books whose stock is
+// expected below zero are reported but left for an admin to correct.
+func (s InventoryService) Reconcile(ctx context.Context, repair bool) ([]domain.StockCount, []domain.InventoryMovement, error) {
+	counts, err := s.repo.GetStockCounts(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var drifted []domain.StockCount
+	var repairs []domain.InventoryMovement
+	for _, count := range counts {
+		if count.Drift() == 0 {
+			continue
+		}
+		drifted = append(drifted, count)
+
+		if !repair {
+			continue
+		}
+		movement, repaired, err := s.repo.RepairStock(ctx, count.BookID)
+		if err != nil {
+			if errors.Is(err, domain.ErrInsufficientStock) || errors.Is(err, domain.ErrNotFound) {
+				continue
+			}
+			return nil, nil, fmt.Errorf("failed to repair stock of book %d: %w", count.BookID, err)
+		}
+		if repaired {
+			repairs = append(repairs, movement)
+		}
+	}
+
+	return drifted, repairs, nil
+}
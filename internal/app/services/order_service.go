@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// OrderService is an order service
+type OrderService struct {
+	repo OrderRepository
+}
+
+// NewOrderService creates a new order service
+func NewOrderService(repo OrderRepository) OrderService {
+	return OrderService{
+		repo: repo,
+	}
+}
+
+// GetOrder returns an order of a user, orders of other users are not found
+func (s OrderService) GetOrder(ctx context.Context, userID, id int) (domain.Order, error) {
+	order, err := s.repo.GetOrder(ctx, id)
+	if err != nil {
+		return domain.Order{}, err
+	}
+	if order.UserID() != userID {
+		return domain.Order{}, domain.ErrNotFound
+	}
+
+	return order, nil
+}
+
+// GetOrders returns the orders of a user, newest first
+func (s OrderService) GetOrders(ctx context.Context, userID int, limit, offset int) ([]domain.Order, error) {
+	return s.repo.GetOrders(ctx, userID, limit, offset)
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// PromotionService manages promotions and coupon codes
+type PromotionService struct {
+	repo PromotionRepository
+}
+
+// NewPromotionService creates a new promotion service
+func NewPromotionService(repo PromotionRepository) PromotionService {
+	return PromotionService{
+		repo: repo,
+	}
+}
+
+func (s PromotionService) GetPromotion(ctx context.Context, id int) (domain.Promotion, error) {
+	return s.repo.GetPromotion(ctx, id)
+}
+
+func (s PromotionService) GetPromotions(ctx context.Context) ([]domain.Promotion, error) {
+	return s.repo.GetPromotions(ctx, false)
+}
+
+func (s PromotionService) CreatePromotion(ctx context.Context, promotion domain.Promotion) (domain.Promotion, error) {
+	return s.repo.CreatePromotion(ctx, promotion)
+}
+
+func (s PromotionService) UpdatePromotion(ctx context.Context, promotion domain.Promotion) (domain.Promotion, error) {
+	return s.repo.UpdatePromotion(ctx, promotion)
+}
+
+func (s PromotionService) DeletePromotion(ctx context.Context, id int) error {
+	return s.repo.DeletePromotion(ctx, id)
+}
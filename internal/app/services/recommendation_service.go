@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// RecommendationService recommends books bought together by customers
+type RecommendationService struct {
+	repo     RecommendationRepository
+	cartRepo CartRepository
+}
+
+// NewRecommendationService creates a new recommendation service
+func NewRecommendationService(repo RecommendationRepository, cartRepo CartRepository) RecommendationService {
+	return RecommendationService{
+		repo:     repo,
+		cartRepo: cartRepo,
+	}
+}
+
+// GetRecommendations returns the books recommended for a book, the books bought by its customers first and then
+// best sellers of its categories. The book itself is never recommended, nor are the books in the cart of the user
+// when userID is not zero.
+func (s RecommendationService) GetRecommendations(ctx context.Context, bookID, userID int) ([]domain.Recommendation, error) {
+	excludeIDs := []int{bookID}
+	if userID > 0 {
+		cart, err := s.cartRepo.GetCart(ctx, userID)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+		excludeIDs = append(excludeIDs, cart.BookIDs()...)
+	}
+
+	recommendations, err := s.repo.GetCoPurchased(ctx, bookID, excludeIDs, domain.MaxRecommendations)
+	if err != nil {
+		return nil, err
+	}
+	if len(recommendations) >= domain.MaxRecommendations {
+		return recommendations, nil
+	}
+
+	// books with too few purchases are completed with the best sellers of their categories
+	for _, recommendation := range recommendations {
+		excludeIDs = append(excludeIDs, recommendation.Book.ID())
+	}
+	bestsellers, err := s.repo.GetCategoryBestsellers(ctx, bookID, excludeIDs, domain.MaxRecommendations-len(recommendations))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(recommendations, bestsellers...), nil
+}
+
+// ComputeRecommendations recomputes the recommendations of every book from the order history
+func (s RecommendationService) ComputeRecommendations(ctx context.Context) (int, error) {
+	return s.repo.ComputeRecommendations(ctx)
+}
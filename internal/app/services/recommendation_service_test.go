@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRecommendationRepo recommends books in the order of its lists, skipping the excluded ones
+type fakeRecommendationRepo struct {
+	coPurchased []int
+	bestsellers []int
+}
+
+func (r fakeRecommendationRepo) ComputeRecommendations(_ context.Context) (int, error) {
+	return 0, nil
+}
+
+func (r fakeRecommendationRepo) GetCoPurchased(_ context.Context, _ int, excludeIDs []int, limit int) ([]domain.Recommendation, error) {
+	return recommend(r.coPurchased, excludeIDs, limit, domain.RecommendationCoPurchase), nil
+}
+
+func (r fakeRecommendationRepo) GetCategoryBestsellers(_ context.Context, _ int, excludeIDs []int, limit int) ([]domain.Recommendation, error) {
+	return recommend(r.bestsellers, excludeIDs, limit, domain.RecommendationCategory), nil
+}
+
+func recommend(bookIDs, excludeIDs []int, limit int, source string) []domain.Recommendation {
+	var recommendations []domain.Recommendation
+	for _, bookID := range bookIDs {
+		if len(recommendations) == limit {
+			break
+		}
+		if !slices.Contains(excludeIDs, bookID) {
+			recommendations = append(recommendations, domain.Recommendation{
+				Book:   domain.RestoreBook(domain.NewBookData{ID: bookID}),
+				Source: source,
+			})
+		}
+	}
+	return recommendations
+}
+
+type fakeCartRepo struct {
+	CartRepository
+	carts map[int]domain.Cart
+}
+
+func (r fakeCartRepo) GetCart(_ context.Context, userID int) (domain.Cart, error) {
+	cart, ok := r.carts[userID]
+	if !ok {
+		return domain.Cart{}, domain.ErrNotFound
+	}
+	return cart, nil
+}
+
+func recommendedIDs(recommendations []domain.Recommendation) []int {
+	bookIDs := make([]int, len(recommendations))
+	for i, recommendation := range recommendations {
+		bookIDs[i] = recommendation.Book.ID()
+	}
+	return bookIDs
+}
+
+func TestRecommendationService_GetRecommendations(t *testing.T) {
+	repo := fakeRecommendationRepo{
+		coPurchased: []int{2, 3, 4},
+		bestsellers: []int{1, 3, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14},
+	}
+	cartRepo := fakeCartRepo{carts: map[int]domain.Cart{
+		7: domain.RestoreCart(domain.NewCartData{UserID: 7, BookIDs: []int{3, 5}}),
+	}}
+	service := NewRecommendationService(repo, cartRepo)
+
+	// anonymous users get co-purchased books first, completed with best sellers up to the limit, without the book
+	// itself or repeats
+	recommendations, err := service.GetRecommendations(context.Background(), 1, 0)
+	require.NoError(t, err)
+	require.Len(t, recommendations, domain.MaxRecommendations)
+	require.Equal(t, []int{2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, recommendedIDs(recommendations))
+	require.Equal(t, domain.RecommendationCoPurchase, recommendations[2].Source)
+	require.Equal(t, domain.RecommendationCategory, recommendations[3].Source)
+
+	// the books in the cart of the user are skipped
+	recommendations, err = service.GetRecommendations(context.Background(), 1, 7)
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 4, 6, 7, 8, 9, 10, 11, 12, 13}, recommendedIDs(recommendations))
+
+	// users without a cart are recommended as anonymous users
+	recommendations, err = service.GetRecommendations(context.Background(), 1, 8)
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, recommendedIDs(recommendations))
+}
+
+func TestRecommendationService_GetRecommendations_EnoughCoPurchased(t *testing.T) {
+	repo := fakeRecommendationRepo{
+		coPurchased: []int{2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12},
+		bestsellers: []int{13},
+	}
+	service := NewRecommendationService(repo, fakeCartRepo{})
+
+	recommendations, err := service.GetRecommendations(context.Background(), 1, 0)
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, recommendedIDs(recommendations))
+	for _, recommendation := range recommendations {
+		require.Equal(t, domain.RecommendationCoPurchase, recommendation.Source)
+	}
+}
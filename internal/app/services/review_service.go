@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// ReviewService submits the reviews of users and moderates them
+type ReviewService struct {
+	repo ReviewRepository
+}
+
+// NewReviewService creates a new review service
+func NewReviewService(repo ReviewRepository) ReviewService {
+	return ReviewService{
+		repo: repo,
+	}
+}
+
+// CreateReview submits a review for moderation, the user must have purchased the book
+func (s ReviewService) CreateReview(ctx context.Context, review domain.Review) (domain.Review, error) {
+	return s.repo.CreateReview(ctx, review)
+}
+
+// GetBookReviews returns the approved reviews of a book, newest first
+func (s ReviewService) GetBookReviews(ctx context.Context, bookID, limit, offset int) ([]domain.Review, error) {
+	return s.repo.GetBookReviews(ctx, bookID, limit, offset)
+}
+
+// GetReviews returns the reviews with a status, oldest first
+func (s ReviewService) GetReviews(ctx context.Context, status string, limit, offset int) ([]domain.Review, error) {
+	return s.repo.GetReviews(ctx, status, limit, offset)
+}
+
+// ApproveReview publishes a pending review and adds its rating to the book
+func (s ReviewService) ApproveReview(ctx context.Context, id, adminID int) (domain.Review, error) {
+	return s.repo.ModerateReview(ctx, id, domain.ReviewStatusApproved, "", adminID)
+}
+
+// RejectReview rejects a pending review, its author may submit it again
+func (s ReviewService) RejectReview(ctx context.Context, id int, reason string, adminID int) (domain.Review, error) {
+	return s.repo.ModerateReview(ctx, id, domain.ReviewStatusRejected, reason, adminID)
+}
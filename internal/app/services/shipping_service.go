@@ -0,0 +1,31 @@
+package services
+
+import (
+	"context"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// ShippingService manages the shipping rate table
+type ShippingService struct {
+	repo ShippingRateRepository
+}
+
+// NewShippingService creates a new shipping service
+func NewShippingService(repo ShippingRateRepository) ShippingService {
+	return ShippingService{
+		repo: repo,
+	}
+}
+
+func (s ShippingService) GetRates(ctx context.Context) ([]domain.ShippingRate, error) {
+	return s.repo.GetRates(ctx, "")
+}
+
+func (s ShippingService) SetRate(ctx context.Context, rate domain.ShippingRate) (domain.ShippingRate, error) {
+	return s.repo.SetRate(ctx, rate)
+}
+
+func (s ShippingService) DeleteRate(ctx context.Context, id int) error {
+	return s.repo.DeleteRate(ctx, id)
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// backInStockBatchSize bounds the notifications sent by a single run of NotifyBackInStock
+const backInStockBatchSize = 100
+
+// StockSubscriptionService notifies users when the sold-out books they wait for are back in stock
+type StockSubscriptionService struct {
+	repo StockSubscriptionRepository
+}
+
+// NewStockSubscriptionService creates a new stock subscription service
+func NewStockSubscriptionService(repo StockSubscriptionRepository) StockSubscriptionService {
+	return StockSubscriptionService{
+		repo: repo,
+	}
+}
+
+// Subscribe subscribes a user to a sold-out book
+func (s StockSubscriptionService) Subscribe(ctx context.Context, userID, bookID int) (domain.StockSubscription, error) {
+	return s.repo.Subscribe(ctx, userID, bookID)
+}
+
+// Unsubscribe removes the subscription of a user to a book
+func (s StockSubscriptionService) Unsubscribe(ctx context.Context, userID, bookID int) error {
+	return s.repo.Unsubscribe(ctx, userID, bookID)
+}
+
+// NotifyBackInStock notifies the queued subscribers of books back in stock in the order they subscribed and reports
+// how many were notified. Users over the rate limit keep their place for a later run, a failed notification doesn't
+// stop the others and is retried.
+func (s StockSubscriptionService) NotifyBackInStock(ctx context.Context, notifier Notifier) (int, error) {
+	notices, err := s.repo.GetDueBackInStock(ctx, time.Now().Add(-domain.BackInStockRateWindow), backInStockBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sentTo := make(map[int]int)
+	var sent int
+	var errs []error
+	for _, notice := range notices {
+		userID := notice.Subscription.UserID()
+		if notice.RecentlyNotified+sentTo[userID] >= domain.BackInStockRateLimit {
+			continue
+		}
+
+		if err := notifier.Notify(ctx, notice.Notification()); err != nil {
+			errs = append(errs, fmt.Errorf("failed to notify user %d of book %d: %w", userID, notice.Subscription.BookID(), err))
+			continue
+		}
+		if err := s.repo.MarkNotified(ctx, userID, notice.Subscription.BookID(), time.Now()); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sentTo[userID]++
+		sent++
+	}
+
+	return sent, errors.Join(errs...)
+}
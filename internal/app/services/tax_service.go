@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// TaxCalculator calculates the sales tax of a quote shipped to a region
+type TaxCalculator interface {
+	Calculate(ctx context.Context, region domain.TaxRegion, quote domain.Quote) ([]domain.TaxLine, error)
+}
+
+// TableTaxCalculator calculates taxes from the rate table of the shipping country
+type TableTaxCalculator struct {
+	repo TaxRateRepository
+}
+
+// NewTableTaxCalculator creates a new table-driven tax calculator
+func NewTableTaxCalculator(repo TaxRateRepository) TableTaxCalculator {
+	return TableTaxCalculator{
+		repo: repo,
+	}
+}
+
+func (c TableTaxCalculator) Calculate(ctx context.Context, region domain.TaxRegion, quote domain.Quote) ([]domain.TaxLine, error) {
+	rates, err := c.repo.GetRates(ctx, region.Country)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tax rates: %w", err)
+	}
+
+	return domain.TaxTable(rates).Calculate(region, quote)
+}
+
+// TaxService manages the tax rate table
+type TaxService struct {
+	repo TaxRateRepository
+}
+
+// NewTaxService creates a new tax service
+func NewTaxService(repo TaxRateRepository) TaxService {
+	return TaxService{
+		repo: repo,
+	}
+}
+
+func (s TaxService) GetRates(ctx context.Context) ([]domain.TaxRate, error) {
+	return s.repo.GetRates(ctx, "")
+}
+
+func (s TaxService) SetRate(ctx context.Context, rate domain.TaxRate) (domain.TaxRate, error) {
+	return s.repo.SetRate(ctx, rate)
+}
+
+func (s TaxService) DeleteRate(ctx context.Context, id int) error {
+	return s.repo.DeleteRate(ctx, id)
+}
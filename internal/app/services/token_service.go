@@ -67,15 +67,12 @@ func (s TokenService) GetUser(token string) (domain.User, error) {
 	if !t.Valid {
 		return domain.User{}, errors.New("invalid token")
 	}
-	user, err := userClaimsToDomainUser(userClaims)
-	if err != nil {
-		return domain.User{}, fmt.Errorf("failed to convert user claims to domain user: %w", err)
-	}
-	return user, nil
+	return userClaimsToDomainUser(userClaims), nil
 }
 
-func userClaimsToDomainUser(claims UserClaims) (domain.User, error) {
-	return domain.NewUser(domain.NewUserData{
+// userClaimsToDomainUser restores the user a token was issued to, it was validated when it was stored
+func userClaimsToDomainUser(claims UserClaims) domain.User {
+	return domain.RestoreUser(domain.NewUserData{
 		ID:       claims.UserID,
 		Username: claims.UserName,
 		Admin:    claims.Admin,
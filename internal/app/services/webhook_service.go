@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// WebhookService manages webhook subscriptions and their deliveries
+type WebhookService struct {
+	repo WebhookRepository
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(repo WebhookRepository) WebhookService {
+	return WebhookService{
+		repo: repo,
+	}
+}
+
+func (s WebhookService) GetWebhook(ctx context.Context, id int) (domain.WebhookSubscription, error) {
+	return s.repo.GetSubscription(ctx, id)
+}
+
+func (s WebhookService) GetWebhooks(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	return s.repo.GetSubscriptions(ctx, false)
+}
+
+func (s WebhookService) CreateWebhook(ctx context.Context, subscription domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	return s.repo.CreateSubscription(ctx, subscription)
+}
+
+func (s WebhookService) UpdateWebhook(ctx context.Context, subscription domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	return s.repo.UpdateSubscription(ctx, subscription)
+}
+
+func (s WebhookService) DeleteWebhook(ctx context.Context, id int) error {
+	return s.repo.DeleteSubscription(ctx, id)
+}
+
+func (s WebhookService) GetDeliveries(ctx context.Context, subscriptionID int, limit, offset int) ([]domain.WebhookDelivery, error) {
+	return s.repo.GetDeliveries(ctx, subscriptionID, limit, offset)
+}
+
+func (s WebhookService) RetryDelivery(ctx context.Context, subscriptionID int, deliveryID int64) error {
+	return s.repo.RetryDelivery(ctx, subscriptionID, deliveryID)
+}
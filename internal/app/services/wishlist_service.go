@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/northwindman/book-shop/internal/app/common/slugerrors"
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// WishlistService manages the wishlists of users and moves their books to carts
+type WishlistService struct {
+	repo     WishlistRepository
+	cartRepo CartRepository
+}
+
+// NewWishlistService creates a new wishlist service
+func NewWishlistService(repo WishlistRepository, cartRepo CartRepository) WishlistService {
+	return WishlistService{
+		repo:     repo,
+		cartRepo: cartRepo,
+	}
+}
+
+// GetWishlist returns the wishlist of a user
+func (s WishlistService) GetWishlist(ctx context.Context, userID int) (domain.Wishlist, error) {
+	return s.repo.GetWishlist(ctx, userID)
+}
+
+// GetSharedWishlist returns the wishlist shared with a token, malformed tokens are not found
+func (s WishlistService) GetSharedWishlist(ctx context.Context, token string) (domain.Wishlist, error) {
+	if !domain.ValidShareToken(token) {
+		return domain.Wishlist{}, domain.ErrNotFound
+	}
+	return s.repo.GetSharedWishlist(ctx, token)
+}
+
+// AddItem adds a book to the wishlist of a user and returns the wishlist
+func (s WishlistService) AddItem(ctx context.Context, userID, bookID int) (domain.Wishlist, error) {
+	if err := s.repo.AddItem(ctx, userID, bookID); err != nil {
+		return domain.Wishlist{}, err
+	}
+	return s.repo.GetWishlist(ctx, userID)
+}
+
+// RemoveItem removes a book from the wishlist of a user, books not on it are not found
+func (s WishlistService) RemoveItem(ctx context.Context, userID, bookID int) error {
+	removed, err := s.repo.RemoveItems(ctx, userID, []int{bookID})
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Share creates a new public link to the wishlist of a user and returns the wishlist, earlier links stop working
+func (s WishlistService) Share(ctx context.Context, userID int) (domain.Wishlist, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return domain.Wishlist{}, fmt.Errorf("failed to generate share token: %w", err)
+	}
+
+	if err := s.repo.SetShareToken(ctx, userID, hex.EncodeToString(b)); err != nil {
+		return domain.Wishlist{}, err
+	}
+	return s.repo.GetWishlist(ctx, userID)
+}
+
+// Unshare stops sharing the wishlist of a user
+func (s WishlistService) Unshare(ctx context.Context, userID int) error {
+	return s.repo.SetShareToken(ctx, userID, "")
+}
+
+// MoveToCart moves books of the wishlist of a user to the cart, all of them when bookIDs is empty.
+// Books that are out of stock and can't be pre-ordered stay on the wishlist and are returned as unavailable.
+// Moved books are reserved in the cart like any other. A move that failed half way can be retried: books already in
+// the cart are not reserved again, they are only removed from the wishlist.
+func (s WishlistService) MoveToCart(ctx context.Context, userID int, bookIDs []int) ([]int, []int, error) {
+	wishlist, err := s.repo.GetWishlist(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(bookIDs) == 0 {
+		bookIDs = wishlist.BookIDs()
+	}
+	var unique []int
+	for _, bookID := range bookIDs {
+		if !slices.Contains(unique, bookID) {
+			unique = append(unique, bookID)
+		}
+	}
+	bookIDs = unique
+	for _, bookID := range bookIDs {
+		if !wishlist.Contains(bookID) {
+			return nil, nil, slugerrors.NewBadRequestError(fmt.Sprintf("book %d is not on the wishlist", bookID), "not-in-wishlist")
+		}
+	}
+
+	cart, err := s.cartRepo.GetCart(ctx, userID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	var moved, unavailable, added []int
+	for _, bookID := range bookIDs {
+		if slices.Contains(cart.BookIDs(), bookID) {
+			moved = append(moved, bookID)
+			continue
+		}
+
+		single, err := domain.NewCart(domain.NewCartData{UserID: userID, BookIDs: []int{bookID}})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create domain cart: %w", err)
+		}
+		ok, err := s.cartRepo.CheckStocks(ctx, single)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to check stocks: %w", err)
+		}
+		if !ok {
+			unavailable = append(unavailable, bookID)
+			continue
+		}
+		moved = append(moved, bookID)
+		added = append(added, bookID)
+	}
+
+	if len(added) > 0 {
+		updated, err := domain.NewCart(domain.NewCartData{
+			UserID:          userID,
+			BookIDs:         append(slices.Clone(cart.BookIDs()), added...),
+			PreorderBookIDs: cart.PreorderBookIDs(),
+			CouponCode:      cart.CouponCode(),
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create domain cart: %w", err)
+		}
+		if err := s.cartRepo.UpdateCartAndStocks(ctx, updated); err != nil {
+			return nil, nil, fmt.Errorf("failed to update cart and stocks: %w", err)
+		}
+	}
+
+	// the cart is updated first, so a failure here leaves the books in both and a retry completes the move
+	if _, err := s.repo.RemoveItems(ctx, userID, moved); err != nil {
+		return nil, nil, fmt.Errorf("failed to remove moved books from the wishlist: %w", err)
+	}
+
+	return moved, unavailable, nil
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWishlistRepo keeps the wishlist of a single user in memory and fails the first removals
+type fakeWishlistRepo struct {
+	WishlistRepository
+	userID         int
+	bookIDs        []int
+	removeFailures int
+}
+
+func (r *fakeWishlistRepo) GetWishlist(_ context.Context, userID int) (domain.Wishlist, error) {
+	items := make([]domain.WishlistItem, len(r.bookIDs))
+	for i, bookID := range r.bookIDs {
+		items[i] = domain.WishlistItem{Book: domain.RestoreBook(domain.NewBookData{ID: bookID})}
+	}
+	return domain.NewWishlist(domain.NewWishlistData{UserID: userID, Items: items})
+}
+
+func (r *fakeWishlistRepo) RemoveItems(_ context.Context, _ int, bookIDs []int) (int, error) {
+	if r.removeFailures > 0 {
+		r.removeFailures--
+		return 0, errors.New("connection reset")
+	}
+	before := len(r.bookIDs)
+	r.bookIDs = slices.DeleteFunc(r.bookIDs, func(bookID int) bool {
+		return slices.Contains(bookIDs, bookID)
+	})
+	return before - len(r.bookIDs), nil
+}
+
+// fakeStockCartRepo keeps carts in memory and reserves a copy of a book for every update adding it
+type fakeStockCartRepo struct {
+	fakeCartRepo
+	available    map[int]bool
+	reservations map[int]int
+}
+
+func (r *fakeStockCartRepo) CheckStocks(_ context.Context, cart domain.Cart) (bool, error) {
+	for _, bookID := range cart.BookIDs() {
+		if !r.available[bookID] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (r *fakeStockCartRepo) UpdateCartAndStocks(_ context.Context, cart domain.Cart) error {
+	old := r.carts[cart.UserID()]
+	for _, bookID := range cart.BookIDs() {
+		if !slices.Contains(old.BookIDs(), bookID) {
+			r.reservations[bookID]++
+		}
+	}
+	r.carts[cart.UserID()] = cart
+	return nil
+}
+
+func TestWishlistService_MoveToCart(t *testing.T) {
+	repo := &fakeWishlistRepo{bookIDs: []int{5, 6, 7}}
+	cartRepo := &fakeStockCartRepo{
+		fakeCartRepo: fakeCartRepo{carts: map[int]domain.Cart{}},
+		available:    map[int]bool{5: true, 6: true},
+		reservations: map[int]int{},
+	}
+	service := NewWishlistService(repo, cartRepo)
+
+	// repeated books are moved once
+	moved, unavailable, err := service.MoveToCart(context.Background(), 1, []int{5, 5, 7})
+	require.NoError(t, err)
+	require.Equal(t, []int{5}, moved)
+	require.Equal(t, []int{7}, unavailable)
+	require.Equal(t, []int{5}, cartRepo.carts[1].BookIDs())
+	require.Equal(t, []int{6, 7}, repo.bookIDs)
+}
+
+func TestWishlistService_MoveToCart_Retry(t *testing.T) {
+	repo := &fakeWishlistRepo{bookIDs: []int{5, 6}, removeFailures: 1}
+	cartRepo := &fakeStockCartRepo{
+		fakeCartRepo: fakeCartRepo{carts: map[int]domain.Cart{}},
+		available:    map[int]bool{5: true, 6: true},
+		reservations: map[int]int{},
+	}
+	service := NewWishlistService(repo, cartRepo)
+
+	_, _, err := service.MoveToCart(context.Background(), 1, nil)
+	require.Error(t, err)
+	require.Equal(t, []int{5, 6}, cartRepo.carts[1].BookIDs())
+	require.Equal(t, []int{5, 6}, repo.bookIDs)
+
+	// the retry removes the books from the wishlist without reserving them again
+	moved, unavailable, err := service.MoveToCart(context.Background(), 1, nil)
+	require.NoError(t, err)
+	require.Equal(t, []int{5, 6}, moved)
+	require.Empty(t, unavailable)
+	require.Empty(t, repo.bookIDs)
+	require.Equal(t, map[int]int{5: 1, 6: 1}, cartRepo.reservations)
+}
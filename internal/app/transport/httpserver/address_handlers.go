@@ -0,0 +1,159 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/northwindman/book-shop/internal/app/common/server"
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// GetAddresses returns the address book of the current user
+func (h HttpServer) GetAddresses(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	addresses, err := h.addressService.GetAddresses(r.Context(), user.ID())
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := make([]AddressResponse, 0, len(addresses))
+	for _, address := range addresses {
+		response = append(response, toResponseAddress(address))
+	}
+
+	server.RespondOK(response, w, r)
+}
+
+// GetAddress returns an address of the current user by ID
+func (h HttpServer) GetAddress(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	addressID, err := strconv.Atoi(vars["address_id"])
+	if err != nil {
+		server.BadRequest("invalid-address-id", err, w, r)
+		return
+	}
+
+	address, err := h.addressService.GetAddress(r.Context(), user.ID(), addressID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("address-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(toResponseAddress(address), w, r)
+}
+
+// CreateAddress adds an address to the address book of the current user
+func (h HttpServer) CreateAddress(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	var addressRequest AddressRequest
+	if err := json.NewDecoder(r.Body).Decode(&addressRequest); err != nil {
+		server.BadRequest("invalid-json", err, w, r)
+		return
+	}
+
+	address, err := toDomainAddress(0, user.ID(), addressRequest)
+	if err != nil {
+		server.RespondWithError(validationProblem(err), w, r)
+		return
+	}
+
+	insertedAddress, err := h.addressService.CreateAddress(r.Context(), address)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(toResponseAddress(insertedAddress), w, r)
+}
+
+// UpdateAddress replaces an address of the current user by ID
+func (h HttpServer) UpdateAddress(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	addressID, err := strconv.Atoi(vars["address_id"])
+	if err != nil {
+		server.BadRequest("invalid-address-id", err, w, r)
+		return
+	}
+
+	var addressRequest AddressRequest
+	if err := json.NewDecoder(r.Body).Decode(&addressRequest); err != nil {
+		server.BadRequest("invalid-json", err, w, r)
+		return
+	}
+
+	address, err := toDomainAddress(addressID, user.ID(), addressRequest)
+	if err != nil {
+		server.RespondWithError(validationProblem(err), w, r)
+		return
+	}
+
+	updatedAddress, err := h.addressService.UpdateAddress(r.Context(), address)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("address-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(toResponseAddress(updatedAddress), w, r)
+}
+
+// DeleteAddress deletes an address of the current user by ID, orders shipped to it keep their copy
+func (h HttpServer) DeleteAddress(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	addressID, err := strconv.Atoi(vars["address_id"])
+	if err != nil {
+		server.BadRequest("invalid-address-id", err, w, r)
+		return
+	}
+
+	err = h.addressService.DeleteAddress(r.Context(), user.ID(), addressID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("address-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(map[string]bool{"ok": true}, w, r)
+}
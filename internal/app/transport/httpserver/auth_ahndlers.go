@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/northwindman/book-shop/internal/app/common/server"
+	"github.com/northwindman/book-shop/internal/pkg/password"
 )
 
 func (h HttpServer) SignUp(w http.ResponseWriter, r *http.Request) {
@@ -19,7 +20,7 @@ func (h HttpServer) SignUp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	hashedPassword, err := hashPassword(authRequest.Password)
+	hashedPassword, err := password.Hash(authRequest.Password)
 	if err != nil {
 		server.RespondWithError(err, w, r)
 		return
@@ -27,7 +28,7 @@ func (h HttpServer) SignUp(w http.ResponseWriter, r *http.Request) {
 
 	user, err := toDomainUser(authRequest.Username, hashedPassword)
 	if err != nil {
-		server.RespondWithError(err, w, r)
+		server.RespondWithError(validationProblem(err), w, r)
 		return
 	}
 
@@ -58,7 +59,7 @@ func (h HttpServer) SignIn(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !checkPasswordHash(authRequest.Password, user.Password()) {
+	if !password.Check(authRequest.Password, user.Password()) {
 		server.BadRequest("invalid-password", nil, w, r)
 		return
 	}
@@ -35,6 +35,18 @@ func (h HttpServer) CheckAdmin(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// OptionalUser adds the user of the Authorization header to the context of requests that may be anonymous, requests
+// without the header are passed on as they are
+func (h HttpServer) OptionalUser(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(AuthorizationHeader) == "" {
+			next(w, r)
+			return
+		}
+		h.CheckAuthorizedUser(next)(w, r)
+	}
+}
+
 func (h HttpServer) CheckAuthorizedUser(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		token := r.Header.Get(AuthorizationHeader)
@@ -0,0 +1,201 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/northwindman/book-shop/internal/app/common/server"
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// GetAuthor returns an author by ID with the books credited to them
+func (h HttpServer) GetAuthor(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	authorID, err := strconv.Atoi(vars["author_id"])
+	if err != nil {
+		server.BadRequest("invalid-author-id", err, w, r)
+		return
+	}
+
+	author, err := h.authorService.GetAuthor(r.Context(), authorID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("author-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	convert, variant, err := h.priceConverter(r)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	books, err := h.authorService.GetAuthorBooks(r.Context(), authorID)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := AuthorDetailResponse{
+		AuthorResponse: toResponseAuthor(author),
+		Books:          make([]AuthorBookResponse, 0, len(books)),
+	}
+	for _, book := range books {
+		bookResponse, err := toDisplayBook(book.Book, convert)
+		if err != nil {
+			server.RespondWithError(err, w, r)
+			return
+		}
+		response.Books = append(response.Books, AuthorBookResponse{Book: bookResponse, Role: book.Role})
+	}
+
+	setDisplayETag(w, author.Version(), variant)
+	server.RespondOK(response, w, r)
+}
+
+// GetAuthors returns the authors ordered by name
+func (h HttpServer) GetAuthors(w http.ResponseWriter, r *http.Request) {
+	authors, err := h.authorService.GetAuthors(r.Context())
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := make([]AuthorResponse, 0, len(authors))
+	for _, author := range authors {
+		response = append(response, toResponseAuthor(author))
+	}
+
+	server.RespondOK(response, w, r)
+}
+
+// CreateAuthor creates a new author, names differing only in case, spacing or punctuation are the same author
+func (h HttpServer) CreateAuthor(w http.ResponseWriter, r *http.Request) {
+	var authorRequest AuthorRequest
+	if err := json.NewDecoder(r.Body).Decode(&authorRequest); err != nil {
+		server.BadRequest("invalid-json", err, w, r)
+		return
+	}
+
+	author, err := domain.NewAuthor(domain.NewAuthorData{
+		Name: authorRequest.Name,
+		Bio:  authorRequest.Bio,
+	})
+	if err != nil {
+		server.RespondWithError(validationProblem(err), w, r)
+		return
+	}
+
+	insertedAuthor, err := h.authorService.CreateAuthor(r.Context(), author)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	w.Header().Set(ETagHeader, etag(insertedAuthor.Version()))
+	server.RespondOK(toResponseAuthor(insertedAuthor), w, r)
+}
+
+// UpdateAuthor applies a JSON Merge Patch to an author by ID, the If-Match header must carry the ETag of the
+// current version. Renaming an author updates the bylines of their books.
+func (h HttpServer) UpdateAuthor(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	authorID, err := strconv.Atoi(vars["author_id"])
+	if err != nil {
+		server.BadRequest("invalid-author-id", err, w, r)
+		return
+	}
+
+	var patchRequest AuthorPatchRequest
+	nulls, err := decodeMergePatch(r, &patchRequest)
+	if err != nil {
+		server.BadRequest("invalid-json", err, w, r)
+		return
+	}
+
+	patch, err := toDomainAuthorPatch(patchRequest, nulls)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	currentAuthor, err := h.authorService.GetAuthor(r.Context(), authorID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("author-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	if err := checkIfMatch(r, currentAuthor.Version()); err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	author, changed, err := currentAuthor.Patch(patch)
+	if err != nil {
+		server.RespondWithError(validationProblem(err), w, r)
+		return
+	}
+
+	updatedAuthor := currentAuthor
+	if len(changed) > 0 {
+		updatedAuthor, err = h.authorService.UpdateAuthor(r.Context(), author, changed)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				server.NotFound("author-not-found", err, w, r)
+				return
+			}
+			server.RespondWithError(err, w, r)
+			return
+		}
+	}
+
+	w.Header().Set(ETagHeader, etag(updatedAuthor.Version()))
+	server.RespondOK(toResponseAuthor(updatedAuthor), w, r)
+}
+
+// DeleteAuthor deletes an author credited on no book, the If-Match header must carry the ETag of the current version
+func (h HttpServer) DeleteAuthor(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	authorID, err := strconv.Atoi(vars["author_id"])
+	if err != nil {
+		server.BadRequest("invalid-author-id", err, w, r)
+		return
+	}
+
+	currentAuthor, err := h.authorService.GetAuthor(r.Context(), authorID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("author-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	if err := checkIfMatch(r, currentAuthor.Version()); err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	err = h.authorService.DeleteAuthor(r.Context(), authorID, currentAuthor.Version())
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("author-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(map[string]bool{"deleted": true}, w, r)
+}
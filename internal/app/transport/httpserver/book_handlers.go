@@ -3,8 +3,11 @@ package httpserver
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/northwindman/book-shop/internal/app/common/server"
@@ -19,6 +22,12 @@ func (h HttpServer) GetBook(w http.ResponseWriter, r *http.Request) {
 		server.BadRequest("invalid-book-id", err, w, r)
 		return
 	}
+	convert, variant, err := h.priceConverter(r)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
 	book, err := h.bookService.GetBook(r.Context(), bookID)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
@@ -29,8 +38,13 @@ func (h HttpServer) GetBook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response := toResponseBook(book)
+	response, err := toDisplayBook(book, convert)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
 
+	setDisplayETag(w, book.Version(), variant)
 	server.RespondOK(response, w, r)
 }
 
@@ -42,14 +56,9 @@ func (h HttpServer) CreateBook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := bookRequest.Validate(); err != nil {
-		server.BadRequest("invalid-request", err, w, r)
-		return
-	}
-
 	book, err := toDomainBook(bookRequest)
 	if err != nil {
-		server.RespondWithError(err, w, r)
+		server.RespondWithError(validationProblem(err), w, r)
 		return
 	}
 
@@ -61,10 +70,12 @@ func (h HttpServer) CreateBook(w http.ResponseWriter, r *http.Request) {
 
 	response := toResponseBook(insertedBook)
 
+	w.Header().Set(ETagHeader, etag(insertedBook.Version()))
 	server.RespondOK(response, w, r)
 }
 
-// UpdateBook updates a book by ID
+// UpdateBook applies a JSON Merge Patch to a book by ID, the If-Match header must carry the ETag of the current version.
+// Only the patched fields are validated and updated, a patch that changes nothing returns the book as is.
 func (h HttpServer) UpdateBook(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	bookID, err := strconv.Atoi(vars["book_id"])
@@ -73,18 +84,20 @@ func (h HttpServer) UpdateBook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var bookRequest BookRequest
-	if err := json.NewDecoder(r.Body).Decode(&bookRequest); err != nil {
+	var patchRequest BookPatchRequest
+	nulls, err := decodeMergePatch(r, &patchRequest)
+	if err != nil {
 		server.BadRequest("invalid-json", err, w, r)
 		return
 	}
 
-	if err := bookRequest.Validate(); err != nil {
-		server.BadRequest("invalid-request", err, w, r)
+	patch, err := toDomainBookPatch(patchRequest, nulls)
+	if err != nil {
+		server.RespondWithError(validationProblem(err), w, r)
 		return
 	}
 
-	_, err = h.bookService.GetBook(r.Context(), bookID)
+	currentBook, err := h.bookService.GetBook(r.Context(), bookID)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
 			server.NotFound("book-not-found", err, w, r)
@@ -94,31 +107,37 @@ func (h HttpServer) UpdateBook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	book, err := domain.NewBook(domain.NewBookData{
-		ID:         bookID,
-		Title:      bookRequest.Title,
-		Year:       bookRequest.Year,
-		Author:     bookRequest.Author,
-		Price:      bookRequest.Price,
-		CategoryID: bookRequest.CategoryID,
-	})
-	if err != nil {
+	if err := checkIfMatch(r, currentBook.Version()); err != nil {
 		server.RespondWithError(err, w, r)
 		return
 	}
 
-	updatedBook, err := h.bookService.UpdateBook(r.Context(), book)
+	book, changed, err := currentBook.Patch(patch)
 	if err != nil {
-		server.RespondWithError(err, w, r)
+		server.RespondWithError(validationProblem(err), w, r)
 		return
 	}
 
+	updatedBook := currentBook
+	if len(changed) > 0 {
+		updatedBook, err = h.bookService.UpdateBook(r.Context(), book, changed)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				server.NotFound("book-not-found", err, w, r)
+				return
+			}
+			server.RespondWithError(err, w, r)
+			return
+		}
+	}
+
 	response := toResponseBook(updatedBook)
 
+	w.Header().Set(ETagHeader, etag(updatedBook.Version()))
 	server.RespondOK(response, w, r)
 }
 
-// DeleteBook deletes a book by ID
+// DeleteBook deletes a book by ID, the If-Match header must carry the ETag of the current version
 func (h HttpServer) DeleteBook(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	bookID, err := strconv.Atoi(vars["book_id"])
@@ -127,7 +146,7 @@ func (h HttpServer) DeleteBook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = h.bookService.GetBook(r.Context(), bookID)
+	currentBook, err := h.bookService.GetBook(r.Context(), bookID)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
 			server.NotFound("book-not-found", err, w, r)
@@ -137,7 +156,12 @@ func (h HttpServer) DeleteBook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.bookService.DeleteBook(r.Context(), bookID)
+	if err := checkIfMatch(r, currentBook.Version()); err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	err = h.bookService.DeleteBook(r.Context(), bookID, currentBook.Version())
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
 			server.NotFound("book-not-found", err, w, r)
@@ -162,6 +186,22 @@ func (h HttpServer) GetBooks(w http.ResponseWriter, r *http.Request) {
 		}
 		categoryIDs = append(categoryIDs, categoryID)
 	}
+	// books of subcategories are listed unless descendants=false
+	descendants := true
+	if query := r.URL.Query().Get("descendants"); query != "" {
+		var err error
+		descendants, err = strconv.ParseBool(query)
+		if err != nil {
+			server.BadRequest("invalid-descendants", err, w, r)
+			return
+		}
+	}
+	// order
+	sort := r.URL.Query().Get("sort")
+	if sort != "" && !slices.Contains(domain.BookSorts, sort) {
+		server.BadRequest("invalid-sort", fmt.Errorf("unknown sort %q, expected one of %s", sort, strings.Join(domain.BookSorts, ", ")), w, r)
+		return
+	}
 	// page
 	page, err := strconv.Atoi(r.URL.Query().Get("page"))
 	if err != nil {
@@ -173,7 +213,13 @@ func (h HttpServer) GetBooks(w http.ResponseWriter, r *http.Request) {
 		offset = (page - 1) * limit
 	}
 
-	books, err := h.bookService.GetBooks(r.Context(), categoryIDs, limit, offset)
+	convert, _, err := h.priceConverter(r)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	books, err := h.bookService.GetBooks(r.Context(), categoryIDs, descendants, sort, limit, offset)
 	if err != nil {
 		server.RespondWithError(err, w, r)
 		return
@@ -181,8 +227,47 @@ func (h HttpServer) GetBooks(w http.ResponseWriter, r *http.Request) {
 
 	response := make([]BookResponse, 0, len(books))
 	for _, book := range books {
-		response = append(response, toResponseBook(book))
+		bookResponse, err := toDisplayBook(book, convert)
+		if err != nil {
+			server.RespondWithError(err, w, r)
+			return
+		}
+		response = append(response, bookResponse)
+	}
+
+	w.Header().Add("Vary", "Accept-Currency")
+	server.RespondOK(response, w, r)
+}
+
+// GetBookByISBN returns the book with an ISBN-10 or ISBN-13, hyphens are ignored
+func (h HttpServer) GetBookByISBN(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	convert, variant, err := h.priceConverter(r)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	book, err := h.bookService.GetBookByISBN(r.Context(), vars["isbn"])
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidISBN) {
+			server.BadRequest("invalid-isbn", err, w, r)
+			return
+		}
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("book-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response, err := toDisplayBook(book, convert)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
 	}
 
+	setDisplayETag(w, book.Version(), variant)
 	server.RespondOK(response, w, r)
 }
@@ -2,11 +2,15 @@ package httpserver
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/gorilla/mux"
+	"github.com/northwindman/book-shop/internal/app/common/server"
 	"github.com/northwindman/book-shop/internal/app/domain"
 	"github.com/northwindman/book-shop/internal/app/transport/httpserver/mock"
 	"github.com/stretchr/testify/mock"
@@ -16,11 +20,14 @@ import (
 func TestHttpServer_GetBook(t *testing.T) {
 	bookServiceMock := mocks.NewBookService(t)
 
+	price, err := domain.NewMoney(1000, "USD")
+	require.NoError(t, err)
+
 	testCreatedBook, err := domain.NewBook(domain.NewBookData{
 		Title:      "The history of Toptal",
 		Year:       2010,
 		Author:     "Taso Du Val",
-		Price:      1000,
+		Price:      price,
 		Stock:      100,
 		CategoryID: 1,
 	})
@@ -28,13 +35,13 @@ func TestHttpServer_GetBook(t *testing.T) {
 
 	bookServiceMock.On("CreateBook", mock.Anything, mock.Anything).Return(testCreatedBook, nil)
 
-	httpServer := NewHttpServer(nil, nil, bookServiceMock, nil, nil)
+	httpServer := NewHttpServer(Services{BookService: bookServiceMock})
 
 	newBookRequest := []byte(`{
   "title": "The history of Toptal",
   "year": 2010,
   "author": "Taso Du Val",
-  "price": 1000,
+  "price": {"amount_minor": 1000, "currency": "USD"},
   "stock": 100,
   "category_id": 1
 }
@@ -59,7 +66,298 @@ func TestHttpServer_GetBook(t *testing.T) {
 	require.Equal(t, createBookResponse.Title, testCreatedBook.Title())
 	require.Equal(t, createBookResponse.Year, testCreatedBook.Year())
 	require.Equal(t, createBookResponse.Author, testCreatedBook.Author())
-	require.Equal(t, createBookResponse.Price, testCreatedBook.Price())
+	require.Equal(t, createBookResponse.Price.AmountMinor, testCreatedBook.Price().Amount())
+	require.Equal(t, createBookResponse.Price.Currency, testCreatedBook.Price().Currency())
+	require.Equal(t, "10.00", createBookResponse.Price.Amount)
 	require.Equal(t, createBookResponse.Stock, testCreatedBook.Stock())
 	require.Equal(t, createBookResponse.CategoryID, testCreatedBook.CategoryID())
 }
+
+func TestHttpServer_CreateBook_LegacyPrice(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+	bookServiceMock.On("CreateBook", mock.Anything, mock.MatchedBy(func(book domain.Book) bool {
+		return book.Price().Amount() == 1200 && book.Price().Currency() == domain.SettlementCurrency
+	})).Return(func(_ context.Context, book domain.Book) (domain.Book, error) {
+		return book, nil
+	})
+
+	httpServer := NewHttpServer(Services{BookService: bookServiceMock})
+
+	req := httptest.NewRequest(http.MethodPost, "/book", bytes.NewBufferString(`{
+  "title": "The history of Toptal",
+  "year": 2010,
+  "author": "Taso Du Val",
+  "price": 12,
+  "stock": 100,
+  "category_id": 1
+}`))
+	w := httptest.NewRecorder()
+
+	httpServer.CreateBook(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var createBookResponse BookResponse
+	err := json.NewDecoder(res.Body).Decode(&createBookResponse)
+	require.NoError(t, err)
+
+	require.Equal(t, int64(1200), createBookResponse.Price.AmountMinor)
+	require.Equal(t, "12.00", createBookResponse.Price.Amount)
+}
+
+func TestPriceRequest_UnmarshalJSON(t *testing.T) {
+	price := &PriceRequest{AmountMinor: 1299, Currency: "EUR"}
+	require.NoError(t, json.Unmarshal([]byte("null"), price))
+	require.Equal(t, &PriceRequest{AmountMinor: 1299, Currency: "EUR"}, price, "null leaves the price untouched")
+
+	price = &PriceRequest{}
+	require.NoError(t, json.Unmarshal([]byte(`{"amount_minor": 1299}`), price))
+	require.Equal(t, &PriceRequest{AmountMinor: 1299}, price)
+
+	// only book prices accept the legacy bare integer
+	var amount MoneyRequest
+	require.Error(t, json.Unmarshal([]byte("12"), &amount))
+}
+
+func TestHttpServer_CreateBook_InvalidRequest(t *testing.T) {
+	httpServer := NewHttpServer(Services{BookService: mocks.NewBookService(t)})
+
+	req := httptest.NewRequest(http.MethodPost, "/book", bytes.NewBufferString(`{"year": 2010, "stock": 1}`))
+	w := httptest.NewRecorder()
+
+	httpServer.CreateBook(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	require.Equal(t, server.ProblemContentType, res.Header.Get("Content-Type"))
+
+	var problem server.ProblemDetails
+	err := json.NewDecoder(res.Body).Decode(&problem)
+	require.NoError(t, err)
+
+	require.Equal(t, "invalid-request", problem.Slug)
+	fields := make([]string, 0, len(problem.Errors))
+	for _, fieldError := range problem.Errors {
+		fields = append(fields, fieldError.Field)
+	}
+	require.Equal(t, []string{"title", "author", "price", "category_id"}, fields)
+}
+
+// fixedRateService serves fixed exchange rates
+type fixedRateService struct {
+	ExchangeRateService
+	rates map[string]domain.ExchangeRate
+}
+
+func (s fixedRateService) GetRate(_ context.Context, currency string) (domain.ExchangeRate, error) {
+	rate, ok := s.rates[currency]
+	if !ok {
+		return domain.ExchangeRate{}, domain.ErrNotFound
+	}
+	return rate, nil
+}
+
+func TestHttpServer_GetBook_DisplayETag(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+
+	price, err := domain.NewMoney(1000, "USD")
+	require.NoError(t, err)
+	book, err := domain.NewBook(domain.NewBookData{
+		ID:         1,
+		Title:      "The history of Toptal",
+		Year:       2010,
+		Author:     "Taso Du Val",
+		Price:      price,
+		CategoryID: 1,
+		Version:    2,
+	})
+	require.NoError(t, err)
+	bookServiceMock.On("GetBook", mock.Anything, 1).Return(book, nil)
+
+	rate, err := domain.NewExchangeRate(domain.NewExchangeRateData{Currency: "EUR", Rate: "0.92", UpdatedAt: time.UnixMicro(5)})
+	require.NoError(t, err)
+	rates := fixedRateService{rates: map[string]domain.ExchangeRate{"EUR": rate}}
+	httpServer := NewHttpServer(Services{BookService: bookServiceMock, ExchangeRateService: rates})
+
+	get := func(currency string) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, "/book/1", nil)
+		req = mux.SetURLVars(req, map[string]string{"book_id": "1"})
+		if currency != "" {
+			req.Header.Set("Accept-Currency", currency)
+		}
+		w := httptest.NewRecorder()
+		httpServer.GetBook(w, req)
+		res := w.Result()
+		res.Body.Close()
+		return res
+	}
+
+	res := get("")
+	require.Equal(t, `"2"`, res.Header.Get(ETagHeader))
+	require.Equal(t, "Accept-Currency", res.Header.Get("Vary"))
+	require.Equal(t, `"2-USD"`, get("USD").Header.Get(ETagHeader))
+	require.Equal(t, `"2-EUR-5"`, get("EUR").Header.Get(ETagHeader))
+
+	// a new rate is a new representation
+	rates.rates["EUR"], err = domain.NewExchangeRate(domain.NewExchangeRateData{Currency: "EUR", Rate: "0.93", UpdatedAt: time.UnixMicro(6)})
+	require.NoError(t, err)
+	require.Equal(t, `"2-EUR-6"`, get("EUR").Header.Get(ETagHeader))
+}
+
+func TestHttpServer_UpdateBook_IfMatch(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+
+	price, err := domain.NewMoney(1000, "USD")
+	require.NoError(t, err)
+
+	currentBook, err := domain.NewBook(domain.NewBookData{
+		ID:         1,
+		Title:      "The history of Toptal",
+		Year:       2010,
+		Author:     "Taso Du Val",
+		Price:      price,
+		CategoryID: 1,
+		Version:    2,
+	})
+	require.NoError(t, err)
+
+	updatedBook, err := domain.NewBook(domain.NewBookData{
+		ID:         1,
+		Title:      "The history of Toptal, 2nd edition",
+		Year:       2010,
+		Author:     "Taso Du Val",
+		Price:      price,
+		CategoryID: 1,
+		Version:    3,
+	})
+	require.NoError(t, err)
+
+	bookServiceMock.On("GetBook", mock.Anything, 1).Return(currentBook, nil)
+	bookServiceMock.On("UpdateBook", mock.Anything, mock.MatchedBy(func(book domain.Book) bool {
+		return book.Version() == 2
+	}), []string{"title"}).Return(updatedBook, nil).Once()
+
+	httpServer := NewHttpServer(Services{BookService: bookServiceMock})
+
+	updateBookRequest := `{
+  "title": "The history of Toptal, 2nd edition",
+  "year": 2010,
+  "author": "Taso Du Val",
+  "price": {"amount_minor": 1000, "currency": "USD"},
+  "category_id": 1
+}`
+
+	update := func(ifMatch string) *http.Response {
+		req := httptest.NewRequest(http.MethodPatch, "/admin/books/1", bytes.NewBufferString(updateBookRequest))
+		req = mux.SetURLVars(req, map[string]string{"book_id": "1"})
+		if ifMatch != "" {
+			req.Header.Set(IfMatchHeader, ifMatch)
+		}
+		w := httptest.NewRecorder()
+		httpServer.UpdateBook(w, req)
+		return w.Result()
+	}
+
+	res := update("")
+	res.Body.Close()
+	require.Equal(t, http.StatusPreconditionRequired, res.StatusCode)
+
+	res = update(`"1"`)
+	res.Body.Close()
+	require.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+
+	res = update(`"1-EUR-5"`)
+	res.Body.Close()
+	require.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+
+	// the entity tags of every display currency of the current version match
+	res = update(`"2-EUR-5"`)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, `"3"`, res.Header.Get(ETagHeader))
+
+	var updateBookResponse BookResponse
+	err = json.NewDecoder(res.Body).Decode(&updateBookResponse)
+	require.NoError(t, err)
+	require.Equal(t, 3, updateBookResponse.Version)
+}
+
+func TestHttpServer_UpdateBook_MergePatch(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+
+	price, err := domain.NewMoney(1000, "USD")
+	require.NoError(t, err)
+
+	currentBook, err := domain.NewBook(domain.NewBookData{
+		ID:          1,
+		Title:       "The history of Toptal",
+		Year:        2010,
+		Author:      "Taso Du Val",
+		Price:       price,
+		Stock:       7,
+		CategoryID:  1,
+		TaxClass:    domain.TaxClassReduced,
+		WeightGrams: 400,
+		Version:     1,
+	})
+	require.NoError(t, err)
+
+	bookServiceMock.On("GetBook", mock.Anything, 1).Return(currentBook, nil)
+
+	httpServer := NewHttpServer(Services{BookService: bookServiceMock})
+
+	patch := func(body string) *http.Response {
+		req := httptest.NewRequest(http.MethodPatch, "/book/1", bytes.NewBufferString(body))
+		req = mux.SetURLVars(req, map[string]string{"book_id": "1"})
+		req.Header.Set("Content-Type", MergePatchContentType)
+		req.Header.Set(IfMatchHeader, `"1"`)
+		w := httptest.NewRecorder()
+		httpServer.UpdateBook(w, req)
+		return w.Result()
+	}
+
+	// only the price is validated and updated, the other fields are kept
+	bookServiceMock.On("UpdateBook", mock.Anything, mock.MatchedBy(func(book domain.Book) bool {
+		return book.Price().Amount() == 1500 && book.Title() == currentBook.Title() &&
+			book.TaxClass() == domain.TaxClassReduced && book.Stock() == 7
+	}), []string{"price"}).Return(currentBook, nil).Once()
+
+	res := patch(`{"price": {"amount_minor": 1500}}`)
+	res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	// removing the weight restores its default
+	bookServiceMock.On("UpdateBook", mock.Anything, mock.MatchedBy(func(book domain.Book) bool {
+		return book.WeightGrams() == 0
+	}), []string{"weight_grams"}).Return(currentBook, nil).Once()
+
+	res = patch(`{"weight_grams": null, "year": 2010}`)
+	res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	// required fields cannot be removed, invalid fields are reported
+	res = patch(`{"title": null, "year": 1000}`)
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	var problem server.ProblemDetails
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&problem))
+	res.Body.Close()
+	require.Equal(t, "invalid-request", problem.Slug)
+	require.Len(t, problem.Errors, 1)
+	require.Equal(t, "title", problem.Errors[0].Field)
+
+	res = patch(`{"year": 1000}`)
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&problem))
+	res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	require.Equal(t, "year", problem.Errors[0].Field)
+
+	// stock is not patchable
+	res = patch(`{"stock": 10}`)
+	res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
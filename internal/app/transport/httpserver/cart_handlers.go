@@ -5,8 +5,18 @@ import (
 	"net/http"
 
 	"github.com/northwindman/book-shop/internal/app/common/server"
+	"github.com/northwindman/book-shop/internal/app/domain"
 )
 
+// taxRegion returns the shipping region of the country and state query parameters, the default region when none is given
+func taxRegion(r *http.Request) (domain.TaxRegion, error) {
+	country, state := r.URL.Query().Get("country"), r.URL.Query().Get("state")
+	if country == "" && state == "" {
+		return domain.DefaultTaxRegion, nil
+	}
+	return domain.NewTaxRegion(country, state)
+}
+
 func (h HttpServer) UpdateCart(w http.ResponseWriter, r *http.Request) {
 	user, err := getUserFromContext(r.Context())
 	if err != nil {
@@ -14,6 +24,12 @@ func (h HttpServer) UpdateCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	region, err := taxRegion(r)
+	if err != nil {
+		server.RespondWithError(validationProblem(err), w, r)
+		return
+	}
+
 	var cartRequest CartRequest
 	if err := json.NewDecoder(r.Body).Decode(&cartRequest); err != nil {
 		server.BadRequest("invalid-json", err, w, r)
@@ -28,7 +44,7 @@ func (h HttpServer) UpdateCart(w http.ResponseWriter, r *http.Request) {
 
 	cart, err := toDomainCart(user.ID(), cartRequest)
 	if err != nil {
-		server.RespondWithError(err, w, r)
+		server.RespondWithError(validationProblem(err), w, r)
 		return
 	}
 
@@ -38,11 +54,128 @@ func (h HttpServer) UpdateCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response := toResponseCart(updatedCart)
+	quote, err := h.cartService.Quote(r.Context(), updatedCart, region)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := toResponseCart(updatedCart, quote)
+
+	server.RespondOK(response, w, r)
+}
+
+// GetCart returns the cart of the current user with its items and totals
+func (h HttpServer) GetCart(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	region, err := taxRegion(r)
+	if err != nil {
+		server.RespondWithError(validationProblem(err), w, r)
+		return
+	}
+
+	cart, quote, err := h.cartService.GetCart(r.Context(), user.ID(), region)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := toResponseCart(cart, quote)
 
 	server.RespondOK(response, w, r)
 }
 
+// ApplyCoupon applies a coupon code to the cart of the current user
+func (h HttpServer) ApplyCoupon(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	region, err := taxRegion(r)
+	if err != nil {
+		server.RespondWithError(validationProblem(err), w, r)
+		return
+	}
+
+	var couponRequest CouponRequest
+	if err := json.NewDecoder(r.Body).Decode(&couponRequest); err != nil {
+		server.BadRequest("invalid-json", err, w, r)
+		return
+	}
+
+	cart, quote, err := h.cartService.ApplyCoupon(r.Context(), user.ID(), couponRequest.Code, region)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := toResponseCart(cart, quote)
+
+	server.RespondOK(response, w, r)
+}
+
+// RemoveCoupon removes the coupon from the cart of the current user
+func (h HttpServer) RemoveCoupon(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	region, err := taxRegion(r)
+	if err != nil {
+		server.RespondWithError(validationProblem(err), w, r)
+		return
+	}
+
+	cart, quote, err := h.cartService.RemoveCoupon(r.Context(), user.ID(), region)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := toResponseCart(cart, quote)
+
+	server.RespondOK(response, w, r)
+}
+
+// GetShippingOptions returns the shipping methods available to the region of the country and state query parameters
+// priced for the cart of the current user
+func (h HttpServer) GetShippingOptions(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	region, err := taxRegion(r)
+	if err != nil {
+		server.RespondWithError(validationProblem(err), w, r)
+		return
+	}
+
+	options, err := h.cartService.ShippingOptions(r.Context(), user.ID(), region)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := make([]ShippingOptionResponse, 0, len(options))
+	for _, option := range options {
+		response = append(response, toResponseShippingOption(option))
+	}
+
+	server.RespondOK(response, w, r)
+}
+
+// Checkout places an order for the cart of the current user shipped to one of their addresses
 func (h HttpServer) Checkout(w http.ResponseWriter, r *http.Request) {
 	user, err := getUserFromContext(r.Context())
 	if err != nil {
@@ -50,11 +183,24 @@ func (h HttpServer) Checkout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.cartService.Checkout(r.Context(), user.ID())
+	var checkoutRequest CheckoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&checkoutRequest); err != nil {
+		server.BadRequest("invalid-json", err, w, r)
+		return
+	}
+
+	if err := checkoutRequest.Validate(); err != nil {
+		server.BadRequest("invalid-request", err, w, r)
+		return
+	}
+
+	order, err := h.cartService.Checkout(r.Context(), user.ID(), checkoutRequest.AddressID, checkoutRequest.ShippingMethod)
 	if err != nil {
 		server.RespondWithError(err, w, r)
 		return
 	}
 
-	server.RespondOK(map[string]bool{"ok": true}, w, r)
+	response := toResponseOrder(order)
+
+	server.RespondOK(response, w, r)
 }
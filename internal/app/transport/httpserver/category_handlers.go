@@ -31,6 +31,7 @@ func (h HttpServer) GetCategory(w http.ResponseWriter, r *http.Request) {
 
 	response := toResponseCategory(category)
 
+	w.Header().Set(ETagHeader, etag(category.Version()))
 	server.RespondOK(response, w, r)
 }
 
@@ -42,16 +43,12 @@ func (h HttpServer) CreateCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := categoryRequest.Validate(); err != nil {
-		server.BadRequest("invalid-request", err, w, r)
-		return
-	}
-
 	category, err := domain.NewCategory(domain.NewCategoryData{
-		Name: categoryRequest.Name,
+		Name:     categoryRequest.Name,
+		ParentID: categoryRequest.ParentID,
 	})
 	if err != nil {
-		server.RespondWithError(err, w, r)
+		server.RespondWithError(validationProblem(err), w, r)
 		return
 	}
 
@@ -63,9 +60,12 @@ func (h HttpServer) CreateCategory(w http.ResponseWriter, r *http.Request) {
 
 	response := toResponseCategory(insertedCategory)
 
+	w.Header().Set(ETagHeader, etag(insertedCategory.Version()))
 	server.RespondOK(response, w, r)
 }
 
+// UpdateCategory applies a JSON Merge Patch to a category by ID, the If-Match header must carry the ETag of the
+// current version. A patch that changes nothing returns the category as is.
 func (h HttpServer) UpdateCategory(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	categoryID, err := strconv.Atoi(vars["category_id"])
@@ -74,18 +74,20 @@ func (h HttpServer) UpdateCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var categoryRequest CategoryRequest
-	if err := json.NewDecoder(r.Body).Decode(&categoryRequest); err != nil {
+	var patchRequest CategoryPatchRequest
+	nulls, err := decodeMergePatch(r, &patchRequest)
+	if err != nil {
 		server.BadRequest("invalid-json", err, w, r)
 		return
 	}
 
-	if err := categoryRequest.Validate(); err != nil {
-		server.BadRequest("invalid-request", err, w, r)
+	patch, err := toDomainCategoryPatch(patchRequest, nulls)
+	if err != nil {
+		server.RespondWithError(err, w, r)
 		return
 	}
 
-	_, err = h.categoryService.GetCategory(r.Context(), categoryID)
+	currentCategory, err := h.categoryService.GetCategory(r.Context(), categoryID)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
 			server.NotFound("category-not-found", err, w, r)
@@ -95,26 +97,37 @@ func (h HttpServer) UpdateCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	category, err := domain.NewCategory(domain.NewCategoryData{
-		ID:   categoryID,
-		Name: categoryRequest.Name,
-	})
-	if err != nil {
+	if err := checkIfMatch(r, currentCategory.Version()); err != nil {
 		server.RespondWithError(err, w, r)
 		return
 	}
 
-	updatedCategory, err := h.categoryService.UpdateCategory(r.Context(), category)
+	category, changed, err := currentCategory.Patch(patch)
 	if err != nil {
-		server.RespondWithError(err, w, r)
+		server.RespondWithError(validationProblem(err), w, r)
 		return
 	}
 
+	updatedCategory := currentCategory
+	if len(changed) > 0 {
+		updatedCategory, err = h.categoryService.UpdateCategory(r.Context(), category, changed)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				server.NotFound("category-not-found", err, w, r)
+				return
+			}
+			server.RespondWithError(err, w, r)
+			return
+		}
+	}
+
 	response := toResponseCategory(updatedCategory)
 
+	w.Header().Set(ETagHeader, etag(updatedCategory.Version()))
 	server.RespondOK(response, w, r)
 }
 
+// DeleteCategory deletes a category by ID, the If-Match header must carry the ETag of the current version
 func (h HttpServer) DeleteCategory(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	categoryID, err := strconv.Atoi(vars["category_id"])
@@ -123,7 +136,7 @@ func (h HttpServer) DeleteCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = h.categoryService.GetCategory(r.Context(), categoryID)
+	currentCategory, err := h.categoryService.GetCategory(r.Context(), categoryID)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
 			server.NotFound("category-not-found", err, w, r)
@@ -133,7 +146,12 @@ func (h HttpServer) DeleteCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.categoryService.DeleteCategory(r.Context(), categoryID)
+	if err := checkIfMatch(r, currentCategory.Version()); err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	err = h.categoryService.DeleteCategory(r.Context(), categoryID, currentCategory.Version())
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
 			server.NotFound("category-not-found", err, w, r)
@@ -146,13 +164,30 @@ func (h HttpServer) DeleteCategory(w http.ResponseWriter, r *http.Request) {
 	server.RespondOK(map[string]bool{"deleted": true}, w, r)
 }
 
+// GetCategories returns the categories ordered by ID, or with tree=true the top level categories with the categories
+// below them
 func (h HttpServer) GetCategories(w http.ResponseWriter, r *http.Request) {
+	var tree bool
+	if query := r.URL.Query().Get("tree"); query != "" {
+		var err error
+		tree, err = strconv.ParseBool(query)
+		if err != nil {
+			server.BadRequest("invalid-tree", err, w, r)
+			return
+		}
+	}
+
 	categories, err := h.categoryService.GetCategories(r.Context())
 	if err != nil {
 		server.RespondWithError(err, w, r)
 		return
 	}
 
+	if tree {
+		server.RespondOK(toResponseCategoryTree(domain.CategoryTree(categories)), w, r)
+		return
+	}
+
 	response := make([]CategoryResponse, 0, len(categories))
 	for _, category := range categories {
 		response = append(response, toResponseCategory(category))
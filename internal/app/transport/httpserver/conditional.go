@@ -0,0 +1,52 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/northwindman/book-shop/internal/app/common/slugerrors"
+)
+
+const (
+	ETagHeader    = "ETag"
+	IfMatchHeader = "If-Match"
+)
+
+// etag returns the strong entity tag of a resource version
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// setDisplayETag sets the entity tag of a resource version rendered with prices in the display currency of variant,
+// every display currency is a representation of its own
+func setDisplayETag(w http.ResponseWriter, version int, variant string) {
+	w.Header().Add("Vary", "Accept-Currency")
+	if variant == "" {
+		w.Header().Set(ETagHeader, etag(version))
+		return
+	}
+	w.Header().Set(ETagHeader, `"`+strconv.Itoa(version)+"-"+variant+`"`)
+}
+
+// checkIfMatch requires an If-Match header listing the entity tag of the current version of a resource, or "*".
+// Editing a resource without it could silently overwrite a concurrent edit. The entity tags of every display
+// currency of the current version match.
+func checkIfMatch(r *http.Request, version int) error {
+	header := strings.Join(r.Header.Values(IfMatchHeader), ",")
+	if strings.TrimSpace(header) == "" {
+		return slugerrors.NewPreconditionRequiredError(
+			"the If-Match header with the ETag of the resource is required", "if-match-required")
+	}
+
+	current := etag(version)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == current || strings.HasPrefix(tag, strings.TrimSuffix(current, `"`)+"-") {
+			return nil
+		}
+	}
+
+	return slugerrors.NewPreconditionFailedError(
+		"the resource was modified since it was read, fetch it again and retry", "version-conflict")
+}
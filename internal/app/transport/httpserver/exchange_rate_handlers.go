@@ -0,0 +1,114 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/northwindman/book-shop/internal/app/common/server"
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// GetExchangeRates returns all exchange rates
+func (h HttpServer) GetExchangeRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.exchangeRateService.GetRates(r.Context())
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := make([]ExchangeRateResponse, 0, len(rates))
+	for _, rate := range rates {
+		response = append(response, toResponseExchangeRate(rate))
+	}
+
+	server.RespondOK(response, w, r)
+}
+
+// SetExchangeRate creates or replaces the exchange rate of a currency
+func (h HttpServer) SetExchangeRate(w http.ResponseWriter, r *http.Request) {
+	var rateRequest ExchangeRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&rateRequest); err != nil {
+		server.BadRequest("invalid-json", err, w, r)
+		return
+	}
+
+	rate, err := domain.NewExchangeRate(domain.NewExchangeRateData{
+		Currency: mux.Vars(r)["currency"],
+		Rate:     rateRequest.Rate,
+	})
+	if err != nil {
+		server.RespondWithError(validationProblem(err), w, r)
+		return
+	}
+
+	savedRates, err := h.exchangeRateService.SetRates(r.Context(), rate)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(toResponseExchangeRate(savedRates[0]), w, r)
+}
+
+// DeleteExchangeRate deletes the exchange rate of a currency, prices are no longer displayed in it
+func (h HttpServer) DeleteExchangeRate(w http.ResponseWriter, r *http.Request) {
+	err := h.exchangeRateService.DeleteRate(r.Context(), mux.Vars(r)["currency"])
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("exchange-rate-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(map[string]bool{"ok": true}, w, r)
+}
+
+// priceConverter returns a converter to the display currency requested with the currency query parameter
+// or the Accept-Currency header, it returns nil when no currency is requested. The variant identifies the
+// representation in entity tags, it names the currency and the version of its rate.
+func (h HttpServer) priceConverter(r *http.Request) (convert func(domain.Money) (domain.Money, error), variant string, err error) {
+	currency := r.URL.Query().Get("currency")
+	if currency == "" {
+		// only the preferred currency of the header is used, e.g. "EUR" in "EUR, GBP;q=0.5"
+		currency, _, _ = strings.Cut(r.Header.Get("Accept-Currency"), ",")
+		currency, _, _ = strings.Cut(currency, ";")
+	}
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+
+	switch currency {
+	case "":
+		return nil, "", nil
+	case domain.SettlementCurrency:
+		return func(price domain.Money) (domain.Money, error) { return price, nil }, currency, nil
+	}
+
+	rate, err := h.exchangeRateService.GetRate(r.Context(), currency)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return rate.Convert, currency + "-" + strconv.FormatInt(rate.UpdatedAt().UnixMicro(), 10), nil
+}
+
+// toDisplayBook renders a book with its price converted to the display currency, if any
+func toDisplayBook(book domain.Book, convert func(domain.Money) (domain.Money, error)) (BookResponse, error) {
+	response := toResponseBook(book)
+	if convert == nil {
+		return response, nil
+	}
+
+	displayPrice, err := convert(book.Price())
+	if err != nil {
+		return BookResponse{}, err
+	}
+	price := toResponseMoney(displayPrice)
+	response.DisplayPrice = &price
+
+	return response, nil
+}
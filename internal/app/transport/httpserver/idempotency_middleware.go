@@ -0,0 +1,133 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/northwindman/book-shop/internal/app/common/server"
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// maxIdempotentBodySize bounds the request bodies read to fingerprint them
+	maxIdempotentBodySize = 1 << 20
+)
+
+// Idempotent runs a request sent with an Idempotency-Key header once and replays its response to retries.
+// Reusing a key for a different request, or while the first request is still running, is a conflict.
+// Keys are scoped to the current user, so the handler must be wrapped in CheckAuthorizedUser.
+// Server errors are not stored, a retry runs the request again.
+func (h HttpServer) Idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next(w, r)
+			return
+		}
+
+		user, err := getUserFromContext(r.Context())
+		if err != nil {
+			server.BadRequest("invalid-user", err, w, r)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentBodySize+1))
+		if err != nil {
+			server.BadRequest("invalid-body", err, w, r)
+			return
+		}
+		if len(body) > maxIdempotentBodySize {
+			server.BadRequest("request-too-large", fmt.Errorf("request body exceeds %d bytes", maxIdempotentBodySize), w, r)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		request, err := domain.NewIdempotentRequest(user.ID(), key, r.Method, r.URL.Path, r.URL.RawQuery, body)
+		if err != nil {
+			server.RespondWithError(validationProblem(err), w, r)
+			return
+		}
+
+		stored, replay, err := h.idempotencyService.Begin(r.Context(), request)
+		switch {
+		case errors.Is(err, domain.ErrIdempotencyKeyReused):
+			server.Conflict("idempotency-key-reused", err, w, r)
+			return
+		case errors.Is(err, domain.ErrIdempotencyKeyInProgress):
+			server.Conflict("idempotency-key-in-progress", err, w, r)
+			return
+		case err != nil:
+			server.RespondWithError(err, w, r)
+			return
+		}
+
+		if replay {
+			if stored.ContentType != "" {
+				w.Header().Set("Content-Type", stored.ContentType)
+			}
+			w.Header().Set(IdempotentReplayedHeader, "true")
+			w.WriteHeader(stored.StatusCode)
+			_, _ = w.Write(stored.Body)
+			return
+		}
+
+		// the key is kept or released even when the client goes away
+		ctx := context.WithoutCancel(r.Context())
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := h.idempotencyService.Release(ctx, request); err != nil {
+				log.Printf("failed to release idempotency key: %v", err)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next(recorder, r)
+
+		if recorder.statusCode >= http.StatusInternalServerError {
+			return
+		}
+		err = h.idempotencyService.Complete(ctx, request, domain.IdempotentResponse{
+			StatusCode:  recorder.statusCode,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		})
+		if err != nil {
+			log.Printf("failed to store idempotent response: %v", err)
+			return
+		}
+		completed = true
+	}
+}
+
+// responseRecorder writes a response through and keeps a copy of it
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	if !r.wroteHeader {
+		r.statusCode = statusCode
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,120 @@
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryIdempotencyService keeps idempotency keys in memory, a nil response marks a request in progress
+type memoryIdempotencyService struct {
+	mu        sync.Mutex
+	requests  map[string]domain.IdempotentRequest
+	responses map[string]*domain.IdempotentResponse
+}
+
+func newMemoryIdempotencyService() *memoryIdempotencyService {
+	return &memoryIdempotencyService{
+		requests:  map[string]domain.IdempotentRequest{},
+		responses: map[string]*domain.IdempotentResponse{},
+	}
+}
+
+func (s *memoryIdempotencyService) Begin(_ context.Context, request domain.IdempotentRequest) (domain.IdempotentResponse, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.requests[request.Key()]
+	switch {
+	case !ok:
+		s.requests[request.Key()] = request
+		s.responses[request.Key()] = nil
+		return domain.IdempotentResponse{}, false, nil
+	case existing.Fingerprint() != request.Fingerprint():
+		return domain.IdempotentResponse{}, false, domain.ErrIdempotencyKeyReused
+	case s.responses[request.Key()] == nil:
+		return domain.IdempotentResponse{}, false, domain.ErrIdempotencyKeyInProgress
+	}
+	return *s.responses[request.Key()], true, nil
+}
+
+func (s *memoryIdempotencyService) Complete(_ context.Context, request domain.IdempotentRequest, response domain.IdempotentResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses[request.Key()] = &response
+	return nil
+}
+
+func (s *memoryIdempotencyService) Release(_ context.Context, request domain.IdempotentRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.requests, request.Key())
+	delete(s.responses, request.Key())
+	return nil
+}
+
+func TestHttpServer_Idempotent(t *testing.T) {
+	user, err := domain.NewUser(domain.NewUserData{ID: 1, Username: "bob"})
+	require.NoError(t, err)
+
+	httpServer := NewHttpServer(Services{IdempotencyService: newMemoryIdempotencyService()})
+
+	calls := 0
+	status := http.StatusOK
+	handler := httpServer.Idempotent(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"order":1}`))
+	})
+
+	sendTo := func(target, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), ContextUserKey, user))
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+	send := func(key, body string) *httptest.ResponseRecorder {
+		return sendTo("/checkout", key, body)
+	}
+
+	first := send("key-1", `{"address_id":1}`)
+	require.Equal(t, http.StatusOK, first.Code)
+	require.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+
+	replayed := send("key-1", `{"address_id":1}`)
+	require.Equal(t, http.StatusOK, replayed.Code)
+	require.Equal(t, "true", replayed.Header().Get(IdempotentReplayedHeader))
+	require.Equal(t, "application/json", replayed.Header().Get("Content-Type"))
+	require.Equal(t, `{"order":1}`, replayed.Body.String())
+	require.Equal(t, 1, calls)
+
+	require.Equal(t, http.StatusConflict, send("key-1", `{"address_id":2}`).Code)
+	require.Equal(t, http.StatusConflict, sendTo("/checkout?currency=EUR", "key-1", `{"address_id":1}`).Code)
+	require.Equal(t, 1, calls)
+
+	// requests without a key and failed requests run every time
+	send("", `{"address_id":1}`)
+	send("", `{"address_id":1}`)
+	require.Equal(t, 3, calls)
+
+	status = http.StatusInternalServerError
+	send("key-2", `{}`)
+	status = http.StatusOK
+	require.Equal(t, http.StatusOK, send("key-2", `{}`).Code)
+	require.Equal(t, 5, calls)
+
+	require.Equal(t, http.StatusBadRequest, send(strings.Repeat("k", 256), `{}`).Code)
+}
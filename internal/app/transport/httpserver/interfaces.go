@@ -6,6 +6,7 @@ import (
 	"context"
 
 	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/pkg/scheduler"
 )
 
 // UserService is a user service
@@ -24,10 +25,11 @@ type TokenService interface {
 // BookService is a book service
 type BookService interface {
 	GetBook(ctx context.Context, id int) (domain.Book, error)
-	GetBooks(ctx context.Context, categoryIDs []int, limit, offset int) ([]domain.Book, error)
+	GetBookByISBN(ctx context.Context, isbn string) (domain.Book, error)
+	GetBooks(ctx context.Context, categoryIDs []int, descendants bool, sort string, limit, offset int) ([]domain.Book, error)
 	CreateBook(ctx context.Context, book domain.Book) (domain.Book, error)
-	UpdateBook(ctx context.Context, book domain.Book) (domain.Book, error)
-	DeleteBook(ctx context.Context, id int) error
+	UpdateBook(ctx context.Context, book domain.Book, fields []string) (domain.Book, error)
+	DeleteBook(ctx context.Context, id, version int) error
 }
 
 // CategoryService is a category service
@@ -35,11 +37,133 @@ type CategoryService interface {
 	GetCategory(ctx context.Context, id int) (domain.Category, error)
 	GetCategories(ctx context.Context) ([]domain.Category, error)
 	CreateCategory(ctx context.Context, category domain.Category) (domain.Category, error)
-	UpdateCategory(ctx context.Context, category domain.Category) (domain.Category, error)
-	DeleteCategory(ctx context.Context, id int) error
+	UpdateCategory(ctx context.Context, category domain.Category, fields []string) (domain.Category, error)
+	DeleteCategory(ctx context.Context, id, version int) error
+}
+
+// AuthorService manages the authors credited on books
+type AuthorService interface {
+	GetAuthor(ctx context.Context, id int) (domain.Author, error)
+	GetAuthors(ctx context.Context) ([]domain.Author, error)
+	GetAuthorBooks(ctx context.Context, id int) ([]domain.AuthorBook, error)
+	CreateAuthor(ctx context.Context, author domain.Author) (domain.Author, error)
+	UpdateAuthor(ctx context.Context, author domain.Author, fields []string) (domain.Author, error)
+	DeleteAuthor(ctx context.Context, id, version int) error
 }
 
 type CartService interface {
+	GetCart(ctx context.Context, userID int, region domain.TaxRegion) (domain.Cart, domain.Quote, error)
+	Quote(ctx context.Context, cart domain.Cart, region domain.TaxRegion) (domain.Quote, error)
 	UpdateCartAndStocks(ctx context.Context, cart domain.Cart) (domain.Cart, error)
-	Checkout(ctx context.Context, userID int) error
+	ApplyCoupon(ctx context.Context, userID int, code string, region domain.TaxRegion) (domain.Cart, domain.Quote, error)
+	RemoveCoupon(ctx context.Context, userID int, region domain.TaxRegion) (domain.Cart, domain.Quote, error)
+	ShippingOptions(ctx context.Context, userID int, region domain.TaxRegion) ([]domain.ShippingOption, error)
+	Checkout(ctx context.Context, userID, addressID int, shippingMethod string) (domain.Order, error)
+}
+
+// AddressService manages the address books of users
+type AddressService interface {
+	GetAddress(ctx context.Context, userID, id int) (domain.Address, error)
+	GetAddresses(ctx context.Context, userID int) ([]domain.Address, error)
+	CreateAddress(ctx context.Context, address domain.Address) (domain.Address, error)
+	UpdateAddress(ctx context.Context, address domain.Address) (domain.Address, error)
+	DeleteAddress(ctx context.Context, userID, id int) error
+}
+
+// ShippingService manages the shipping rate table
+type ShippingService interface {
+	GetRates(ctx context.Context) ([]domain.ShippingRate, error)
+	SetRate(ctx context.Context, rate domain.ShippingRate) (domain.ShippingRate, error)
+	DeleteRate(ctx context.Context, id int) error
+}
+
+// TaxService manages the tax rate table
+type TaxService interface {
+	GetRates(ctx context.Context) ([]domain.TaxRate, error)
+	SetRate(ctx context.Context, rate domain.TaxRate) (domain.TaxRate, error)
+	DeleteRate(ctx context.Context, id int) error
+}
+
+// PromotionService manages promotions and coupon codes
+type PromotionService interface {
+	GetPromotion(ctx context.Context, id int) (domain.Promotion, error)
+	GetPromotions(ctx context.Context) ([]domain.Promotion, error)
+	CreatePromotion(ctx context.Context, promotion domain.Promotion) (domain.Promotion, error)
+	UpdatePromotion(ctx context.Context, promotion domain.Promotion) (domain.Promotion, error)
+	DeletePromotion(ctx context.Context, id int) error
+}
+
+// OrderService is an order service
+type OrderService interface {
+	GetOrder(ctx context.Context, userID, id int) (domain.Order, error)
+	GetOrders(ctx context.Context, userID int, limit, offset int) ([]domain.Order, error)
+}
+
+// ExchangeRateService manages the exchange rates of display currencies
+type ExchangeRateService interface {
+	GetRate(ctx context.Context, currency string) (domain.ExchangeRate, error)
+	GetRates(ctx context.Context) ([]domain.ExchangeRate, error)
+	SetRates(ctx context.Context, rates ...domain.ExchangeRate) ([]domain.ExchangeRate, error)
+	DeleteRate(ctx context.Context, currency string) error
+}
+
+// InventoryService adjusts the stock of books through the inventory ledger
+type InventoryService interface {
+	AdjustStock(ctx context.Context, movement domain.InventoryMovement) (domain.InventoryMovement, error)
+	GetMovements(ctx context.Context, bookID, limit, offset int) ([]domain.InventoryMovement, error)
+	GetLowStock(ctx context.Context) ([]domain.LowStockItem, error)
+}
+
+// StockSubscriptionService subscribes users to sold-out books
+type StockSubscriptionService interface {
+	Subscribe(ctx context.Context, userID, bookID int) (domain.StockSubscription, error)
+	Unsubscribe(ctx context.Context, userID, bookID int) error
+}
+
+// WishlistService manages the wishlists of users
+type WishlistService interface {
+	GetWishlist(ctx context.Context, userID int) (domain.Wishlist, error)
+	GetSharedWishlist(ctx context.Context, token string) (domain.Wishlist, error)
+	AddItem(ctx context.Context, userID, bookID int) (domain.Wishlist, error)
+	RemoveItem(ctx context.Context, userID, bookID int) error
+	MoveToCart(ctx context.Context, userID int, bookIDs []int) ([]int, []int, error)
+	Share(ctx context.Context, userID int) (domain.Wishlist, error)
+	Unshare(ctx context.Context, userID int) error
+}
+
+// ReviewService submits and moderates the reviews of books
+type ReviewService interface {
+	CreateReview(ctx context.Context, review domain.Review) (domain.Review, error)
+	GetBookReviews(ctx context.Context, bookID, limit, offset int) ([]domain.Review, error)
+	GetReviews(ctx context.Context, status string, limit, offset int) ([]domain.Review, error)
+	ApproveReview(ctx context.Context, id, adminID int) (domain.Review, error)
+	RejectReview(ctx context.Context, id int, reason string, adminID int) (domain.Review, error)
+}
+
+// RecommendationService recommends books bought together by customers
+type RecommendationService interface {
+	GetRecommendations(ctx context.Context, bookID, userID int) ([]domain.Recommendation, error)
+}
+
+// IdempotencyService stores the responses of requests sent with idempotency keys
+type IdempotencyService interface {
+	Begin(ctx context.Context, request domain.IdempotentRequest) (domain.IdempotentResponse, bool, error)
+	Complete(ctx context.Context, request domain.IdempotentRequest, response domain.IdempotentResponse) error
+	Release(ctx context.Context, request domain.IdempotentRequest) error
+}
+
+// JobService reports the status of background jobs
+type JobService interface {
+	Statuses() []scheduler.Status
+}
+
+// WebhookService is a webhook service
+type WebhookService interface {
+	GetWebhook(ctx context.Context, id int) (domain.WebhookSubscription, error)
+	GetWebhooks(ctx context.Context) ([]domain.WebhookSubscription, error)
+	CreateWebhook(ctx context.Context, subscription domain.WebhookSubscription) (domain.WebhookSubscription, error)
+	UpdateWebhook(ctx context.Context, subscription domain.WebhookSubscription) (domain.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	GetDeliveries(ctx context.Context, subscriptionID int, limit, offset int) ([]domain.WebhookDelivery, error)
+	RetryDelivery(ctx context.Context, subscriptionID int, deliveryID int64) error
 }
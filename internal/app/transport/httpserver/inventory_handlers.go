@@ -0,0 +1,111 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/northwindman/book-shop/internal/app/common/server"
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// AdjustStock restocks a book, records a return or corrects its stock, the adjustment is recorded in the ledger
+func (h HttpServer) AdjustStock(w http.ResponseWriter, r *http.Request) {
+	admin, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bookID, err := strconv.Atoi(vars["book_id"])
+	if err != nil {
+		server.BadRequest("invalid-book-id", err, w, r)
+		return
+	}
+
+	var adjustmentRequest StockAdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&adjustmentRequest); err != nil {
+		server.BadRequest("invalid-json", err, w, r)
+		return
+	}
+
+	adjustment, err := domain.NewStockAdjustment(bookID, adjustmentRequest.Type, adjustmentRequest.Delta,
+		adjustmentRequest.Reason, adjustmentRequest.OrderID, admin.ID())
+	if err != nil {
+		server.RespondWithError(validationProblem(err), w, r)
+		return
+	}
+
+	movement, err := h.inventoryService.AdjustStock(r.Context(), adjustment)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("book-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(toResponseInventoryMovement(movement), w, r)
+}
+
+// GetStockMovements returns the inventory ledger of a book, newest first
+func (h HttpServer) GetStockMovements(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bookID, err := strconv.Atoi(vars["book_id"])
+	if err != nil {
+		server.BadRequest("invalid-book-id", err, w, r)
+		return
+	}
+
+	_, err = h.bookService.GetBook(r.Context(), bookID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("book-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	// page
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit := 50
+	offset := (page - 1) * limit
+
+	movements, err := h.inventoryService.GetMovements(r.Context(), bookID, limit, offset)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := make([]InventoryMovementResponse, 0, len(movements))
+	for _, movement := range movements {
+		response = append(response, toResponseInventoryMovement(movement))
+	}
+
+	server.RespondOK(response, w, r)
+}
+
+// GetLowStock returns the books at or below their reorder threshold with suggested reorder quantities,
+// lowest stock first
+func (h HttpServer) GetLowStock(w http.ResponseWriter, r *http.Request) {
+	items, err := h.inventoryService.GetLowStock(r.Context())
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := make([]LowStockResponse, 0, len(items))
+	for _, item := range items {
+		response = append(response, toResponseLowStock(item))
+	}
+
+	server.RespondOK(response, w, r)
+}
@@ -0,0 +1,19 @@
+package httpserver
+
+import (
+	"net/http"
+
+	"github.com/northwindman/book-shop/internal/app/common/server"
+)
+
+// GetJobs returns the status of the background jobs of this instance
+func (h HttpServer) GetJobs(w http.ResponseWriter, r *http.Request) {
+	statuses := h.jobService.Statuses()
+
+	response := make([]JobStatusResponse, 0, len(statuses))
+	for _, status := range statuses {
+		response = append(response, toResponseJobStatus(status))
+	}
+
+	server.RespondOK(response, w, r)
+}
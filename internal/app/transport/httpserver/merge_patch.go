@@ -0,0 +1,47 @@
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+)
+
+// MergePatchContentType is the media type of a JSON Merge Patch (RFC 7396), plain JSON bodies are accepted too
+const MergePatchContentType = "application/merge-patch+json"
+
+// decodeMergePatch decodes a JSON Merge Patch into patch, a struct of pointer fields left nil for absent members.
+// The members set to null, which remove a value, are returned sorted since they decode to nil too.
+// Members unknown to patch are rejected.
+func decodeMergePatch(r *http.Request, patch any) ([]string, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the patch: %w", err)
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(body, &members); err != nil {
+		return nil, fmt.Errorf("the patch must be a JSON object: %w", err)
+	}
+	if members == nil {
+		return nil, fmt.Errorf("the patch must be a JSON object, not null")
+	}
+
+	var nulls []string
+	for name, value := range members {
+		if string(bytes.TrimSpace(value)) == "null" {
+			nulls = append(nulls, name)
+		}
+	}
+	sort.Strings(nulls)
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(patch); err != nil {
+		return nil, err
+	}
+
+	return nulls, nil
+}
@@ -1,63 +1,214 @@
 package httpserver
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
+	"time"
 
 	"github.com/northwindman/book-shop/internal/app/domain"
 )
 
-type BookRequest struct {
-	Title      string `json:"title"`
-	Year       int    `json:"year"`
-	Author     string `json:"author"`
-	Price      int    `json:"price"`
-	Stock      int    `json:"stock"`
-	CategoryID int    `json:"category_id"`
+// MoneyRequest is an amount in minor units, the currency defaults to the settlement currency
+type MoneyRequest struct {
+	AmountMinor int64  `json:"amount_minor"`
+	Currency    string `json:"currency"`
 }
 
-func (r *BookRequest) Validate() error {
-	if r.Title == "" {
-		return fmt.Errorf("%w: title", domain.ErrRequired)
-	}
-	if r.Year <= 0 {
-		return fmt.Errorf("%w: year", domain.ErrNegative)
-	}
-	if r.Author == "" {
-		return fmt.Errorf("%w: author", domain.ErrRequired)
-	}
-	if r.Price <= 0 {
-		return fmt.Errorf("%w: price", domain.ErrNegative)
+// PriceRequest is the price of a book, it also accepts the legacy bare integer, a whole amount of the settlement currency
+type PriceRequest MoneyRequest
+
+// UnmarshalJSON decodes a MoneyRequest or the legacy bare integer
+func (m *PriceRequest) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
 	}
-	if r.CategoryID == 0 {
-		return fmt.Errorf("%w: category_id", domain.ErrRequired)
+
+	var whole int64
+	if err := json.Unmarshal(data, &whole); err == nil {
+		exponent, err := domain.CurrencyExponent(domain.SettlementCurrency)
+		if err != nil {
+			return err
+		}
+		amount := whole
+		for i := 0; i < exponent; i++ {
+			if amount > math.MaxInt64/10 || amount < math.MinInt64/10 {
+				return fmt.Errorf("amount %d is out of range", whole)
+			}
+			amount *= 10
+		}
+		*m = PriceRequest{AmountMinor: amount, Currency: domain.SettlementCurrency}
+		return nil
 	}
-	return nil
+
+	return json.Unmarshal(data, (*MoneyRequest)(m))
+}
+
+// MoneyResponse renders an amount both as a decimal string and in minor units
+type MoneyResponse struct {
+	Amount      string `json:"amount"`
+	AmountMinor int64  `json:"amount_minor"`
+	Currency    string `json:"currency"`
+}
+
+type BookRequest struct {
+	Title      string        `json:"title"`
+	Year       int           `json:"year"`
+	Author     string        `json:"author"`
+	Price      *PriceRequest `json:"price"`
+	Stock      int           `json:"stock"`
+	CategoryID int           `json:"category_id"`
+	TaxClass   string        `json:"tax_class"`
+	// WeightGrams is the shipping weight of the book
+	WeightGrams int `json:"weight_grams"`
+	// ReorderThreshold is the stock at or below which the book is reported as low on stock
+	ReorderThreshold int `json:"reorder_threshold"`
+	// ReleaseDate is the publication date as YYYY-MM-DD, empty when the book is released
+	ReleaseDate string `json:"release_date"`
+	// Preorder lets customers order the book while it is out of stock, up to PreorderCap copies
+	Preorder    bool `json:"preorder"`
+	PreorderCap int  `json:"preorder_cap"`
+	// Authors credits the authors of the book, it replaces Author which then credits a single author by name
+	Authors []BookAuthorRequest `json:"authors"`
+	// ISBN is an ISBN-10 or ISBN-13 with or without hyphens, it is stored as an ISBN-13
+	ISBN      string `json:"isbn"`
+	Publisher string `json:"publisher"`
+	Edition   int    `json:"edition"`
+	// Language is an ISO 639-1 code such as "en"
+	Language  string `json:"language"`
+	PageCount int    `json:"page_count"`
+	// Format is hardcover, paperback or ebook
+	Format      string `json:"format"`
+	Description string `json:"description"`
+	// CategoryIDs are the categories of the book, CategoryID is its primary category and defaults to the first
+	CategoryIDs []int `json:"category_ids"`
+}
+
+// BookAuthorRequest credits an author by ID, or by name when it has no ID yet, the role defaults to author
+type BookAuthorRequest struct {
+	AuthorID int    `json:"author_id"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+}
+
+// BookPatchRequest is a JSON Merge Patch of a book, absent members are left unchanged
+type BookPatchRequest struct {
+	Title            *string              `json:"title"`
+	Year             *int                 `json:"year"`
+	Author           *string              `json:"author"`
+	Price            *PriceRequest        `json:"price"`
+	CategoryID       *int                 `json:"category_id"`
+	TaxClass         *string              `json:"tax_class"`
+	WeightGrams      *int                 `json:"weight_grams"`
+	ReorderThreshold *int                 `json:"reorder_threshold"`
+	ReleaseDate      *string              `json:"release_date"`
+	Preorder         *bool                `json:"preorder"`
+	PreorderCap      *int                 `json:"preorder_cap"`
+	Authors          *[]BookAuthorRequest `json:"authors"`
+	ISBN             *string              `json:"isbn"`
+	Publisher        *string              `json:"publisher"`
+	Edition          *int                 `json:"edition"`
+	Language         *string              `json:"language"`
+	PageCount        *int                 `json:"page_count"`
+	Format           *string              `json:"format"`
+	Description      *string              `json:"description"`
+	CategoryIDs      *[]int               `json:"category_ids"`
 }
 
 type BookResponse struct {
-	ID         int    `json:"id"`
-	Title      string `json:"title"`
-	Year       int    `json:"year"`
-	Author     string `json:"author"`
-	Price      int    `json:"price"`
-	Stock      int    `json:"stock"`
-	CategoryID int    `json:"category_id"`
+	ID               int           `json:"id"`
+	Title            string        `json:"title"`
+	Year             int           `json:"year"`
+	Author           string        `json:"author"`
+	Price            MoneyResponse `json:"price"`
+	Stock            int           `json:"stock"`
+	CategoryID       int           `json:"category_id"`
+	TaxClass         string        `json:"tax_class"`
+	WeightGrams      int           `json:"weight_grams"`
+	ReorderThreshold int           `json:"reorder_threshold"`
+	ReleaseDate      string        `json:"release_date,omitempty"`
+	Preorder         bool          `json:"preorder"`
+	PreorderCap      int           `json:"preorder_cap"`
+	// Preorders is the number of copies pre-ordered and not yet fulfilled
+	Preorders int `json:"preorders"`
+	// Authors are the credited authors, Author is their byline
+	Authors []BookAuthorResponse `json:"authors,omitempty"`
+	// ISBN is the ISBN-13 of the book, the publication metadata is omitted when unknown
+	ISBN        string `json:"isbn,omitempty"`
+	Publisher   string `json:"publisher,omitempty"`
+	Edition     int    `json:"edition,omitempty"`
+	Language    string `json:"language,omitempty"`
+	PageCount   int    `json:"page_count,omitempty"`
+	Format      string `json:"format,omitempty"`
+	Description string `json:"description,omitempty"`
+	// CategoryIDs are the categories of the book, CategoryID is its primary category
+	CategoryIDs []int `json:"category_ids,omitempty"`
+	// RatingAverage is the average rating of the approved reviews rounded to two decimals, zero when there are none
+	RatingAverage float64 `json:"rating_average"`
+	RatingCount   int     `json:"rating_count"`
+	Version       int     `json:"version"`
+	// DisplayPrice is the price in the requested display currency, orders are always settled in the price currency
+	DisplayPrice *MoneyResponse `json:"display_price,omitempty"`
+}
+
+type BookAuthorResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+type AuthorRequest struct {
+	Name string `json:"name"`
+	Bio  string `json:"bio"`
+}
+
+// AuthorPatchRequest is a JSON Merge Patch of an author, absent members are left unchanged
+type AuthorPatchRequest struct {
+	Name *string `json:"name"`
+	Bio  *string `json:"bio"`
+}
+
+type AuthorResponse struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Bio     string `json:"bio,omitempty"`
+	Version int    `json:"version"`
+}
+
+// AuthorDetailResponse is an author with the books credited to them
+type AuthorDetailResponse struct {
+	AuthorResponse
+	Books []AuthorBookResponse `json:"books"`
+}
+
+type AuthorBookResponse struct {
+	Book BookResponse `json:"book"`
+	Role string       `json:"role"`
 }
 
 type CategoryRequest struct {
 	Name string `json:"name"`
+	// ParentID is the category the category belongs to, zero for a top level category
+	ParentID int `json:"parent_id"`
 }
 
-func (r *CategoryRequest) Validate() error {
-	if r.Name == "" {
-		return fmt.Errorf("%w: name", domain.ErrRequired)
-	}
-	return nil
+// CategoryPatchRequest is a JSON Merge Patch of a category, absent members are left unchanged
+type CategoryPatchRequest struct {
+	Name     *string `json:"name"`
+	ParentID *int    `json:"parent_id"`
 }
 
 type CategoryResponse struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	ParentID int    `json:"parent_id,omitempty"`
+	Version  int    `json:"version"`
+}
+
+// CategoryNodeResponse is a category with the categories below it
+type CategoryNodeResponse struct {
+	CategoryResponse
+	Children []CategoryNodeResponse `json:"children"`
 }
 
 type AuthRequest struct {
@@ -66,13 +217,14 @@ type AuthRequest struct {
 }
 
 func (r *AuthRequest) Validate() error {
+	var v violations
 	if r.Username == "" {
-		return fmt.Errorf("%w: username", domain.ErrRequired)
+		v.add("username", domain.ErrRequired)
 	}
 	if r.Password == "" {
-		return fmt.Errorf("%w: password", domain.ErrRequired)
+		v.add("password", domain.ErrRequired)
 	}
-	return nil
+	return v.err()
 }
 
 type CartRequest struct {
@@ -80,5 +232,335 @@ type CartRequest struct {
 }
 
 type CartResponse struct {
+	BookIDs    []int  `json:"book_ids"`
+	CouponCode string `json:"coupon_code,omitempty"`
+	// CouponError explains why the coupon of the cart is not applied anymore
+	CouponError   string             `json:"coupon_error,omitempty"`
+	Items         []LineItemResponse `json:"items"`
+	Discounts     []DiscountResponse `json:"discounts"`
+	Subtotal      MoneyResponse      `json:"subtotal"`
+	DiscountTotal MoneyResponse      `json:"discount_total"`
+	TaxRegion     string             `json:"tax_region"`
+	Taxes         []TaxResponse      `json:"taxes"`
+	TaxTotal      MoneyResponse      `json:"tax_total"`
+	Total         MoneyResponse      `json:"total"`
+}
+
+type CheckoutRequest struct {
+	AddressID      int    `json:"address_id"`
+	ShippingMethod string `json:"shipping_method"`
+}
+
+func (r *CheckoutRequest) Validate() error {
+	var v violations
+	if r.AddressID == 0 {
+		v.add("address_id", domain.ErrRequired)
+	}
+	if r.ShippingMethod == "" {
+		v.add("shipping_method", domain.ErrRequired)
+	}
+	return v.err()
+}
+
+type AddressRequest struct {
+	Name       string `json:"name"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	State      string `json:"state"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+	Phone      string `json:"phone"`
+}
+
+type AddressResponse struct {
+	ID         int    `json:"id,omitempty"`
+	Name       string `json:"name"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	State      string `json:"state,omitempty"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+	Phone      string `json:"phone,omitempty"`
+}
+
+type ShippingOptionResponse struct {
+	Method string        `json:"method"`
+	Name   string        `json:"name"`
+	Cost   MoneyResponse `json:"cost"`
+}
+
+type ShippingRateRequest struct {
+	Method        string        `json:"method"`
+	Name          string        `json:"name"`
+	Country       string        `json:"country"`
+	BaseAmount    *MoneyRequest `json:"base_amount"`
+	PerItemAmount *MoneyRequest `json:"per_item_amount"`
+	PerKgAmount   *MoneyRequest `json:"per_kg_amount"`
+}
+
+type ShippingRateResponse struct {
+	ID            int           `json:"id"`
+	Method        string        `json:"method"`
+	Name          string        `json:"name"`
+	Country       string        `json:"country,omitempty"`
+	BaseAmount    MoneyResponse `json:"base_amount"`
+	PerItemAmount MoneyResponse `json:"per_item_amount"`
+	PerKgAmount   MoneyResponse `json:"per_kg_amount"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+type TaxResponse struct {
+	Name     string        `json:"name"`
+	TaxClass string        `json:"tax_class"`
+	Rate     string        `json:"rate"`
+	Taxable  MoneyResponse `json:"taxable"`
+	Amount   MoneyResponse `json:"amount"`
+}
+
+type TaxRateRequest struct {
+	Country  string `json:"country"`
+	State    string `json:"state"`
+	TaxClass string `json:"tax_class"`
+	Rate     string `json:"rate"`
+	Name     string `json:"name"`
+}
+
+type TaxRateResponse struct {
+	ID        int       `json:"id"`
+	Country   string    `json:"country"`
+	State     string    `json:"state,omitempty"`
+	TaxClass  string    `json:"tax_class"`
+	Rate      string    `json:"rate"`
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CouponRequest struct {
+	Code string `json:"code"`
+}
+
+type DiscountResponse struct {
+	PromotionID int           `json:"promotion_id,omitempty"`
+	Code        string        `json:"code,omitempty"`
+	Description string        `json:"description"`
+	Amount      MoneyResponse `json:"amount"`
+}
+
+type LineItemResponse struct {
+	BookID int           `json:"book_id"`
+	Title  string        `json:"title"`
+	Price  MoneyResponse `json:"price"`
+	// Preorder marks a book ordered before it is in stock
+	Preorder bool `json:"preorder,omitempty"`
+}
+
+type OrderResponse struct {
+	ID            int                `json:"id"`
+	Status        string             `json:"status"`
+	Items         []LineItemResponse `json:"items"`
+	Discounts     []DiscountResponse `json:"discounts"`
+	Subtotal      MoneyResponse      `json:"subtotal"`
+	DiscountTotal MoneyResponse      `json:"discount_total"`
+	// Address and Shipping are missing on orders placed before addresses were collected
+	Address   *AddressResponse        `json:"address,omitempty"`
+	Shipping  *ShippingOptionResponse `json:"shipping,omitempty"`
+	TaxRegion string                  `json:"tax_region,omitempty"`
+	Taxes     []TaxResponse           `json:"taxes"`
+	TaxTotal  MoneyResponse           `json:"tax_total"`
+	Total     MoneyResponse           `json:"total"`
+	CreatedAt time.Time               `json:"created_at"`
+}
+
+type PromotionRequest struct {
+	Code           string        `json:"code"`
+	Description    string        `json:"description"`
+	Kind           string        `json:"kind"`
+	Percent        int           `json:"percent"`
+	Amount         *MoneyRequest `json:"amount"`
+	MinSubtotal    *MoneyRequest `json:"min_subtotal"`
+	Scope          string        `json:"scope"`
+	CategoryID     int           `json:"category_id"`
+	BookIDs        []int         `json:"book_ids"`
+	StartsAt       *time.Time    `json:"starts_at"`
+	EndsAt         *time.Time    `json:"ends_at"`
+	MaxUses        int           `json:"max_uses"`
+	MaxUsesPerUser int           `json:"max_uses_per_user"`
+	Active         *bool         `json:"active"`
+}
+
+type PromotionResponse struct {
+	ID             int            `json:"id"`
+	Code           string         `json:"code,omitempty"`
+	Description    string         `json:"description"`
+	Kind           string         `json:"kind"`
+	Percent        int            `json:"percent,omitempty"`
+	Amount         *MoneyResponse `json:"amount,omitempty"`
+	MinSubtotal    MoneyResponse  `json:"min_subtotal"`
+	Scope          string         `json:"scope"`
+	CategoryID     int            `json:"category_id,omitempty"`
+	BookIDs        []int          `json:"book_ids,omitempty"`
+	StartsAt       *time.Time     `json:"starts_at,omitempty"`
+	EndsAt         *time.Time     `json:"ends_at,omitempty"`
+	MaxUses        int            `json:"max_uses"`
+	MaxUsesPerUser int            `json:"max_uses_per_user"`
+	Active         bool           `json:"active"`
+}
+
+type ExchangeRateRequest struct {
+	Rate string `json:"rate"`
+}
+
+type ExchangeRateResponse struct {
+	Currency     string    `json:"currency"`
+	BaseCurrency string    `json:"base_currency"`
+	Rate         string    `json:"rate"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type JobStatusResponse struct {
+	Name         string     `json:"name"`
+	Interval     string     `json:"interval"`
+	Running      bool       `json:"running"`
+	Runs         int64      `json:"runs"`
+	Failures     int64      `json:"failures"`
+	Skipped      int64      `json:"skipped"`
+	LastRunAt    *time.Time `json:"last_run_at,omitempty"`
+	LastDuration string     `json:"last_duration,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	NextRunAt    *time.Time `json:"next_run_at,omitempty"`
+}
+
+type WebhookRequest struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"event_types"`
+	Active     *bool    `json:"active"`
+}
+
+type WebhookResponse struct {
+	ID         int      `json:"id"`
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Active     bool     `json:"active"`
+	// Secret is only returned when the webhook is created
+	Secret string `json:"secret,omitempty"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             int64                    `json:"id"`
+	EventID        int64                    `json:"event_id"`
+	EventType      string                   `json:"event_type"`
+	Status         string                   `json:"status"`
+	Attempts       int                      `json:"attempts"`
+	NextAttemptAt  *time.Time               `json:"next_attempt_at,omitempty"`
+	LastStatusCode int                      `json:"last_status_code,omitempty"`
+	LastError      string                   `json:"last_error,omitempty"`
+	CreatedAt      time.Time                `json:"created_at"`
+	Payload        json.RawMessage          `json:"payload"`
+	AttemptLog     []WebhookAttemptResponse `json:"attempt_log"`
+}
+
+type WebhookAttemptResponse struct {
+	AttemptedAt time.Time `json:"attempted_at"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DurationMs  int64     `json:"duration_ms"`
+}
+
+// StockAdjustmentRequest is a stock change recorded by an admin, delta is positive for restocks and returns
+type StockAdjustmentRequest struct {
+	Type   string `json:"type"`
+	Delta  int    `json:"delta"`
+	Reason string `json:"reason"`
+	// OrderID is the order a return relates to
+	OrderID int `json:"order_id"`
+}
+
+// LowStockResponse is a book at or below its reorder threshold with a suggested reorder quantity
+type LowStockResponse struct {
+	BookID           int    `json:"book_id"`
+	Title            string `json:"title"`
+	Stock            int    `json:"stock"`
+	ReorderThreshold int    `json:"reorder_threshold"`
+	SoldRecently     int    `json:"sold_recently"`
+	// DaysOfCover is how many days the stock lasts at the recent sales pace, omitted for books that did not sell
+	DaysOfCover      *int `json:"days_of_cover,omitempty"`
+	SuggestedReorder int  `json:"suggested_reorder"`
+}
+
+type WishlistItemRequest struct {
+	BookID int `json:"book_id"`
+}
+
+// WishlistMoveRequest lists the books of the wishlist to move to the cart, all of them when empty
+type WishlistMoveRequest struct {
 	BookIDs []int `json:"book_ids"`
 }
+
+type WishlistItemResponse struct {
+	Book    BookResponse `json:"book"`
+	AddedAt time.Time    `json:"added_at"`
+}
+
+type WishlistResponse struct {
+	Items []WishlistItemResponse `json:"items"`
+	// ShareToken opens the wishlist at GET /wishlists/{token}, it is only shown to the owner of the wishlist
+	ShareToken string `json:"share_token,omitempty"`
+}
+
+type WishlistMoveResponse struct {
+	Moved []int `json:"moved"`
+	// Unavailable are the books left on the wishlist because they are out of stock
+	Unavailable []int `json:"unavailable"`
+}
+
+type ReviewRequest struct {
+	Rating int    `json:"rating"`
+	Body   string `json:"body"`
+}
+
+// ReviewRejectRequest tells the author why the review was rejected
+type ReviewRejectRequest struct {
+	Reason string `json:"reason"`
+}
+
+type ReviewResponse struct {
+	ID        int       `json:"id"`
+	BookID    int       `json:"book_id"`
+	UserID    int       `json:"user_id"`
+	Rating    int       `json:"rating"`
+	Body      string    `json:"body"`
+	Status    string    `json:"status"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// ModeratedAt is when an admin approved or rejected the review, omitted while it is pending
+	ModeratedAt *time.Time `json:"moderated_at,omitempty"`
+}
+
+type RecommendationResponse struct {
+	Book BookResponse `json:"book"`
+	// Score is the share of customers the books have in common, omitted for category recommendations
+	Score  float64 `json:"score,omitempty"`
+	Source string  `json:"source"`
+}
+
+type StockSubscriptionResponse struct {
+	BookID     int        `json:"book_id"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	NotifiedAt *time.Time `json:"notified_at,omitempty"`
+}
+
+type InventoryMovementResponse struct {
+	ID         int64     `json:"id"`
+	BookID     int       `json:"book_id"`
+	Type       string    `json:"type"`
+	Delta      int       `json:"delta"`
+	StockAfter int       `json:"stock_after"`
+	Reason     string    `json:"reason,omitempty"`
+	OrderID    int       `json:"order_id,omitempty"`
+	UserID     int       `json:"user_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
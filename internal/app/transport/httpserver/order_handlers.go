@@ -0,0 +1,71 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/northwindman/book-shop/internal/app/common/server"
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// GetOrders returns the orders of the current user, newest first
+func (h HttpServer) GetOrders(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	// page
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit := 20
+	offset := (page - 1) * limit
+
+	orders, err := h.orderService.GetOrders(r.Context(), user.ID(), limit, offset)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := make([]OrderResponse, 0, len(orders))
+	for _, order := range orders {
+		response = append(response, toResponseOrder(order))
+	}
+
+	server.RespondOK(response, w, r)
+}
+
+// GetOrder returns an order of the current user by ID
+func (h HttpServer) GetOrder(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	orderID, err := strconv.Atoi(vars["order_id"])
+	if err != nil {
+		server.BadRequest("invalid-order-id", err, w, r)
+		return
+	}
+
+	order, err := h.orderService.GetOrder(r.Context(), user.ID(), orderID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("order-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := toResponseOrder(order)
+
+	server.RespondOK(response, w, r)
+}
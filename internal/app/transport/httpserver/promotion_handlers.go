@@ -0,0 +1,125 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/northwindman/book-shop/internal/app/common/server"
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// GetPromotions returns all promotions
+func (h HttpServer) GetPromotions(w http.ResponseWriter, r *http.Request) {
+	promotions, err := h.promotionService.GetPromotions(r.Context())
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := make([]PromotionResponse, 0, len(promotions))
+	for _, promotion := range promotions {
+		response = append(response, toResponsePromotion(promotion))
+	}
+
+	server.RespondOK(response, w, r)
+}
+
+// GetPromotion returns a promotion by ID
+func (h HttpServer) GetPromotion(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	promotionID, err := strconv.Atoi(vars["promotion_id"])
+	if err != nil {
+		server.BadRequest("invalid-promotion-id", err, w, r)
+		return
+	}
+
+	promotion, err := h.promotionService.GetPromotion(r.Context(), promotionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("promotion-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(toResponsePromotion(promotion), w, r)
+}
+
+// CreatePromotion creates a new promotion, promotions without a code apply to every cart
+func (h HttpServer) CreatePromotion(w http.ResponseWriter, r *http.Request) {
+	var promotionRequest PromotionRequest
+	if err := json.NewDecoder(r.Body).Decode(&promotionRequest); err != nil {
+		server.BadRequest("invalid-json", err, w, r)
+		return
+	}
+
+	promotion, err := toDomainPromotion(0, promotionRequest)
+	if err != nil {
+		server.RespondWithError(validationProblem(err), w, r)
+		return
+	}
+
+	insertedPromotion, err := h.promotionService.CreatePromotion(r.Context(), promotion)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(toResponsePromotion(insertedPromotion), w, r)
+}
+
+// UpdatePromotion replaces a promotion by ID
+func (h HttpServer) UpdatePromotion(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	promotionID, err := strconv.Atoi(vars["promotion_id"])
+	if err != nil {
+		server.BadRequest("invalid-promotion-id", err, w, r)
+		return
+	}
+
+	var promotionRequest PromotionRequest
+	if err := json.NewDecoder(r.Body).Decode(&promotionRequest); err != nil {
+		server.BadRequest("invalid-json", err, w, r)
+		return
+	}
+
+	promotion, err := toDomainPromotion(promotionID, promotionRequest)
+	if err != nil {
+		server.RespondWithError(validationProblem(err), w, r)
+		return
+	}
+
+	updatedPromotion, err := h.promotionService.UpdatePromotion(r.Context(), promotion)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("promotion-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(toResponsePromotion(updatedPromotion), w, r)
+}
+
+// DeletePromotion deletes a promotion by ID, orders keep their discounts
+func (h HttpServer) DeletePromotion(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	promotionID, err := strconv.Atoi(vars["promotion_id"])
+	if err != nil {
+		server.BadRequest("invalid-promotion-id", err, w, r)
+		return
+	}
+
+	err = h.promotionService.DeletePromotion(r.Context(), promotionID)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(map[string]bool{"ok": true}, w, r)
+}
@@ -0,0 +1,61 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/northwindman/book-shop/internal/app/common/server"
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// GetRecommendations returns the books customers bought together with a book, completed with popular books of its
+// category. Books out of stock are skipped, and so are the books in the cart of a signed-in user.
+func (h HttpServer) GetRecommendations(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bookID, err := strconv.Atoi(vars["book_id"])
+	if err != nil {
+		server.BadRequest("invalid-book-id", err, w, r)
+		return
+	}
+
+	var userID int
+	if user, err := getUserFromContext(r.Context()); err == nil {
+		userID = user.ID()
+	}
+
+	convert, _, err := h.priceConverter(r)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	recommendations, err := h.recommendationService.GetRecommendations(r.Context(), bookID, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("book-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := make([]RecommendationResponse, 0, len(recommendations))
+	for _, recommendation := range recommendations {
+		bookResponse, err := toDisplayBook(recommendation.Book, convert)
+		if err != nil {
+			server.RespondWithError(err, w, r)
+			return
+		}
+		response = append(response, RecommendationResponse{
+			Book:   bookResponse,
+			Score:  recommendation.Score,
+			Source: recommendation.Source,
+		})
+	}
+
+	w.Header().Add("Vary", "Accept-Currency")
+	w.Header().Add("Vary", AuthorizationHeader)
+	server.RespondOK(response, w, r)
+}
@@ -0,0 +1,186 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/northwindman/book-shop/internal/app/common/server"
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// CreateReview submits a review of a book purchased by the current user, it is published once approved
+func (h HttpServer) CreateReview(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bookID, err := strconv.Atoi(vars["book_id"])
+	if err != nil {
+		server.BadRequest("invalid-book-id", err, w, r)
+		return
+	}
+
+	var reviewRequest ReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&reviewRequest); err != nil {
+		server.BadRequest("invalid-json", err, w, r)
+		return
+	}
+
+	review, err := domain.NewReview(domain.NewReviewData{
+		BookID: bookID,
+		UserID: user.ID(),
+		Rating: reviewRequest.Rating,
+		Body:   reviewRequest.Body,
+	})
+	if err != nil {
+		server.RespondWithError(validationProblem(err), w, r)
+		return
+	}
+
+	review, err = h.reviewService.CreateReview(r.Context(), review)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("book-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(toResponseReview(review), w, r)
+}
+
+// GetBookReviews returns the approved reviews of a book, newest first
+func (h HttpServer) GetBookReviews(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bookID, err := strconv.Atoi(vars["book_id"])
+	if err != nil {
+		server.BadRequest("invalid-book-id", err, w, r)
+		return
+	}
+
+	_, err = h.bookService.GetBook(r.Context(), bookID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("book-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	limit, offset := reviewPage(r)
+	reviews, err := h.reviewService.GetBookReviews(r.Context(), bookID, limit, offset)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(toResponseReviews(reviews), w, r)
+}
+
+// GetReviews returns the moderation queue, the pending reviews oldest first, or the reviews with the status of the
+// status query parameter
+func (h HttpServer) GetReviews(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = domain.ReviewStatusPending
+	}
+	if !slices.Contains(domain.ReviewStatuses, status) {
+		server.BadRequest("invalid-status", fmt.Errorf("unknown review status %q, expected one of %s", status,
+			strings.Join(domain.ReviewStatuses, ", ")), w, r)
+		return
+	}
+
+	limit, offset := reviewPage(r)
+	reviews, err := h.reviewService.GetReviews(r.Context(), status, limit, offset)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(toResponseReviews(reviews), w, r)
+}
+
+// ApproveReview publishes a pending review, its rating counts towards the rating of the book
+func (h HttpServer) ApproveReview(w http.ResponseWriter, r *http.Request) {
+	admin, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	reviewID, err := strconv.Atoi(vars["review_id"])
+	if err != nil {
+		server.BadRequest("invalid-review-id", err, w, r)
+		return
+	}
+
+	review, err := h.reviewService.ApproveReview(r.Context(), reviewID, admin.ID())
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("review-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(validationProblem(err), w, r)
+		return
+	}
+
+	server.RespondOK(toResponseReview(review), w, r)
+}
+
+// RejectReview rejects a pending review with an optional reason, its author may submit the review again
+func (h HttpServer) RejectReview(w http.ResponseWriter, r *http.Request) {
+	admin, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	reviewID, err := strconv.Atoi(vars["review_id"])
+	if err != nil {
+		server.BadRequest("invalid-review-id", err, w, r)
+		return
+	}
+
+	var rejectRequest ReviewRejectRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&rejectRequest); err != nil {
+			server.BadRequest("invalid-json", err, w, r)
+			return
+		}
+	}
+
+	review, err := h.reviewService.RejectReview(r.Context(), reviewID, rejectRequest.Reason, admin.ID())
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("review-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(validationProblem(err), w, r)
+		return
+	}
+
+	server.RespondOK(toResponseReview(review), w, r)
+}
+
+// reviewPage returns the limit and offset of the page query parameter, the first page by default
+func reviewPage(r *http.Request) (int, int) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit := 20
+	return limit, (page - 1) * limit
+}
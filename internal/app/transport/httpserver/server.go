@@ -2,21 +2,74 @@ package httpserver
 
 // HttpServer is a HTTP server for ports
 type HttpServer struct {
-	userService     UserService
-	tokenService    TokenService
-	bookService     BookService
-	categoryService CategoryService
-	cartService     CartService
+	userService              UserService
+	tokenService             TokenService
+	bookService              BookService
+	categoryService          CategoryService
+	cartService              CartService
+	orderService             OrderService
+	addressService           AddressService
+	promotionService         PromotionService
+	taxService               TaxService
+	shippingService          ShippingService
+	exchangeRateService      ExchangeRateService
+	jobService               JobService
+	webhookService           WebhookService
+	idempotencyService       IdempotencyService
+	inventoryService         InventoryService
+	stockSubscriptionService StockSubscriptionService
+	wishlistService          WishlistService
+	reviewService            ReviewService
+	recommendationService    RecommendationService
+	authorService            AuthorService
+}
+
+// Services are the application services a HTTP server exposes, the services a handler doesn't use can be left nil
+type Services struct {
+	UserService              UserService
+	TokenService             TokenService
+	BookService              BookService
+	CategoryService          CategoryService
+	CartService              CartService
+	OrderService             OrderService
+	AddressService           AddressService
+	PromotionService         PromotionService
+	TaxService               TaxService
+	ShippingService          ShippingService
+	ExchangeRateService      ExchangeRateService
+	JobService               JobService
+	WebhookService           WebhookService
+	IdempotencyService       IdempotencyService
+	InventoryService         InventoryService
+	StockSubscriptionService StockSubscriptionService
+	WishlistService          WishlistService
+	ReviewService            ReviewService
+	RecommendationService    RecommendationService
+	AuthorService            AuthorService
 }
 
 // NewHttpServer creates a new HTTP server for ports
-func NewHttpServer(userService UserService, tokenService TokenService, bookService BookService,
-	categoryService CategoryService, cartService CartService) HttpServer {
+func NewHttpServer(services Services) HttpServer {
 	return HttpServer{
-		userService:     userService,
-		tokenService:    tokenService,
-		bookService:     bookService,
-		categoryService: categoryService,
-		cartService:     cartService,
+		userService:              services.UserService,
+		tokenService:             services.TokenService,
+		bookService:              services.BookService,
+		categoryService:          services.CategoryService,
+		cartService:              services.CartService,
+		orderService:             services.OrderService,
+		addressService:           services.AddressService,
+		promotionService:         services.PromotionService,
+		taxService:               services.TaxService,
+		shippingService:          services.ShippingService,
+		exchangeRateService:      services.ExchangeRateService,
+		jobService:               services.JobService,
+		webhookService:           services.WebhookService,
+		idempotencyService:       services.IdempotencyService,
+		inventoryService:         services.InventoryService,
+		stockSubscriptionService: services.StockSubscriptionService,
+		wishlistService:          services.WishlistService,
+		reviewService:            services.ReviewService,
+		recommendationService:    services.RecommendationService,
+		authorService:            services.AuthorService,
 	}
 }
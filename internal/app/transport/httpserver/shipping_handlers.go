@@ -0,0 +1,73 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/northwindman/book-shop/internal/app/common/server"
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// GetShippingRates returns the shipping rate table
+func (h HttpServer) GetShippingRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.shippingService.GetRates(r.Context())
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := make([]ShippingRateResponse, 0, len(rates))
+	for _, rate := range rates {
+		response = append(response, toResponseShippingRate(rate))
+	}
+
+	server.RespondOK(response, w, r)
+}
+
+// SetShippingRate creates or replaces the rate of a shipping method to a country, or to all other countries
+func (h HttpServer) SetShippingRate(w http.ResponseWriter, r *http.Request) {
+	var rateRequest ShippingRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&rateRequest); err != nil {
+		server.BadRequest("invalid-json", err, w, r)
+		return
+	}
+
+	rate, err := toDomainShippingRate(rateRequest)
+	if err != nil {
+		server.RespondWithError(validationProblem(err), w, r)
+		return
+	}
+
+	savedRate, err := h.shippingService.SetRate(r.Context(), rate)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(toResponseShippingRate(savedRate), w, r)
+}
+
+// DeleteShippingRate deletes a shipping rate by ID
+func (h HttpServer) DeleteShippingRate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	rateID, err := strconv.Atoi(vars["shipping_rate_id"])
+	if err != nil {
+		server.BadRequest("invalid-shipping-rate-id", err, w, r)
+		return
+	}
+
+	err = h.shippingService.DeleteRate(r.Context(), rateID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("shipping-rate-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(map[string]bool{"ok": true}, w, r)
+}
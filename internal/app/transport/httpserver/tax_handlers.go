@@ -0,0 +1,79 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/northwindman/book-shop/internal/app/common/server"
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// GetTaxRates returns the tax rate table
+func (h HttpServer) GetTaxRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.taxService.GetRates(r.Context())
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := make([]TaxRateResponse, 0, len(rates))
+	for _, rate := range rates {
+		response = append(response, toResponseTaxRate(rate))
+	}
+
+	server.RespondOK(response, w, r)
+}
+
+// SetTaxRate creates or replaces the rate of a tax class in a country or state
+func (h HttpServer) SetTaxRate(w http.ResponseWriter, r *http.Request) {
+	var rateRequest TaxRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&rateRequest); err != nil {
+		server.BadRequest("invalid-json", err, w, r)
+		return
+	}
+
+	rate, err := domain.NewTaxRate(domain.NewTaxRateData{
+		Country:  rateRequest.Country,
+		State:    rateRequest.State,
+		TaxClass: rateRequest.TaxClass,
+		Rate:     rateRequest.Rate,
+		Name:     rateRequest.Name,
+	})
+	if err != nil {
+		server.RespondWithError(validationProblem(err), w, r)
+		return
+	}
+
+	savedRate, err := h.taxService.SetRate(r.Context(), rate)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(toResponseTaxRate(savedRate), w, r)
+}
+
+// DeleteTaxRate deletes a tax rate by ID
+func (h HttpServer) DeleteTaxRate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	rateID, err := strconv.Atoi(vars["tax_rate_id"])
+	if err != nil {
+		server.BadRequest("invalid-tax-rate-id", err, w, r)
+		return
+	}
+
+	err = h.taxService.DeleteRate(r.Context(), rateID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("tax-rate-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(map[string]bool{"ok": true}, w, r)
+}
@@ -2,40 +2,250 @@ package httpserver
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"time"
 
 	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/northwindman/book-shop/internal/pkg/scheduler"
 )
 
 func toResponseBook(book domain.Book) BookResponse {
+	var authors []BookAuthorResponse
+	for _, author := range book.Authors() {
+		authors = append(authors, BookAuthorResponse{ID: author.AuthorID, Name: author.Name, Role: author.Role})
+	}
+
 	return BookResponse{
-		ID:         book.ID(),
-		Title:      book.Title(),
-		Year:       book.Year(),
-		Author:     book.Author(),
-		Price:      book.Price(),
-		Stock:      book.Stock(),
-		CategoryID: book.CategoryID(),
+		ID:               book.ID(),
+		Title:            book.Title(),
+		Year:             book.Year(),
+		Author:           book.Author(),
+		Price:            toResponseMoney(book.Price()),
+		Stock:            book.Stock(),
+		CategoryID:       book.CategoryID(),
+		TaxClass:         book.TaxClass(),
+		WeightGrams:      book.WeightGrams(),
+		ReorderThreshold: book.ReorderThreshold(),
+		ReleaseDate:      formatDate(book.ReleaseDate()),
+		Preorder:         book.Preorder(),
+		PreorderCap:      book.PreorderCap(),
+		Preorders:        book.Preorders(),
+		Authors:          authors,
+		ISBN:             book.ISBN(),
+		Publisher:        book.Publisher(),
+		Edition:          book.Edition(),
+		Language:         book.Language(),
+		PageCount:        book.PageCount(),
+		Format:           book.Format(),
+		Description:      book.Description(),
+		CategoryIDs:      book.CategoryIDs(),
+		RatingAverage:    math.Round(book.RatingAverage()*100) / 100,
+		RatingCount:      book.RatingCount(),
+		Version:          book.Version(),
+	}
+}
+
+func toResponseAuthor(author domain.Author) AuthorResponse {
+	return AuthorResponse{
+		ID:      author.ID(),
+		Name:    author.Name(),
+		Bio:     author.Bio(),
+		Version: author.Version(),
 	}
 }
 
 func toResponseCategory(category domain.Category) CategoryResponse {
 	return CategoryResponse{
-		ID:   category.ID(),
-		Name: category.Name(),
+		ID:       category.ID(),
+		Name:     category.Name(),
+		ParentID: category.ParentID(),
+		Version:  category.Version(),
 	}
 }
 
+func toResponseCategoryTree(nodes []domain.CategoryNode) []CategoryNodeResponse {
+	response := make([]CategoryNodeResponse, 0, len(nodes))
+	for _, node := range nodes {
+		response = append(response, CategoryNodeResponse{
+			CategoryResponse: toResponseCategory(node.Category),
+			Children:         toResponseCategoryTree(node.Children),
+		})
+	}
+	return response
+}
+
 func toDomainBook(bookRequest BookRequest) (domain.Book, error) {
+	price, err := toDomainPrice(bookRequest.Price)
+	if err != nil {
+		return domain.Book{}, err
+	}
+	releaseDate, err := parseDate("release_date", bookRequest.ReleaseDate)
+	if err != nil {
+		return domain.Book{}, err
+	}
+
 	return domain.NewBook(domain.NewBookData{
-		Title:      bookRequest.Title,
-		Year:       bookRequest.Year,
-		Author:     bookRequest.Author,
-		Price:      bookRequest.Price,
-		Stock:      bookRequest.Stock,
-		CategoryID: bookRequest.CategoryID,
+		Title:            bookRequest.Title,
+		Year:             bookRequest.Year,
+		Author:           bookRequest.Author,
+		Price:            price,
+		Stock:            bookRequest.Stock,
+		CategoryID:       bookRequest.CategoryID,
+		TaxClass:         bookRequest.TaxClass,
+		WeightGrams:      bookRequest.WeightGrams,
+		ReorderThreshold: bookRequest.ReorderThreshold,
+		ReleaseDate:      releaseDate,
+		Preorder:         bookRequest.Preorder,
+		PreorderCap:      bookRequest.PreorderCap,
+		Authors:          toDomainBookAuthors(bookRequest.Authors),
+		ISBN:             bookRequest.ISBN,
+		Publisher:        bookRequest.Publisher,
+		Edition:          bookRequest.Edition,
+		Language:         bookRequest.Language,
+		PageCount:        bookRequest.PageCount,
+		Format:           bookRequest.Format,
+		Description:      bookRequest.Description,
+		CategoryIDs:      bookRequest.CategoryIDs,
 	})
 }
 
+// toDomainBookAuthors converts the credits of a book request, nil when the request has none
+func toDomainBookAuthors(authorRequests []BookAuthorRequest) []domain.BookAuthor {
+	if authorRequests == nil {
+		return nil
+	}
+
+	authors := make([]domain.BookAuthor, len(authorRequests))
+	for i, authorRequest := range authorRequests {
+		role := authorRequest.Role
+		if role == "" {
+			role = domain.AuthorRoleAuthor
+		}
+		authors[i] = domain.BookAuthor{AuthorID: authorRequest.AuthorID, Name: authorRequest.Name, Role: role}
+	}
+	return authors
+}
+
+// toDomainBookPatch converts a merge patch of a book. Removing the tax class, the weight, the reorder threshold or
+// the pre-order settings restores their default, removing the release date marks the book released and removing
+// the publication metadata clears it. The other members cannot be removed.
+func toDomainBookPatch(patchRequest BookPatchRequest, nulls []string) (domain.BookPatch, error) {
+	var v violations
+	for _, field := range nulls {
+		switch field {
+		case "tax_class":
+			patchRequest.TaxClass = new(string)
+		case "weight_grams":
+			patchRequest.WeightGrams = new(int)
+		case "reorder_threshold":
+			patchRequest.ReorderThreshold = new(int)
+		case "release_date":
+			patchRequest.ReleaseDate = new(string)
+		case "preorder":
+			patchRequest.Preorder = new(bool)
+		case "preorder_cap":
+			patchRequest.PreorderCap = new(int)
+		case "isbn":
+			patchRequest.ISBN = new(string)
+		case "publisher":
+			patchRequest.Publisher = new(string)
+		case "edition":
+			patchRequest.Edition = new(int)
+		case "language":
+			patchRequest.Language = new(string)
+		case "page_count":
+			patchRequest.PageCount = new(int)
+		case "format":
+			patchRequest.Format = new(string)
+		case "description":
+			patchRequest.Description = new(string)
+		default:
+			v.add(field, domain.ErrRequired)
+		}
+	}
+	if err := v.err(); err != nil {
+		return domain.BookPatch{}, err
+	}
+
+	patch := domain.BookPatch{
+		Title:            patchRequest.Title,
+		Year:             patchRequest.Year,
+		Author:           patchRequest.Author,
+		CategoryID:       patchRequest.CategoryID,
+		TaxClass:         patchRequest.TaxClass,
+		WeightGrams:      patchRequest.WeightGrams,
+		ReorderThreshold: patchRequest.ReorderThreshold,
+		Preorder:         patchRequest.Preorder,
+		PreorderCap:      patchRequest.PreorderCap,
+		ISBN:             patchRequest.ISBN,
+		Publisher:        patchRequest.Publisher,
+		Edition:          patchRequest.Edition,
+		Language:         patchRequest.Language,
+		PageCount:        patchRequest.PageCount,
+		Format:           patchRequest.Format,
+		Description:      patchRequest.Description,
+		CategoryIDs:      patchRequest.CategoryIDs,
+	}
+	if patchRequest.Authors != nil {
+		authors := toDomainBookAuthors(*patchRequest.Authors)
+		patch.Authors = &authors
+	}
+	if patchRequest.ReleaseDate != nil {
+		releaseDate, err := parseDate("release_date", *patchRequest.ReleaseDate)
+		if err != nil {
+			return domain.BookPatch{}, err
+		}
+		patch.ReleaseDate = &releaseDate
+	}
+	if patchRequest.Price != nil {
+		price, err := toDomainPrice(patchRequest.Price)
+		if err != nil {
+			return domain.BookPatch{}, err
+		}
+		patch.Price = &price
+	}
+
+	return patch, nil
+}
+
+// toDomainAuthorPatch converts a merge patch of an author, removing the bio clears it and the name cannot be removed
+func toDomainAuthorPatch(patchRequest AuthorPatchRequest, nulls []string) (domain.AuthorPatch, error) {
+	var v violations
+	for _, field := range nulls {
+		if field == "bio" {
+			patchRequest.Bio = new(string)
+			continue
+		}
+		v.add(field, domain.ErrRequired)
+	}
+	if err := v.err(); err != nil {
+		return domain.AuthorPatch{}, err
+	}
+
+	return domain.AuthorPatch{Name: patchRequest.Name, Bio: patchRequest.Bio}, nil
+}
+
+// toDomainCategoryPatch converts a merge patch of a category, removing the parent makes it a top level category and
+// the name cannot be removed
+func toDomainCategoryPatch(patchRequest CategoryPatchRequest, nulls []string) (domain.CategoryPatch, error) {
+	var v violations
+	for _, field := range nulls {
+		if field == "parent_id" {
+			patchRequest.ParentID = new(int)
+			continue
+		}
+		v.add(field, domain.ErrRequired)
+	}
+	if err := v.err(); err != nil {
+		return domain.CategoryPatch{}, err
+	}
+
+	return domain.CategoryPatch{Name: patchRequest.Name, ParentID: patchRequest.ParentID}, nil
+}
+
 func toDomainUser(username, password string) (domain.User, error) {
 	return domain.NewUser(domain.NewUserData{
 		Username: username,
@@ -50,10 +260,365 @@ func toDomainCart(userID int, cartRequest CartRequest) (domain.Cart, error) {
 	})
 }
 
-func toResponseCart(cart domain.Cart) CartResponse {
-	return CartResponse{
-		BookIDs: cart.BookIDs(),
+// toDomainPrice converts a requested price, a missing price is left for the domain to reject
+func toDomainPrice(priceRequest *PriceRequest) (domain.Money, error) {
+	return toDomainMoney("price", (*MoneyRequest)(priceRequest))
+}
+
+// toDomainMoney converts a requested amount of a field, a missing amount is left for the domain to reject
+func toDomainMoney(field string, moneyRequest *MoneyRequest) (domain.Money, error) {
+	if moneyRequest == nil {
+		return domain.Money{}, nil
 	}
+
+	currency := moneyRequest.Currency
+	if currency == "" {
+		currency = domain.SettlementCurrency
+	}
+
+	price, err := domain.NewMoney(moneyRequest.AmountMinor, currency)
+	if err != nil {
+		return domain.Money{}, domain.ValidationErrors{{Field: field, Err: err}}
+	}
+
+	return price, nil
+}
+
+func toResponseMoney(money domain.Money) MoneyResponse {
+	return MoneyResponse{
+		Amount:      money.String(),
+		AmountMinor: money.Amount(),
+		Currency:    money.Currency(),
+	}
+}
+
+func toResponseLineItems(lines []domain.QuoteLine) []LineItemResponse {
+	items := make([]LineItemResponse, len(lines))
+	for i, line := range lines {
+		items[i] = LineItemResponse{
+			BookID:   line.BookID,
+			Title:    line.Title,
+			Price:    toResponseMoney(line.Price),
+			Preorder: line.Preorder,
+		}
+	}
+	return items
+}
+
+func toResponseExchangeRate(rate domain.ExchangeRate) ExchangeRateResponse {
+	return ExchangeRateResponse{
+		Currency:     rate.Currency(),
+		BaseCurrency: domain.SettlementCurrency,
+		Rate:         rate.Rate(),
+		UpdatedAt:    rate.UpdatedAt(),
+	}
+}
+
+func toResponseDiscounts(discounts []domain.QuoteDiscount) []DiscountResponse {
+	response := make([]DiscountResponse, len(discounts))
+	for i, discount := range discounts {
+		response[i] = DiscountResponse{
+			PromotionID: discount.PromotionID,
+			Code:        discount.Code,
+			Description: discount.Description,
+			Amount:      toResponseMoney(discount.Amount),
+		}
+	}
+	return response
+}
+
+func toResponseTaxes(taxes []domain.TaxLine) []TaxResponse {
+	response := make([]TaxResponse, len(taxes))
+	for i, tax := range taxes {
+		response[i] = TaxResponse{
+			Name:     tax.Name,
+			TaxClass: tax.TaxClass,
+			Rate:     tax.Rate,
+			Taxable:  toResponseMoney(tax.Taxable),
+			Amount:   toResponseMoney(tax.Amount),
+		}
+	}
+	return response
+}
+
+func toResponseTaxRate(rate domain.TaxRate) TaxRateResponse {
+	return TaxRateResponse{
+		ID:        rate.ID(),
+		Country:   rate.Country(),
+		State:     rate.State(),
+		TaxClass:  rate.TaxClass(),
+		Rate:      rate.Rate(),
+		Name:      rate.Name(),
+		UpdatedAt: rate.UpdatedAt(),
+	}
+}
+
+func toResponseCart(cart domain.Cart, quote domain.Quote) CartResponse {
+	response := CartResponse{
+		BookIDs:       cart.BookIDs(),
+		CouponCode:    cart.CouponCode(),
+		Items:         toResponseLineItems(quote.Lines()),
+		Discounts:     toResponseDiscounts(quote.Discounts()),
+		Subtotal:      toResponseMoney(quote.Subtotal()),
+		DiscountTotal: toResponseMoney(quote.DiscountTotal()),
+		TaxRegion:     quote.TaxRegion().String(),
+		Taxes:         toResponseTaxes(quote.Taxes()),
+		TaxTotal:      toResponseMoney(quote.TaxTotal()),
+		Total:         toResponseMoney(quote.Total()),
+	}
+	if err := quote.CouponError(); err != nil {
+		response.CouponError = err.Error()
+	}
+	return response
+}
+
+func toDomainAddress(id, userID int, addressRequest AddressRequest) (domain.Address, error) {
+	return domain.NewAddress(domain.NewAddressData{
+		ID:         id,
+		UserID:     userID,
+		Name:       addressRequest.Name,
+		Line1:      addressRequest.Line1,
+		Line2:      addressRequest.Line2,
+		City:       addressRequest.City,
+		State:      addressRequest.State,
+		PostalCode: addressRequest.PostalCode,
+		Country:    addressRequest.Country,
+		Phone:      addressRequest.Phone,
+	})
+}
+
+func toResponseAddress(address domain.Address) AddressResponse {
+	return AddressResponse{
+		ID:         address.ID(),
+		Name:       address.Name(),
+		Line1:      address.Line1(),
+		Line2:      address.Line2(),
+		City:       address.City(),
+		State:      address.State(),
+		PostalCode: address.PostalCode(),
+		Country:    address.Country(),
+		Phone:      address.Phone(),
+	}
+}
+
+func toResponseShippingOption(option domain.ShippingOption) ShippingOptionResponse {
+	return ShippingOptionResponse{
+		Method: option.Method,
+		Name:   option.Name,
+		Cost:   toResponseMoney(option.Cost),
+	}
+}
+
+func toDomainShippingRate(rateRequest ShippingRateRequest) (domain.ShippingRate, error) {
+	var amounts [3]domain.Money
+	for i, amount := range []struct {
+		field   string
+		request *MoneyRequest
+	}{
+		{"base_amount", rateRequest.BaseAmount},
+		{"per_item_amount", rateRequest.PerItemAmount},
+		{"per_kg_amount", rateRequest.PerKgAmount},
+	} {
+		var err error
+		amounts[i], err = toDomainMoney(amount.field, amount.request)
+		if err != nil {
+			return domain.ShippingRate{}, err
+		}
+	}
+
+	return domain.NewShippingRate(domain.NewShippingRateData{
+		Method:        rateRequest.Method,
+		Name:          rateRequest.Name,
+		Country:       rateRequest.Country,
+		BaseAmount:    amounts[0],
+		PerItemAmount: amounts[1],
+		PerKgAmount:   amounts[2],
+	})
+}
+
+func toResponseShippingRate(rate domain.ShippingRate) ShippingRateResponse {
+	return ShippingRateResponse{
+		ID:            rate.ID(),
+		Method:        rate.Method(),
+		Name:          rate.Name(),
+		Country:       rate.Country(),
+		BaseAmount:    toResponseMoney(rate.BaseAmount()),
+		PerItemAmount: toResponseMoney(rate.PerItemAmount()),
+		PerKgAmount:   toResponseMoney(rate.PerKgAmount()),
+		UpdatedAt:     rate.UpdatedAt(),
+	}
+}
+
+func toResponseOrder(order domain.Order) OrderResponse {
+	response := OrderResponse{
+		ID:            order.ID(),
+		Status:        order.Status(),
+		Items:         toResponseLineItems(order.Lines()),
+		Discounts:     toResponseDiscounts(order.Discounts()),
+		Subtotal:      toResponseMoney(order.Subtotal()),
+		DiscountTotal: toResponseMoney(order.DiscountTotal()),
+		TaxRegion:     order.TaxRegion().String(),
+		Taxes:         toResponseTaxes(order.Taxes()),
+		TaxTotal:      toResponseMoney(order.TaxTotal()),
+		Total:         toResponseMoney(order.Total()),
+		CreatedAt:     order.CreatedAt(),
+	}
+	if !order.Address().IsZero() {
+		address := toResponseAddress(order.Address())
+		// the address is a copy, the address book entry it was copied from may be gone
+		address.ID = 0
+		response.Address = &address
+	}
+	if order.Shipping().Method != "" {
+		shipping := toResponseShippingOption(order.Shipping())
+		response.Shipping = &shipping
+	}
+	return response
+}
+
+func toDomainPromotion(id int, promotionRequest PromotionRequest) (domain.Promotion, error) {
+	var amount domain.Money
+	if promotionRequest.Amount != nil {
+		var err error
+		amount, err = toDomainMoney("amount", promotionRequest.Amount)
+		if err != nil {
+			return domain.Promotion{}, err
+		}
+	}
+	var minSubtotal domain.Money
+	if promotionRequest.MinSubtotal != nil {
+		var err error
+		minSubtotal, err = toDomainMoney("min_subtotal", promotionRequest.MinSubtotal)
+		if err != nil {
+			return domain.Promotion{}, err
+		}
+	}
+
+	active := true
+	if promotionRequest.Active != nil {
+		active = *promotionRequest.Active
+	}
+
+	data := domain.NewPromotionData{
+		ID:             id,
+		Code:           promotionRequest.Code,
+		Description:    promotionRequest.Description,
+		Kind:           promotionRequest.Kind,
+		Percent:        promotionRequest.Percent,
+		Amount:         amount,
+		MinSubtotal:    minSubtotal,
+		Scope:          promotionRequest.Scope,
+		CategoryID:     promotionRequest.CategoryID,
+		BookIDs:        promotionRequest.BookIDs,
+		MaxUses:        promotionRequest.MaxUses,
+		MaxUsesPerUser: promotionRequest.MaxUsesPerUser,
+		Active:         active,
+	}
+	if promotionRequest.StartsAt != nil {
+		data.StartsAt = *promotionRequest.StartsAt
+	}
+	if promotionRequest.EndsAt != nil {
+		data.EndsAt = *promotionRequest.EndsAt
+	}
+
+	return domain.NewPromotion(data)
+}
+
+func toResponsePromotion(promotion domain.Promotion) PromotionResponse {
+	response := PromotionResponse{
+		ID:             promotion.ID(),
+		Code:           promotion.Code(),
+		Description:    promotion.Description(),
+		Kind:           promotion.Kind(),
+		Scope:          promotion.Scope(),
+		CategoryID:     promotion.CategoryID(),
+		BookIDs:        promotion.BookIDs(),
+		MaxUses:        promotion.MaxUses(),
+		MaxUsesPerUser: promotion.MaxUsesPerUser(),
+		Active:         promotion.Active(),
+		MinSubtotal:    toResponseMoney(promotion.MinSubtotal()),
+	}
+	if promotion.Kind() == domain.PromotionPercent {
+		response.Percent = promotion.Percent()
+	} else {
+		amount := toResponseMoney(promotion.Amount())
+		response.Amount = &amount
+	}
+	if !promotion.StartsAt().IsZero() {
+		startsAt := promotion.StartsAt()
+		response.StartsAt = &startsAt
+	}
+	if !promotion.EndsAt().IsZero() {
+		endsAt := promotion.EndsAt()
+		response.EndsAt = &endsAt
+	}
+	return response
+}
+
+func toResponseJobStatus(status scheduler.Status) JobStatusResponse {
+	response := JobStatusResponse{
+		Name:      status.Name,
+		Interval:  status.Interval.String(),
+		Running:   status.Running,
+		Runs:      status.Runs,
+		Failures:  status.Failures,
+		Skipped:   status.Skipped,
+		LastError: status.LastError,
+	}
+	if !status.LastRunAt.IsZero() {
+		response.LastRunAt = &status.LastRunAt
+		response.LastDuration = status.LastDuration.String()
+	}
+	if !status.NextRunAt.IsZero() {
+		response.NextRunAt = &status.NextRunAt
+	}
+	return response
+}
+
+func toResponseWebhook(subscription domain.WebhookSubscription) WebhookResponse {
+	return WebhookResponse{
+		ID:         subscription.ID(),
+		URL:        subscription.URL(),
+		EventTypes: subscription.EventTypes(),
+		Active:     subscription.Active(),
+	}
+}
+
+func toResponseWebhookDelivery(delivery domain.WebhookDelivery) WebhookDeliveryResponse {
+	response := WebhookDeliveryResponse{
+		ID:             delivery.ID(),
+		EventID:        delivery.EventID(),
+		EventType:      delivery.EventType(),
+		Status:         delivery.Status(),
+		Attempts:       delivery.Attempts(),
+		LastStatusCode: delivery.LastStatusCode(),
+		LastError:      delivery.LastError(),
+		CreatedAt:      delivery.CreatedAt(),
+		Payload:        delivery.Payload(),
+		AttemptLog:     make([]WebhookAttemptResponse, 0, len(delivery.AttemptLog())),
+	}
+	if delivery.Status() == domain.WebhookDeliveryPending {
+		nextAttemptAt := delivery.NextAttemptAt()
+		response.NextAttemptAt = &nextAttemptAt
+	}
+	for _, attempt := range delivery.AttemptLog() {
+		response.AttemptLog = append(response.AttemptLog, WebhookAttemptResponse{
+			AttemptedAt: attempt.AttemptedAt,
+			StatusCode:  attempt.StatusCode,
+			Error:       attempt.Error,
+			DurationMs:  attempt.Duration.Milliseconds(),
+		})
+	}
+	return response
+}
+
+// newWebhookSecret generates a random secret for signing webhook payloads
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
 }
 
 func getUserFromContext(ctx context.Context) (domain.User, error) {
@@ -67,3 +632,113 @@ func getUserFromContext(ctx context.Context) (domain.User, error) {
 	}
 	return user, nil
 }
+
+func toResponseInventoryMovement(movement domain.InventoryMovement) InventoryMovementResponse {
+	return InventoryMovementResponse{
+		ID:         movement.ID(),
+		BookID:     movement.BookID(),
+		Type:       movement.Type(),
+		Delta:      movement.Delta(),
+		StockAfter: movement.StockAfter(),
+		Reason:     movement.Reason(),
+		OrderID:    movement.OrderID(),
+		UserID:     movement.UserID(),
+		CreatedAt:  movement.CreatedAt(),
+	}
+}
+
+func toResponseLowStock(item domain.LowStockItem) LowStockResponse {
+	response := LowStockResponse{
+		BookID:           item.BookID,
+		Title:            item.Title,
+		Stock:            item.Stock,
+		ReorderThreshold: item.ReorderThreshold,
+		SoldRecently:     item.SoldRecently,
+		SuggestedReorder: item.SuggestedReorder(),
+	}
+	if days, ok := item.DaysOfCover(); ok {
+		response.DaysOfCover = &days
+	}
+	return response
+}
+
+func toResponseStockSubscription(subscription domain.StockSubscription) StockSubscriptionResponse {
+	response := StockSubscriptionResponse{
+		BookID:    subscription.BookID(),
+		Status:    subscription.Status(),
+		CreatedAt: subscription.CreatedAt(),
+	}
+	if notifiedAt := subscription.NotifiedAt(); !notifiedAt.IsZero() {
+		response.NotifiedAt = &notifiedAt
+	}
+	return response
+}
+
+// dateLayout is the format of dates without a time
+const dateLayout = "2006-01-02"
+
+// parseDate parses a date of a field, an empty date is the zero time
+func parseDate(field, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		var v violations
+		v.add(field, fmt.Errorf("%w: dates are formatted as YYYY-MM-DD", domain.ErrInvalidFormat))
+		return time.Time{}, v.err()
+	}
+
+	return date, nil
+}
+
+// formatDate formats a date, the zero time is empty
+func formatDate(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.Format(dateLayout)
+}
+
+// toResponseWishlist converts a wishlist, the share token is only shown to the owner
+func toResponseWishlist(wishlist domain.Wishlist, owner bool) WishlistResponse {
+	response := WishlistResponse{
+		Items: make([]WishlistItemResponse, len(wishlist.Items())),
+	}
+	for i, item := range wishlist.Items() {
+		response.Items[i] = WishlistItemResponse{
+			Book:    toResponseBook(item.Book),
+			AddedAt: item.AddedAt,
+		}
+	}
+	if owner {
+		response.ShareToken = wishlist.ShareToken()
+	}
+	return response
+}
+
+func toResponseReview(review domain.Review) ReviewResponse {
+	response := ReviewResponse{
+		ID:        review.ID(),
+		BookID:    review.BookID(),
+		UserID:    review.UserID(),
+		Rating:    review.Rating(),
+		Body:      review.Body(),
+		Status:    review.Status(),
+		Reason:    review.Reason(),
+		CreatedAt: review.CreatedAt(),
+	}
+	if moderatedAt := review.ModeratedAt(); !moderatedAt.IsZero() {
+		response.ModeratedAt = &moderatedAt
+	}
+	return response
+}
+
+func toResponseReviews(reviews []domain.Review) []ReviewResponse {
+	response := make([]ReviewResponse, 0, len(reviews))
+	for _, review := range reviews {
+		response = append(response, toResponseReview(review))
+	}
+	return response
+}
@@ -0,0 +1,45 @@
+package httpserver
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/northwindman/book-shop/internal/app/common/slugerrors"
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// violations collects every invalid field of a request instead of stopping at the first one
+type violations []slugerrors.FieldViolation
+
+func (v *violations) add(field string, err error) {
+	*v = append(*v, slugerrors.FieldViolation{Field: field, Message: err.Error()})
+}
+
+// err returns a validation SlugError listing all violations, or nil when the request is valid
+func (v violations) err() error {
+	if len(v) == 0 {
+		return nil
+	}
+
+	fields := make([]string, len(v))
+	for i, violation := range v {
+		fields[i] = violation.Field + ": " + violation.Message
+	}
+
+	return slugerrors.NewValidationError("invalid request: "+strings.Join(fields, ", "), "invalid-request", v...)
+}
+
+// validationProblem converts violated domain invariants into a validation SlugError,
+// any other error is returned unchanged
+func validationProblem(err error) error {
+	var validationErrors domain.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	var v violations
+	for _, validationError := range validationErrors {
+		v.add(validationError.Field, validationError.Err)
+	}
+	return v.err()
+}
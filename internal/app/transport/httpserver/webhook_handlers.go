@@ -0,0 +1,248 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/northwindman/book-shop/internal/app/common/server"
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// GetWebhooks returns all webhook subscriptions
+func (h HttpServer) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := h.webhookService.GetWebhooks(r.Context())
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := make([]WebhookResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		response = append(response, toResponseWebhook(subscription))
+	}
+
+	server.RespondOK(response, w, r)
+}
+
+// GetWebhook returns a webhook subscription by ID
+func (h HttpServer) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	webhookID, err := strconv.Atoi(vars["webhook_id"])
+	if err != nil {
+		server.BadRequest("invalid-webhook-id", err, w, r)
+		return
+	}
+
+	subscription, err := h.webhookService.GetWebhook(r.Context(), webhookID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("webhook-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(toResponseWebhook(subscription), w, r)
+}
+
+// CreateWebhook creates a new webhook subscription, a signing secret is generated when none is provided
+func (h HttpServer) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var webhookRequest WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&webhookRequest); err != nil {
+		server.BadRequest("invalid-json", err, w, r)
+		return
+	}
+
+	secret := webhookRequest.Secret
+	if secret == "" {
+		var err error
+		secret, err = newWebhookSecret()
+		if err != nil {
+			server.RespondWithError(err, w, r)
+			return
+		}
+	}
+
+	active := true
+	if webhookRequest.Active != nil {
+		active = *webhookRequest.Active
+	}
+
+	subscription, err := domain.NewWebhookSubscription(domain.NewWebhookSubscriptionData{
+		URL:        webhookRequest.URL,
+		Secret:     secret,
+		EventTypes: webhookRequest.EventTypes,
+		Active:     active,
+	})
+	if err != nil {
+		server.RespondWithError(validationProblem(err), w, r)
+		return
+	}
+
+	insertedSubscription, err := h.webhookService.CreateWebhook(r.Context(), subscription)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := toResponseWebhook(insertedSubscription)
+	response.Secret = insertedSubscription.Secret()
+
+	server.RespondOK(response, w, r)
+}
+
+// UpdateWebhook updates a webhook subscription by ID, the secret is kept when none is provided
+func (h HttpServer) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	webhookID, err := strconv.Atoi(vars["webhook_id"])
+	if err != nil {
+		server.BadRequest("invalid-webhook-id", err, w, r)
+		return
+	}
+
+	var webhookRequest WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&webhookRequest); err != nil {
+		server.BadRequest("invalid-json", err, w, r)
+		return
+	}
+
+	existing, err := h.webhookService.GetWebhook(r.Context(), webhookID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("webhook-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	secret := existing.Secret()
+	if webhookRequest.Secret != "" {
+		secret = webhookRequest.Secret
+	}
+
+	active := existing.Active()
+	if webhookRequest.Active != nil {
+		active = *webhookRequest.Active
+	}
+
+	subscription, err := domain.NewWebhookSubscription(domain.NewWebhookSubscriptionData{
+		ID:         webhookID,
+		URL:        webhookRequest.URL,
+		Secret:     secret,
+		EventTypes: webhookRequest.EventTypes,
+		Active:     active,
+	})
+	if err != nil {
+		server.RespondWithError(validationProblem(err), w, r)
+		return
+	}
+
+	updatedSubscription, err := h.webhookService.UpdateWebhook(r.Context(), subscription)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(toResponseWebhook(updatedSubscription), w, r)
+}
+
+// DeleteWebhook deletes a webhook subscription by ID together with its deliveries
+func (h HttpServer) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	webhookID, err := strconv.Atoi(vars["webhook_id"])
+	if err != nil {
+		server.BadRequest("invalid-webhook-id", err, w, r)
+		return
+	}
+
+	_, err = h.webhookService.GetWebhook(r.Context(), webhookID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("webhook-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	err = h.webhookService.DeleteWebhook(r.Context(), webhookID)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(map[string]bool{"deleted": true}, w, r)
+}
+
+// GetWebhookDeliveries returns the latest deliveries of a webhook subscription with their attempts
+func (h HttpServer) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	webhookID, err := strconv.Atoi(vars["webhook_id"])
+	if err != nil {
+		server.BadRequest("invalid-webhook-id", err, w, r)
+		return
+	}
+
+	_, err = h.webhookService.GetWebhook(r.Context(), webhookID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("webhook-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	// page
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit := 20
+	offset := (page - 1) * limit
+
+	deliveries, err := h.webhookService.GetDeliveries(r.Context(), webhookID, limit, offset)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := make([]WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		response = append(response, toResponseWebhookDelivery(delivery))
+	}
+
+	server.RespondOK(response, w, r)
+}
+
+// RetryWebhookDelivery moves a dead-lettered delivery back to the queue
+func (h HttpServer) RetryWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	webhookID, err := strconv.Atoi(vars["webhook_id"])
+	if err != nil {
+		server.BadRequest("invalid-webhook-id", err, w, r)
+		return
+	}
+	deliveryID, err := strconv.ParseInt(vars["delivery_id"], 10, 64)
+	if err != nil {
+		server.BadRequest("invalid-delivery-id", err, w, r)
+		return
+	}
+
+	err = h.webhookService.RetryDelivery(r.Context(), webhookID, deliveryID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("dead-delivery-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(map[string]bool{"ok": true}, w, r)
+}
@@ -0,0 +1,169 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/northwindman/book-shop/internal/app/common/server"
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// GetWishlist returns the wishlist of the current user
+func (h HttpServer) GetWishlist(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	wishlist, err := h.wishlistService.GetWishlist(r.Context(), user.ID())
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(toResponseWishlist(wishlist, true), w, r)
+}
+
+// AddWishlistItem adds a book to the wishlist of the current user, it does not reserve stock
+func (h HttpServer) AddWishlistItem(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	var itemRequest WishlistItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&itemRequest); err != nil {
+		server.BadRequest("invalid-json", err, w, r)
+		return
+	}
+	if itemRequest.BookID <= 0 {
+		var v violations
+		v.add("book_id", domain.ErrRequired)
+		server.RespondWithError(v.err(), w, r)
+		return
+	}
+
+	wishlist, err := h.wishlistService.AddItem(r.Context(), user.ID(), itemRequest.BookID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("book-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(toResponseWishlist(wishlist, true), w, r)
+}
+
+// RemoveWishlistItem removes a book from the wishlist of the current user
+func (h HttpServer) RemoveWishlistItem(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bookID, err := strconv.Atoi(vars["book_id"])
+	if err != nil {
+		server.BadRequest("invalid-book-id", err, w, r)
+		return
+	}
+
+	err = h.wishlistService.RemoveItem(r.Context(), user.ID(), bookID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("wishlist-item-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(map[string]bool{"ok": true}, w, r)
+}
+
+// MoveWishlistToCart moves books of the wishlist of the current user to the cart, all of them without book IDs.
+// Books out of stock stay on the wishlist.
+func (h HttpServer) MoveWishlistToCart(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	var moveRequest WishlistMoveRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&moveRequest); err != nil {
+			server.BadRequest("invalid-json", err, w, r)
+			return
+		}
+	}
+
+	moved, unavailable, err := h.wishlistService.MoveToCart(r.Context(), user.ID(), moveRequest.BookIDs)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := WishlistMoveResponse{
+		Moved:       append([]int{}, moved...),
+		Unavailable: append([]int{}, unavailable...),
+	}
+	server.RespondOK(response, w, r)
+}
+
+// ShareWishlist creates a public link to the wishlist of the current user, an earlier link stops working
+func (h HttpServer) ShareWishlist(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	wishlist, err := h.wishlistService.Share(r.Context(), user.ID())
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(toResponseWishlist(wishlist, true), w, r)
+}
+
+// UnshareWishlist stops sharing the wishlist of the current user
+func (h HttpServer) UnshareWishlist(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	if err := h.wishlistService.Unshare(r.Context(), user.ID()); err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(map[string]bool{"ok": true}, w, r)
+}
+
+// GetSharedWishlist returns a wishlist shared through its link, it needs no authentication
+func (h HttpServer) GetSharedWishlist(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	wishlist, err := h.wishlistService.GetSharedWishlist(r.Context(), vars["token"])
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("wishlist-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(toResponseWishlist(wishlist, false), w, r)
+}
@@ -0,0 +1,185 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+const (
+	defaultMaxAttempts = 8
+	defaultBaseBackoff = 30 * time.Second
+	defaultMaxBackoff  = 6 * time.Hour
+	defaultBatchSize   = 50
+	defaultTimeout     = 10 * time.Second
+)
+
+// Repository stores webhook subscriptions and deliveries
+type Repository interface {
+	GetSubscription(ctx context.Context, id int) (domain.WebhookSubscription, error)
+	GetSubscriptions(ctx context.Context, activeOnly bool) ([]domain.WebhookSubscription, error)
+	EnqueueDeliveries(ctx context.Context, deliveries []domain.WebhookDelivery) error
+	GetDueDeliveries(ctx context.Context, limit int) ([]domain.WebhookDelivery, error)
+	RecordDeliveryAttempt(ctx context.Context, delivery domain.WebhookDelivery, attempt domain.WebhookAttempt) error
+}
+
+// Dispatcher sends due webhook deliveries and retries failures with exponential backoff
+type Dispatcher struct {
+	repo        Repository
+	client      *http.Client
+	maxAttempts int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+	now         func() time.Time
+}
+
+// DispatcherOption configures a dispatcher
+type DispatcherOption func(d *Dispatcher)
+
+// WithHTTPClient sets the HTTP client deliveries are sent with
+func WithHTTPClient(client *http.Client) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.client = client
+	}
+}
+
+// WithRetryPolicy sets the number of attempts before a delivery is dead-lettered and the backoff bounds
+func WithRetryPolicy(maxAttempts int, baseBackoff, maxBackoff time.Duration) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.maxAttempts = maxAttempts
+		d.baseBackoff = baseBackoff
+		d.maxBackoff = maxBackoff
+	}
+}
+
+// NewDispatcher creates a new dispatcher
+func NewDispatcher(repo Repository, opts ...DispatcherOption) Dispatcher {
+	d := Dispatcher{
+		repo:        repo,
+		client:      &http.Client{Timeout: defaultTimeout},
+		maxAttempts: defaultMaxAttempts,
+		baseBackoff: defaultBaseBackoff,
+		maxBackoff:  defaultMaxBackoff,
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(&d)
+	}
+	return d
+}
+
+// Run sends all deliveries that are due
+func (d Dispatcher) Run(ctx context.Context) error {
+	deliveries, err := d.repo.GetDueDeliveries(ctx, defaultBatchSize)
+	if err != nil {
+		return err
+	}
+
+	subscriptions := map[int]domain.WebhookSubscription{}
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		subscription, ok := subscriptions[delivery.SubscriptionID()]
+		if !ok {
+			subscription, err = d.repo.GetSubscription(ctx, delivery.SubscriptionID())
+			if err != nil {
+				return fmt.Errorf("failed to get webhook subscription: %w", err)
+			}
+			subscriptions[delivery.SubscriptionID()] = subscription
+		}
+
+		if err := d.Deliver(ctx, subscription, delivery); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Deliver makes one attempt to deliver to the subscription and records its outcome
+func (d Dispatcher) Deliver(ctx context.Context, subscription domain.WebhookSubscription, delivery domain.WebhookDelivery) error {
+	attempt := d.send(ctx, subscription, delivery)
+
+	var retryAt time.Time
+	if delivery.Attempts()+1 < d.maxAttempts {
+		retryAt = attempt.AttemptedAt.Add(d.Backoff(delivery.Attempts() + 1))
+	}
+
+	return d.repo.RecordDeliveryAttempt(ctx, delivery.WithAttempt(attempt, retryAt), attempt)
+}
+
+// Backoff returns the delay before the next attempt after the given number of failed attempts
+func (d Dispatcher) Backoff(attempts int) time.Duration {
+	backoff := d.baseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= d.maxBackoff {
+			return d.maxBackoff
+		}
+	}
+	return backoff
+}
+
+func (d Dispatcher) send(ctx context.Context, subscription domain.WebhookSubscription, delivery domain.WebhookDelivery) domain.WebhookAttempt {
+	attempt := domain.WebhookAttempt{
+		DeliveryID:  delivery.ID(),
+		AttemptedAt: d.now(),
+	}
+
+	body := []byte(delivery.Payload())
+	timestamp := attempt.AttemptedAt.Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL(), bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.EventType())
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID(), 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret(), timestamp, body))
+
+	started := time.Now()
+	resp, err := d.client.Do(req)
+	attempt.Duration = time.Since(started)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	attempt.StatusCode = resp.StatusCode
+	if !attempt.Succeeded() {
+		attempt.Error = fmt.Sprintf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return attempt
+}
+
+// Payload is the JSON body of a webhook delivery
+type Payload struct {
+	ID         int64           `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+func toPayload(event domain.Event) Payload {
+	return Payload{
+		ID:         event.ID(),
+		Type:       event.Type(),
+		OccurredAt: event.OccurredAt(),
+		Data:       event.Payload(),
+	}
+}
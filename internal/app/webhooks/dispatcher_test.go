@@ -0,0 +1,166 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	subscriptions []domain.WebhookSubscription
+	deliveries    []domain.WebhookDelivery
+	attempts      []domain.WebhookAttempt
+}
+
+func (r *fakeRepo) GetSubscription(_ context.Context, id int) (domain.WebhookSubscription, error) {
+	for _, subscription := range r.subscriptions {
+		if subscription.ID() == id {
+			return subscription, nil
+		}
+	}
+	return domain.WebhookSubscription{}, domain.ErrNotFound
+}
+
+func (r *fakeRepo) GetSubscriptions(_ context.Context, _ bool) ([]domain.WebhookSubscription, error) {
+	return r.subscriptions, nil
+}
+
+func (r *fakeRepo) EnqueueDeliveries(_ context.Context, deliveries []domain.WebhookDelivery) error {
+	r.deliveries = append(r.deliveries, deliveries...)
+	return nil
+}
+
+func (r *fakeRepo) GetDueDeliveries(_ context.Context, _ int) ([]domain.WebhookDelivery, error) {
+	var due []domain.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.Status() == domain.WebhookDeliveryPending {
+			due = append(due, delivery)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeRepo) RecordDeliveryAttempt(_ context.Context, delivery domain.WebhookDelivery, attempt domain.WebhookAttempt) error {
+	for i := range r.deliveries {
+		if r.deliveries[i].EventID() == delivery.EventID() {
+			r.deliveries[i] = delivery
+		}
+	}
+	r.attempts = append(r.attempts, attempt)
+	return nil
+}
+
+func newTestSubscription(t *testing.T, url string) domain.WebhookSubscription {
+	subscription, err := domain.NewWebhookSubscription(domain.NewWebhookSubscriptionData{
+		ID:         1,
+		URL:        url,
+		Secret:     "top-secret",
+		EventTypes: []string{domain.EventCheckoutCompleted},
+		Active:     true,
+	})
+	require.NoError(t, err)
+	return subscription
+}
+
+func enqueueCheckout(t *testing.T, repo *fakeRepo) {
+	event, err := domain.NewEvent(domain.NewEventData{
+		ID:            42,
+		Type:          domain.EventCheckoutCompleted,
+		AggregateType: domain.AggregateUser,
+		AggregateID:   7,
+		Payload:       domain.CheckoutCompletedPayload{UserID: 7, OrderID: 3, BookIDs: []int{1, 2}, TotalAmount: 2500, Currency: "USD"},
+	})
+	require.NoError(t, err)
+
+	otherEvent, err := domain.NewEvent(domain.NewEventData{
+		ID:            43,
+		Type:          domain.EventBookCreated,
+		AggregateType: domain.AggregateBook,
+		AggregateID:   1,
+		Payload:       domain.BookCreatedPayload{BookID: 1},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, NewSink(repo).Publish(context.Background(), []domain.Event{event, otherEvent}))
+	require.Len(t, repo.deliveries, 1, "only subscribed event types are enqueued")
+}
+
+func TestDispatcher_DeliversSignedPayload(t *testing.T) {
+	var received Payload
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		timestamp, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		require.NoError(t, err)
+		require.True(t, Verify("top-secret", timestamp, body, r.Header.Get(HeaderSignature)))
+		require.Equal(t, domain.EventCheckoutCompleted, r.Header.Get(HeaderEvent))
+
+		require.NoError(t, json.Unmarshal(body, &received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	repo := &fakeRepo{subscriptions: []domain.WebhookSubscription{newTestSubscription(t, receiver.URL)}}
+	enqueueCheckout(t, repo)
+
+	require.NoError(t, NewDispatcher(repo).Run(context.Background()))
+
+	require.Equal(t, int64(42), received.ID)
+	require.JSONEq(t, `{"user_id":7,"order_id":3,"book_ids":[1,2],"total_amount":2500,"currency":"USD"}`, string(received.Data))
+	require.Equal(t, domain.WebhookDeliverySucceeded, repo.deliveries[0].Status())
+	require.Equal(t, 1, repo.deliveries[0].Attempts())
+	require.Len(t, repo.attempts, 1)
+	require.Equal(t, http.StatusNoContent, repo.attempts[0].StatusCode)
+}
+
+func TestDispatcher_RetriesWithBackoffAndDeadLetters(t *testing.T) {
+	calls := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	repo := &fakeRepo{subscriptions: []domain.WebhookSubscription{newTestSubscription(t, receiver.URL)}}
+	enqueueCheckout(t, repo)
+
+	dispatcher := NewDispatcher(repo, WithRetryPolicy(3, time.Second, 3*time.Second))
+
+	require.NoError(t, dispatcher.Run(context.Background()))
+	delivery := repo.deliveries[0]
+	require.Equal(t, domain.WebhookDeliveryPending, delivery.Status())
+	require.Equal(t, http.StatusInternalServerError, delivery.LastStatusCode())
+	require.Equal(t, repo.attempts[0].AttemptedAt.Add(time.Second), delivery.NextAttemptAt())
+
+	require.NoError(t, dispatcher.Run(context.Background()))
+	delivery = repo.deliveries[0]
+	require.Equal(t, domain.WebhookDeliveryPending, delivery.Status())
+	require.Equal(t, repo.attempts[1].AttemptedAt.Add(2*time.Second), delivery.NextAttemptAt())
+
+	require.NoError(t, dispatcher.Run(context.Background()))
+	require.Equal(t, domain.WebhookDeliveryDead, repo.deliveries[0].Status())
+	require.Equal(t, 3, repo.deliveries[0].Attempts())
+
+	// dead deliveries are not retried
+	require.NoError(t, dispatcher.Run(context.Background()))
+	require.Equal(t, 3, calls)
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	dispatcher := NewDispatcher(&fakeRepo{}, WithRetryPolicy(10, 30*time.Second, 5*time.Minute))
+
+	require.Equal(t, 30*time.Second, dispatcher.Backoff(1))
+	require.Equal(t, time.Minute, dispatcher.Backoff(2))
+	require.Equal(t, 4*time.Minute, dispatcher.Backoff(4))
+	require.Equal(t, 5*time.Minute, dispatcher.Backoff(5))
+	require.Equal(t, 5*time.Minute, dispatcher.Backoff(9))
+}
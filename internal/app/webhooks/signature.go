@@ -0,0 +1,33 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// Headers sent with every delivery.
+const (
+	HeaderEvent     = "X-Bookshop-Event"
+	HeaderDelivery  = "X-Bookshop-Delivery"
+	HeaderTimestamp = "X-Bookshop-Timestamp"
+	HeaderSignature = "X-Bookshop-Signature"
+
+	signaturePrefix = "sha256="
+)
+
+// Sign returns the signature header value for a payload sent at timestamp (unix seconds).
+// The HMAC-SHA256 covers "<timestamp>.<body>" so receivers can reject replayed deliveries.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is valid for the payload sent at timestamp
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
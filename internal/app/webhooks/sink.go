@@ -0,0 +1,62 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/northwindman/book-shop/internal/app/domain"
+)
+
+// Sink is an outbox sink that enqueues a delivery for every subscription matching a relayed event
+type Sink struct {
+	repo Repository
+}
+
+// NewSink creates a new webhook sink
+func NewSink(repo Repository) Sink {
+	return Sink{
+		repo: repo,
+	}
+}
+
+func (s Sink) Name() string {
+	return "webhooks"
+}
+
+func (s Sink) Publish(ctx context.Context, events []domain.Event) error {
+	subscriptions, err := s.repo.GetSubscriptions(ctx, true)
+	if err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	var deliveries []domain.WebhookDelivery
+	for _, event := range events {
+		for _, subscription := range subscriptions {
+			if !subscription.Subscribed(event.Type()) {
+				continue
+			}
+
+			payload, err := json.Marshal(toPayload(event))
+			if err != nil {
+				return fmt.Errorf("failed to marshal webhook payload: %w", err)
+			}
+
+			delivery, err := domain.NewWebhookDelivery(domain.NewWebhookDeliveryData{
+				SubscriptionID: subscription.ID(),
+				EventID:        event.ID(),
+				EventType:      event.Type(),
+				Payload:        payload,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create webhook delivery: %w", err)
+			}
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	return s.repo.EnqueueDeliveries(ctx, deliveries)
+}
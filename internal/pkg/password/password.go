@@ -0,0 +1,17 @@
+package password
+
+import "golang.org/x/crypto/bcrypt"
+
+const cost = 10
+
+// Hash returns a bcrypt hash of the password
+func Hash(password string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	return string(bytes), err
+}
+
+// Check reports whether the password matches the bcrypt hash
+func Check(password, hash string) bool {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	return err == nil
+}
@@ -0,0 +1,57 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+)
+
+// AdvisoryLocker coordinates work across instances with Postgres session-level advisory locks
+type AdvisoryLocker struct {
+	db *DB
+}
+
+// NewAdvisoryLocker creates a new advisory locker
+func NewAdvisoryLocker(db *DB) AdvisoryLocker {
+	return AdvisoryLocker{
+		db: db,
+	}
+}
+
+// TryLock tries to acquire the advisory lock for name without waiting.
+// The lock is held by a dedicated connection until release is called.
+func (l AdvisoryLocker) TryLock(ctx context.Context, name string) (release func(), acquired bool, err error) {
+	key := advisoryLockKey(name)
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get a connection: %w", err)
+	}
+
+	err = conn.NewRaw("SELECT pg_try_advisory_lock(?)", key).Scan(ctx, &acquired)
+	if err != nil {
+		_ = conn.Close()
+		return nil, false, fmt.Errorf("failed to acquire advisory lock: %w", err)
+	}
+	if !acquired {
+		_ = conn.Close()
+		return nil, false, nil
+	}
+
+	release = func() {
+		// the caller's context may already be cancelled, the lock must be released regardless
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(?)", key); err != nil {
+			log.Printf("failed to release advisory lock %q: %v", name, err)
+		}
+		_ = conn.Close()
+	}
+
+	return release, true, nil
+}
+
+func advisoryLockKey(name string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return int64(h.Sum64())
+}
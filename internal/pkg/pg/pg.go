@@ -40,3 +40,15 @@ func Dial(dsn string) (*DB, error) {
 
 	return &DB{bunDB}, nil
 }
+
+// IsUniqueViolation reports whether err is a unique constraint violation
+func IsUniqueViolation(err error) bool {
+	var pgErr pgdriver.Error
+	return errors.As(err, &pgErr) && pgErr.Field('C') == "23505"
+}
+
+// IsForeignKeyViolation reports whether err is a foreign key constraint violation
+func IsForeignKeyViolation(err error) bool {
+	var pgErr pgdriver.Error
+	return errors.As(err, &pgErr) && pgErr.Field('C') == "23503"
+}
@@ -0,0 +1,225 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Job is a periodic job run by the scheduler
+type Job struct {
+	// Name identifies the job in statuses and is used as the lock name for exclusive jobs
+	Name string
+	// Interval is the delay between two runs
+	Interval time.Duration
+	// Jitter is the maximum random delay added to every interval so replicas don't run in lockstep
+	Jitter time.Duration
+	// Exclusive jobs run on a single instance at a time, and once per interval across instances when the scheduler
+	// has a run log
+	Exclusive bool
+	// Run does the actual work
+	Run func(ctx context.Context) error
+}
+
+// Locker coordinates exclusive jobs across instances
+type Locker interface {
+	TryLock(ctx context.Context, name string) (release func(), acquired bool, err error)
+}
+
+// RunLog remembers when exclusive jobs last ran on any instance
+type RunLog interface {
+	LastRun(ctx context.Context, name string) (time.Time, error)
+	RecordRun(ctx context.Context, name string, at time.Time) error
+}
+
+// Status is a snapshot of a job's metrics and last run
+type Status struct {
+	Name         string
+	Interval     time.Duration
+	Running      bool
+	Runs         int64
+	Failures     int64
+	Skipped      int64
+	LastRunAt    time.Time
+	LastDuration time.Duration
+	LastError    string
+	NextRunAt    time.Time
+}
+
+// Scheduler runs registered periodic jobs until its context is cancelled
+type Scheduler struct {
+	locker Locker
+	runLog RunLog
+
+	mu       sync.Mutex
+	jobs     []Job
+	statuses map[string]*Status
+}
+
+// New creates a new scheduler. The locker may be nil when exclusive jobs are not used, without a run log exclusive
+// jobs run once per interval on every instance.
+func New(locker Locker, runLog RunLog) *Scheduler {
+	return &Scheduler{
+		locker:   locker,
+		runLog:   runLog,
+		statuses: map[string]*Status{},
+	}
+}
+
+// Register registers a job. Jobs must be registered before Run is called.
+func (s *Scheduler) Register(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs = append(s.jobs, job)
+	s.statuses[job.Name] = &Status{Name: job.Name, Interval: job.Interval}
+}
+
+// Run runs all registered jobs and blocks until ctx is cancelled and every running job has returned
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	jobs := append([]Job(nil), s.jobs...)
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+	wg.Wait()
+}
+
+// Statuses returns the statuses of all registered jobs sorted by name
+func (s *Scheduler) Statuses() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]Status, 0, len(s.statuses))
+	for _, status := range s.statuses {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	timer := time.NewTimer(s.nextDelay(job, true))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			notDueFor, err := s.runOnce(ctx, job)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("job %s failed: %v", job.Name, err)
+			}
+			if notDueFor > 0 {
+				// another instance ran the job recently, retry once its interval has passed
+				timer.Reset(s.delay(job, notDueFor))
+				continue
+			}
+			timer.Reset(s.nextDelay(job, false))
+		}
+	}
+}
+
+// runOnce runs the job unless another instance is running it or ran it less than an interval ago, in which case
+// it returns how long until the job is due
+func (s *Scheduler) runOnce(ctx context.Context, job Job) (time.Duration, error) {
+	exclusive := job.Exclusive && s.locker != nil
+	if exclusive {
+		release, acquired, err := s.locker.TryLock(ctx, job.Name)
+		if err != nil {
+			s.finish(job.Name, time.Now(), err)
+			return 0, err
+		}
+		if !acquired {
+			// another instance is running the job
+			s.update(job.Name, func(status *Status) { status.Skipped++ })
+			return 0, nil
+		}
+		defer release()
+	}
+
+	if exclusive && s.runLog != nil {
+		// the last run is read under the lock, so no other instance can run the job in between
+		lastRunAt, err := s.runLog.LastRun(ctx, job.Name)
+		if err != nil {
+			s.finish(job.Name, time.Now(), err)
+			return 0, err
+		}
+		if notDueFor := job.Interval - time.Since(lastRunAt); !lastRunAt.IsZero() && notDueFor > 0 {
+			s.update(job.Name, func(status *Status) { status.Skipped++ })
+			return notDueFor, nil
+		}
+	}
+
+	s.update(job.Name, func(status *Status) { status.Running = true })
+	startedAt := time.Now()
+	err := job.Run(ctx)
+	if exclusive && s.runLog != nil {
+		// failed runs are recorded too, so a failing job is not retried by every instance in turn
+		if recordErr := s.runLog.RecordRun(ctx, job.Name, startedAt); recordErr != nil {
+			err = errors.Join(err, recordErr)
+		}
+	}
+	s.finish(job.Name, startedAt, err)
+
+	return 0, err
+}
+
+func (s *Scheduler) finish(name string, startedAt time.Time, err error) {
+	s.update(name, func(status *Status) {
+		status.Running = false
+		status.Runs++
+		status.LastRunAt = startedAt
+		status.LastDuration = time.Since(startedAt)
+		status.LastError = ""
+		if err != nil {
+			status.Failures++
+			status.LastError = err.Error()
+		}
+	})
+}
+
+func (s *Scheduler) nextDelay(job Job, first bool) time.Duration {
+	delay := job.Interval
+	if first {
+		// spread the first runs of freshly started instances
+		delay = 0
+	}
+
+	return s.delay(job, delay)
+}
+
+// delay adds the job's jitter to delay and records when the job runs next
+func (s *Scheduler) delay(job Job, delay time.Duration) time.Duration {
+	if job.Jitter > 0 {
+		delay += rand.N(job.Jitter)
+	}
+
+	s.update(job.Name, func(status *Status) { status.NextRunAt = time.Now().Add(delay) })
+
+	return delay
+}
+
+func (s *Scheduler) update(name string, fn func(status *Status)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if status, ok := s.statuses[name]; ok {
+		fn(status)
+	}
+}
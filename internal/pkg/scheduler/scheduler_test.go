@@ -0,0 +1,165 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLocker struct {
+	acquired bool
+	released atomic.Int32
+}
+
+func (l *fakeLocker) TryLock(_ context.Context, _ string) (func(), bool, error) {
+	if !l.acquired {
+		return nil, false, nil
+	}
+	return func() { l.released.Add(1) }, true, nil
+}
+
+// mutexLocker is a locker shared by the schedulers of several fake instances
+type mutexLocker struct {
+	mu sync.Mutex
+}
+
+func (l *mutexLocker) TryLock(_ context.Context, _ string) (func(), bool, error) {
+	if !l.mu.TryLock() {
+		return nil, false, nil
+	}
+	return l.mu.Unlock, true, nil
+}
+
+// fakeRunLog is a run log shared by the schedulers of several fake instances
+type fakeRunLog struct {
+	mu   sync.Mutex
+	runs map[string]time.Time
+}
+
+func (l *fakeRunLog) LastRun(_ context.Context, name string) (time.Time, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.runs[name], nil
+}
+
+func (l *fakeRunLog) RecordRun(_ context.Context, name string, at time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.runs[name] = at
+	return nil
+}
+
+func TestScheduler_Run(t *testing.T) {
+	var runs atomic.Int32
+	locker := &fakeLocker{acquired: true}
+
+	s := New(locker, nil)
+	s.Register(Job{
+		Name:      "counter",
+		Interval:  time.Millisecond,
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			if runs.Add(1) == 1 {
+				return errors.New("first run fails")
+			}
+			return nil
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop after cancellation")
+	}
+
+	statuses := s.Statuses()
+	require.Len(t, statuses, 1)
+	require.Equal(t, "counter", statuses[0].Name)
+	require.False(t, statuses[0].Running)
+	require.EqualValues(t, runs.Load(), statuses[0].Runs)
+	require.EqualValues(t, 1, statuses[0].Failures)
+	require.Empty(t, statuses[0].LastError)
+	require.EqualValues(t, runs.Load(), locker.released.Load())
+}
+
+func TestScheduler_SkipsWhenLockIsHeldElsewhere(t *testing.T) {
+	var runs atomic.Int32
+
+	s := New(&fakeLocker{acquired: false}, nil)
+	s.Register(Job{
+		Name:      "exclusive",
+		Interval:  time.Millisecond,
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go s.Run(ctx)
+
+	require.Eventually(t, func() bool { return s.Statuses()[0].Skipped >= 3 }, time.Second, time.Millisecond)
+	cancel()
+
+	require.Zero(t, runs.Load())
+	require.Zero(t, s.Statuses()[0].Runs)
+}
+
+func TestScheduler_RunsOncePerIntervalAcrossInstances(t *testing.T) {
+	var runs atomic.Int32
+	locker := &mutexLocker{}
+	runLog := &fakeRunLog{runs: map[string]time.Time{}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var instances []*Scheduler
+	for i := 0; i < 3; i++ {
+		s := New(locker, runLog)
+		s.Register(Job{
+			Name:      "report",
+			Interval:  time.Hour,
+			Exclusive: true,
+			Run: func(ctx context.Context) error {
+				runs.Add(1)
+				return nil
+			},
+		})
+		instances = append(instances, s)
+		go s.Run(ctx)
+	}
+
+	require.Eventually(t, func() bool {
+		for _, s := range instances {
+			status := s.Statuses()[0]
+			if status.Runs+status.Skipped == 0 {
+				return false
+			}
+		}
+		return true
+	}, time.Second, time.Millisecond)
+	cancel()
+
+	require.EqualValues(t, 1, runs.Load())
+	for _, s := range instances {
+		status := s.Statuses()[0]
+		if status.Skipped > 0 {
+			// the skipped instance waits for the interval of the run elsewhere
+			require.WithinDuration(t, time.Now().Add(time.Hour), status.NextRunAt, time.Second)
+		}
+	}
+}